@@ -66,6 +66,12 @@ spec:
             secretKeyRef:
               name: mongo-data-secret
               key: key
+        - name: EMCO_DATA_KEYS
+          valueFrom:
+            secretKeyRef:
+              name: mongo-data-secret
+              key: keys
+              optional: true
         {{- end}}
         {{- if eq (empty .Values.global.disableDbAuth) true }}
         - name: DB_EMCO_USERNAME
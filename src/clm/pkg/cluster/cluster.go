@@ -273,7 +273,7 @@ func (v *ClusterClient) CreateCluster(ctx context.Context, provider string, p Cl
 		return Cluster{}, pkgerrors.Wrap(err, "Error creating cloud config")
 	}
 
-	if p.Spec.Props.GitOpsType != "" {
+	if p.Spec.Props.GitOpsType != "" || p.Spec.Props.DeploymentTarget != "" {
 		_, err = ccc.CreateGitOpsConfig(ctx, provider, p.Metadata.Name, p.Spec, "0", "default")
 		if err != nil {
 			return Cluster{}, pkgerrors.Wrap(err, "Error creating cloud config")
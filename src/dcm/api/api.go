@@ -75,6 +75,7 @@ func NewRouter(
 	lcRouter.HandleFunc("/logical-clouds/{logicalCloud}/status", logicalCloudHandler.statusHandler).Methods("GET")
 	lcRouter.HandleFunc("/logical-clouds/{logicalCloud}/status",
 		logicalCloudHandler.statusHandler).Queries("status", "{status}", "type", "{type}", "output", "{output}", "cluster", "{cluster}", "clusters", "{clusters}")
+	lcRouter.HandleFunc("/logical-clouds/{logicalCloud}/status/watch", logicalCloudHandler.statusWatchHandler).Methods("GET")
 
 	// Set up Cluster API
 	clusterHandler := clusterHandler{client: clusterClient}
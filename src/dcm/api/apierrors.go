@@ -31,6 +31,8 @@ var apiErrors = []apierror.APIError{
 	{ID: "Key Value not found", Message: "Key Value not found", Status: http.StatusNotFound},
 	{ID: "KV pair name mismatch", Message: "KV pair name mismatch", Status: http.StatusConflict},
 	{ID: "Logical Cloud already exists", Message: "Logical Cloud already exists", Status: http.StatusConflict},
+	{ID: "Project quota exceeded", Message: "Project quota exceeded", Status: http.StatusForbidden},
+	{ID: "is locked by another request", Message: "The project is locked by another request", Status: http.StatusConflict},
 	{ID: "Logical Cloud not found", Message: "Logical Cloud not found", Status: http.StatusNotFound},
 	{ID: "Logical Cloud name mismatch", Message: "Logical Cloud name mismatch", Status: http.StatusConflict},
 	{ID: "The Logical Cloud can't be deleted yet, it is being terminated", Message: "The Logical Cloud can't be deleted yet, it is being terminated", Status: http.StatusConflict},
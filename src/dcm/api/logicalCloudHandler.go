@@ -13,6 +13,8 @@ import (
 	"github.com/gorilla/mux"
 	dcm "gitlab.com/project-emco/core/emco-base/src/dcm/pkg/module"
 	"gitlab.com/project-emco/core/emco-base/src/orchestrator/common"
+	statusnotifypb "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/grpc/statusnotify"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/grpc/statusnotifyserver"
 	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/apierror"
 	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
 	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/validation"
@@ -530,3 +532,30 @@ func (h logicalCloudHandler) statusHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 }
+
+// statusWatchHandler streams the logical cloud status notifications as Server-Sent Events
+func (h logicalCloudHandler) statusWatchHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	qParams, err := url.ParseQuery(r.URL.RawQuery)
+	if err != nil {
+		log.Error(err.Error(), log.Fields{})
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	reg, err := statusnotifyserver.StatusRegistrationFromQuery(qParams)
+	if err != nil {
+		log.Error(err.Error(), log.Fields{})
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	reg.Key = &statusnotifypb.StatusRegistration_LcKey{
+		LcKey: &statusnotifypb.LcKey{
+			Project:      vars["project"],
+			LogicalCloud: vars["logicalCloud"],
+		},
+	}
+
+	statusnotifyserver.ServeStatusWatch(w, r, reg)
+}
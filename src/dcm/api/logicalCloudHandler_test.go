@@ -16,7 +16,6 @@ import (
 	orch_mocks "gitlab.com/project-emco/core/emco-base/src/orchestrator/api/mocks"
 	module "gitlab.com/project-emco/core/emco-base/src/orchestrator/common"
 	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/module/types"
-	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/state"
 	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/status"
 )
 
@@ -608,7 +607,7 @@ var _ = Describe("LogicalCloudHandler", func() {
 			lcStatus: status.LogicalCloudStatus{
 				Project:      "test-project",
 				LogicalCloud: "testlogicalcloud",
-				StatusResult: status.StatusResult{"logical-cloud", state.StateInfo{}, "", "", "", nil, nil, nil, nil, nil, nil},
+				StatusResult: status.StatusResult{Name: "logical-cloud"},
 				// StatusContextId: "",
 				// Actions:         nil,
 			},
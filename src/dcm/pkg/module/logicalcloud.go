@@ -15,6 +15,7 @@ import (
 	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/appcontext"
 	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/db"
 	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/quota"
 	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/state"
 	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/status"
 
@@ -66,11 +67,21 @@ func (v *LogicalCloudClient) Create(ctx context.Context, project string, c commo
 		LogicalCloudName: c.MetaData.Name,
 	}
 
+	// The quota is checked and the Logical Cloud created under the lock of the project
+	unlock, err := quota.Lock(ctx, project)
+	if err != nil {
+		return common.LogicalCloud{}, err
+	}
+	defer unlock()
+
 	//Check if this Logical Cloud already exists
-	_, err := v.Get(ctx, project, c.MetaData.Name)
+	_, err = v.Get(ctx, project, c.MetaData.Name)
 	if err == nil {
 		return common.LogicalCloud{}, pkgerrors.New("Logical Cloud already exists")
 	}
+	if err := quota.CheckProjectQuota(ctx, project, quota.LogicalClouds); err != nil {
+		return common.LogicalCloud{}, err
+	}
 
 	// if Logical Cloud Level is not specified, it defaults to 1:
 	if c.Specification.Level == "" {
@@ -142,5 +142,5 @@ func (d lcHelpers) PrepareStatusNotification(reg *statusnotifypb.StatusRegistrat
 }
 
 func StartStatusNotifyServer() *statusnotifyserver.StatusNotifyServer {
-	return statusnotifyserver.NewStatusNotifyServer("digStatus", lcHelpers{})
+	return statusnotifyserver.NewStatusNotifyServer("dcm", "digStatus", lcHelpers{})
 }
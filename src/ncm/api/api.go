@@ -81,6 +81,7 @@ func NewRouter(testClient interface{}) *mux.Router {
 	v2Router.HandleFunc("/cluster-providers/{clusterProvider}/clusters/{cluster}/status", schedulerHandler.statusSchedulerHandler).Methods("GET")
 	v2Router.HandleFunc("/cluster-providers/{clusterProvider}/clusters/{cluster}/status",
 		schedulerHandler.statusSchedulerHandler).Queries("instance", "{instance}", "status", "{status}", "type", "{type}", "output", "{output}", "app", "{app}", "cluster", "{cluster}", "resource", "{resource}")
+	v2Router.HandleFunc("/cluster-providers/{clusterProvider}/clusters/{cluster}/status/watch", schedulerHandler.statusWatchSchedulerHandler).Methods("GET")
 
 	return router
 }
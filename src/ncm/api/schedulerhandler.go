@@ -10,6 +10,8 @@ import (
 	"strings"
 
 	"gitlab.com/project-emco/core/emco-base/src/ncm/pkg/scheduler"
+	statusnotifypb "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/grpc/statusnotify"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/grpc/statusnotifyserver"
 	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/apierror"
 	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
 	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/validation"
@@ -199,3 +201,30 @@ func (h schedulerHandler) statusSchedulerHandler(w http.ResponseWriter, r *http.
 		return
 	}
 }
+
+// statusWatchSchedulerHandler streams the cluster network intents status notifications as Server-Sent Events
+func (h schedulerHandler) statusWatchSchedulerHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	qParams, err := url.ParseQuery(r.URL.RawQuery)
+	if err != nil {
+		log.Error(err.Error(), log.Fields{})
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	reg, err := statusnotifyserver.StatusRegistrationFromQuery(qParams)
+	if err != nil {
+		log.Error(err.Error(), log.Fields{})
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	reg.Key = &statusnotifypb.StatusRegistration_ClusterKey{
+		ClusterKey: &statusnotifypb.ClusterKey{
+			ClusterProvider: vars["clusterProvider"],
+			Cluster:         vars["cluster"],
+		},
+	}
+
+	statusnotifyserver.ServeStatusWatch(w, r, reg)
+}
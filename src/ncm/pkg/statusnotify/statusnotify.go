@@ -146,5 +146,5 @@ func (d clusterHelpers) PrepareStatusNotification(reg *statusnotifypb.StatusRegi
 }
 
 func StartStatusNotifyServer() *statusnotifyserver.StatusNotifyServer {
-	return statusnotifyserver.NewStatusNotifyServer("clusterStatus", clusterHelpers{})
+	return statusnotifyserver.NewStatusNotifyServer("ncm", "clusterStatus", clusterHelpers{})
 }
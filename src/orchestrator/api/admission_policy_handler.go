@@ -0,0 +1,190 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	pkgerrors "github.com/pkg/errors"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/apierror"
+	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/validation"
+	moduleLib "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/module"
+)
+
+var admissionPolicyJSONFile string = "json-schemas/admission-policy.json"
+
+// Used to store backend implementations objects
+// Also simplifies mocking for unit testing purposes
+type admissionPolicyHandler struct {
+	// Interface that implements AdmissionPolicy operations
+	// We will set this variable with a mock interface for testing
+	client moduleLib.AdmissionPolicyManager
+}
+
+// createHandler creates an admission policy. The policies of the routes without
+// a project are global.
+func (h admissionPolicyHandler) createHandler(w http.ResponseWriter, r *http.Request) {
+	var ap moduleLib.AdmissionPolicy
+	ctx := r.Context()
+	vars := mux.Vars(r)
+
+	err := json.NewDecoder(r.Body).Decode(&ap)
+	switch {
+	case err == io.EOF:
+		log.Error(err.Error(), log.Fields{})
+		http.Error(w, "Empty body", http.StatusBadRequest)
+		return
+	case err != nil:
+		log.Error(err.Error(), log.Fields{})
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	// Verify JSON Body
+	err, httpError := validation.ValidateJsonSchemaData(admissionPolicyJSONFile, ap)
+	if err != nil {
+		log.Error(err.Error(), log.Fields{})
+		http.Error(w, err.Error(), httpError)
+		return
+	}
+
+	ret, err := h.client.CreateAdmissionPolicy(ctx, vars["project"], ap, false)
+	if err != nil {
+		apiErr := apierror.HandleErrors(vars, err, ap, apiErrors)
+		http.Error(w, apiErr.Message, apiErr.Status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(ret)
+	if err != nil {
+		log.Error(err.Error(), log.Fields{})
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// updateHandler creates or updates an admission policy
+func (h admissionPolicyHandler) updateHandler(w http.ResponseWriter, r *http.Request) {
+	var ap moduleLib.AdmissionPolicy
+	ctx := r.Context()
+	vars := mux.Vars(r)
+	name := vars["admissionPolicy"]
+
+	err := json.NewDecoder(r.Body).Decode(&ap)
+	switch {
+	case err == io.EOF:
+		log.Error(err.Error(), log.Fields{})
+		http.Error(w, "Empty body", http.StatusBadRequest)
+		return
+	case err != nil:
+		log.Error(err.Error(), log.Fields{})
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	// Verify JSON Body
+	err, httpError := validation.ValidateJsonSchemaData(admissionPolicyJSONFile, ap)
+	if err != nil {
+		log.Error(err.Error(), log.Fields{})
+		http.Error(w, err.Error(), httpError)
+		return
+	}
+
+	// name in URL should match name in body
+	if ap.Metadata.Name != name {
+		log.Error("Mismatched name in PUT request", log.Fields{})
+		http.Error(w, "Mismatched name in PUT request", http.StatusBadRequest)
+		return
+	}
+
+	ret, err := h.client.CreateAdmissionPolicy(ctx, vars["project"], ap, true)
+	if err != nil {
+		apiErr := apierror.HandleErrors(vars, err, ap, apiErrors)
+		http.Error(w, apiErr.Message, apiErr.Status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(ret)
+	if err != nil {
+		log.Error(err.Error(), log.Fields{})
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// getHandler returns an admission policy, or all the admission policies
+func (h admissionPolicyHandler) getHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := mux.Vars(r)
+	name := vars["admissionPolicy"]
+	var ret interface{}
+	var err error
+
+	if len(name) == 0 {
+		ret, err = h.client.GetAdmissionPolicies(ctx, vars["project"])
+	} else {
+		ret, err = h.client.GetAdmissionPolicy(ctx, vars["project"], name)
+	}
+	if err != nil {
+		apiErr := apierror.HandleErrors(vars, err, nil, apiErrors)
+		http.Error(w, apiErr.Message, apiErr.Status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(ret)
+	if err != nil {
+		log.Error(err.Error(), log.Fields{})
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// deleteHandler removes an admission policy
+func (h admissionPolicyHandler) deleteHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := mux.Vars(r)
+
+	err := h.client.DeleteAdmissionPolicy(ctx, vars["project"], vars["admissionPolicy"])
+	if err != nil {
+		apiErr := apierror.HandleErrors(vars, err, nil, apiErrors)
+		http.Error(w, apiErr.Message, apiErr.Status)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeAdmissionReport reports the violations of the admission policies, if the
+// error is an admission denial. It returns false for the other errors.
+func writeAdmissionReport(w http.ResponseWriter, err error) bool {
+	denied, ok := pkgerrors.Cause(err).(*moduleLib.AdmissionDeniedError)
+	if !ok {
+		return false
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusForbidden)
+	if err := json.NewEncoder(w).Encode(denied.Report); err != nil {
+		log.Error(err.Error(), log.Fields{})
+	}
+	return true
+}
+
+// writeAdmissionWarnings returns the violations of the warn admission policies in
+// Warning headers, which must be set before the status is written
+func writeAdmissionWarnings(w http.ResponseWriter, warnings *moduleLib.AdmissionWarnings) {
+	for _, v := range warnings.Violations() {
+		w.Header().Add("Warning", fmt.Sprintf("299 - %q", v.Policy+": "+v.Message))
+	}
+}
@@ -32,12 +32,14 @@ func NewRouter(projectClient moduleLib.ProjectManager,
 	moduleClient = moduleLib.NewClient()
 
 	//setting routes for project
+	var projectQuotaClient moduleLib.ProjectQuotaManager
 	if projectClient == nil {
 		projectClient = moduleClient.Project
-
+		projectQuotaClient = moduleClient.ProjectQuota
 	}
 	projHandler := projectHandler{
-		client: projectClient,
+		client:      projectClient,
+		quotaClient: projectQuotaClient,
 	}
 	if ControllerClient == nil {
 		ControllerClient = moduleClient.Controller
@@ -51,6 +53,45 @@ func NewRouter(projectClient moduleLib.ProjectManager,
 	v2Router.HandleFunc("/projects", projHandler.getHandler).Methods("GET")
 	v2Router.HandleFunc("/projects/{project}", projHandler.deleteHandler).Methods("DELETE")
 
+	quotaHandler := projectQuotaHandler{
+		client: moduleClient.ProjectQuota,
+	}
+	v2Router.HandleFunc("/projects/{project}/quotas", quotaHandler.createHandler).Methods("POST")
+	v2Router.HandleFunc("/projects/{project}/quotas", quotaHandler.updateHandler).Methods("PUT")
+	v2Router.HandleFunc("/projects/{project}/quotas", quotaHandler.getHandler).Methods("GET")
+	v2Router.HandleFunc("/projects/{project}/quotas", quotaHandler.deleteHandler).Methods("DELETE")
+
+	admissionHandler := admissionPolicyHandler{
+		client: moduleClient.AdmissionPolicy,
+	}
+	v2Router.HandleFunc("/admission-policies", admissionHandler.createHandler).Methods("POST")
+	v2Router.HandleFunc("/admission-policies", admissionHandler.getHandler).Methods("GET")
+	v2Router.HandleFunc("/admission-policies/{admissionPolicy}", admissionHandler.updateHandler).Methods("PUT")
+	v2Router.HandleFunc("/admission-policies/{admissionPolicy}", admissionHandler.getHandler).Methods("GET")
+	v2Router.HandleFunc("/admission-policies/{admissionPolicy}", admissionHandler.deleteHandler).Methods("DELETE")
+	v2Router.HandleFunc("/projects/{project}/admission-policies", admissionHandler.createHandler).Methods("POST")
+	v2Router.HandleFunc("/projects/{project}/admission-policies", admissionHandler.getHandler).Methods("GET")
+	v2Router.HandleFunc("/projects/{project}/admission-policies/{admissionPolicy}", admissionHandler.updateHandler).Methods("PUT")
+	v2Router.HandleFunc("/projects/{project}/admission-policies/{admissionPolicy}", admissionHandler.getHandler).Methods("GET")
+	v2Router.HandleFunc("/projects/{project}/admission-policies/{admissionPolicy}", admissionHandler.deleteHandler).Methods("DELETE")
+
+	approvalHandler := approvalHandler{
+		client: moduleClient.Approval,
+	}
+	v2Router.HandleFunc("/projects/{project}/approval-policy", approvalHandler.putPolicyHandler).Methods("POST", "PUT")
+	v2Router.HandleFunc("/projects/{project}/approval-policy", approvalHandler.getPolicyHandler).Methods("GET")
+	v2Router.HandleFunc("/projects/{project}/approval-policy", approvalHandler.deletePolicyHandler).Methods("DELETE")
+	v2Router.HandleFunc("/projects/{project}/composite-apps/{compositeApp}/{compositeAppVersion}/deployment-intent-groups/{deploymentIntentGroup}/approval-policy", approvalHandler.putPolicyHandler).Methods("POST", "PUT")
+	v2Router.HandleFunc("/projects/{project}/composite-apps/{compositeApp}/{compositeAppVersion}/deployment-intent-groups/{deploymentIntentGroup}/approval-policy", approvalHandler.getPolicyHandler).Methods("GET")
+	v2Router.HandleFunc("/projects/{project}/composite-apps/{compositeApp}/{compositeAppVersion}/deployment-intent-groups/{deploymentIntentGroup}/approval-policy", approvalHandler.deletePolicyHandler).Methods("DELETE")
+	v2Router.HandleFunc("/projects/{project}/composite-apps/{compositeApp}/{compositeAppVersion}/deployment-intent-groups/{deploymentIntentGroup}/approvals", approvalHandler.getApprovalsHandler).Methods("GET")
+
+	queueHandler := queueHandler{
+		client: moduleClient.Queue,
+	}
+	v2Router.HandleFunc("/projects/{project}/composite-apps/{compositeApp}/{compositeAppVersion}/deployment-intent-groups/{deploymentIntentGroup}/operations/queue", queueHandler.getHandler).Methods("GET")
+	v2Router.HandleFunc("/projects/{project}/composite-apps/{compositeApp}/{compositeAppVersion}/deployment-intent-groups/{deploymentIntentGroup}/operations/queue/cancel", queueHandler.cancelHandler).Methods("POST")
+
 	//setting routes for compositeApp
 	if compositeAppClient == nil {
 		compositeAppClient = moduleClient.CompositeApp
@@ -186,6 +227,7 @@ func NewRouter(projectClient moduleLib.ProjectManager,
 	v2Router.HandleFunc("/projects/{project}/composite-apps/{compositeApp}/{compositeAppVersion}/deployment-intent-groups/{deploymentIntentGroup}/approve", instantiationHandler.approveHandler).Methods("POST")
 	v2Router.HandleFunc("/projects/{project}/composite-apps/{compositeApp}/{compositeAppVersion}/deployment-intent-groups/{deploymentIntentGroup}/terminate", instantiationHandler.terminateHandler).Methods("POST")
 	v2Router.HandleFunc("/projects/{project}/composite-apps/{compositeApp}/{compositeAppVersion}/deployment-intent-groups/{deploymentIntentGroup}/instantiate", instantiationHandler.instantiateHandler).Methods("POST")
+	v2Router.HandleFunc("/projects/{project}/composite-apps/{compositeApp}/{compositeAppVersion}/deployment-intent-groups/{deploymentIntentGroup}/validate", instantiationHandler.validateHandler).Methods("POST")
 	v2Router.HandleFunc("/projects/{project}/composite-apps/{compositeApp}/{compositeAppVersion}/deployment-intent-groups/{deploymentIntentGroup}/stop", instantiationHandler.stopHandler).Methods("POST")
 	v2Router.HandleFunc("/projects/{project}/composite-apps/{compositeApp}/{compositeAppVersion}/deployment-intent-groups/{deploymentIntentGroup}/status", instantiationHandler.statusHandler).Methods("GET")
 	v2Router.HandleFunc("/projects/{project}/composite-apps/{compositeApp}/{compositeAppVersion}/deployment-intent-groups/{deploymentIntentGroup}/status",
@@ -199,7 +241,9 @@ func NewRouter(projectClient moduleLib.ProjectManager,
 		"resource", "{resource}",
 		"apps", "{apps}",
 		"clusters", "{clusters}",
-		"resources", "{resources}")
+		"resources", "{resources}",
+		"drifted", "{drifted}")
+	v2Router.HandleFunc("/projects/{project}/composite-apps/{compositeApp}/{compositeAppVersion}/deployment-intent-groups/{deploymentIntentGroup}/status/watch", instantiationHandler.statusWatchHandler).Methods("GET")
 
 	// setting routes for Update
 	updateHandler := updateHandler{
@@ -223,5 +267,18 @@ func NewRouter(projectClient moduleLib.ProjectManager,
 	v2Router.HandleFunc("/projects/{project}/composite-apps/{compositeApp}/{compositeAppVersion}/apps/{app}/dependency", appDependencyHandler.getAllAppDependencyHandler).Methods("GET")
 	v2Router.HandleFunc("/projects/{project}/composite-apps/{compositeApp}/{compositeAppVersion}/apps/{app}/dependency/{dependency}", appDependencyHandler.deleteappDependencyHandler).Methods("DELETE")
 
+	backupHandler := backupHandler{
+		client: moduleClient.Backup,
+	}
+	v2Router.HandleFunc("/backup", backupHandler.backupHandler).Methods("GET")
+	v2Router.HandleFunc("/restore", backupHandler.restoreHandler).Methods("POST")
+	v2Router.HandleFunc("/migrations", migrationsHandler).Methods("GET")
+
+	dataKeyHandler := dataKeyHandler{
+		client: moduleClient.DataKey,
+	}
+	v2Router.HandleFunc("/data-keys/rotation", dataKeyHandler.startRotationHandler).Methods("POST")
+	v2Router.HandleFunc("/data-keys/rotation", dataKeyHandler.getRotationHandler).Methods("GET")
+
 	return router
 }
@@ -31,6 +31,40 @@ var apiErrors = []apierror.APIError{
 	{ID: "Controller already exists", Message: "Controller already exists", Status: http.StatusConflict},
 	{ID: "The DeploymentIntentGroup is not updated", Message: "The specified DeploymentIntentGroup is not in Created status", Status: http.StatusConflict},
 	{ID: "AppDependency not found", Message: "AppDependency not found", Status: http.StatusNotFound},
+	{ID: "Project quota exceeded", Message: "Project quota exceeded", Status: http.StatusForbidden},
+	{ID: "is locked by another request", Message: "The project is locked by another request", Status: http.StatusConflict},
+	{ID: "ProjectQuota already exists", Message: "ProjectQuota already exists", Status: http.StatusConflict},
+	{ID: "ProjectQuota not found", Message: "ProjectQuota not found", Status: http.StatusNotFound},
+	{ID: "is locked by another lifecycle operation", Message: "DeploymentIntentGroup is locked by another lifecycle operation", Status: http.StatusConflict},
+	{ID: "AdmissionPolicy already exists", Message: "AdmissionPolicy already exists", Status: http.StatusConflict},
+	{ID: "AdmissionPolicy not found", Message: "AdmissionPolicy not found", Status: http.StatusNotFound},
+	{ID: "Invalid AdmissionPolicy", Message: "Invalid AdmissionPolicy", Status: http.StatusBadRequest},
+	{ID: "ApprovalPolicy already exists", Message: "ApprovalPolicy already exists", Status: http.StatusConflict},
+	{ID: "ApprovalPolicy not found", Message: "ApprovalPolicy not found", Status: http.StatusNotFound},
+	{ID: "Invalid ApprovalPolicy", Message: "Invalid ApprovalPolicy", Status: http.StatusBadRequest},
+	{ID: "DeploymentIntentGroup approvals are insufficient", Message: "DeploymentIntentGroup approvals are insufficient", Status: http.StatusForbidden},
+	{ID: "AppContext not found for the DeploymentIntentGroup", Message: "AppContext not found for the DeploymentIntentGroup", Status: http.StatusNotFound},
+	{ID: "Event not found in the AppContext queue", Message: "Event not found in the AppContext queue", Status: http.StatusNotFound},
+	{ID: "Event is not pending", Message: "Event is not pending", Status: http.StatusConflict},
+	{ID: "Error getting the AppContext queue from rsync", Message: "Error getting the AppContext queue from rsync", Status: http.StatusServiceUnavailable},
+	{ID: "Error validating the resources in rsync", Message: "Error validating the resources in rsync", Status: http.StatusServiceUnavailable},
+}
+
+var backupErrors = []apierror.APIError{
+	{ID: "A backup or restore is in progress", Message: "A backup or restore is in progress", Status: http.StatusConflict},
+	{ID: "The resources kept changing during the backup", Message: "The resources kept changing during the backup, try again later", Status: http.StatusServiceUnavailable},
+	{ID: "Invalid backup archive", Message: "Invalid backup archive", Status: http.StatusUnprocessableEntity},
+	{ID: "Unsupported backup format version", Message: "Unsupported backup format version", Status: http.StatusUnprocessableEntity},
+	{ID: "cannot be restored to a", Message: "The backup was created with a different database type", Status: http.StatusUnprocessableEntity},
+	{ID: "which is newer than version", Message: "The backup was created by a newer version of EMCO", Status: http.StatusUnprocessableEntity},
+	{ID: "Invalid kubeconfig of cluster", Message: "Invalid kubeconfig, the kubeconfigs must be base64 encoded", Status: http.StatusUnprocessableEntity},
+	{ID: "does not support backup and restore", Message: "The database does not support backup and restore", Status: http.StatusNotImplemented},
+}
+
+var dataKeyErrors = []apierror.APIError{
+	{ID: "A data key rotation is already running", Message: "A data key rotation is already running", Status: http.StatusConflict},
+	{ID: "Data encryption is not configured", Message: "Data encryption is not configured", Status: http.StatusConflict},
+	{ID: "No data key rotation found", Message: "No data key rotation found", Status: http.StatusNotFound},
 }
 
 var lcErrors = []apierror.APIError{
@@ -0,0 +1,174 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/apierror"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/config"
+	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/validation"
+	moduleLib "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/module"
+)
+
+var approvalPolicyJSONFile string = "json-schemas/approval-policy.json"
+var approvalJSONFile string = "json-schemas/approval.json"
+
+// Used to store backend implementations objects
+// Also simplifies mocking for unit testing purposes
+type approvalHandler struct {
+	// Interface that implements Approval operations
+	// We will set this variable with a mock interface for testing
+	client moduleLib.ApprovalManager
+}
+
+// putPolicyHandler creates or replaces the approval policy of a project, or of a
+// deployment intent group when the route has one
+func (h approvalHandler) putPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	var ap moduleLib.ApprovalPolicy
+	ctx := r.Context()
+	vars := mux.Vars(r)
+
+	err := json.NewDecoder(r.Body).Decode(&ap)
+	switch {
+	case err == io.EOF:
+		log.Error(err.Error(), log.Fields{})
+		http.Error(w, "Empty body", http.StatusBadRequest)
+		return
+	case err != nil:
+		log.Error(err.Error(), log.Fields{})
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	// Verify JSON Body
+	err, httpError := validation.ValidateJsonSchemaData(approvalPolicyJSONFile, ap)
+	if err != nil {
+		log.Error(err.Error(), log.Fields{})
+		http.Error(w, err.Error(), httpError)
+		return
+	}
+
+	ret, err := h.client.CreateApprovalPolicy(ctx, vars["project"], vars["compositeApp"], vars["compositeAppVersion"],
+		vars["deploymentIntentGroup"], ap, r.Method == http.MethodPut)
+	if err != nil {
+		apiErr := apierror.HandleErrors(vars, err, ap, apiErrors)
+		http.Error(w, apiErr.Message, apiErr.Status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if r.Method == http.MethodPut {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusCreated)
+	}
+	err = json.NewEncoder(w).Encode(ret)
+	if err != nil {
+		log.Error(err.Error(), log.Fields{})
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// getPolicyHandler returns the approval policy of a project or deployment intent group
+func (h approvalHandler) getPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := mux.Vars(r)
+
+	ret, err := h.client.GetApprovalPolicy(ctx, vars["project"], vars["compositeApp"], vars["compositeAppVersion"], vars["deploymentIntentGroup"])
+	if err != nil {
+		apiErr := apierror.HandleErrors(vars, err, nil, apiErrors)
+		http.Error(w, apiErr.Message, apiErr.Status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(ret)
+	if err != nil {
+		log.Error(err.Error(), log.Fields{})
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// deletePolicyHandler removes the approval policy of a project or deployment intent group
+func (h approvalHandler) deletePolicyHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := mux.Vars(r)
+
+	err := h.client.DeleteApprovalPolicy(ctx, vars["project"], vars["compositeApp"], vars["compositeAppVersion"], vars["deploymentIntentGroup"])
+	if err != nil {
+		apiErr := apierror.HandleErrors(vars, err, nil, apiErrors)
+		http.Error(w, apiErr.Message, apiErr.Status)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getApprovalsHandler returns the approvals of a deployment intent group
+func (h approvalHandler) getApprovalsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := mux.Vars(r)
+
+	ret, err := h.client.GetApprovals(ctx, vars["project"], vars["compositeApp"], vars["compositeAppVersion"], vars["deploymentIntentGroup"])
+	if err != nil {
+		apiErr := apierror.HandleErrors(vars, err, nil, apiErrors)
+		http.Error(w, apiErr.Message, apiErr.Status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(ret)
+	if err != nil {
+		log.Error(err.Error(), log.Fields{})
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// approvalRequest returns the approval of the request. The approver and its groups
+// are read from the claims of the bearer token, verified with the configured JWKS.
+func approvalRequest(r *http.Request) (moduleLib.ApprovalRequest, error, int) {
+	req := moduleLib.ApprovalRequest{}
+	if r.Body != nil {
+		err := json.NewDecoder(r.Body).Decode(&req)
+		switch {
+		case err == io.EOF:
+			// The comment is optional
+		case err != nil:
+			return req, err, http.StatusUnprocessableEntity
+		default:
+			if err, httpError := validation.ValidateJsonSchemaData(approvalJSONFile, req); err != nil {
+				return req, err, httpError
+			}
+		}
+	}
+
+	claims := tokenClaims(r)
+	cfg := config.GetConfiguration()
+	if s, ok := claims[cfg.ApproverClaim].(string); ok {
+		req.Approver = s
+	} else if s, ok := claims["sub"].(string); ok {
+		req.Approver = s
+	}
+	switch groups := claims[cfg.ApproverGroupsClaim].(type) {
+	case string:
+		req.Groups = []string{groups}
+	case []interface{}:
+		for _, g := range groups {
+			if s, ok := g.(string); ok {
+				req.Groups = append(req.Groups, s)
+			}
+		}
+	}
+	return req, nil, http.StatusOK
+}
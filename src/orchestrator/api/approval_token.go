@@ -0,0 +1,180 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package api
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/config"
+	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+)
+
+// The JWKS is fetched again for an unknown key at most once per interval
+const jwksRefreshInterval = time.Minute
+
+// jwks caches the keys verifying the bearer tokens of the approvers
+type jwks struct {
+	url     string
+	keys    map[string]*rsa.PublicKey
+	fetched time.Time
+	client  *http.Client
+	sync.Mutex
+}
+
+var approverKeys = &jwks{client: &http.Client{Timeout: 10 * time.Second}}
+
+// key returns the key with the ID, fetching the JWKS if the key is unknown
+func (j *jwks) key(url, kid string) (*rsa.PublicKey, error) {
+	j.Lock()
+	defer j.Unlock()
+	if j.url != url {
+		j.url, j.keys, j.fetched = url, nil, time.Time{}
+	}
+	if k, ok := j.keys[kid]; ok {
+		return k, nil
+	}
+	if time.Since(j.fetched) < jwksRefreshInterval {
+		return nil, pkgerrors.Errorf("Unknown token key %s", kid)
+	}
+	j.fetched = time.Now()
+	keys, err := j.fetch(url)
+	if err != nil {
+		return nil, err
+	}
+	j.keys = keys
+	if k, ok := j.keys[kid]; ok {
+		return k, nil
+	}
+	return nil, pkgerrors.Errorf("Unknown token key %s", kid)
+}
+
+// fetch reads the RSA keys of the JWKS
+func (j *jwks) fetch(url string) (map[string]*rsa.PublicKey, error) {
+	resp, err := j.client.Get(url)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "Error fetching the JWKS")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, pkgerrors.Errorf("Error fetching the JWKS: %s", resp.Status)
+	}
+	set := struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, pkgerrors.Wrap(err, "Invalid JWKS")
+	}
+	keys := map[string]*rsa.PublicKey{}
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			continue
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			continue
+		}
+		keys[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+	return keys, nil
+}
+
+// verifyToken verifies the RS256 signature of the JWT with the keys of the JWKS,
+// its expiry and its issuer, and returns its claims
+func verifyToken(token, url, issuer string) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, pkgerrors.New("Invalid token")
+	}
+	header := struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}{}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+	if header.Alg != "RS256" {
+		return nil, pkgerrors.Errorf("Unsupported token algorithm %s", header.Alg)
+	}
+	key, err := approverKeys.key(url, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "Invalid token signature")
+	}
+	sum := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, sum[:], sig); err != nil {
+		return nil, pkgerrors.Wrap(err, "Invalid token signature")
+	}
+
+	claims := map[string]interface{}{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	now := float64(time.Now().Unix())
+	exp, ok := claims["exp"].(float64)
+	if !ok || exp <= now {
+		return nil, pkgerrors.New("Token expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && nbf > now {
+		return nil, pkgerrors.New("Token not valid yet")
+	}
+	if issuer != "" && claims["iss"] != issuer {
+		return nil, pkgerrors.Errorf("Unexpected token issuer %v", claims["iss"])
+	}
+	return claims, nil
+}
+
+func decodeSegment(s string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return pkgerrors.Wrap(err, "Invalid token")
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return pkgerrors.Wrap(err, "Invalid token")
+	}
+	return nil
+}
+
+// tokenClaims returns the claims of the bearer token of the request, if any.
+// The token is only trusted once verified with the configured JWKS, the request
+// has no claims if the JWKS is not configured.
+func tokenClaims(r *http.Request) map[string]interface{} {
+	claims := map[string]interface{}{}
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		return claims
+	}
+	cfg := config.GetConfiguration()
+	if cfg.ApproverJwksURL == "" {
+		log.Warn("The bearer token is not verified without approver-jwks-url", log.Fields{})
+		return claims
+	}
+	verified, err := verifyToken(strings.TrimSpace(strings.TrimPrefix(auth, "Bearer ")), cfg.ApproverJwksURL, cfg.ApproverIssuer)
+	if err != nil {
+		log.Warn("Invalid bearer token", log.Fields{"error": err.Error()})
+		return claims
+	}
+	return verified
+}
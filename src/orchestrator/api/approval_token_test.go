@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package api
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/config"
+)
+
+// signToken returns the RS256 JWT of the claims signed with the key
+func signToken(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": kid})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	sum := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, sum[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestTokenClaims(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "key1",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	}))
+	defer server.Close()
+
+	cfg := config.GetConfiguration()
+	savedURL, savedIssuer := cfg.ApproverJwksURL, cfg.ApproverIssuer
+	defer func() { cfg.ApproverJwksURL, cfg.ApproverIssuer = savedURL, savedIssuer }()
+	cfg.ApproverJwksURL, cfg.ApproverIssuer = server.URL, "https://issuer"
+
+	exp := time.Now().Add(time.Hour).Unix()
+	valid := map[string]interface{}{"iss": "https://issuer", "exp": exp, "preferred_username": "alice"}
+	testCases := []struct {
+		label    string
+		token    string
+		approver string
+	}{
+		{
+			label:    "Verified token",
+			token:    signToken(t, key, "key1", valid),
+			approver: "alice",
+		},
+		{
+			label: "Forged token",
+			token: signToken(t, other, "key1", valid),
+		},
+		{
+			label: "Unknown key",
+			token: signToken(t, key, "key2", valid),
+		},
+		{
+			label: "Expired token",
+			token: signToken(t, key, "key1", map[string]interface{}{"iss": "https://issuer", "exp": time.Now().Add(-time.Minute).Unix(), "preferred_username": "alice"}),
+		},
+		{
+			label: "Token without expiry",
+			token: signToken(t, key, "key1", map[string]interface{}{"iss": "https://issuer", "preferred_username": "alice"}),
+		},
+		{
+			label: "Other issuer",
+			token: signToken(t, key, "key1", map[string]interface{}{"iss": "https://other", "exp": exp, "preferred_username": "alice"}),
+		},
+		{
+			label: "Unsigned token",
+			token: base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." +
+				base64.RawURLEncoding.EncodeToString([]byte(`{"preferred_username":"alice"}`)) + ".",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/", nil)
+			r.Header.Set("Authorization", "Bearer "+testCase.token)
+			if approver, _ := tokenClaims(r)["preferred_username"].(string); approver != testCase.approver {
+				t.Errorf("Unexpected approver %q, expected %q", approver, testCase.approver)
+			}
+		})
+	}
+
+	// The token is not trusted without the JWKS
+	cfg.ApproverJwksURL = ""
+	r := httptest.NewRequest("POST", "/", nil)
+	r.Header.Set("Authorization", "Bearer "+signToken(t, key, "key1", valid))
+	if len(tokenClaims(r)) != 0 {
+		t.Error("Claims of a token not verified")
+	}
+}
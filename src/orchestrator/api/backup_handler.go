@@ -0,0 +1,102 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/apierror"
+	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+	moduleLib "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/module"
+)
+
+// Used to store backend implementations objects
+// Also simplifies mocking for unit testing purposes
+type backupHandler struct {
+	// Interface that implements backup and restore operations
+	// We will set this variable with a mock interface for testing
+	client moduleLib.BackupManager
+}
+
+// backupHandler returns a gzipped tar archive of the EMCO resources and AppContexts
+// curl -o emco-backup.tar.gz http://localhost:9015/v2/backup
+func (h backupHandler) backupHandler(w http.ResponseWriter, r *http.Request) {
+	var buf bytes.Buffer
+
+	// The archive is built before it is sent, so that an error can still be returned
+	manifest, err := h.client.Backup(r.Context(), &buf)
+	if err != nil {
+		apiErr := apierror.HandleErrors(mux.Vars(r), err, nil, backupErrors)
+		http.Error(w, apiErr.Message, apiErr.Status)
+		return
+	}
+
+	name := fmt.Sprintf("emco-backup-%s.tar.gz", manifest.CreatedAt.Format("20060102T150405Z"))
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", "attachment; filename="+name)
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.WriteHeader(http.StatusOK)
+	_, err = buf.WriteTo(w)
+	if err != nil {
+		log.Error(err.Error(), log.Fields{})
+	}
+}
+
+// restoreHandler restores a backup archive
+// This is a multipart handler. See following example curl request
+// curl -X POST http://localhost:9015/v2/restore \
+// -F "metadata={\"kubeconfigs\":[{\"clusterProvider\":\"provider1\",\"cluster\":\"cluster1\",\"kubeconfig\":\"<base64>\"}]};type=application/json" \
+// -F file=@/pathToBackupFile
+func (h backupHandler) restoreHandler(w http.ResponseWriter, r *http.Request) {
+	var options moduleLib.RestoreOptions
+
+	err := r.ParseMultipartForm(maxMemory)
+	if err != nil {
+		log.Error(err.Error(), log.Fields{})
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	// The metadata is optional
+	if metadata := r.FormValue("metadata"); metadata != "" {
+		err = json.Unmarshal([]byte(metadata), &options)
+		if err != nil {
+			log.Error(err.Error(), log.Fields{})
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		log.Error(err.Error(), log.Fields{})
+		http.Error(w, "Unable to process file", http.StatusUnprocessableEntity)
+		return
+	}
+	defer file.Close()
+
+	start := time.Now()
+	manifest, err := h.client.Restore(r.Context(), file, options)
+	if err != nil {
+		apiErr := apierror.HandleErrors(mux.Vars(r), err, nil, backupErrors)
+		http.Error(w, apiErr.Message, apiErr.Status)
+		return
+	}
+	log.Info("Restore completed", log.Fields{"duration": time.Since(start).String()})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(manifest)
+	if err != nil {
+		log.Error(err.Error(), log.Fields{})
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
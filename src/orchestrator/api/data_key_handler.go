@@ -0,0 +1,62 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/apierror"
+	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+	moduleLib "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/module"
+)
+
+// Used to store backend implementations objects
+// Also simplifies mocking for unit testing purposes
+type dataKeyHandler struct {
+	// Interface that implements the data key rotation operations
+	// We will set this variable with a mock interface for testing
+	client moduleLib.DataKeyManager
+}
+
+// startRotationHandler starts the re-encryption of the encrypted fields with the active data key
+// curl -X POST http://localhost:9015/v2/data-keys/rotation
+func (h dataKeyHandler) startRotationHandler(w http.ResponseWriter, r *http.Request) {
+	report, err := h.client.StartRotation()
+	if err != nil {
+		apiErr := apierror.HandleErrors(mux.Vars(r), err, nil, dataKeyErrors)
+		http.Error(w, apiErr.Message, apiErr.Status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	err = json.NewEncoder(w).Encode(report)
+	if err != nil {
+		log.Error(err.Error(), log.Fields{})
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// getRotationHandler returns the progress of the last data key rotation
+// curl http://localhost:9015/v2/data-keys/rotation
+func (h dataKeyHandler) getRotationHandler(w http.ResponseWriter, r *http.Request) {
+	report, err := h.client.GetRotation()
+	if err != nil {
+		apiErr := apierror.HandleErrors(mux.Vars(r), err, nil, dataKeyErrors)
+		http.Error(w, apiErr.Message, apiErr.Status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(report)
+	if err != nil {
+		log.Error(err.Error(), log.Fields{})
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
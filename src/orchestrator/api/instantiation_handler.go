@@ -11,10 +11,13 @@ import (
 
 	"github.com/gorilla/mux"
 	pkgerrors "github.com/pkg/errors"
+	statusnotifypb "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/grpc/statusnotify"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/grpc/statusnotifyserver"
 	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/apierror"
 	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
 	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/validation"
 	moduleLib "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/module"
+	statusLib "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/status"
 )
 
 /* Used to store backend implementation objects
@@ -33,9 +36,28 @@ func (h instantiationHandler) approveHandler(w http.ResponseWriter, r *http.Requ
 	v := vars["compositeAppVersion"]
 	di := vars["deploymentIntentGroup"]
 
-	iErr := h.client.Approve(ctx, p, ca, v, di)
+	req, err, httpError := approvalRequest(r)
+	if err != nil {
+		log.Error(err.Error(), log.Fields{})
+		http.Error(w, err.Error(), httpError)
+		return
+	}
+
+	iErr := h.client.Approve(ctx, p, ca, v, di, req)
 	if iErr != nil {
 		log.Error(iErr.Error(), log.Fields{})
+		if strings.Contains(iErr.Error(), "is locked by another lifecycle operation") {
+			http.Error(w, iErr.Error(), http.StatusConflict)
+			return
+		}
+		if strings.Contains(iErr.Error(), "Approver identity is required") {
+			http.Error(w, iErr.Error(), http.StatusUnauthorized)
+			return
+		}
+		if strings.Contains(iErr.Error(), "is not an allowed approver") {
+			http.Error(w, iErr.Error(), http.StatusForbidden)
+			return
+		}
 		http.Error(w, iErr.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -44,7 +66,7 @@ func (h instantiationHandler) approveHandler(w http.ResponseWriter, r *http.Requ
 }
 
 func (h instantiationHandler) instantiateHandler(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+	ctx, warnings := moduleLib.WithAdmissionWarnings(r.Context())
 	vars := mux.Vars(r)
 	p := vars["project"]
 	ca := vars["compositeApp"]
@@ -54,6 +76,9 @@ func (h instantiationHandler) instantiateHandler(w http.ResponseWriter, r *http.
 	iErr := h.client.Instantiate(ctx, p, ca, v, di)
 	if iErr != nil {
 		log.Error(":: Error instantiate handler ::", log.Fields{"Error": iErr.Error(), "project": p, "compositeApp": ca, "compositeAppVer": v, "depGroup": di})
+		if writeAdmissionReport(w, iErr) {
+			return
+		}
 		apiErr := apierror.HandleLogicalCloudErrors(vars, iErr, lcErrors)
 		if (apiErr == apierror.APIError{}) {
 			// There are no logical cloud error(s). Check for api specific error(s)
@@ -67,9 +92,38 @@ func (h instantiationHandler) instantiateHandler(w http.ResponseWriter, r *http.
 		return
 	}
 	log.Info("instantiateHandler ... end ", log.Fields{"project": p, "compositeApp": ca, "compositeAppVer": v, "depGroup": di, "returnValue": iErr})
+	writeAdmissionWarnings(w, warnings)
 	w.WriteHeader(http.StatusAccepted)
 }
 
+func (h instantiationHandler) validateHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := mux.Vars(r)
+	p := vars["project"]
+	ca := vars["compositeApp"]
+	v := vars["compositeAppVersion"]
+	di := vars["deploymentIntentGroup"]
+
+	report, err := h.client.Validate(ctx, p, ca, v, di)
+	if err != nil {
+		log.Error(":: Error validate handler ::", log.Fields{"Error": err.Error(), "project": p, "compositeApp": ca, "compositeAppVer": v, "depGroup": di})
+		apiErr := apierror.HandleLogicalCloudErrors(vars, err, lcErrors)
+		if (apiErr == apierror.APIError{}) {
+			// There are no logical cloud error(s). Check for api specific error(s)
+			apiErr = apierror.HandleErrors(vars, err, nil, apiErrors)
+		}
+		http.Error(w, apiErr.Message, apiErr.Status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		log.Error(err.Error(), log.Fields{})
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
 func (h instantiationHandler) terminateHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	vars := mux.Vars(r)
@@ -81,6 +135,10 @@ func (h instantiationHandler) terminateHandler(w http.ResponseWriter, r *http.Re
 	iErr := h.client.Terminate(ctx, p, ca, v, di)
 	if iErr != nil {
 		log.Error(iErr.Error(), log.Fields{})
+		if strings.Contains(iErr.Error(), "is locked by another lifecycle operation") {
+			http.Error(w, iErr.Error(), http.StatusConflict)
+			return
+		}
 		http.Error(w, iErr.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -99,6 +157,10 @@ func (h instantiationHandler) stopHandler(w http.ResponseWriter, r *http.Request
 	iErr := h.client.Stop(ctx, p, ca, v, di)
 	if iErr != nil {
 		log.Error(iErr.Error(), log.Fields{})
+		if strings.Contains(iErr.Error(), "is locked by another lifecycle operation") {
+			http.Error(w, iErr.Error(), http.StatusConflict)
+			return
+		}
 		http.Error(w, iErr.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -188,6 +250,18 @@ func (h instantiationHandler) statusHandler(w http.ResponseWriter, r *http.Reque
 		queryResources = false
 	}
 
+	var queryDrifted bool
+	if _, found := qParams["drifted"]; found {
+		if queryType != "ready" {
+			log.Error("Invalid drifted query", log.Fields{})
+			http.Error(w, "The drifted query is only supported with status=ready", http.StatusBadRequest)
+			return
+		}
+		queryDrifted = true
+	} else {
+		queryDrifted = false
+	}
+
 	var filterApps []string
 	if a, found := qParams["app"]; found {
 		filterApps = a
@@ -264,7 +338,12 @@ func (h instantiationHandler) statusHandler(w http.ResponseWriter, r *http.Reque
 	} else if queryResources {
 		status, iErr = h.client.StatusResourcesByApp(ctx, p, ca, v, di, queryInstance, queryType, filterApps, filterClusters)
 	} else {
-		status, iErr = h.client.Status(ctx, p, ca, v, di, queryInstance, queryType, queryOutput, filterApps, filterClusters, filterResources)
+		var diStatus moduleLib.DeploymentStatus
+		diStatus, iErr = h.client.Status(ctx, p, ca, v, di, queryInstance, queryType, queryOutput, filterApps, filterClusters, filterResources)
+		if queryDrifted {
+			statusLib.FilterDriftedResources(&diStatus.StatusResult)
+		}
+		status = diStatus
 	}
 	if iErr != nil {
 		log.Error(iErr.Error(), log.Fields{})
@@ -283,3 +362,32 @@ func (h instantiationHandler) statusHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 }
+
+// statusWatchHandler streams the deployment intent group status notifications as Server-Sent Events
+func (h instantiationHandler) statusWatchHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	qParams, err := url.ParseQuery(r.URL.RawQuery)
+	if err != nil {
+		log.Error(err.Error(), log.Fields{})
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	reg, err := statusnotifyserver.StatusRegistrationFromQuery(qParams)
+	if err != nil {
+		log.Error(err.Error(), log.Fields{})
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	reg.Key = &statusnotifypb.StatusRegistration_DigKey{
+		DigKey: &statusnotifypb.DigKey{
+			Project:               vars["project"],
+			CompositeApp:          vars["compositeApp"],
+			CompositeAppVersion:   vars["compositeAppVersion"],
+			DeploymentIntentGroup: vars["deploymentIntentGroup"],
+		},
+	}
+
+	statusnotifyserver.ServeStatusWatch(w, r, reg)
+}
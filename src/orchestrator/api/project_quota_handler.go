@@ -0,0 +1,120 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/apierror"
+	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/validation"
+	moduleLib "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/module"
+)
+
+var projectQuotaJSONFile string = "json-schemas/project-quota.json"
+
+// Used to store backend implementations objects
+// Also simplifies mocking for unit testing purposes
+type projectQuotaHandler struct {
+	// Interface that implements ProjectQuota operations
+	// We will set this variable with a mock interface for testing
+	client moduleLib.ProjectQuotaManager
+}
+
+// createHandler sets the quota of a project
+func (h projectQuotaHandler) createHandler(w http.ResponseWriter, r *http.Request) {
+	h.createOrUpdate(w, r, false)
+}
+
+// updateHandler updates the quota of a project
+func (h projectQuotaHandler) updateHandler(w http.ResponseWriter, r *http.Request) {
+	h.createOrUpdate(w, r, true)
+}
+
+func (h projectQuotaHandler) createOrUpdate(w http.ResponseWriter, r *http.Request, exists bool) {
+	var q moduleLib.ProjectQuota
+	ctx := r.Context()
+	vars := mux.Vars(r)
+	project := vars["project"]
+
+	err := json.NewDecoder(r.Body).Decode(&q)
+	switch {
+	case err == io.EOF:
+		log.Error(err.Error(), log.Fields{})
+		http.Error(w, "Empty body", http.StatusBadRequest)
+		return
+	case err != nil:
+		log.Error(err.Error(), log.Fields{})
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	// Verify JSON Body
+	err, httpError := validation.ValidateJsonSchemaData(projectQuotaJSONFile, q)
+	if err != nil {
+		log.Error(err.Error(), log.Fields{})
+		http.Error(w, err.Error(), httpError)
+		return
+	}
+
+	ret, err := h.client.CreateProjectQuota(ctx, project, q, exists)
+	if err != nil {
+		apiErr := apierror.HandleErrors(vars, err, q, apiErrors)
+		http.Error(w, apiErr.Message, apiErr.Status)
+		return
+	}
+
+	statusCode := http.StatusCreated
+	if exists {
+		statusCode = http.StatusOK
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	err = json.NewEncoder(w).Encode(ret)
+	if err != nil {
+		log.Error(err.Error(), log.Fields{})
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// getHandler returns the quota of a project
+func (h projectQuotaHandler) getHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := mux.Vars(r)
+
+	ret, err := h.client.GetProjectQuota(ctx, vars["project"])
+	if err != nil {
+		apiErr := apierror.HandleErrors(vars, err, nil, apiErrors)
+		http.Error(w, apiErr.Message, apiErr.Status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(ret)
+	if err != nil {
+		log.Error(err.Error(), log.Fields{})
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// deleteHandler removes the quota of a project
+func (h projectQuotaHandler) deleteHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := mux.Vars(r)
+
+	err := h.client.DeleteProjectQuota(ctx, vars["project"])
+	if err != nil {
+		apiErr := apierror.HandleErrors(vars, err, nil, apiErrors)
+		http.Error(w, apiErr.Message, apiErr.Status)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -23,6 +23,14 @@ type projectHandler struct {
 	// Interface that implements Project operations
 	// We will set this variable with a mock interface for testing
 	client moduleLib.ProjectManager
+	// Interface that reports the quota and usage of a project, may be nil
+	quotaClient moduleLib.ProjectQuotaManager
+}
+
+// projectResponse is a Project with the quota and usage of the project
+type projectResponse struct {
+	moduleLib.Project
+	Status *moduleLib.ProjectQuotaStatus `json:"status,omitempty"`
 }
 
 // Create handles creation of the Project entry in the database
@@ -153,13 +161,24 @@ func (h projectHandler) getHandler(w http.ResponseWriter, r *http.Request) {
 
 	}
 
-	ret, err := h.client.GetProject(ctx, name)
+	p, err := h.client.GetProject(ctx, name)
 	if err != nil {
 		apiErr := apierror.HandleErrors(vars, err, nil, apiErrors)
 		http.Error(w, apiErr.Message, apiErr.Status)
 		return
 	}
 
+	ret := projectResponse{Project: p}
+	if h.quotaClient != nil {
+		s, err := h.quotaClient.GetProjectQuotaStatus(ctx, name)
+		if err != nil {
+			apiErr := apierror.HandleErrors(vars, err, nil, apiErrors)
+			http.Error(w, apiErr.Message, apiErr.Status)
+			return
+		}
+		ret.Status = &s
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	err = json.NewEncoder(w).Encode(ret)
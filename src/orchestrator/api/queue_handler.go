@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/apierror"
+	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/validation"
+	moduleLib "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/module"
+)
+
+var queueCancelJSONFile string = "json-schemas/queue-cancel.json"
+
+// Used to store backend implementations objects
+// Also simplifies mocking for unit testing purposes
+type queueHandler struct {
+	// Interface that implements the operations queue operations
+	// We will set this variable with a mock interface for testing
+	client moduleLib.QueueManager
+}
+
+// getHandler returns the rsync event queues of the deployment intent group
+func (h queueHandler) getHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := mux.Vars(r)
+
+	ret, err := h.client.GetQueue(ctx, vars["project"], vars["compositeApp"], vars["compositeAppVersion"], vars["deploymentIntentGroup"])
+	if err != nil {
+		apiErr := apierror.HandleErrors(vars, err, nil, apiErrors)
+		http.Error(w, apiErr.Message, apiErr.Status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(ret)
+	if err != nil {
+		log.Error(err.Error(), log.Fields{})
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// cancelHandler cancels a pending event of the deployment intent group
+func (h queueHandler) cancelHandler(w http.ResponseWriter, r *http.Request) {
+	var req moduleLib.QueueCancelRequest
+	ctx := r.Context()
+	vars := mux.Vars(r)
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	switch {
+	case err == io.EOF:
+		log.Error(err.Error(), log.Fields{})
+		http.Error(w, "Empty body", http.StatusBadRequest)
+		return
+	case err != nil:
+		log.Error(err.Error(), log.Fields{})
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	// Verify JSON Body
+	err, httpError := validation.ValidateJsonSchemaData(queueCancelJSONFile, req)
+	if err != nil {
+		log.Error(err.Error(), log.Fields{})
+		http.Error(w, err.Error(), httpError)
+		return
+	}
+
+	err = h.client.CancelEvent(ctx, vars["project"], vars["compositeApp"], vars["compositeAppVersion"], vars["deploymentIntentGroup"], req)
+	if err != nil {
+		apiErr := apierror.HandleErrors(vars, err, req, apiErrors)
+		http.Error(w, apiErr.Message, apiErr.Status)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
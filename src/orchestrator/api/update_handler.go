@@ -84,7 +84,7 @@ func (h updateHandler) migrateHandler(w http.ResponseWriter, r *http.Request) {
 
 func (h updateHandler) updateHandler(w http.ResponseWriter, r *http.Request) {
 
-	ctx := r.Context()
+	ctx, warnings := moduleLib.WithAdmissionWarnings(r.Context())
 	vars := mux.Vars(r)
 	p := vars["project"]
 	ca := vars["compositeApp"]
@@ -95,6 +95,9 @@ func (h updateHandler) updateHandler(w http.ResponseWriter, r *http.Request) {
 	if iErr != nil {
 		log.Error(":: Error update handler ::", log.Fields{"Error": iErr.Error(), "project": p, "compositeApp": ca, "compositeAppVer": v,
 			"depGroup": di})
+		if writeAdmissionReport(w, iErr) {
+			return
+		}
 		apiErr := apierror.HandleLogicalCloudErrors(vars, iErr, lcErrors)
 		if (apiErr == apierror.APIError{}) {
 			// There are no logical cloud error(s). Check for api specific error(s)
@@ -109,6 +112,7 @@ func (h updateHandler) updateHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	log.Info("updateHandler ... end ", log.Fields{"project": p, "compositeApp": ca, "compositeAppVer": v,
 		"depGroup": di, "returnValue": iErr})
+	writeAdmissionWarnings(w, warnings)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusAccepted)
 	err := json.NewEncoder(w).Encode(revisionID)
@@ -12,12 +12,15 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 	"gitlab.com/project-emco/core/emco-base/src/orchestrator/api"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/admission"
 	register "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/grpc"
 	contextDb "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/contextdb"
 	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/db"
 	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+	inframetrics "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/metrics"
 	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/rpc"
 	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/metrics"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/module"
 	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/module/controller"
 	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/statusnotify"
 )
@@ -27,6 +30,7 @@ func main() {
 
 	ctx := context.Background()
 
+	module.RegisterPolicyEvaluator(admission.NewRegoEvaluator())
 	err := db.InitializeDatabaseConnection(ctx, "emco")
 	if err != nil {
 		log.Error("Unable to initialize mongo database connection", log.Fields{"Error": err})
@@ -55,6 +59,7 @@ func main() {
 	prometheus.MustRegister(metrics.GenericAppPlacementIntentGauge)
 	prometheus.MustRegister(metrics.AppGauge)
 	prometheus.MustRegister(metrics.DependencyGauge)
+	prometheus.MustRegister(inframetrics.LifecycleDuration)
 
 	server, err := controller.NewControllerServer("orchestrator",
 		api.NewRouter(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil),
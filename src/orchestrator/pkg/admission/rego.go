@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+// Package admission evaluates the Rego modules of the admission policies with
+// the embedded Open Policy Agent.
+package admission
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// denyRule is the rule reporting the violations of a policy
+const denyRule = "deny"
+
+// RegoEvaluator implements the module.PolicyEvaluator with OPA
+type RegoEvaluator struct{}
+
+// NewRegoEvaluator returns an instance of the RegoEvaluator
+func NewRegoEvaluator() *RegoEvaluator {
+	return &RegoEvaluator{}
+}
+
+// compile parses and compiles the Rego module, and returns the query of its deny rule
+func compile(name, module string) (*ast.Compiler, string, error) {
+	m, err := ast.ParseModule(name+".rego", module)
+	if err != nil {
+		return nil, "", err
+	}
+	if m == nil {
+		return nil, "", pkgerrors.New("Empty Rego module")
+	}
+	compiler := ast.NewCompiler()
+	compiler.Compile(map[string]*ast.Module{name: m})
+	if compiler.Failed() {
+		return nil, "", compiler.Errors
+	}
+	return compiler, m.Package.Path.String() + "." + denyRule, nil
+}
+
+// Validate returns an error if the Rego module does not compile, or if it does not
+// report the violations with a partial set deny rule, e.g. deny[msg] { ... }
+func (e *RegoEvaluator) Validate(ctx context.Context, name, module string) error {
+	if _, _, err := compile(name, module); err != nil {
+		return err
+	}
+	m, _ := ast.ParseModule(name+".rego", module)
+	found := false
+	for _, r := range m.Rules {
+		if !r.Head.Name.Equal(ast.Var(denyRule)) {
+			continue
+		}
+		if r.Head.DocKind() != ast.PartialSetDoc {
+			return pkgerrors.Errorf("The %s rule must be a partial set rule, e.g. %s[msg] { ... }", denyRule, denyRule)
+		}
+		found = true
+	}
+	if !found {
+		return pkgerrors.Errorf("The module has no %s rule", denyRule)
+	}
+	return nil
+}
+
+// Evaluate returns the messages of the deny rule of the Rego module for the input.
+// The deny rule is a set of strings, or of objects with a msg attribute. Any other
+// defined value of the rule, e.g. a true boolean, is a violation as well.
+func (e *RegoEvaluator) Evaluate(ctx context.Context, name, module string, input interface{}) ([]string, error) {
+	compiler, query, err := compile(name, module)
+	if err != nil {
+		return nil, err
+	}
+
+	rs, err := rego.New(rego.Query(query), rego.Compiler(compiler), rego.Input(input)).Eval(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var msgs []string
+	for _, r := range rs {
+		// the rule is undefined, and there is no result, when nothing is denied
+		for _, expr := range r.Expressions {
+			switch values := expr.Value.(type) {
+			case []interface{}:
+				for _, v := range values {
+					msgs = append(msgs, message(v))
+				}
+			case bool:
+				if values {
+					msgs = append(msgs, fmt.Sprintf("The %s rule of the policy is true", denyRule))
+				}
+			default:
+				msgs = append(msgs, message(values))
+			}
+		}
+	}
+	return msgs, nil
+}
+
+// message returns the message of a value of the deny rule
+func message(v interface{}) string {
+	switch m := v.(type) {
+	case string:
+		return m
+	case map[string]interface{}:
+		if msg, ok := m["msg"].(string); ok {
+			return msg
+		}
+	}
+	return fmt.Sprintf("%v", v)
+}
@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package admission
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+const privilegedPolicy = `
+package emco.admission.privileged
+
+deny[msg] {
+	r := input.resources[_]
+	c := r.object.spec.containers[_]
+	c.securityContext.privileged
+	msg := sprintf("container %s of %s in cluster %s is privileged", [c.name, r.name, r.cluster])
+}
+`
+
+const registryPolicy = `
+package emco.admission.registries
+
+deny[{"msg": msg}] {
+	r := input.resources[_]
+	c := r.object.spec.containers[_]
+	not startswith(c.image, "registry.example.com/")
+	msg := sprintf("image %s is not from an approved registry", [c.image])
+}
+`
+
+func TestEvaluate(t *testing.T) {
+	ctx := context.Background()
+	input := map[string]interface{}{
+		"resources": []interface{}{
+			map[string]interface{}{
+				"cluster": "provider+cluster1",
+				"name":    "pod1+Pod",
+				"object": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"containers": []interface{}{
+							map[string]interface{}{"name": "c1", "image": "registry.example.com/app", "securityContext": map[string]interface{}{"privileged": true}},
+							map[string]interface{}{"name": "c2", "image": "docker.io/app"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	testCases := []struct {
+		label    string
+		policy   string
+		expected []string
+	}{
+		{"Privileged container", privilegedPolicy, []string{"container c1 of pod1+Pod in cluster provider+cluster1 is privileged"}},
+		{"Image registry", registryPolicy, []string{"image docker.io/app is not from an approved registry"}},
+		{"No violation", "package emco.admission.none\n\ndeny[msg] {\n\tfalse\n\tmsg := \"never\"\n}\n", nil},
+		{"Boolean rule", "package emco.admission.bool\n\ndeny {\n\tinput.resources[_].cluster == \"provider+cluster1\"\n}\n", []string{"The deny rule of the policy is true"}},
+		{"Boolean rule not matching", "package emco.admission.bool\n\ndeny {\n\tinput.resources[_].cluster == \"provider+cluster2\"\n}\n", nil},
+	}
+
+	e := NewRegoEvaluator()
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			msgs, err := e.Evaluate(ctx, "policy", tc.policy, input)
+			if err != nil {
+				t.Fatalf("Evaluate returned an error: %s", err)
+			}
+			sort.Strings(msgs)
+			if !reflect.DeepEqual(msgs, tc.expected) {
+				t.Errorf("Evaluate returned %v, expected %v", msgs, tc.expected)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	e := NewRegoEvaluator()
+	if err := e.Validate(context.Background(), "policy", privilegedPolicy); err != nil {
+		t.Errorf("Validate returned an error: %s", err)
+	}
+	if err := e.Validate(context.Background(), "policy", "package emco\n\ndeny[msg] {\n\tmsg := undefined_function(1)\n}\n"); err == nil {
+		t.Errorf("Validate accepted an invalid module")
+	}
+	if err := e.Validate(context.Background(), "policy", ""); err == nil {
+		t.Errorf("Validate accepted an empty module")
+	}
+	if err := e.Validate(context.Background(), "policy", "package emco\n\nallow[msg] {\n\tmsg := \"no deny rule\"\n}\n"); err == nil {
+		t.Errorf("Validate accepted a module without deny rule")
+	}
+	if err := e.Validate(context.Background(), "policy", "package emco\n\ndeny {\n\tinput.x == 1\n}\n"); err == nil {
+		t.Errorf("Validate accepted a boolean deny rule")
+	}
+}
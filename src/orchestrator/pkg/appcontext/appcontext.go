@@ -25,6 +25,9 @@ const OrderInstruction = "order"
 // DependencyInstruction type constants
 const DependencyInstruction = "dependency"
 
+// HookInstruction type constants
+const HookInstruction = "hook"
+
 // Level constant names
 const ResourceLevel = "resource"
 const AppLevel = "app"
@@ -42,6 +45,7 @@ type AppContext struct {
 //	Terminated - terminate has completed
 //	InstantiateFailed - the instantiate action has failed
 //	TerminateFailed - the terminate action has failed
+//	Pending - the action has completed except on offline clusters, where it is pending
 type AppContextStatus struct {
 	Status StatusValue
 }
@@ -57,6 +61,7 @@ type statuses struct {
 	Updating          StatusValue
 	Updated           StatusValue
 	UpdateFailed      StatusValue
+	Pending           StatusValue
 }
 
 var AppContextStatusEnum = &statuses{
@@ -70,35 +75,73 @@ var AppContextStatusEnum = &statuses{
 	Updating:          "Updating",
 	Updated:           "Updated",
 	UpdateFailed:      "UpdatedFailed",
+	Pending:           "Pending(cluster offline)",
 }
 
 type clusterStatuses struct {
 	Unknown   StatusValue
 	Available StatusValue
 	Retrying  StatusValue
+	Offline   StatusValue
 }
 
 var ClusterReadyStatusEnum = &clusterStatuses{
 	Unknown:   "Unknown",
 	Available: "Available",
 	Retrying:  "Retrying",
+	Offline:   "Offline",
 }
 
 // CompositeAppMeta contains all the possible attributes an
 // appcontext /meta handle of a Composite App or Logical Cloud, may have.
 // Note: only some of these fields will be used in each for each of the types above:
 type CompositeAppMeta struct {
-	Project               string   `json:"Project"`
-	CompositeApp          string   `json:"CompositeApp"`
-	Version               string   `json:"Version"`
-	Release               string   `json:"Release"`
-	DeploymentIntentGroup string   `json:"DeploymentIntentGroup"`
-	Namespace             string   `json:"Namespace"`
-	Level                 string   `json:"Level"`
-	ChildContextIDs       []string `json:"ChildContextIDs"`
-	LogicalCloud          string   `json:"LogicalCloud"`
-	LogicalCloudNamespace string   `json:"LogicalCloudNamespace"`
-	LogicalCloudLevel     string   `json:"LogicalCloudLevel"`
+	Project               string       `json:"Project"`
+	CompositeApp          string       `json:"CompositeApp"`
+	Version               string       `json:"Version"`
+	Release               string       `json:"Release"`
+	DeploymentIntentGroup string       `json:"DeploymentIntentGroup"`
+	Namespace             string       `json:"Namespace"`
+	Level                 string       `json:"Level"`
+	ChildContextIDs       []string     `json:"ChildContextIDs"`
+	LogicalCloud          string       `json:"LogicalCloud"`
+	LogicalCloudNamespace string       `json:"LogicalCloudNamespace"`
+	LogicalCloudLevel     string       `json:"LogicalCloudLevel"`
+	DriftPolicy           string       `json:"DriftPolicy,omitempty"`
+	RetryPolicy           *RetryPolicy `json:"RetryPolicy,omitempty"`
+	// Apps deployed by rsync as Helm releases, by app name
+	HelmReleases map[string]HelmRelease `json:"HelmReleases,omitempty"`
+	// Resources unchanged since they were last applied are applied again
+	ForceApply bool `json:"ForceApply,omitempty"`
+}
+
+// Drift policies supported for a Composite App. With the report policy,
+// resources that no longer match their desired state are only reported in
+// the status. With the remediate policy, rsync also re-applies them.
+const (
+	DriftPolicyReport    = "report"
+	DriftPolicyRemediate = "remediate"
+)
+
+// RetryPolicy is the backoff of rsync between the retries of a resource
+// which failed with a transient error, or of a cluster which is not
+// reachable. The intervals and the elapsed time are in seconds, the values
+// which are not set keep the rsync defaults.
+type RetryPolicy struct {
+	MaxRetries      int     `json:"maxRetries,omitempty"`
+	InitialInterval int     `json:"initialInterval,omitempty"`
+	MaxInterval     int     `json:"maxInterval,omitempty"`
+	Multiplier      float64 `json:"multiplier,omitempty"`
+	Jitter          float64 `json:"jitter,omitempty"`
+	MaxElapsedTime  int     `json:"maxElapsedTime,omitempty"`
+}
+
+// HelmRelease is the chart of an app which rsync deploys as a Helm release
+// named after the app, instead of applying its resources one by one
+type HelmRelease struct {
+	Chart      string `json:"chart"`
+	Version    string `json:"version"`
+	AppVersion string `json:"appVersion,omitempty"`
 }
 
 // Init app context
@@ -491,7 +534,7 @@ func (ac *AppContext) GetResourceNames(ctx context.Context, appname string, clus
 
 //Add instruction under given handle and type
 func (ac *AppContext) AddInstruction(ctx context.Context, handle interface{}, level string, insttype string, value interface{}) (interface{}, error) {
-	if !(insttype == OrderInstruction || insttype == DependencyInstruction) {
+	if !(insttype == OrderInstruction || insttype == DependencyInstruction || insttype == HookInstruction) {
 		log.Error("Not a valid app context instruction type", log.Fields{})
 		return nil, pkgerrors.Errorf("Not a valid app context instruction type")
 	}
@@ -568,7 +611,7 @@ func (ac *AppContext) UpdateInstructionValue(ctx context.Context, handle interfa
 
 //Returns the resource instruction for a given instruction type
 func (ac *AppContext) GetResourceInstruction(ctx context.Context, appname string, clustername string, insttype string) (interface{}, error) {
-	if !(insttype == OrderInstruction || insttype == DependencyInstruction) {
+	if !(insttype == OrderInstruction || insttype == DependencyInstruction || insttype == HookInstruction) {
 		log.Error("Not a valid app context instruction type", log.Fields{})
 		return nil, pkgerrors.Errorf("Not a valid app context instruction type")
 	}
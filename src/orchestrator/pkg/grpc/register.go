@@ -15,6 +15,7 @@ import (
 	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/grpc/statusnotify"
 	statusnotifypb "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/grpc/statusnotify"
 	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/tracing"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 )
@@ -46,7 +47,7 @@ func NewGrpcServer(defaultName, envName string, defaultPort int, registerFn func
 	port := getGrpcServerPort(defaultName, envName, defaultPort)
 
 	grpcServer := grpc.NewServer(
-		grpc.UnaryInterceptor(otelgrpc.UnaryServerInterceptor()),
+		grpc.ChainUnaryInterceptor(otelgrpc.UnaryServerInterceptor(), tracing.UnaryServerInterceptor()),
 		grpc.StreamInterceptor(otelgrpc.StreamServerInterceptor()),
 	)
 	registerFn(grpcServer, srv)
@@ -0,0 +1,66 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package rsyncmgmtclient
+
+import (
+	"context"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	inc "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/grpc/installappclient"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/rpc"
+	mgmtpb "gitlab.com/project-emco/core/emco-base/src/rsync/pkg/grpc/rsyncmgmt"
+)
+
+const rsyncName = "rsync"
+
+func getClient(ctx context.Context) (mgmtpb.RsyncmgmtClient, error) {
+	conn := rpc.GetRpcConn(ctx, rsyncName)
+	if conn == nil {
+		inc.InitRsyncClient()
+		conn = rpc.GetRpcConn(ctx, rsyncName)
+	}
+	if conn == nil {
+		return nil, pkgerrors.Errorf("Could not get the rsync management client: %v", rsyncName)
+	}
+	return mgmtpb.NewRsyncmgmtClient(conn), nil
+}
+
+// InvokeGetQueue returns the event queue of the AppContext from rsync
+func InvokeGetQueue(ctx context.Context, appContextID string) (*mgmtpb.GetQueueResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	client, err := getClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.GetQueue(ctx, &mgmtpb.GetQueueRequest{AppContext: appContextID})
+}
+
+// InvokeCancelEvent cancels a pending event of the AppContext in rsync
+func InvokeCancelEvent(ctx context.Context, appContextID string, index int) error {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	client, err := getClient(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = client.CancelEvent(ctx, &mgmtpb.CancelEventRequest{AppContext: appContextID, Index: int32(index)})
+	return err
+}
+
+// InvokeValidateAppContext validates the resources of the AppContext on their clusters in rsync
+func InvokeValidateAppContext(ctx context.Context, appContextID string) (*mgmtpb.ValidateAppContextResponse, error) {
+	// The resources are sent to every cluster of the AppContext
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	client, err := getClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.ValidateAppContext(ctx, &mgmtpb.ValidateAppContextRequest{AppContext: appContextID})
+}
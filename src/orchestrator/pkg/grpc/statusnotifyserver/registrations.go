@@ -0,0 +1,240 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package statusnotifyserver
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	pb "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/grpc/statusnotify"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/contextdb"
+	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+	proto "google.golang.org/protobuf/proto"
+)
+
+// The status notification registrations are stored in the context database, so
+// that the replicas of a service share them:
+//
+//	/statusnotify/<service>/registrations/<clientId>/ - the storedRegistration of the client
+//	/statusnotify/<service>/active/<clientId>/        - the replica serving the stream of the client
+//
+// The active key is bound to the lease of the replica, so a client whose replica
+// died can register again, without a key, on any other replica to resume its stream.
+// The registration of a client which does not resume its stream within
+// registrationRetention is removed by the replicas, as if the client deregistered.
+
+// Interval of the check of the registration of an open stream, to close the
+// stream when the client is deregistered through another replica
+var registrationCheckInterval = 5 * time.Second
+
+// The registrations without a stream are removed after registrationRetention, and
+// checked every registrationCollectInterval
+var (
+	registrationRetention       = time.Hour
+	registrationCollectInterval = 10 * time.Minute
+)
+
+// storedRegistration is the registration of a client, and the last notification sent to it
+type storedRegistration struct {
+	Registration     []byte `json:"registration"`
+	AppContextID     string `json:"appContextId"`
+	LastNotification []byte `json:"lastNotification,omitempty"`
+	// Time at which a replica found the registration without a stream
+	Inactive *time.Time `json:"inactive,omitempty"`
+}
+
+// replicaID returns a unique name of the replica, the pod name in a Kubernetes deployment
+func replicaID() string {
+	if name, err := os.Hostname(); err == nil && name != "" {
+		return name
+	}
+	return fmt.Sprintf("replica-%016x", rand.Uint64())
+}
+
+// sharedRegistrations is true when the context database is available to share the registrations
+func sharedRegistrations() bool {
+	return contextdb.Db != nil
+}
+
+func (s *StatusNotifyServer) registrationKey(clientId string) string {
+	return "/statusnotify/" + s.service + "/registrations/" + clientId + "/"
+}
+
+func (s *StatusNotifyServer) activeKey(clientId string) string {
+	return "/statusnotify/" + s.service + "/active/" + clientId + "/"
+}
+
+// loadRegistration returns the stored registration of the client, or false if the client is not registered
+func (s *StatusNotifyServer) loadRegistration(ctx context.Context, clientId string) (*pb.StatusRegistration, string, *pb.StatusNotification, bool, error) {
+	if !sharedRegistrations() {
+		return nil, "", nil, false, nil
+	}
+	var sr storedRegistration
+	if err := contextdb.Db.Get(ctx, s.registrationKey(clientId), &sr); err != nil {
+		if strings.Contains(err.Error(), "Key doesn't exist") {
+			return nil, "", nil, false, nil
+		}
+		return nil, "", nil, false, err
+	}
+	reg := &pb.StatusRegistration{}
+	if err := proto.Unmarshal(sr.Registration, reg); err != nil {
+		return nil, "", nil, false, pkgerrors.Wrap(err, "Invalid stored status notification registration")
+	}
+	var lastNotif *pb.StatusNotification
+	if len(sr.LastNotification) > 0 {
+		lastNotif = &pb.StatusNotification{}
+		if err := proto.Unmarshal(sr.LastNotification, lastNotif); err != nil {
+			lastNotif = nil
+		}
+	}
+	return reg, sr.AppContextID, lastNotif, true, nil
+}
+
+// storeRegistration stores the registration of the client and the last notification sent to it
+func (s *StatusNotifyServer) storeRegistration(ctx context.Context, clientId string, si streamInfo) error {
+	if !sharedRegistrations() {
+		return nil
+	}
+	sr := storedRegistration{AppContextID: si.appContextID}
+	var err error
+	if sr.Registration, err = proto.Marshal(si.reg); err != nil {
+		return err
+	}
+	if si.lastNotif != nil {
+		if sr.LastNotification, err = proto.Marshal(si.lastNotif); err != nil {
+			return err
+		}
+	}
+	return contextdb.Db.Put(ctx, s.registrationKey(clientId), sr)
+}
+
+// deleteRegistration removes the stored registration of the client
+func (s *StatusNotifyServer) deleteRegistration(ctx context.Context, clientId string) error {
+	if !sharedRegistrations() {
+		return nil
+	}
+	return contextdb.Db.Delete(ctx, s.registrationKey(clientId))
+}
+
+// claimStream marks the stream of the client as served by this replica. It fails
+// if the stream of the client is open on any replica.
+func (s *StatusNotifyServer) claimStream(ctx context.Context, clientId string) error {
+	if !sharedRegistrations() {
+		return nil
+	}
+	if err := contextdb.CreateWithLease(ctx, s.activeKey(clientId), s.replica); err != nil {
+		if strings.Contains(err.Error(), "Key exists") {
+			return pkgerrors.New("Duplicate client ID: " + clientId)
+		}
+		return err
+	}
+	return nil
+}
+
+// releaseStream marks the stream of the client as closed
+func (s *StatusNotifyServer) releaseStream(clientId string) {
+	if !sharedRegistrations() {
+		return
+	}
+	// the stream context is usually done at this point
+	if err := contextdb.Db.Delete(context.Background(), s.activeKey(clientId)); err != nil {
+		log.Error("[StatusNotify gRPC] Error releasing the status notification stream", log.Fields{"client": clientId, "error": err})
+	}
+}
+
+// isActive returns true if the stream of the client is open on a replica
+func (s *StatusNotifyServer) isActive(ctx context.Context, clientId string) (bool, error) {
+	var replica string
+	if err := contextdb.Db.Get(ctx, s.activeKey(clientId), &replica); err != nil {
+		if strings.Contains(err.Error(), "Key doesn't exist") {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// collectRegistrations removes the registrations of the clients which have not
+// resumed their stream within registrationRetention. A registration without a
+// stream is marked inactive when it is found, and removed when it is found again
+// after registrationRetention. A registration is stored without the mark when its
+// client resumes the stream.
+func (s *StatusNotifyServer) collectRegistrations(ctx context.Context, now time.Time) error {
+	if !sharedRegistrations() {
+		return nil
+	}
+	prefix := "/statusnotify/" + s.service + "/registrations/"
+	keys, err := contextdb.Db.GetAllKeys(ctx, prefix)
+	if err != nil {
+		if strings.Contains(err.Error(), "Key doesn't exist") {
+			return nil
+		}
+		return err
+	}
+	for _, key := range keys {
+		clientId := strings.TrimSuffix(strings.TrimPrefix(key, prefix), "/")
+		active, err := s.isActive(ctx, clientId)
+		if err != nil {
+			return err
+		}
+		if active {
+			continue
+		}
+		var sr storedRegistration
+		if err := contextdb.Db.Get(ctx, key, &sr); err != nil {
+			if strings.Contains(err.Error(), "Key doesn't exist") {
+				continue
+			}
+			return err
+		}
+		switch {
+		case sr.Inactive == nil:
+			sr.Inactive = &now
+			err = contextdb.Db.Put(ctx, key, sr)
+		case now.Sub(*sr.Inactive) >= registrationRetention:
+			log.Info("[StatusNotify gRPC] Removing the registration of a client which has not resumed its stream",
+				log.Fields{"client": clientId, "inactive": *sr.Inactive})
+			err = contextdb.Db.Delete(ctx, key)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runRegistrationCollector removes the expired registrations until stop is closed
+func (s *StatusNotifyServer) runRegistrationCollector(stop <-chan struct{}) {
+	ticker := time.NewTicker(registrationCollectInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		if err := s.collectRegistrations(context.Background(), time.Now()); err != nil {
+			log.Warn("[StatusNotify gRPC] Unable to remove the expired status notification registrations", log.Fields{"error": err})
+		}
+	}
+}
+
+// isRegistered returns false if the client has been deregistered, e.g. through another replica
+func (s *StatusNotifyServer) isRegistered(ctx context.Context, clientId string) bool {
+	if !sharedRegistrations() {
+		return true
+	}
+	_, _, _, found, err := s.loadRegistration(ctx, clientId)
+	if err != nil {
+		// keep the stream open while the context database is unavailable
+		log.Warn("[StatusNotify gRPC] Unable to check the status notification registration", log.Fields{"client": clientId, "error": err})
+		return true
+	}
+	return found
+}
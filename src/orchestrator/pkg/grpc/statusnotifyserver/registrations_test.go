@@ -0,0 +1,136 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package statusnotifyserver
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	pb "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/grpc/statusnotify"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/contextdb"
+)
+
+var _ = Describe("SharedRegistrations", func() {
+	var (
+		ns  *StatusNotifyServer
+		dir string
+		db  contextdb.ContextDb
+		ctx = context.Background()
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "statusnotify")
+		Expect(err).To(BeNil())
+		db = contextdb.Db
+		contextdb.Db, err = contextdb.NewEmbeddedContextDb(filepath.Join(dir, "contextdb.json"))
+		Expect(err).To(BeNil())
+		ns = NewStatusNotifyServer("mock", "mockStatus", mockHelpers{})
+	})
+
+	AfterEach(func() {
+		contextdb.Db = db
+		os.RemoveAll(dir)
+	})
+
+	It("stores and loads a registration with its last notification", func() {
+		_, _, _, found, err := ns.loadRegistration(ctx, "client1")
+		Expect(err).To(BeNil())
+		Expect(found).To(BeFalse())
+
+		reg := makeClient1Registration()
+		reg.ClientId = "client1"
+		si := streamInfo{reg: reg, appContextID: "appcontext1", lastNotif: &pb.StatusNotification{StatusValue: pb.StatusValue_READY}}
+		Expect(ns.storeRegistration(ctx, "client1", si)).To(BeNil())
+
+		stored, appContextID, lastNotif, found, err := ns.loadRegistration(ctx, "client1")
+		Expect(err).To(BeNil())
+		Expect(found).To(BeTrue())
+		Expect(appContextID).To(Equal("appcontext1"))
+		Expect(stored.ClientId).To(Equal("client1"))
+		Expect(stored.StatusType).To(Equal(pb.StatusValue_READY))
+		Expect(lastNotif.StatusValue).To(Equal(pb.StatusValue_READY))
+
+		Expect(ns.isRegistered(ctx, "client1")).To(BeTrue())
+		Expect(ns.deleteRegistration(ctx, "client1")).To(BeNil())
+		Expect(ns.isRegistered(ctx, "client1")).To(BeFalse())
+	})
+
+	It("rejects a stream already open on a replica", func() {
+		other := NewStatusNotifyServer("mock", "mockStatus", mockHelpers{})
+		Expect(ns.claimStream(ctx, "client1")).To(BeNil())
+		err := other.claimStream(ctx, "client1")
+		Expect(err).NotTo(BeNil())
+		Expect(err.Error()).To(ContainSubstring("Duplicate client ID"))
+
+		ns.releaseStream("client1")
+		Expect(other.claimStream(ctx, "client1")).To(BeNil())
+	})
+
+	It("removes the registration of a status watch when its request ends", func() {
+		// another client already watches the app context, so no readyNotify stream is needed
+		ns.appContexts[""] = appContextInfo{
+			statusClientIDs: map[string]struct{}{"client2": {}},
+			queryFilters:    make(map[string]filters),
+		}
+		reqCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		stream, err := newSSEStream(reqCtx, httptest.NewRecorder())
+		Expect(err).To(BeNil())
+		reg := makeClient1Registration()
+		reg.ClientId = "watch-1"
+
+		done := make(chan error)
+		go func() { done <- ns.StatusRegister(reg, stream) }()
+		Eventually(func() bool { return ns.isRegistered(ctx, "watch-1") }).Should(BeTrue())
+		cancel()
+		Eventually(done).Should(Receive(BeNil()))
+		Expect(ns.isRegistered(ctx, "watch-1")).To(BeFalse())
+		Expect(ns.claimStream(ctx, "watch-1")).To(BeNil())
+	})
+
+	It("removes the registrations which are not resumed within the retention", func() {
+		now := time.Now()
+		for _, clientId := range []string{"client1", "client2"} {
+			reg := makeClient1Registration()
+			reg.ClientId = clientId
+			Expect(ns.storeRegistration(ctx, clientId, streamInfo{reg: reg, appContextID: "appcontext1"})).To(BeNil())
+		}
+		// the stream of client2 is open
+		Expect(ns.claimStream(ctx, "client2")).To(BeNil())
+
+		// the registration without a stream is marked inactive first
+		Expect(ns.collectRegistrations(ctx, now)).To(BeNil())
+		Expect(ns.isRegistered(ctx, "client1")).To(BeTrue())
+		Expect(ns.collectRegistrations(ctx, now.Add(registrationRetention/2))).To(BeNil())
+		Expect(ns.isRegistered(ctx, "client1")).To(BeTrue())
+		Expect(ns.collectRegistrations(ctx, now.Add(registrationRetention))).To(BeNil())
+		Expect(ns.isRegistered(ctx, "client1")).To(BeFalse())
+		Expect(ns.isRegistered(ctx, "client2")).To(BeTrue())
+	})
+
+	It("keeps the registration of a client which resumes its stream", func() {
+		now := time.Now()
+		reg := makeClient1Registration()
+		reg.ClientId = "client1"
+		si := streamInfo{reg: reg, appContextID: "appcontext1"}
+		Expect(ns.storeRegistration(ctx, "client1", si)).To(BeNil())
+		Expect(ns.collectRegistrations(ctx, now)).To(BeNil())
+
+		// the resumed stream stores the registration without the inactive mark
+		Expect(ns.storeRegistration(ctx, "client1", si)).To(BeNil())
+		Expect(ns.collectRegistrations(ctx, now.Add(registrationRetention))).To(BeNil())
+		Expect(ns.isRegistered(ctx, "client1")).To(BeTrue())
+	})
+
+	It("uses a readyNotify client name per replica", func() {
+		Expect(ns.readyNotifyClientName()).To(Equal("mockStatus-" + ns.replica))
+	})
+})
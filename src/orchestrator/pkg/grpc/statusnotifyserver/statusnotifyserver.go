@@ -19,6 +19,7 @@ import (
 	readynotifypb "gitlab.com/project-emco/core/emco-base/src/rsync/pkg/grpc/readynotify"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
 	proto "google.golang.org/protobuf/proto"
 )
 
@@ -55,7 +56,9 @@ type appContextInfo struct {
 // StatusNotifyServer will be initialized by NewStatusNotifyServer() and
 // its lifecycle is valid until all the clients unsubscribed the stream notification channel
 type StatusNotifyServer struct {
-	name string
+	name    string
+	service string // name of the service, used to share the registrations with its other replicas
+	replica string // name of the replica of the service
 	// clientId is expected to be unique.  Registering with a clientId that is already in the
 	// map will be rejected - i.e. the new stream will close immediately with an error
 	appContexts       map[string]appContextInfo // map[appcontextid]appContextInfo
@@ -64,6 +67,7 @@ type StatusNotifyServer struct {
 	sh                StatusNotifyServerHelpers
 	readyNotifyClient readynotifypb.ReadyNotifyClient
 	mutex             sync.Mutex
+	stop              chan struct{} // stops the registration collector
 }
 
 var notifServer *StatusNotifyServer
@@ -244,20 +248,61 @@ func (s *StatusNotifyServer) StatusRegister(reg *pb.StatusRegistration, stream p
 		log.Info("[StatusNotify gRPC] Recieved a status notification registration with invalid client ID", log.Fields{})
 		return pkgerrors.New("Invalid client ID")
 	}
-	if _, ok := s.statusClients[clientId]; ok {
+	s.mutex.Lock()
+	_, ok := s.statusClients[clientId]
+	s.mutex.Unlock()
+	if ok {
 		log.Info("[StatusNotify gRPC] Recieved a duplicate status notification registration",
 			log.Fields{"client": clientId})
 		return pkgerrors.New("Duplicate client ID: " + clientId)
 	}
-	appContextID, err := s.sh.GetAppContextId(ctx, reg)
+	if err := s.claimStream(ctx, clientId); err != nil {
+		log.Info("[StatusNotify gRPC] Status notification stream of the client is open on another replica",
+			log.Fields{"client": clientId, "error": err})
+		return err
+	}
+	defer s.releaseStream(clientId)
+	if _, ok := stream.(*sseStream); ok {
+		// A status watch is not resumed, its registration ends with its stream
+		defer func() {
+			if err := s.deleteRegistration(context.Background(), clientId); err != nil {
+				log.Error("[StatusNotify gRPC] Could not remove the status watch registration",
+					log.Fields{"client": clientId, "error": err})
+			}
+		}()
+	}
+
+	// A registration without a key resumes the stored registration of the client,
+	// e.g. after the replica serving its stream has been restarted
+	storedReg, appContextID, lastNotif, resumed, err := s.loadRegistration(ctx, clientId)
 	if err != nil {
-		log.Info("[StatusNotify gRPC] Could not get appContextID for status notification registration",
-			log.Fields{"client": clientId, "AppContextID": appContextID})
+		log.Error("[StatusNotify gRPC] Could not get the stored status notification registration",
+			log.Fields{"client": clientId, "error": err})
 		return err
 	}
+	if resumed && reg.Key == nil {
+		reg = storedReg
+	} else {
+		resumed = false
+		lastNotif = nil
+		appContextID, err = s.sh.GetAppContextId(ctx, reg)
+		if err != nil {
+			log.Info("[StatusNotify gRPC] Could not get appContextID for status notification registration",
+				log.Fields{"client": clientId, "AppContextID": appContextID})
+			return err
+		}
+	}
 
 	log.Info("[StatusNotify gRPC] Recieved a status notification registration",
-		log.Fields{"client": clientId, "appContextID": appContextID})
+		log.Fields{"client": clientId, "appContextID": appContextID, "resumed": resumed})
+
+	// Store the registration before the client is added to the maps, so that
+	// it does not overwrite the last notification sent once it is added
+	si := streamInfo{reg: reg, appContextID: appContextID, lastNotif: lastNotif}
+	if err := s.storeRegistration(ctx, clientId, si); err != nil {
+		log.Error("[StatusNotify gRPC] Could not store the status notification registration",
+			log.Fields{"client": clientId, "error": err})
+	}
 
 	// Add the client info to the statusnotify server maps
 	needReadyNotifyStream := false
@@ -281,6 +326,7 @@ func (s *StatusNotifyServer) StatusRegister(reg *pb.StatusRegistration, stream p
 		stream:       stream,
 		reg:          reg,
 		appContextID: appContextID,
+		lastNotif:    lastNotif,
 	}
 
 	updateQueryFilters(clientId, false)
@@ -312,7 +358,7 @@ func (s *StatusNotifyServer) StatusRegister(reg *pb.StatusRegistration, stream p
 		)
 
 		readyNotifyStream, err := s.readyNotifyClient.Alert(readyNotifyStreamCtx,
-			&readynotifypb.Topic{ClientName: s.name, AppContext: appContextID})
+			&readynotifypb.Topic{ClientName: s.readyNotifyClientName(), AppContext: appContextID})
 		if err != nil {
 			s.mutex.Unlock()
 			log.Error("[StatusNotify gRPC] Could not get ReadyNotify Stream",
@@ -334,7 +380,20 @@ func (s *StatusNotifyServer) StatusRegister(reg *pb.StatusRegistration, stream p
 		s.mutex.Unlock()
 	}
 
-	// Keep stream open
+	// Registration is complete - send the stream header so that the client knows the stream is active
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		log.Warn("[StatusNotify gRPC] Failed to send stream header", log.Fields{"client": clientId, "error": err})
+	}
+
+	// Send the current status to a resumed client if it changed while the client was disconnected
+	if resumed {
+		s.sendCurrentStatus(ctx, clientId)
+	}
+
+	// Keep stream open, the registration of the client is kept when it disconnects,
+	// so that it can resume the stream
+	ticker := time.NewTicker(registrationCheckInterval)
+	defer ticker.Stop()
 	for {
 		select {
 		case <-ctx.Done():
@@ -346,11 +405,50 @@ func (s *StatusNotifyServer) StatusRegister(reg *pb.StatusRegistration, stream p
 			log.Info("[StatusNotify gRPC] Stop channel has been triggered for client", log.Fields{"client": clientId})
 			wg.Wait()
 			return nil
-		default:
+		case <-ticker.C:
+			if !s.isRegistered(ctx, clientId) {
+				log.Info("[StatusNotify gRPC] Client has been deregistered by another replica", log.Fields{"client": clientId})
+				cleanup(ctx, clientId)
+				return nil
+			}
 		}
 	}
 }
 
+// sendCurrentStatus sends the current status to the client, unless it is equal to the last notification
+func (s *StatusNotifyServer) sendCurrentStatus(ctx context.Context, clientId string) {
+	s.mutex.Lock()
+	si, ok := s.statusClients[clientId]
+	if !ok {
+		s.mutex.Unlock()
+		return
+	}
+	qType, qOutput, qApps, qClusters, qResources := GetStatusParameters(si.reg)
+	statusResult := s.sh.StatusQuery(ctx, si.reg, si.appContextID, qType, qOutput, qApps, qClusters, qResources)
+	notification := s.sh.PrepareStatusNotification(si.reg, statusResult)
+	if si.lastNotif != nil && proto.Equal(si.lastNotif, notification) {
+		s.mutex.Unlock()
+		return
+	}
+	si.lastNotif = notification
+	s.statusClients[clientId] = si
+	if err := si.stream.Send(notification); err != nil {
+		log.Error("[StatusNotify gRPC] Status notification failed to be sent", log.Fields{"clientId": clientId, "appContextID": si.appContextID, "err": err})
+	}
+	s.mutex.Unlock()
+
+	// The context database is not accessed with the lock held
+	if err := s.storeRegistration(ctx, clientId, si); err != nil {
+		log.Error("[StatusNotify gRPC] Could not store the last status notification", log.Fields{"clientId": clientId, "err": err})
+	}
+}
+
+// readyNotifyClientName is the name of the rsync readyNotify client of the replica. Each replica
+// has its own readyNotify streams, so the names of the replicas must be distinct.
+func (s *StatusNotifyServer) readyNotifyClientName() string {
+	return s.name + "-" + s.replica
+}
+
 // SendStatusNotification sends a status notification message to the subscriber
 func sendStatusNotifications(ctx context.Context, stream readynotifypb.ReadyNotify_AlertClient, wg *sync.WaitGroup, appContextID string) error {
 	span := trace.SpanFromContext(ctx)
@@ -427,6 +525,7 @@ func sendStatusNotifications(ctx context.Context, stream readynotifypb.ReadyNoti
 		log.Trace("[StatusNotify gRPC] handling status events", log.Fields{"apps": apps, "clusters": clusters})
 
 		notifServer.mutex.Lock()
+		sent := make(map[string]streamInfo)
 		acInfo, ok := notifServer.appContexts[appContextID]
 		if !ok {
 			notifServer.mutex.Unlock()
@@ -478,6 +577,7 @@ func sendStatusNotifications(ctx context.Context, stream readynotifypb.ReadyNoti
 				}
 				si.lastNotif = notification
 				notifServer.statusClients[clientId] = si
+				sent[clientId] = si
 				err := si.stream.Send(notification)
 				if err != nil {
 					log.Error("[StatusNotify gRPC] Status notification failed to be sent", log.Fields{"clientId": clientId, "appContextID": appContextID, "err": err})
@@ -488,6 +588,13 @@ func sendStatusNotifications(ctx context.Context, stream readynotifypb.ReadyNoti
 		}
 		notifServer.mutex.Unlock()
 
+		// The last notifications are stored without the lock held, so that the
+		// context database does not delay the notifications of the other app contexts
+		for clientId, si := range sent {
+			if err := notifServer.storeRegistration(ctx, clientId, si); err != nil {
+				log.Error("[StatusNotify gRPC] Could not store the last status notification", log.Fields{"clientId": clientId, "err": err})
+			}
+		}
 	}
 	return nil
 }
@@ -518,9 +625,9 @@ func cleanup(ctx context.Context, clientId string) {
 	if len(acInfo.statusClientIDs) == 0 {
 		// if no clients are left for the app context - unsubscribe from rsync readyNotify service
 		_, err := notifServer.readyNotifyClient.Unsubscribe(ctx,
-			&readynotifypb.Topic{ClientName: notifServer.name, AppContext: si.appContextID})
+			&readynotifypb.Topic{ClientName: notifServer.readyNotifyClientName(), AppContext: si.appContextID})
 		if err != nil {
-			log.Error("[StatusNotify gRPC] Error unsubscribing from rsync readyNotify", log.Fields{"rsync readyNotify clientId": notifServer.name, "appContextID": si.appContextID, "Error": err})
+			log.Error("[StatusNotify gRPC] Error unsubscribing from rsync readyNotify", log.Fields{"rsync readyNotify clientId": notifServer.readyNotifyClientName(), "appContextID": si.appContextID, "Error": err})
 		}
 
 		delete(notifServer.appContexts, si.appContextID)
@@ -535,13 +642,21 @@ func cleanup(ctx context.Context, clientId string) {
 
 // StatusDeregister will be called when the subscriber wants to terminate the stream
 func (s *StatusNotifyServer) StatusDeregister(ctx context.Context, dereg *pb.StatusDeregistration) (*pb.StatusDeregistrationResponse, error) {
+	// The stream of the client may be served by another replica, which closes it
+	// once it finds out the registration has been removed
+	if err := s.deleteRegistration(ctx, dereg.ClientId); err != nil {
+		log.Error("[StatusNotify gRPC] Could not remove the stored status notification registration",
+			log.Fields{"client": dereg.ClientId, "error": err})
+		return nil, err
+	}
+
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
 	// get the clientId entry, stop the stream
 	si, ok := s.statusClients[dereg.ClientId]
 	if !ok {
-		// client already deregistered, or never existed
+		// client already deregistered, not served by this replica, or never existed
 		return &pb.StatusDeregistrationResponse{}, nil
 	}
 	s.streamChannels[si.stream] <- 1
@@ -565,18 +680,26 @@ func (s *StatusNotifyServer) StatusDeregister(ctx context.Context, dereg *pb.Sta
 	return &pb.StatusDeregistrationResponse{}, nil
 }
 
-// NewStatusNotifyServer will create a new StatusNotifyServer and destroys the previous one
-func NewStatusNotifyServer(readyNotifyClientID string, sh StatusNotifyServerHelpers) *StatusNotifyServer {
+// NewStatusNotifyServer will create a new StatusNotifyServer and destroys the previous one.
+// The registrations are shared by the replicas of the service.
+func NewStatusNotifyServer(service, readyNotifyClientID string, sh StatusNotifyServerHelpers) *StatusNotifyServer {
 
 	s := &StatusNotifyServer{
 		name:              readyNotifyClientID,
+		service:           service,
+		replica:           replicaID(),
 		appContexts:       make(map[string]appContextInfo),
 		statusClients:     make(map[string]streamInfo),
 		streamChannels:    make(map[pb.StatusNotify_StatusRegisterServer]chan int),
 		sh:                sh,
 		readyNotifyClient: nil, // initialize this later on Registration call
+		stop:              make(chan struct{}),
+	}
+	if notifServer != nil {
+		close(notifServer.stop)
 	}
 	notifServer = s
+	go s.runRegistrationCollector(s.stop)
 	return s
 }
 
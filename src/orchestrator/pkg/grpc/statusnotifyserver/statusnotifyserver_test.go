@@ -96,7 +96,7 @@ var _ = Describe("StatusNotifyServer", func() {
 	)
 
 	BeforeEach(func() {
-		ns = NewStatusNotifyServer("mockStatus", mockHelpers{})
+		ns = NewStatusNotifyServer("mock", "mockStatus", mockHelpers{})
 		addSummaryClients(ns)
 	})
 
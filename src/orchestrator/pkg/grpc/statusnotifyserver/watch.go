@@ -0,0 +1,216 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package statusnotifyserver
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	pkgerrors "github.com/pkg/errors"
+	pb "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/grpc/statusnotify"
+	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/validation"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// sseStream adapts an HTTP response to the StatusNotify_StatusRegisterServer
+// interface, so that REST clients can be registered with the StatusNotifyServer
+// in the same way as gRPC clients. Each StatusNotification is written as a
+// Server-Sent Event.
+type sseStream struct {
+	ctx         context.Context
+	w           http.ResponseWriter
+	flusher     http.Flusher
+	headersSent bool
+	mutex       sync.Mutex
+}
+
+func newSSEStream(ctx context.Context, w http.ResponseWriter) (*sseStream, error) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, pkgerrors.New("Streaming is not supported by the http response writer")
+	}
+	return &sseStream{ctx: ctx, w: w, flusher: flusher}, nil
+}
+
+// writeHeader sends the event stream response header. The caller must hold the mutex.
+func (s *sseStream) writeHeader() {
+	if s.headersSent {
+		return
+	}
+	s.w.Header().Set("Content-Type", "text/event-stream")
+	s.w.Header().Set("Cache-Control", "no-cache")
+	s.w.Header().Set("Connection", "keep-alive")
+	s.w.WriteHeader(http.StatusOK)
+	s.flusher.Flush()
+	s.headersSent = true
+}
+
+// Send writes the status notification as a "status" event
+func (s *sseStream) Send(n *pb.StatusNotification) error {
+	data, err := protojson.Marshal(n)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.ctx.Err() != nil {
+		return s.ctx.Err()
+	}
+	s.writeHeader()
+	if _, err := fmt.Fprintf(s.w, "event: status\ndata: %s\n\n", data); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+// SendHeader starts the event stream once the registration has been accepted
+func (s *sseStream) SendHeader(metadata.MD) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.writeHeader()
+	return nil
+}
+
+func (s *sseStream) SetHeader(metadata.MD) error {
+	return nil
+}
+
+func (s *sseStream) SetTrailer(metadata.MD) {}
+
+func (s *sseStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *sseStream) SendMsg(m interface{}) error {
+	n, ok := m.(*pb.StatusNotification)
+	if !ok {
+		return pkgerrors.Errorf("Unsupported message type: %T", m)
+	}
+	return s.Send(n)
+}
+
+func (s *sseStream) RecvMsg(m interface{}) error {
+	return pkgerrors.New("RecvMsg is not supported on a status watch stream")
+}
+
+// StatusRegistrationFromQuery builds a StatusRegistration, without a key, from the
+// query parameters of a status watch request. The supported parameters are the
+// same as the ones of the StatusRegister gRPC API:
+//
+//	clientId - optional, a unique identifier is generated when not provided
+//	status   - ready (default) or deployed
+//	output   - summary (default) or all
+//	app, cluster, resource - optional filters, may be repeated
+func StatusRegistrationFromQuery(qParams url.Values) (*pb.StatusRegistration, error) {
+	reg := &pb.StatusRegistration{
+		StatusType: pb.StatusValue_READY,
+		Output:     pb.OutputType_SUMMARY,
+		Apps:       make([]string, 0),
+		Clusters:   make([]string, 0),
+		Resources:  make([]string, 0),
+	}
+
+	if c, found := qParams["clientId"]; found {
+		if len(c[0]) == 0 {
+			return nil, pkgerrors.New("Invalid clientId query")
+		}
+		reg.ClientId = c[0]
+	} else {
+		reg.ClientId = fmt.Sprintf("watch-%016x", rand.Uint64())
+	}
+
+	if s, found := qParams["status"]; found {
+		switch s[0] {
+		case "ready":
+			reg.StatusType = pb.StatusValue_READY
+		case "deployed":
+			reg.StatusType = pb.StatusValue_DEPLOYED
+		default:
+			return nil, pkgerrors.New("Invalid query status")
+		}
+	}
+
+	if o, found := qParams["output"]; found {
+		switch o[0] {
+		case "summary":
+			reg.Output = pb.OutputType_SUMMARY
+		case "all":
+			reg.Output = pb.OutputType_ALL
+		default:
+			return nil, pkgerrors.New("Invalid query output")
+		}
+	}
+
+	for _, app := range qParams["app"] {
+		if errs := validation.IsValidName(app); len(errs) > 0 {
+			return nil, pkgerrors.New("Invalid app query")
+		}
+		reg.Apps = append(reg.Apps, app)
+	}
+
+	for _, cluster := range qParams["cluster"] {
+		// clusters are provided in the <provider>+<cluster> format used by the status query,
+		// with the '+' encoded as %2B. An unencoded '+' is decoded as a space, which is
+		// accepted as well since the names can't contain spaces.
+		cluster = strings.Replace(cluster, " ", "+", 1)
+		parts := strings.Split(cluster, "+")
+		if len(parts) != 2 {
+			return nil, pkgerrors.New("Invalid cluster query")
+		}
+		for _, p := range parts {
+			if errs := validation.IsValidName(p); len(errs) > 0 {
+				return nil, pkgerrors.New("Invalid cluster query")
+			}
+		}
+		reg.Clusters = append(reg.Clusters, cluster)
+	}
+
+	for _, resource := range qParams["resource"] {
+		if errs := validation.IsValidName(resource); len(errs) > 0 {
+			return nil, pkgerrors.New("Invalid resource query")
+		}
+		reg.Resources = append(reg.Resources, resource)
+	}
+
+	return reg, nil
+}
+
+// ServeStatusWatch registers the HTTP request as a status notification client and
+// streams StatusNotification messages to it as Server-Sent Events until the
+// client disconnects. The registration is handled by the same StatusNotifyServer
+// that serves the StatusRegister gRPC API.
+func ServeStatusWatch(w http.ResponseWriter, r *http.Request, reg *pb.StatusRegistration) {
+	if notifServer == nil {
+		log.Error("[StatusNotify SSE] Status notification server is not running", log.Fields{})
+		http.Error(w, "Status notification server is not running", http.StatusServiceUnavailable)
+		return
+	}
+
+	stream, err := newSSEStream(r.Context(), w)
+	if err != nil {
+		log.Error("[StatusNotify SSE] Unable to create status watch stream", log.Fields{"Error": err})
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	err = notifServer.StatusRegister(reg, stream)
+
+	stream.mutex.Lock()
+	defer stream.mutex.Unlock()
+	if err != nil && !stream.headersSent {
+		log.Error("[StatusNotify SSE] Status watch registration failed", log.Fields{"client": reg.ClientId, "Error": err})
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+}
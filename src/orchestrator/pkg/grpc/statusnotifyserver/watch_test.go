@@ -0,0 +1,73 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package statusnotifyserver
+
+import (
+	"context"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	pb "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/grpc/statusnotify"
+	"google.golang.org/grpc/metadata"
+)
+
+var _ = Describe("StatusWatch", func() {
+	Describe("StatusRegistrationFromQuery", func() {
+		It("uses the default status and output", func() {
+			reg, err := StatusRegistrationFromQuery(url.Values{})
+			Expect(err).To(BeNil())
+			Expect(reg.StatusType).To(Equal(pb.StatusValue_READY))
+			Expect(reg.Output).To(Equal(pb.OutputType_SUMMARY))
+			Expect(reg.ClientId).NotTo(BeEmpty())
+		})
+
+		It("parses the status, output and filters", func() {
+			q, _ := url.ParseQuery("clientId=portal&status=deployed&output=all&app=app1&app=app2&cluster=provider1%2Bcluster1&cluster=provider1+cluster2&resource=res1")
+			reg, err := StatusRegistrationFromQuery(q)
+			Expect(err).To(BeNil())
+			Expect(reg.ClientId).To(Equal("portal"))
+			Expect(reg.StatusType).To(Equal(pb.StatusValue_DEPLOYED))
+			Expect(reg.Output).To(Equal(pb.OutputType_ALL))
+			Expect(reg.Apps).To(Equal([]string{"app1", "app2"}))
+			Expect(reg.Clusters).To(Equal([]string{"provider1+cluster1", "provider1+cluster2"}))
+			Expect(reg.Resources).To(Equal([]string{"res1"}))
+		})
+
+		It("rejects invalid parameters", func() {
+			for _, q := range []string{"status=bogus", "output=detail", "clientId=", "cluster=cluster1", "cluster=provider1+cluster1+x", "cluster=provider1%20%2Bcluster1"} {
+				v, _ := url.ParseQuery(q)
+				_, err := StatusRegistrationFromQuery(v)
+				Expect(err).NotTo(BeNil(), q)
+			}
+		})
+	})
+
+	Describe("sseStream", func() {
+		It("writes notifications as server-sent events", func() {
+			w := httptest.NewRecorder()
+			s, err := newSSEStream(context.Background(), w)
+			Expect(err).To(BeNil())
+
+			Expect(s.SendHeader(metadata.MD{})).To(BeNil())
+			Expect(w.Code).To(Equal(200))
+			Expect(w.Header().Get("Content-Type")).To(Equal("text/event-stream"))
+
+			Expect(s.Send(&pb.StatusNotification{StatusValue: pb.StatusValue_READY})).To(BeNil())
+			body := w.Body.String()
+			Expect(strings.HasPrefix(body, "event: status\ndata: ")).To(BeTrue())
+			Expect(body).To(ContainSubstring("READY"))
+			Expect(strings.HasSuffix(body, "\n\n")).To(BeTrue())
+		})
+
+		It("stops sending once the request context is done", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			s, _ := newSSEStream(ctx, httptest.NewRecorder())
+			cancel()
+			Expect(s.Send(&pb.StatusNotification{})).NotTo(BeNil())
+		})
+	})
+})
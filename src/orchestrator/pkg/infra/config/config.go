@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"log"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 )
@@ -20,7 +21,19 @@ type Configuration struct {
 	Password               string `json:"password"`
 	DatabaseIP             string `json:"database-ip"`
 	DatabaseType           string `json:"database-type"`
+	ContextDbType          string `json:"contextdb-type"`
+	EmbeddedDbDir          string `json:"embedded-db-dir"`
+	DbMigration            string `json:"db-migration"`
+	KmsProvider            string `json:"kms-provider"`
+	KmsKeyFile             string `json:"kms-key-file"`
+	KmsPkcs11TokenFile     string `json:"kms-pkcs11-token-file"`
+	KmsPkcs11KeyLabel      string `json:"kms-pkcs11-key-label"`
+	KmsPkcs11PinFile       string `json:"kms-pkcs11-pin-file"`
+	KmsPluginEndpoint      string `json:"kms-plugin-endpoint"`
+	KmsPluginCAFile        string `json:"kms-plugin-ca-file"`
 	PluginDir              string `json:"plugin-dir"`
+	ConnectorPlugins       string `json:"connector-plugins"`
+	ConnectorPluginCAFile  string `json:"connector-plugin-ca-file"`
 	EtcdIP                 string `json:"etcd-ip"`
 	EtcdCert               string `json:"etcd-cert"`
 	EtcdKey                string `json:"etcd-key"`
@@ -36,8 +49,17 @@ type Configuration struct {
 	KubernetesLabelName    string `json:"kubernetes-label-name"`
 	LogLevel               string `json:"log-level"`
 	MaxRetries             string `json:"max-retries"`
+	MaxWorkers             string `json:"max-workers"`
+	AppContextMaxWorkers   string `json:"appcontext-max-workers"`
+	ClusterQPS             string `json:"cluster-qps"`
+	ClusterBurst           string `json:"cluster-burst"`
 	BackOff                int    `json:"db-schema-backoff"`
 	MaxBackOff             int    `json:"db-schema-max-backoff"`
+	IdempotencyKeyTTL      int    `json:"idempotency-key-ttl"`
+	ApproverClaim          string `json:"approver-claim"`
+	ApproverGroupsClaim    string `json:"approver-groups-claim"`
+	ApproverJwksURL        string `json:"approver-jwks-url"`
+	ApproverIssuer         string `json:"approver-issuer"`
 
 	// EMCO-internal communication
 	//    wait time for a grpc connection to become ready, in milliseconds
@@ -93,7 +115,19 @@ func defaultConfiguration() *Configuration {
 		Password:               "",
 		DatabaseIP:             "127.0.0.1",
 		DatabaseType:           "mongo",
+		ContextDbType:          "etcd",
+		EmbeddedDbDir:          "", // embedded databases are kept in memory only
+		DbMigration:            "apply",
+		KmsProvider:            "", // data keys are read from the environment
+		KmsKeyFile:             "",
+		KmsPkcs11TokenFile:     "",
+		KmsPkcs11KeyLabel:      "",
+		KmsPkcs11PinFile:       "",
+		KmsPluginEndpoint:      "",
+		KmsPluginCAFile:        "",
 		PluginDir:              cwd,
+		ConnectorPlugins:       "", // rsync, comma separated endpoints of connector plugins
+		ConnectorPluginCAFile:  "",
 		EtcdIP:                 "127.0.0.1",
 		EtcdCert:               "",
 		EtcdKey:                "",
@@ -107,10 +141,19 @@ func defaultConfiguration() *Configuration {
 		GrpcServerNameOverride: "",
 		ServicePort:            "",
 		KubernetesLabelName:    "",
+		ApproverClaim:          "preferred_username",
+		ApproverGroupsClaim:    "groups",
+		ApproverJwksURL:        "",     // keys verifying the bearer tokens of the approvers, no approver identity if empty
+		ApproverIssuer:         "",     // issuer of the bearer tokens of the approvers, any if empty
 		LogLevel:               "warn", // default log-level of all modules
 		MaxRetries:             "",     // rsync
+		MaxWorkers:             "",     // rsync, clusters handled at once, unbounded if empty
+		AppContextMaxWorkers:   "",     // rsync, clusters handled at once for an AppContext
+		ClusterQPS:             "",     // rsync, kube client QPS per cluster, client-go default if empty
+		ClusterBurst:           "",     // rsync, kube client burst per cluster
 		BackOff:                5,      // default backoff time interval for ref schema
 		MaxBackOff:             60,     // max backoff time interval for ref schema
+		IdempotencyKeyTTL:      86400,  // responses to requests with an Idempotency-Key are kept 24 hours
 		GrpcConnReadyTime:      1000,   // 1 second in milliseconds
 		GrpcConnTimeout:        1000,   // 1 second
 		GrpcCallTimeout:        10000,  // 10 seconds
@@ -180,3 +223,12 @@ func SetConfigValue(key string, value string) *Configuration {
 	}
 	return c
 }
+
+// ServiceName returns the name of the service, APP_NAME in the deployments or
+// else the name of its executable
+func ServiceName() string {
+	if name, ok := os.LookupEnv("APP_NAME"); ok && name != "" {
+		return name
+	}
+	return filepath.Base(os.Args[0])
+}
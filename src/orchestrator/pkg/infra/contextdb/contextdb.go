@@ -5,6 +5,7 @@ package contextdb
 
 import (
 	"context"
+	"path/filepath"
 
 	pkgerrors "github.com/pkg/errors"
 	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/config"
@@ -46,6 +47,13 @@ func createContextDBClient(dbType string) error {
 		if err != nil {
 			pkgerrors.Wrap(err, "Etcd Client Initialization failed with error")
 		}
+	case "embedded":
+		// create an embedded context database, stored in a file of the service if a directory is configured
+		var file string
+		if dir := config.GetConfiguration().EmbeddedDbDir; dir != "" {
+			file = filepath.Join(dir, config.ServiceName()+".contextdb.json")
+		}
+		Db, err = NewEmbeddedContextDb(file)
 	default:
 		return pkgerrors.New(dbType + "DB not supported")
 	}
@@ -55,8 +63,7 @@ func createContextDBClient(dbType string) error {
 // InitializeContextDatabase sets up the connection to the
 // configured database to allow the application to talk to it.
 func InitializeContextDatabase() error {
-	// Only support Etcd for now
-	err := createContextDBClient("etcd")
+	err := createContextDBClient(config.GetConfiguration().ContextDbType)
 	if err != nil {
 		return pkgerrors.Cause(err)
 	}
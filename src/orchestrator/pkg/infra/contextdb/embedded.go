@@ -0,0 +1,199 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package contextdb
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	pkgerrors "github.com/pkg/errors"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/fileutils"
+)
+
+// EmbeddedContextDb is an implementation of the ContextDb interface which keeps
+// the keys in memory. If a file is provided, the keys are loaded from and written
+// to the file on every change. It follows the semantics of the EtcdClient and is
+// meant for development, CI and single node deployments.
+type EmbeddedContextDb struct {
+	lock     sync.RWMutex
+	file     string
+	lockFile *os.File // held while the process uses the file
+	kvs      map[string]string
+	leased   map[string]struct{} // keys created with a lease, not persisted
+}
+
+// NewEmbeddedContextDb creates an EmbeddedContextDb. The keys are kept in
+// memory only if file is empty.
+func NewEmbeddedContextDb(file string) (ContextDb, error) {
+	e := &EmbeddedContextDb{
+		file:   file,
+		kvs:    make(map[string]string),
+		leased: make(map[string]struct{}),
+	}
+	if file != "" {
+		var err error
+		if e.lockFile, err = fileutils.LockFile(file); err != nil {
+			return nil, pkgerrors.Errorf("Error opening embedded context database: %s", err.Error())
+		}
+		b, err := ioutil.ReadFile(file)
+		if err != nil && !os.IsNotExist(err) {
+			e.close()
+			return nil, pkgerrors.Errorf("Error reading embedded context database file %s: %s", file, err.Error())
+		}
+		if len(b) > 0 {
+			if err := json.Unmarshal(b, &e.kvs); err != nil {
+				e.close()
+				return nil, pkgerrors.Errorf("Error decoding embedded context database file %s: %s", file, err.Error())
+			}
+		}
+	}
+	return e, nil
+}
+
+// persist writes the keys to the file. Must be called with the lock held.
+func (e *EmbeddedContextDb) persist() error {
+	if e.file == "" {
+		return nil
+	}
+	kvs := e.kvs
+	if len(e.leased) > 0 {
+		kvs = make(map[string]string, len(e.kvs))
+		for k, v := range e.kvs {
+			if _, ok := e.leased[k]; !ok {
+				kvs[k] = v
+			}
+		}
+	}
+	b, err := json.Marshal(kvs)
+	if err != nil {
+		return pkgerrors.Errorf("Json Marshal error: %s", err.Error())
+	}
+	if err := fileutils.WriteFile(e.file, b); err != nil {
+		return pkgerrors.Errorf("Error writing embedded context database: %s", err.Error())
+	}
+	return nil
+}
+
+// close releases the lock of the file, e.g. before it is reopened
+func (e *EmbeddedContextDb) close() {
+	if e.lockFile != nil {
+		e.lockFile.Close()
+		e.lockFile = nil
+	}
+}
+
+func (e *EmbeddedContextDb) put(key string, value interface{}) (bool, error) {
+	if key == "" {
+		return false, pkgerrors.Errorf("Key is null")
+	}
+	if value == nil {
+		return false, pkgerrors.Errorf("Value is nil")
+	}
+	v, err := json.Marshal(value)
+	if err != nil {
+		return false, pkgerrors.Errorf("Json Marshal error: %s", err.Error())
+	}
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	_, exists := e.kvs[key]
+	e.kvs[key] = string(v)
+	delete(e.leased, key)
+	if err := e.persist(); err != nil {
+		return exists, pkgerrors.Errorf("Error creating embedded context database entry: %s", err.Error())
+	}
+	return exists, nil
+}
+
+// Put values in the embedded context database
+func (e *EmbeddedContextDb) Put(ctx context.Context, key string, value interface{}) error {
+	_, err := e.put(key, value)
+	return err
+}
+
+// Put values in the embedded context database and check if already present
+func (e *EmbeddedContextDb) PutWithCheck(ctx context.Context, key string, value interface{}) error {
+	exists, err := e.put(key, value)
+	if err != nil {
+		return err
+	}
+	// Like etcd, the value is written even if the key was already present
+	if exists {
+		return pkgerrors.Errorf("Key exists %v", key)
+	}
+	return nil
+}
+
+// Get values from the embedded context database and decodes from json
+func (e *EmbeddedContextDb) Get(ctx context.Context, key string, value interface{}) error {
+	if key == "" {
+		return pkgerrors.Errorf("Key is null")
+	}
+	if value == nil {
+		return pkgerrors.Errorf("Value is nil")
+	}
+	e.lock.RLock()
+	v, ok := e.kvs[key]
+	e.lock.RUnlock()
+	if !ok {
+		return pkgerrors.Errorf("Key doesn't exist")
+	}
+	return json.Unmarshal([]byte(v), value)
+}
+
+// GetAllKeys returns the keys with the prefix, in the same order as etcd
+func (e *EmbeddedContextDb) GetAllKeys(ctx context.Context, path string) ([]string, error) {
+	e.lock.RLock()
+	var keys []string
+	for k := range e.kvs {
+		if strings.HasPrefix(k, path) {
+			keys = append(keys, k)
+		}
+	}
+	e.lock.RUnlock()
+	if len(keys) == 0 {
+		return nil, pkgerrors.Errorf("Key doesn't exist")
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// DeleteAll keys with the prefix from the embedded context database
+func (e *EmbeddedContextDb) DeleteAll(ctx context.Context, key string) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	for k := range e.kvs {
+		if strings.HasPrefix(k, key) {
+			delete(e.kvs, k)
+			delete(e.leased, k)
+		}
+	}
+	if err := e.persist(); err != nil {
+		return pkgerrors.Errorf("Delete failed embedded context database entry: %s", err.Error())
+	}
+	return nil
+}
+
+// Delete values from the embedded context database
+func (e *EmbeddedContextDb) Delete(ctx context.Context, key string) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	delete(e.kvs, key)
+	delete(e.leased, key)
+	if err := e.persist(); err != nil {
+		return pkgerrors.Errorf("Delete failed embedded context database entry: %s", err.Error())
+	}
+	return nil
+}
+
+// HealthCheck verifies that the database file can be written
+func (e *EmbeddedContextDb) HealthCheck() error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.persist()
+}
@@ -0,0 +1,152 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package contextdb
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEmbeddedContextDb(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "contextdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "contextdb.json")
+
+	e, err := NewEmbeddedContextDb(file)
+	if err != nil {
+		t.Fatalf("NewEmbeddedContextDb returned an error: %s", err)
+	}
+	t.Run("Put and Get", func(t *testing.T) {
+		if err := e.Put(ctx, "/context/1/", testStruct{Name: "test", Num: 5}); err != nil {
+			t.Fatalf("Put returned an error: %s", err)
+		}
+		var v testStruct
+		if err := e.Get(ctx, "/context/1/", &v); err != nil {
+			t.Fatalf("Get returned an error: %s", err)
+		}
+		if v.Name != "test" || v.Num != 5 {
+			t.Errorf("Get returned %v", v)
+		}
+		if err := e.Get(ctx, "/context/2/", &v); err == nil || !strings.Contains(err.Error(), "Key doesn't exist") {
+			t.Errorf("Get of a missing key returned %v", err)
+		}
+		if err := e.Put(ctx, "", "value"); err == nil {
+			t.Errorf("Put of an empty key succeeded")
+		}
+	})
+	t.Run("PutWithCheck", func(t *testing.T) {
+		if err := e.PutWithCheck(ctx, "/context/1/app/", "app"); err != nil {
+			t.Fatalf("PutWithCheck returned an error: %s", err)
+		}
+		if err := e.PutWithCheck(ctx, "/context/1/app/", "app"); err == nil || !strings.Contains(err.Error(), "Key exists") {
+			t.Errorf("PutWithCheck of an existing key returned %v", err)
+		}
+	})
+	t.Run("GetAllKeys", func(t *testing.T) {
+		keys, err := e.GetAllKeys(ctx, "/context/1/")
+		if err != nil {
+			t.Fatalf("GetAllKeys returned an error: %s", err)
+		}
+		if !reflect.DeepEqual(keys, []string{"/context/1/", "/context/1/app/"}) {
+			t.Errorf("GetAllKeys returned %v", keys)
+		}
+	})
+	t.Run("Lock", func(t *testing.T) {
+		if _, err := NewEmbeddedContextDb(file); err == nil || !strings.Contains(err.Error(), "used by another process") {
+			t.Errorf("NewEmbeddedContextDb of a file in use returned %v", err)
+		}
+	})
+	t.Run("Reopen", func(t *testing.T) {
+		e.(*EmbeddedContextDb).close()
+		r, err := NewEmbeddedContextDb(file)
+		if err != nil {
+			t.Fatalf("NewEmbeddedContextDb returned an error: %s", err)
+		}
+		e = r
+		var v string
+		if err := r.Get(ctx, "/context/1/app/", &v); err != nil || v != "app" {
+			t.Errorf("Get after reopen returned %v, %v", v, err)
+		}
+	})
+	t.Run("Delete and DeleteAll", func(t *testing.T) {
+		if err := e.Delete(ctx, "/context/1/"); err != nil {
+			t.Fatalf("Delete returned an error: %s", err)
+		}
+		keys, _ := e.GetAllKeys(ctx, "/context/")
+		if !reflect.DeepEqual(keys, []string{"/context/1/app/"}) {
+			t.Errorf("GetAllKeys after Delete returned %v", keys)
+		}
+		if err := e.DeleteAll(ctx, "/context/"); err != nil {
+			t.Fatalf("DeleteAll returned an error: %s", err)
+		}
+		if _, err := e.GetAllKeys(ctx, "/context/"); err == nil {
+			t.Errorf("GetAllKeys after DeleteAll returned no error")
+		}
+	})
+}
+
+func TestEmbeddedContextDbLease(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "contextdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "contextdb.json")
+
+	saved := Db
+	defer func() { Db = saved }()
+	Db, err = NewEmbeddedContextDb(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unlock, err := TryLock(ctx, "dig/p/ca/v/di")
+	if err != nil {
+		t.Fatalf("TryLock returned an error: %s", err)
+	}
+	if _, err := TryLock(ctx, "dig/p/ca/v/di"); err != ErrLocked {
+		t.Errorf("TryLock of a held lock returned %v", err)
+	}
+	if err := unlock(ctx); err != nil {
+		t.Fatal(err)
+	}
+	unlock, err = TryLock(ctx, "dig/p/ca/v/di")
+	if err != nil {
+		t.Errorf("TryLock of a released lock returned %v", err)
+	} else {
+		unlock(ctx)
+	}
+
+	if err := CreateWithLease(ctx, "/active/c1", "replica1"); err != nil {
+		t.Fatalf("CreateWithLease returned an error: %s", err)
+	}
+	if err := CreateWithLease(ctx, "/active/c1", "replica2"); err == nil || !strings.Contains(err.Error(), "Key exists") {
+		t.Errorf("CreateWithLease of an existing key returned %v", err)
+	}
+	Db.Put(ctx, "/context/1/", "1")
+
+	// The keys created with a lease are released when the process dies
+	Db.(*EmbeddedContextDb).close()
+	restarted, err := NewEmbeddedContextDb(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var v string
+	if err := restarted.Get(ctx, "/active/c1", &v); err == nil {
+		t.Errorf("A key created with a lease was persisted")
+	}
+	if err := restarted.Get(ctx, "/context/1/", &v); err != nil {
+		t.Errorf("A key was not persisted: %s", err)
+	}
+}
@@ -8,10 +8,12 @@ import (
 	"encoding/json"
 	"net"
 	"os"
+	"sync"
 	"time"
 
 	pkgerrors "github.com/pkg/errors"
         clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/concurrency"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 )
@@ -26,8 +28,11 @@ type EtcdConfig struct {
 
 // EtcdClient for Etcd
 type EtcdClient struct {
-	cli      *clientv3.Client
-	endpoint string
+	cli          *clientv3.Client
+	endpoint     string
+	sessionLock  sync.Mutex
+	leaseSession *concurrency.Session // lease of the process
+	locks        localLocks           // locks held by the process
 }
 
 // Etcd For Mocking purposes
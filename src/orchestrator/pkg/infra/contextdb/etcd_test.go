@@ -5,6 +5,7 @@ package contextdb
 
 import (
 	"context"
+	"os"
 
 	pkgerrors "github.com/pkg/errors"
 
@@ -272,3 +273,55 @@ func TestGetAll(t *testing.T) {
 		})
 	}
 }
+
+// TestEtcdTryLock runs against the etcd of the EMCO_TEST_ETCD_ENDPOINT host, if set
+func TestEtcdTryLock(t *testing.T) {
+	ctx := context.Background()
+
+	// The lock held by the process is not acquired again, without asking etcd
+	e := &EtcdClient{}
+	e.locks.TryLock(ctx, "dig/p/ca/v/di")
+	if _, locked, err := e.TryLock(ctx, "dig/p/ca/v/di"); locked || err != nil {
+		t.Errorf("TryLock of a lock held by the process returned %v, %v", locked, err)
+	}
+
+	endpoint := os.Getenv("EMCO_TEST_ETCD_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("EMCO_TEST_ETCD_ENDPOINT is not set")
+	}
+	replica := func() *EtcdClient {
+		c, err := NewEtcdClient(nil, EtcdConfig{Endpoint: endpoint})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c.(*EtcdClient)
+	}
+	r1, r2 := replica(), replica()
+	defer r1.cli.Close()
+	defer r2.cli.Close()
+
+	unlock, locked, err := r1.TryLock(ctx, "dig/p/ca/v/di")
+	if !locked || err != nil {
+		t.Fatalf("TryLock returned %v, %v", locked, err)
+	}
+	// The goroutines of a replica share its lease
+	if _, locked, err := r1.TryLock(ctx, "dig/p/ca/v/di"); locked || err != nil {
+		t.Errorf("TryLock of a lock held by the replica returned %v, %v", locked, err)
+	}
+	if _, locked, err := r2.TryLock(ctx, "dig/p/ca/v/di"); locked || err != nil {
+		t.Errorf("TryLock of a lock held by another replica returned %v, %v", locked, err)
+	}
+	if err := unlock(ctx); err != nil {
+		t.Fatal(err)
+	}
+	unlock, locked, err = r2.TryLock(ctx, "dig/p/ca/v/di")
+	if !locked || err != nil {
+		t.Fatalf("TryLock of a released lock returned %v, %v", locked, err)
+	}
+	if err := unlock(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, locked, err := r1.TryLock(ctx, "dig/p/ca/v/di"); !locked || err != nil {
+		t.Errorf("TryLock of a released lock returned %v, %v", locked, err)
+	}
+}
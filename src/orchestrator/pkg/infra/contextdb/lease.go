@@ -0,0 +1,199 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package contextdb
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	pkgerrors "github.com/pkg/errors"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/concurrency"
+)
+
+// UnlockFunc releases a lock
+type UnlockFunc func(ctx context.Context) error
+
+// LeaseDb is implemented by the context databases supporting keys bound to the
+// lifetime of the process which created them. The locks and the keys created with
+// a lease are released when the process dies, so that the replicas of a service
+// can share them.
+type LeaseDb interface {
+	// Acquires the named lock if it is not held, returns false if it is held
+	TryLock(ctx context.Context, name string) (UnlockFunc, bool, error)
+	// Puts the key bound to the lifetime of the process, if it is not present
+	CreateWithLease(ctx context.Context, key string, value interface{}) error
+}
+
+// ErrLocked is returned by TryLock when the lock is held
+var ErrLocked = pkgerrors.New("The lock is held by another process")
+
+// Prefix of the lock keys
+const lockPrefix = "/locks/"
+
+// TryLock acquires the named lock of the context database if it is not held.
+// The context databases which don't support leases use a lock of the process.
+func TryLock(ctx context.Context, name string) (UnlockFunc, error) {
+	var unlock UnlockFunc
+	var locked bool
+	var err error
+	if ldb, ok := Db.(LeaseDb); ok {
+		unlock, locked, err = ldb.TryLock(ctx, name)
+	} else {
+		unlock, locked, err = processLocks.TryLock(ctx, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !locked {
+		return nil, ErrLocked
+	}
+	return unlock, nil
+}
+
+// CreateWithLease puts the key bound to the lifetime of the process if it is not present.
+// It returns an error containing "Key exists" if the key is present.
+func CreateWithLease(ctx context.Context, key string, value interface{}) error {
+	if ldb, ok := Db.(LeaseDb); ok {
+		return ldb.CreateWithLease(ctx, key, value)
+	}
+	return Db.PutWithCheck(ctx, key, value)
+}
+
+// localLocks are the locks of a process
+type localLocks struct {
+	lock  sync.Mutex
+	names map[string]struct{}
+}
+
+var processLocks = &localLocks{names: make(map[string]struct{})}
+
+func (l *localLocks) TryLock(ctx context.Context, name string) (UnlockFunc, bool, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if _, ok := l.names[name]; ok {
+		return nil, false, nil
+	}
+	if l.names == nil {
+		l.names = make(map[string]struct{})
+	}
+	l.names[name] = struct{}{}
+	return func(ctx context.Context) error {
+		l.lock.Lock()
+		defer l.lock.Unlock()
+		delete(l.names, name)
+		return nil
+	}, true, nil
+}
+
+// Time to live of the etcd lease of the process, in seconds
+const etcdLeaseTTL = 15
+
+// session returns the etcd session of the process, creating a new one if the
+// previous session expired, e.g. after a network partition
+func (e *EtcdClient) session() (*concurrency.Session, error) {
+	e.sessionLock.Lock()
+	defer e.sessionLock.Unlock()
+	if e.cli == nil {
+		return nil, pkgerrors.Errorf("Etcd Client not initialized")
+	}
+	if e.leaseSession != nil {
+		select {
+		case <-e.leaseSession.Done():
+		default:
+			return e.leaseSession, nil
+		}
+	}
+	s, err := concurrency.NewSession(e.cli, concurrency.WithTTL(etcdLeaseTTL))
+	if err != nil {
+		return nil, pkgerrors.Errorf("Error creating etcd session: %s", err.Error())
+	}
+	e.leaseSession = s
+	return s, nil
+}
+
+// TryLock acquires the named lock with the lease of the process. etcd takes the
+// holder of the lease as the owner of the lock, so the lock is also held by the
+// client for the other goroutines of the process sharing the lease.
+func (e *EtcdClient) TryLock(ctx context.Context, name string) (UnlockFunc, bool, error) {
+	unlockLocal, locked, err := e.locks.TryLock(ctx, name)
+	if err != nil || !locked {
+		return nil, false, err
+	}
+	s, err := e.session()
+	if err != nil {
+		unlockLocal(ctx)
+		return nil, false, err
+	}
+	m := concurrency.NewMutex(s, lockPrefix+name)
+	if err := m.TryLock(ctx); err != nil {
+		unlockLocal(ctx)
+		if err == concurrency.ErrLocked {
+			return nil, false, nil
+		}
+		return nil, false, pkgerrors.Errorf("Error acquiring etcd lock %s: %s", name, err.Error())
+	}
+	return func(ctx context.Context) error {
+		defer unlockLocal(ctx)
+		return m.Unlock(ctx)
+	}, true, nil
+}
+
+// CreateWithLease puts the key with the lease of the process, if it is not present
+func (e *EtcdClient) CreateWithLease(ctx context.Context, key string, value interface{}) error {
+	if key == "" {
+		return pkgerrors.Errorf("Key is null")
+	}
+	if value == nil {
+		return pkgerrors.Errorf("Value is nil")
+	}
+	v, err := json.Marshal(value)
+	if err != nil {
+		return pkgerrors.Errorf("Json Marshal error: %s", err.Error())
+	}
+	s, err := e.session()
+	if err != nil {
+		return err
+	}
+	resp, err := e.cli.Txn(ctx).
+		If(clientv3.Compare(clientv3.CreateRevision(key), "=", 0)).
+		Then(clientv3.OpPut(key, string(v), clientv3.WithLease(s.Lease()))).
+		Commit()
+	if err != nil {
+		return pkgerrors.Errorf("Error creating etcd entry: %s", err.Error())
+	}
+	if !resp.Succeeded {
+		return pkgerrors.Errorf("Key exists %v", key)
+	}
+	return nil
+}
+
+// TryLock acquires the named lock. The embedded database is used by a single process.
+func (e *EmbeddedContextDb) TryLock(ctx context.Context, name string) (UnlockFunc, bool, error) {
+	return processLocks.TryLock(ctx, name)
+}
+
+// CreateWithLease puts the key if it is not present. The key is not persisted,
+// so it is released when the process dies.
+func (e *EmbeddedContextDb) CreateWithLease(ctx context.Context, key string, value interface{}) error {
+	if key == "" {
+		return pkgerrors.Errorf("Key is null")
+	}
+	if value == nil {
+		return pkgerrors.Errorf("Value is nil")
+	}
+	v, err := json.Marshal(value)
+	if err != nil {
+		return pkgerrors.Errorf("Json Marshal error: %s", err.Error())
+	}
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if _, ok := e.kvs[key]; ok {
+		return pkgerrors.Errorf("Key exists %v", key)
+	}
+	e.kvs[key] = string(v)
+	e.leased[key] = struct{}{}
+	return nil
+}
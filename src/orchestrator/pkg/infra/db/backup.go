@@ -0,0 +1,20 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package db
+
+import (
+	"encoding/json"
+
+	"golang.org/x/net/context"
+)
+
+// BackupStore is implemented by the stores which support backup and restore.
+// The documents are exported in a format specific to the store, so they can
+// only be imported in a store of the same type.
+type BackupStore interface {
+	// Returns all the documents of the collection
+	Export(ctx context.Context, coll string) ([]json.RawMessage, error)
+	// Replaces all the documents of the collection with the documents
+	Import(ctx context.Context, coll string, docs []json.RawMessage) error
+}
@@ -0,0 +1,680 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package db
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+
+	pkgerrors "github.com/pkg/errors"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/fileutils"
+	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+	utils "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/utils"
+	"golang.org/x/net/context"
+)
+
+// embeddedDocument holds the key fields, query fields, keyId, references
+// and tags of a document as JSON values
+type embeddedDocument map[string]json.RawMessage
+
+// EmbeddedStore is an implementation of the db.Store interface which keeps
+// the documents in memory. If a file is provided, the documents are loaded
+// from and written to the file on every change. It follows the semantics of
+// the MongoStore, including the referential integrity checks, and is meant
+// for development, CI and single node deployments.
+type EmbeddedStore struct {
+	lock     sync.RWMutex
+	file     string
+	lockFile *os.File // held while the process uses the file
+	colls    map[string][]embeddedDocument
+	locks    map[string]bool
+}
+
+// NewEmbeddedStore creates an EmbeddedStore. The documents are kept in
+// memory only if file is empty.
+func NewEmbeddedStore(ctx context.Context, file string) (Store, error) {
+	e, err := openEmbeddedStore(file)
+	if err != nil {
+		return nil, err
+	}
+
+	go readRefSchema(ctx, e)
+
+	return e, nil
+}
+
+// openEmbeddedStore loads the documents of the file
+func openEmbeddedStore(file string) (*EmbeddedStore, error) {
+	e := &EmbeddedStore{
+		file:  file,
+		colls: make(map[string][]embeddedDocument),
+		locks: make(map[string]bool),
+	}
+	if file != "" {
+		var err error
+		if e.lockFile, err = fileutils.LockFile(file); err != nil {
+			return nil, pkgerrors.Wrap(err, "Error opening embedded database")
+		}
+		b, err := ioutil.ReadFile(file)
+		if err != nil && !os.IsNotExist(err) {
+			e.close()
+			return nil, pkgerrors.Wrapf(err, "Error reading embedded database file %s", file)
+		}
+		if len(b) > 0 {
+			if err := json.Unmarshal(b, &e.colls); err != nil {
+				e.close()
+				return nil, pkgerrors.Wrapf(err, "Error decoding embedded database file %s", file)
+			}
+		}
+	}
+	return e, nil
+}
+
+// HealthCheck verifies that the database file can be written
+func (e *EmbeddedStore) HealthCheck(ctx context.Context) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.persist()
+}
+
+// persist writes the documents to the file. Must be called with the lock held.
+func (e *EmbeddedStore) persist() error {
+	if e.file == "" {
+		return nil
+	}
+	b, err := json.Marshal(e.colls)
+	if err != nil {
+		return pkgerrors.Wrap(err, "Error encoding embedded database")
+	}
+	if err := fileutils.WriteFile(e.file, b); err != nil {
+		return pkgerrors.Wrap(err, "Error writing embedded database")
+	}
+	return nil
+}
+
+// close releases the lock of the file, e.g. before it is reopened
+func (e *EmbeddedStore) close() {
+	if e.lockFile != nil {
+		e.lockFile.Close()
+		e.lockFile = nil
+	}
+}
+
+// keyFields returns the JSON encoded fields of a key
+func keyFields(key interface{}) (map[string]json.RawMessage, error) {
+	var n map[string]string
+	st, err := json.Marshal(key)
+	if err != nil {
+		return nil, pkgerrors.Errorf("Error Marshalling key: %s", err.Error())
+	}
+	err = json.Unmarshal(st, &n)
+	if err != nil {
+		return nil, pkgerrors.Errorf("Error Unmarshalling key to map: %s", err.Error())
+	}
+	fields := make(map[string]json.RawMessage, len(n))
+	for k, v := range n {
+		fields[k], _ = json.Marshal(v)
+	}
+	return fields, nil
+}
+
+// matches returns true if the document has all the fields with the same values
+func (d embeddedDocument) matches(fields map[string]json.RawMessage) bool {
+	for k, v := range fields {
+		if !bytes.Equal(d[k], v) {
+			return false
+		}
+	}
+	return true
+}
+
+// exactFilter matches the documents which have all the fields of the key,
+// like the findFilter of the MongoStore
+func exactFilter(key Key) (map[string]json.RawMessage, error) {
+	return keyFields(key)
+}
+
+// wildcardFilter matches the documents which have the non empty fields of the key.
+// If a field of the key is empty, only documents of the same key type are matched,
+// like the findFilterWithKey of the MongoStore.
+func wildcardFilter(key Key) (map[string]json.RawMessage, error) {
+	fields, err := keyFields(key)
+	if err != nil {
+		return nil, err
+	}
+	empty, _ := json.Marshal("")
+	filter := make(map[string]json.RawMessage)
+	for k, v := range fields {
+		if bytes.Equal(v, empty) {
+			if _, ok := filter["keyId"]; !ok {
+				keyId, err := createKeyIdField(key)
+				if err != nil {
+					return nil, err
+				}
+				filter["keyId"], _ = json.Marshal(keyId)
+			}
+		} else {
+			filter[k] = v
+		}
+	}
+	return filter, nil
+}
+
+// first returns the index of the first document of the collection matching the filter
+func (e *EmbeddedStore) first(coll string, filter map[string]json.RawMessage) int {
+	for i, d := range e.colls[coll] {
+		if d.matches(filter) {
+			return i
+		}
+	}
+	return -1
+}
+
+// count returns the number of documents of the collection matching the filter
+func (e *EmbeddedStore) count(coll string, filter map[string]json.RawMessage) int {
+	n := 0
+	for _, d := range e.colls[coll] {
+		if d.matches(filter) {
+			n++
+		}
+	}
+	return n
+}
+
+// upsert returns the first document matching the filter, creating it if needed
+func (e *EmbeddedStore) upsert(coll string, filter map[string]json.RawMessage) embeddedDocument {
+	if i := e.first(coll, filter); i >= 0 {
+		return e.colls[coll][i]
+	}
+	d := make(embeddedDocument)
+	for k, v := range filter {
+		d[k] = v
+	}
+	e.colls[coll] = append(e.colls[coll], d)
+	return d
+}
+
+// Unmarshal implements an unmarshaler for the json data
+// that is produced by the embedded database
+func (e *EmbeddedStore) Unmarshal(inp []byte, out interface{}) error {
+	err := json.Unmarshal(inp, out)
+	if err != nil {
+		return pkgerrors.Wrapf(err, "Error Unmarshalling json data to %T", out)
+	}
+
+	// Decrypt data if required
+	oe := utils.GetObjectEncryptor("emco")
+	if oe != nil {
+		_, err := oe.DecryptObject(out)
+		if err != nil {
+			log.Warn("Error to decrypt object", log.Fields{"error": err.Error()})
+		}
+	}
+	return nil
+}
+
+// Insert is used to insert/add element to a document
+func (e *EmbeddedStore) Insert(ctx context.Context, coll string, key Key, query interface{}, tag string, data interface{}) error {
+
+	if data == nil {
+		return pkgerrors.Errorf("db Insert error: No data to store")
+	}
+
+	if !validateParams(coll, key, tag) {
+		return pkgerrors.Errorf("db Insert error: Mandatory fields are missing. Collection: %s, Key: %T %v, Tag: %s", coll, key, key, tag)
+	}
+
+	filter, err := exactFilter(key)
+	if err != nil {
+		return pkgerrors.Wrapf(err, "db Insert error: Error finding filter with key %T %v", key, key)
+	}
+
+	// Create and add keyId tag
+	keyId, err := createKeyIdField(key)
+	if err != nil {
+		return pkgerrors.Wrapf(err, "db Insert error: Error creating KeyID with key %T %v", key, key)
+	}
+
+	// Encrypt data if required
+	oe := utils.GetObjectEncryptor("emco")
+	if oe != nil {
+		var edata interface{}
+		if reflect.TypeOf(data).Kind() == reflect.Ptr {
+			// avoid changing data's field value during encryption
+			edata, err = oe.EncryptObject(reflect.ValueOf(data).Elem().Interface())
+		} else {
+			edata, err = oe.EncryptObject(data)
+		}
+
+		if err != nil {
+			// Do not store in clear the data which must be encrypted, e.g. while the KMS is unavailable
+			log.Error("Error to encrypt object", log.Fields{"collection": coll, "tag": tag, "error": err})
+			return pkgerrors.Wrap(err, "db Insert error: Error encrypting data")
+		}
+		data = edata
+	}
+
+	// verify references for Inserts with the "data" tag
+	var refs []ReferenceEntry
+	if tag == "data" {
+		// References are looked up with Find, so the lock is not held yet
+		refs, err = verifyReferences(ctx, e, coll, key, keyId, data)
+		if err != nil {
+			if strings.Contains(err.Error(), "Parent resource not found") {
+				// these errors should be handled separately, not as an internal server error
+				return pkgerrors.Wrapf(err, "db Insert parent resource not found")
+			}
+
+			if strings.Contains(err.Error(), "is not present in referential schema") {
+				// these errors should be handled separately, not as an internal server error
+				return pkgerrors.Wrapf(err, "db Insert referential schema missing")
+
+			}
+
+			return pkgerrors.Wrapf(err, "db Insert error: Error verifying the references. Collection: %s, Key: %T %v, KeyID: %s", coll, key, key, keyId)
+		}
+	}
+
+	value, err := json.Marshal(data)
+	if err != nil {
+		return pkgerrors.Wrapf(err, "db Insert error: Error Marshalling data")
+	}
+	var queryFields map[string]json.RawMessage
+	if query != nil {
+		queryFields, err = keyFields(query)
+		if err != nil {
+			return pkgerrors.Wrapf(err, "db Insert error: Error updating filter with query %T %v", query, query)
+		}
+	}
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	d := e.upsert(coll, filter)
+	d[tag] = value
+	d["keyId"], _ = json.Marshal(keyId)
+	if tag == "data" {
+		if refs == nil {
+			refs = make([]ReferenceEntry, 0)
+		}
+		d["references"], _ = json.Marshal(refs)
+	}
+	// Add Query fields
+	for k, v := range queryFields {
+		d[k] = v
+	}
+
+	if err := e.persist(); err != nil {
+		return pkgerrors.Wrapf(err, "db Insert error")
+	}
+	return nil
+}
+
+// Find method returns the data stored for this key and for this particular tag
+func (e *EmbeddedStore) Find(ctx context.Context, coll string, key Key, tag string) ([][]byte, error) {
+
+	if !validateParams(coll, key, tag) {
+		return nil, pkgerrors.Errorf("db Find error: Mandatory fields are missing. Collection: %s, Key: %T %v, Tag: %s", coll, key, key, tag)
+	}
+
+	filter, err := wildcardFilter(key)
+	if err != nil {
+		return nil, pkgerrors.Wrapf(err, "db Find error: Error finding filter with key %T %v", key, key)
+	}
+
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	var result [][]byte
+	for _, d := range e.colls[coll] {
+		if !d.matches(filter) {
+			continue
+		}
+		v, ok := d[tag]
+		if !ok {
+			// Like the MongoStore, documents without the tag return no data
+			result = append(result, nil)
+			continue
+		}
+		// Strings are returned as is
+		var s string
+		if err := json.Unmarshal(v, &s); err == nil {
+			result = append(result, []byte(s))
+			continue
+		}
+		data := make([]byte, len(v))
+		copy(data, v)
+		result = append(result, data)
+	}
+	return result, nil
+}
+
+// RemoveAll method to removes all the documet matching key
+func (e *EmbeddedStore) RemoveAll(ctx context.Context, coll string, key Key) error {
+	if !validateParams(coll, key) {
+		return pkgerrors.Errorf("db Remove error: Mandatory fields are missing. Collection: %s, Key: %T %v", coll, key, key)
+	}
+	filter, err := wildcardFilter(key)
+	if err != nil {
+		return pkgerrors.Wrapf(err, "db Remove error: Error finding filter with key %T %v", key, key)
+	}
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	docs := e.colls[coll][:0]
+	for _, d := range e.colls[coll] {
+		if !d.matches(filter) {
+			docs = append(docs, d)
+		}
+	}
+	e.colls[coll] = docs
+
+	if err := e.persist(); err != nil {
+		return pkgerrors.Wrapf(err, "db Remove error: Error deleting document(s) from database. Key: %T %v", key, key)
+	}
+	return nil
+}
+
+// referencedBy returns the number of documents of the collection
+// which reference the resource identified by the key
+func (e *EmbeddedStore) referencedBy(coll string, key Key) (int, error) {
+	keyId, err := createKeyIdField(key)
+	if err != nil {
+		return 0, err
+	}
+	fields, err := keyFields(key)
+	if err != nil {
+		return 0, err
+	}
+	empty, _ := json.Marshal("")
+	n := 0
+	for _, d := range e.colls[coll] {
+		var refs []struct {
+			Key   map[string]json.RawMessage
+			KeyId string
+		}
+		if err := json.Unmarshal(d["references"], &refs); err != nil {
+			continue
+		}
+		for _, r := range refs {
+			if r.KeyId != keyId {
+				continue
+			}
+			found := true
+			for k, v := range fields {
+				if !bytes.Equal(v, empty) && !bytes.Equal(r.Key[k], v) {
+					found = false
+					break
+				}
+			}
+			if found {
+				n++
+				break
+			}
+		}
+	}
+	return n, nil
+}
+
+// Remove method to remove the documet by key if no child references
+func (e *EmbeddedStore) Remove(ctx context.Context, coll string, key Key) error {
+	if !validateParams(coll, key) {
+		return pkgerrors.Errorf("db Remove error: Mandatory fields are missing. Collection: %s, Key: %T %v", coll, key, key)
+	}
+
+	filter, err := exactFilter(key)
+	if err != nil {
+		return pkgerrors.Wrapf(err, "db Remove error: Error finding filter with key %T %v", key, key)
+	}
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	// search for child references - assumes all children are part of the
+	// same collection
+	count := e.count(coll, filter)
+
+	if count == 0 {
+		return pkgerrors.Errorf("db Remove resource not found: The requested resource not found. Key: %T %v", key, key)
+	}
+
+	if count > 1 {
+		return pkgerrors.Errorf("db Remove parent child constraint: Cannot delete parent without deleting child references first. Key: %T %v", key, key)
+	}
+
+	// search to see if this document is referenced by any other document
+	count, err = e.referencedBy(coll, key)
+	if err != nil {
+		return pkgerrors.Wrapf(err, "db Remove error: Error finding referencing resources for key %T %v", key, key)
+	}
+
+	if count > 0 {
+		return pkgerrors.Errorf("db Remove referential constraint: Cannot delete without deleting or updating referencing resources first. Key: %T %v", key, key)
+	}
+
+	// ok to delete the document
+	i := e.first(coll, filter)
+	e.colls[coll] = append(e.colls[coll][:i], e.colls[coll][i+1:]...)
+
+	if err := e.persist(); err != nil {
+		return pkgerrors.Wrapf(err, "db Remove error: Error deleting document from database. Key: %T %v", key, key)
+	}
+	return nil
+}
+
+// RemoveTag is used to remove an element from a document
+func (e *EmbeddedStore) RemoveTag(ctx context.Context, coll string, key Key, tag string) error {
+	filter, err := exactFilter(key)
+	if err != nil {
+		return err
+	}
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	d := e.upsert(coll, filter)
+	delete(d, tag)
+
+	if err := e.persist(); err != nil {
+		return pkgerrors.Errorf("Error removing tag: %s", err.Error())
+	}
+	return nil
+}
+
+// FindDocuments returns the documents of the collection with the keyId, or all the documents if keyId is empty
+func (e *EmbeddedStore) FindDocuments(ctx context.Context, coll string, keyId string) ([]Document, error) {
+	id, _ := json.Marshal(keyId)
+
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	var docs []Document
+	for _, d := range e.colls[coll] {
+		if keyId != "" && !bytes.Equal(d["keyId"], id) {
+			continue
+		}
+		doc, err := d.document()
+		if err != nil {
+			return nil, pkgerrors.Wrap(err, "db FindDocuments error: Unable to decode document")
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+// ScanDocuments calls fn with each document of the collection with the keyId, or with all the
+// documents if keyId is empty. fn is called without holding the store lock, so it can update the documents.
+func (e *EmbeddedStore) ScanDocuments(ctx context.Context, coll string, keyId string, fn func(Document) error) error {
+	id, _ := json.Marshal(keyId)
+
+	e.lock.RLock()
+	stored := append([]embeddedDocument(nil), e.colls[coll]...)
+	e.lock.RUnlock()
+
+	for _, d := range stored {
+		if keyId != "" && !bytes.Equal(d["keyId"], id) {
+			continue
+		}
+		doc, err := d.document()
+		if err != nil {
+			return pkgerrors.Wrap(err, "db ScanDocuments error: Unable to decode document")
+		}
+		if err := fn(doc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CountDocuments returns the number of documents of the collection with the keyId, or of all the documents if keyId is empty
+func (e *EmbeddedStore) CountDocuments(ctx context.Context, coll string, keyId string) (int, error) {
+	id, _ := json.Marshal(keyId)
+
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	n := 0
+	for _, d := range e.colls[coll] {
+		if keyId == "" || bytes.Equal(d["keyId"], id) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// FindDocumentsWithKey returns the documents of the collection matching the key, like Find
+func (e *EmbeddedStore) FindDocumentsWithKey(ctx context.Context, coll string, key Key) ([]Document, error) {
+	filter, err := wildcardFilter(key)
+	if err != nil {
+		return nil, pkgerrors.Wrapf(err, "db FindDocuments error: Error finding filter with key %T %v", key, key)
+	}
+
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	var docs []Document
+	for _, d := range e.colls[coll] {
+		if !d.matches(filter) {
+			continue
+		}
+		doc, err := d.document()
+		if err != nil {
+			return nil, pkgerrors.Wrap(err, "db FindDocuments error: Unable to decode document")
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+// document decodes the fields of the document
+func (d embeddedDocument) document() (Document, error) {
+	doc := make(Document, len(d))
+	for k, v := range d {
+		var value interface{}
+		if err := json.Unmarshal(v, &value); err != nil {
+			return nil, err
+		}
+		doc[k] = value
+	}
+	return doc, nil
+}
+
+// ReplaceDocument replaces a document returned by FindDocuments with its migrated version
+func (e *EmbeddedStore) ReplaceDocument(ctx context.Context, coll string, old, new Document) error {
+	d := make(embeddedDocument, len(new))
+	for k, v := range new {
+		value, err := json.Marshal(v)
+		if err != nil {
+			return pkgerrors.Wrap(err, "db ReplaceDocument error")
+		}
+		d[k] = value
+	}
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	for i, c := range e.colls[coll] {
+		doc := make(Document, len(c))
+		for k, v := range c {
+			var value interface{}
+			if err := json.Unmarshal(v, &value); err != nil {
+				return pkgerrors.Wrap(err, "db ReplaceDocument error: Unable to decode document")
+			}
+			doc[k] = value
+		}
+		if reflect.DeepEqual(doc, old) {
+			e.colls[coll][i] = d
+			if err := e.persist(); err != nil {
+				return pkgerrors.Wrap(err, "db ReplaceDocument error")
+			}
+			return nil
+		}
+	}
+	return pkgerrors.New("db ReplaceDocument error: Document not found")
+}
+
+// Lock acquires the named lock. The locks are held in memory, since the
+// embedded database is used by a single process.
+func (e *EmbeddedStore) Lock(ctx context.Context, coll string, name string) (bool, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if e.locks[coll+"/"+name] {
+		return false, nil
+	}
+	e.locks[coll+"/"+name] = true
+	return true, nil
+}
+
+// Unlock releases the named lock
+func (e *EmbeddedStore) Unlock(ctx context.Context, coll string, name string) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	delete(e.locks, coll+"/"+name)
+	return nil
+}
+
+// Export returns all the documents of the collection
+func (e *EmbeddedStore) Export(ctx context.Context, coll string) ([]json.RawMessage, error) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	docs := make([]json.RawMessage, 0, len(e.colls[coll]))
+	for _, d := range e.colls[coll] {
+		b, err := json.Marshal(d)
+		if err != nil {
+			return nil, pkgerrors.Wrap(err, "db Export error")
+		}
+		docs = append(docs, b)
+	}
+	return docs, nil
+}
+
+// Import replaces all the documents of the collection with the documents returned by Export
+func (e *EmbeddedStore) Import(ctx context.Context, coll string, docs []json.RawMessage) error {
+	values := make([]embeddedDocument, 0, len(docs))
+	for _, d := range docs {
+		var v embeddedDocument
+		if err := json.Unmarshal(d, &v); err != nil {
+			return pkgerrors.Wrap(err, "db Import error: Unable to decode document")
+		}
+		values = append(values, v)
+	}
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.colls[coll] = values
+	if err := e.persist(); err != nil {
+		return pkgerrors.Wrap(err, "db Import error")
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type testClusterProviderKey struct {
+	ClusterProvider string `json:"clusterProvider"`
+}
+
+type testClusterKey struct {
+	ClusterProvider string `json:"clusterProvider"`
+	Cluster         string `json:"cluster"`
+}
+
+type testSpec struct {
+	Name string `json:"name"`
+}
+
+var _ = Describe("Embedded store",
+	func() {
+		var (
+			ctx   context.Context
+			store *EmbeddedStore
+			dir   string
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			var err error
+			dir, err = ioutil.TempDir("", "embedded")
+			Expect(err).To(BeNil())
+			store = &EmbeddedStore{
+				file:  filepath.Join(dir, "emco.db.json"),
+				colls: make(map[string][]embeddedDocument),
+				locks: make(map[string]bool),
+			}
+			refSchemaFile = wd + "/test-schemas/emco-base.yaml"
+			schema, err := readSchema()
+			Expect(err).To(BeNil())
+			waitForSchema, err := processSchema(ctx, store, schema)
+			Expect(err).To(BeNil())
+			Expect(waitForSchema).To(BeFalse())
+		})
+
+		AfterEach(func() {
+			store.close()
+			os.RemoveAll(dir)
+			refSchemaFile = ""
+			refSchemaMap = nil
+			refKeyMap = nil
+		})
+
+		insert := func(key Key, name string) error {
+			return store.Insert(ctx, "resources", key, nil, "data", testSpec{Name: name})
+		}
+
+		find := func(key Key) []testSpec {
+			values, err := store.Find(ctx, "resources", key, "data")
+			Expect(err).To(BeNil())
+			specs := make([]testSpec, 0)
+			for _, v := range values {
+				s := testSpec{}
+				Expect(store.Unmarshal(v, &s)).To(BeNil())
+				specs = append(specs, s)
+			}
+			return specs
+		}
+
+		Context("when inserting resources", func() {
+			It("finds the resources by key and by partial key", func() {
+				validate(insert(testClusterProviderKey{"p1"}, "p1"), "")
+				validate(insert(testClusterProviderKey{"p2"}, "p2"), "")
+				validate(insert(testClusterKey{"p1", "c1"}, "c1"), "")
+				validate(insert(testClusterProviderKey{"p1"}, "p1-updated"), "")
+				Expect(find(testClusterKey{"p1", "c1"})).To(Equal([]testSpec{{"c1"}}))
+				Expect(find(testClusterKey{"p1", ""})).To(Equal([]testSpec{{"c1"}}))
+				Expect(find(testClusterProviderKey{""})).To(Equal([]testSpec{{"p1-updated"}, {"p2"}}))
+				Expect(find(testClusterKey{"p2", "c1"})).To(BeEmpty())
+			})
+
+			It("returns an error if the parent does not exist", func() {
+				validate(insert(testClusterKey{"p1", "c1"}, "c1"), "Parent resource not found")
+			})
+
+			It("adds the query fields to the document", func() {
+				err := store.Insert(ctx, "resources", testClusterProviderKey{"p1"}, testSpec{"q"}, "data", testSpec{Name: "p1"})
+				validate(err, "")
+				Expect(find(testSpec{"q"})).To(Equal([]testSpec{{"p1"}}))
+			})
+
+			It("finds the documents of a resource and of its children", func() {
+				validate(insert(testClusterProviderKey{"p1"}, "p1"), "")
+				validate(insert(testClusterProviderKey{"p2"}, "p2"), "")
+				validate(insert(testClusterKey{"p1", "c1"}, "c1"), "")
+				validate(insert(testClusterKey{"p2", "c2"}, "c2"), "")
+				docs, err := store.FindDocumentsWithKey(ctx, "resources", testClusterProviderKey{"p1"})
+				validate(err, "")
+				Expect(docs).To(HaveLen(2))
+				for _, d := range docs {
+					Expect(d["clusterProvider"]).To(Equal("p1"))
+				}
+			})
+		})
+
+		Context("when removing resources", func() {
+			It("does not remove a parent with children", func() {
+				validate(insert(testClusterProviderKey{"p1"}, "p1"), "")
+				validate(insert(testClusterKey{"p1", "c1"}, "c1"), "")
+				validate(store.Remove(ctx, "resources", testClusterProviderKey{"p1"}), "parent child constraint")
+				validate(store.RemoveAll(ctx, "resources", testClusterKey{"p1", ""}), "")
+				validate(store.Remove(ctx, "resources", testClusterProviderKey{"p1"}), "")
+				Expect(find(testClusterProviderKey{""})).To(BeEmpty())
+				validate(store.Remove(ctx, "resources", testClusterProviderKey{"p1"}), "resource not found")
+			})
+
+			It("does not remove a referenced resource", func() {
+				validate(insert(testClusterProviderKey{"p1"}, "p1"), "")
+				validate(insert(testClusterProviderKey{"p2"}, "p2"), "")
+				refs, _ := json.Marshal([]ReferenceEntry{{Key: map[string]string{"clusterProvider": "p1"}, KeyId: "{clusterProvider}"}})
+				filter, _ := exactFilter(testClusterProviderKey{"p2"})
+				store.colls["resources"][store.first("resources", filter)]["references"] = refs
+				validate(store.Remove(ctx, "resources", testClusterProviderKey{"p1"}), "referential constraint")
+				validate(store.Remove(ctx, "resources", testClusterProviderKey{"p2"}), "")
+				validate(store.Remove(ctx, "resources", testClusterProviderKey{"p1"}), "")
+			})
+
+			It("removes a tag", func() {
+				validate(insert(testClusterProviderKey{"p1"}, "p1"), "")
+				validate(store.Insert(ctx, "resources", testClusterProviderKey{"p1"}, nil, "state", "created"), "")
+				values, err := store.Find(ctx, "resources", testClusterProviderKey{"p1"}, "state")
+				validate(err, "")
+				Expect(values).To(Equal([][]byte{[]byte("created")}))
+				validate(store.RemoveTag(ctx, "resources", testClusterProviderKey{"p1"}, "state"), "")
+				values, err = store.Find(ctx, "resources", testClusterProviderKey{"p1"}, "state")
+				validate(err, "")
+				Expect(values).To(Equal([][]byte{nil}))
+				Expect(find(testClusterProviderKey{"p1"})).To(Equal([]testSpec{{"p1"}}))
+			})
+		})
+
+		Context("when the store is reopened", func() {
+			It("loads the documents from the file", func() {
+				validate(insert(testClusterProviderKey{"p1"}, "p1"), "")
+				validate(insert(testClusterKey{"p1", "c1"}, "c1"), "")
+				reopened, err := openEmbeddedStore(store.file)
+				validate(err, "")
+				store = reopened
+				Expect(find(testClusterKey{"p1", ""})).To(Equal([]testSpec{{"c1"}}))
+				validate(store.Remove(ctx, "resources", testClusterProviderKey{"p1"}), "parent child constraint")
+			})
+
+			It("is not opened by a second process", func() {
+				reopened, err := openEmbeddedStore(store.file)
+				validate(err, "")
+				store = reopened
+				_, err = openEmbeddedStore(store.file)
+				validate(err, "used by another process")
+			})
+		})
+	})
@@ -0,0 +1,170 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package db
+
+import (
+	"context"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/utils"
+)
+
+// Status values of a data key rotation
+const (
+	KeyRotationRunning   = "Running"
+	KeyRotationCompleted = "Completed"
+	KeyRotationFailed    = "Failed"
+)
+
+// KeyRotationReport is the progress of a data key rotation
+type KeyRotationReport struct {
+	Status      string    `json:"status"`
+	ActiveKeyId string    `json:"activeKeyId"`
+	StartedAt   time.Time `json:"startedAt"`
+	FinishedAt  time.Time `json:"finishedAt,omitempty"`
+	Documents   int       `json:"documents"` // number of documents to scan
+	Scanned     int       `json:"scanned"`   // number of documents scanned
+	Updated     int       `json:"updated"`   // number of documents re-encrypted
+	Values      int       `json:"values"`    // number of values re-encrypted
+	Error       string    `json:"error,omitempty"`
+}
+
+const keyRotationLockName = "key-rotation-lock"
+
+// Number of documents between two progress reports
+const keyRotationProgressInterval = 100
+
+// RotateDataKeys re-encrypts with the active key the values of the documents of the collections
+// which are encrypted with another key. The progress function, if not nil, is called periodically
+// with the progress of the rotation. Once the rotation is completed, the previous keys can be
+// removed from the configuration of the services.
+func RotateDataKeys(ctx context.Context, s Store, colls []string, oe utils.IObjectEncryptor, progress func(KeyRotationReport)) (KeyRotationReport, error) {
+	report := KeyRotationReport{Status: KeyRotationRunning, StartedAt: time.Now().UTC()}
+	fail := func(err error) (KeyRotationReport, error) {
+		report.Status = KeyRotationFailed
+		report.FinishedAt = time.Now().UTC()
+		report.Error = err.Error()
+		if progress != nil {
+			progress(report)
+		}
+		return report, err
+	}
+
+	rotator, ok := oe.(utils.IKeyRotator)
+	if oe == nil || !ok {
+		return fail(pkgerrors.New("Data encryption is not configured"))
+	}
+	report.ActiveKeyId = rotator.ActiveKeyId()
+	ms, ok := s.(MigrationStore)
+	if !ok {
+		return fail(pkgerrors.New("The database does not support data key rotation"))
+	}
+
+	for _, coll := range colls {
+		locked, err := ms.Lock(ctx, coll, keyRotationLockName)
+		if err != nil {
+			return fail(pkgerrors.Wrapf(err, "Error locking collection %s for data key rotation", coll))
+		}
+		if !locked {
+			return fail(pkgerrors.Errorf("A data key rotation of collection %s is already running", coll))
+		}
+		err = rotateCollection(ctx, ms, coll, rotator, &report, progress)
+		if uerr := ms.Unlock(ctx, coll, keyRotationLockName); uerr != nil {
+			log.Error("Error unlocking collection after data key rotation", log.Fields{"collection": coll, "error": uerr})
+		}
+		if err != nil {
+			return fail(err)
+		}
+	}
+
+	report.Status = KeyRotationCompleted
+	report.FinishedAt = time.Now().UTC()
+	if progress != nil {
+		progress(report)
+	}
+	log.Info("Data key rotation completed", log.Fields{"activeKeyId": report.ActiveKeyId, "documents": report.Scanned, "updated": report.Updated, "values": report.Values})
+	return report, nil
+}
+
+func rotateCollection(ctx context.Context, ms MigrationStore, coll string, rotator utils.IKeyRotator, report *KeyRotationReport, progress func(KeyRotationReport)) error {
+	count, err := ms.CountDocuments(ctx, coll, "")
+	if err != nil {
+		return pkgerrors.Wrapf(err, "Error counting the documents of collection %s", coll)
+	}
+	report.Documents += count
+
+	// The collection is scanned with a cursor instead of being loaded at once
+	err = ms.ScanDocuments(ctx, coll, "", func(doc Document) error {
+		rotated := copyDocument(doc)
+		values := 0
+		for k, v := range rotated {
+			// The key fields are not encrypted
+			if k == "_id" || k == "keyId" || k == "references" {
+				continue
+			}
+			rv, n, err := reencryptValue(v, rotator)
+			if err != nil {
+				return pkgerrors.Wrapf(err, "Error re-encrypting document of collection %s", coll)
+			}
+			rotated[k] = rv
+			values += n
+		}
+		if values > 0 {
+			if err := ms.ReplaceDocument(ctx, coll, doc, rotated); err != nil {
+				return pkgerrors.Wrapf(err, "Error storing re-encrypted document of collection %s", coll)
+			}
+			report.Updated++
+			report.Values += values
+		}
+		report.Scanned++
+		if report.Scanned%keyRotationProgressInterval == 0 {
+			log.Info("Data key rotation progress", log.Fields{"collection": coll, "scanned": report.Scanned, "documents": report.Documents, "updated": report.Updated})
+			if progress != nil {
+				progress(*report)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return pkgerrors.Wrapf(err, "Error scanning the documents of collection %s", coll)
+	}
+	return nil
+}
+
+// reencryptValue re-encrypts the ciphermessages found in the value and returns their number
+func reencryptValue(v interface{}, rotator utils.IKeyRotator) (interface{}, int, error) {
+	switch t := v.(type) {
+	case string:
+		s, changed, err := rotator.ReencryptString(t)
+		if err != nil || !changed {
+			return v, 0, err
+		}
+		return s, 1, nil
+	case map[string]interface{}:
+		count := 0
+		for k, e := range t {
+			r, n, err := reencryptValue(e, rotator)
+			if err != nil {
+				return v, 0, err
+			}
+			t[k] = r
+			count += n
+		}
+		return t, count, nil
+	case []interface{}:
+		count := 0
+		for i, e := range t {
+			r, n, err := reencryptValue(e, rotator)
+			if err != nil {
+				return v, 0, err
+			}
+			t[i] = r
+			count += n
+		}
+		return t, count, nil
+	}
+	return v, 0, nil
+}
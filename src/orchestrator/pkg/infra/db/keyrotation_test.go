@@ -0,0 +1,199 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package db
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type testSecret struct {
+	Name   string `json:"name"`
+	Secret string `json:"secret"`
+}
+
+var _ = Describe("Data key rotation",
+	func() {
+		var (
+			ctx      context.Context
+			store    *EmbeddedStore
+			previous utils.IObjectEncryptor
+			current  utils.IObjectEncryptor
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			store = &EmbeddedStore{
+				colls: make(map[string][]embeddedDocument),
+				locks: make(map[string]bool),
+			}
+			refSchemaFile = wd + "/test-schemas/emco-base.yaml"
+			schema, err := readSchema()
+			Expect(err).To(BeNil())
+			_, err = processSchema(ctx, store, schema)
+			Expect(err).To(BeNil())
+
+			os.Setenv("ROTATIONPREVIOUS_DATA_KEYS", "k1=previouskey")
+			os.Setenv("ROTATIONCURRENT_DATA_KEYS", "k2=currentkey,k1=previouskey")
+			previous = utils.GetObjectEncryptor("rotationprevious")
+			current = utils.GetObjectEncryptor("rotationcurrent")
+			Expect(previous).NotTo(BeNil())
+			Expect(current).NotTo(BeNil())
+
+			for _, p := range []string{"p1", "p2"} {
+				secret, err := previous.EncryptString("secret-" + p)
+				Expect(err).To(BeNil())
+				Expect(store.Insert(ctx, "resources", testClusterProviderKey{p}, nil, "data", testSecret{Name: p, Secret: secret})).To(BeNil())
+			}
+		})
+
+		AfterEach(func() {
+			os.Unsetenv("ROTATIONPREVIOUS_DATA_KEYS")
+			os.Unsetenv("ROTATIONCURRENT_DATA_KEYS")
+			refSchemaFile = ""
+			refSchemaMap = nil
+			refKeyMap = nil
+		})
+
+		secret := func(p string) string {
+			values, err := store.Find(ctx, "resources", testClusterProviderKey{p}, "data")
+			Expect(err).To(BeNil())
+			Expect(values).To(HaveLen(1))
+			s := testSecret{}
+			Expect(store.Unmarshal(values[0], &s)).To(BeNil())
+			Expect(s.Name).To(Equal(p))
+			return s.Secret
+		}
+
+		It("re-encrypts the values with the active key", func() {
+			var reports []KeyRotationReport
+			report, err := RotateDataKeys(ctx, store, []string{"resources"}, current, func(r KeyRotationReport) {
+				reports = append(reports, r)
+			})
+			Expect(err).To(BeNil())
+			Expect(report.Status).To(Equal(KeyRotationCompleted))
+			Expect(report.ActiveKeyId).To(Equal("k2"))
+			// The collection also has the referential schema document
+			Expect(report.Documents).To(Equal(3))
+			Expect(report.Scanned).To(Equal(report.Documents))
+			Expect(report.Updated).To(Equal(2))
+			Expect(report.Values).To(Equal(2))
+			Expect(reports).NotTo(BeEmpty())
+
+			for _, p := range []string{"p1", "p2"} {
+				s := secret(p)
+				Expect(strings.HasPrefix(s, "v1:k2:")).To(BeTrue())
+				d, err := current.DecryptString(s)
+				Expect(err).To(BeNil())
+				Expect(d).To(Equal("secret-" + p))
+			}
+
+			// Nothing left to rotate
+			report, err = RotateDataKeys(ctx, store, []string{"resources"}, current, nil)
+			Expect(err).To(BeNil())
+			Expect(report.Updated).To(Equal(0))
+		})
+
+		It("does not run while another rotation is running", func() {
+			locked, err := store.Lock(ctx, "resources", keyRotationLockName)
+			Expect(err).To(BeNil())
+			Expect(locked).To(BeTrue())
+			report, err := RotateDataKeys(ctx, store, []string{"resources"}, current, nil)
+			Expect(err).To(MatchError(ContainSubstring("already running")))
+			Expect(report.Status).To(Equal(KeyRotationFailed))
+			Expect(strings.HasPrefix(secret("p1"), "v1:k1:")).To(BeTrue())
+		})
+	},
+)
+
+// batchCollection records the batch size of the finds
+type batchCollection struct {
+	bsonCollection
+	batchSize *int32
+}
+
+func (c *batchCollection) Find(ctx context.Context, filter interface{},
+	opts ...*options.FindOptions) (*mongo.Cursor, error) {
+	for _, o := range opts {
+		c.batchSize = o.BatchSize
+	}
+	return c.bsonCollection.Find(ctx, filter, opts...)
+}
+
+var _ = Describe("Data key rotation of Mongo documents",
+	func() {
+		var (
+			ctx     context.Context
+			store   *MongoStore
+			coll    *batchCollection
+			current utils.IObjectEncryptor
+
+			savedGetCollection = getCollection
+			savedDecodeBytes   = decodeBytes
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			store = &MongoStore{}
+			coll = &batchCollection{}
+			getCollection = func(c string, m *MongoStore) MongoCollection { return coll }
+			decodeBytes = func(sr *mongo.SingleResult) (bson.Raw, error) { return nil, nil }
+			refSchemaFile = wd + "/test-schemas/emco-base.yaml"
+			schema, err := readSchema()
+			Expect(err).To(BeNil())
+			_, err = processSchema(ctx, store, schema)
+			Expect(err).To(BeNil())
+
+			os.Setenv("ROTATIONPREVIOUS_DATA_KEYS", "k1=previouskey")
+			os.Setenv("ROTATIONCURRENT_DATA_KEYS", "k2=currentkey,k1=previouskey")
+			previous := utils.GetObjectEncryptor("rotationprevious")
+			current = utils.GetObjectEncryptor("rotationcurrent")
+			for _, p := range []string{"p1", "p2", "p3"} {
+				secret, err := previous.EncryptString("secret-" + p)
+				Expect(err).To(BeNil())
+				Expect(store.Insert(ctx, "resources", testClusterProviderKey{p}, nil, "data", testSecret{Name: p, Secret: secret})).To(BeNil())
+			}
+		})
+
+		AfterEach(func() {
+			getCollection = savedGetCollection
+			decodeBytes = savedDecodeBytes
+			os.Unsetenv("ROTATIONPREVIOUS_DATA_KEYS")
+			os.Unsetenv("ROTATIONCURRENT_DATA_KEYS")
+			refSchemaFile = ""
+			refSchemaMap = nil
+			refKeyMap = nil
+		})
+
+		It("scans the collection with a cursor in batches", func() {
+			report, err := RotateDataKeys(ctx, store, []string{"resources"}, current, nil)
+			Expect(err).To(BeNil())
+			Expect(report.Status).To(Equal(KeyRotationCompleted))
+			Expect(report.Documents).To(Equal(4))
+			Expect(report.Scanned).To(Equal(report.Documents))
+			Expect(report.Updated).To(Equal(3))
+			Expect(coll.batchSize).NotTo(BeNil())
+			Expect(*coll.batchSize).To(Equal(int32(scanBatchSize)))
+
+			rotated := 0
+			for _, d := range coll.docs {
+				data, ok := bsonValue(d["data"]).(map[string]interface{})
+				if !ok {
+					continue
+				}
+				Expect(strings.HasPrefix(data["secret"].(string), "v1:k2:")).To(BeTrue())
+				rotated++
+			}
+			Expect(rotated).To(Equal(3))
+		})
+	},
+)
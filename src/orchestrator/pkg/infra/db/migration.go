@@ -0,0 +1,374 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package db
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/config"
+	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+	"golang.org/x/net/context"
+)
+
+// Values of the db-migration configuration key
+const (
+	MigrationApply   = "apply"
+	MigrationDryRun  = "dry-run"
+	MigrationDisable = "disable"
+)
+
+// Document is a stored document, with the key fields, keyId, references and tags
+type Document map[string]interface{}
+
+// MigrationStore is implemented by the stores which support data migrations
+type MigrationStore interface {
+	// Returns the documents of the collection with the keyId, or all the documents if keyId is empty
+	FindDocuments(ctx context.Context, coll string, keyId string) ([]Document, error)
+	// Calls fn with each document of the collection with the keyId, or with all the documents if keyId is empty
+	ScanDocuments(ctx context.Context, coll string, keyId string, fn func(Document) error) error
+	// Returns the number of documents of the collection with the keyId, or of all the documents if keyId is empty
+	CountDocuments(ctx context.Context, coll string, keyId string) (int, error)
+	// Returns the documents of the collection matching the key, like Find
+	FindDocumentsWithKey(ctx context.Context, coll string, key Key) ([]Document, error)
+	// Replaces a document returned by FindDocuments with its migrated version
+	ReplaceDocument(ctx context.Context, coll string, old, new Document) error
+	// Acquires the named lock, returns false if the lock is held by someone else
+	Lock(ctx context.Context, coll string, name string) (bool, error)
+	// Releases the named lock
+	Unlock(ctx context.Context, coll string, name string) error
+}
+
+// Migration is a versioned change of the documents of a collection.
+// The migrations of a component are applied in version order, once.
+type Migration struct {
+	Component   string // name of the service which owns the documents
+	Collection  string // collection of the documents
+	Version     int    // version of the component data after the migration, starting at 1
+	Description string
+	KeyId       string // keyId of the documents to migrate, see KeyIdOf
+	// Migrate changes the document in place and returns true if it was changed
+	Migrate func(doc Document) (bool, error)
+}
+
+// MigrationReport is the result of a migration
+type MigrationReport struct {
+	Component   string `json:"component"`
+	Collection  string `json:"collection"`
+	Version     int    `json:"version"`
+	Description string `json:"description"`
+	Documents   int    `json:"documents"` // number of documents changed, or to change in a dry run
+	DryRun      bool   `json:"dryRun"`
+}
+
+// MigrationVersionKey is the key of the document holding the data version
+// of each component for a collection
+type MigrationVersionKey struct {
+	Collection string `json:"migrationCollection"`
+}
+
+const migrationVersionTag = "versions"
+const migrationLockName = "migration-lock"
+const migrationLockRetries = 60
+
+var migrationLockInterval = 5 * time.Second
+
+var migrations []Migration
+var migrationReports []MigrationReport
+var migrationsLock sync.Mutex
+
+// RegisterMigrations registers the migrations of a service. The migrations are run by
+// InitializeDatabaseConnection, so they must be registered before it is called.
+func RegisterMigrations(ms ...Migration) {
+	migrationsLock.Lock()
+	defer migrationsLock.Unlock()
+	migrations = append(migrations, ms...)
+}
+
+// KeyIdOf returns the keyId of the documents stored with the type of key
+func KeyIdOf(key Key) string {
+	keyId, err := createKeyIdField(key)
+	if err != nil {
+		return ""
+	}
+	return keyId
+}
+
+// FieldRename renames the field From to To in the object(s) found at Path.
+// Path is a dot separated list of fields from the root of the document. A field
+// followed by [] selects all the elements of an array and * selects all the values
+// of an object. An empty To removes the field.
+type FieldRename struct {
+	Path string
+	From string
+	To   string
+}
+
+// RenameFieldsMigration returns a migration which renames fields of the documents with the type of key
+func RenameFieldsMigration(component, coll string, version int, description string, key Key, renames []FieldRename) Migration {
+	return Migration{
+		Component:   component,
+		Collection:  coll,
+		Version:     version,
+		Description: description,
+		KeyId:       KeyIdOf(key),
+		Migrate: func(doc Document) (bool, error) {
+			changed := false
+			for _, r := range renames {
+				var segments []string
+				if r.Path != "" {
+					segments = strings.Split(r.Path, ".")
+				}
+				for _, obj := range selectObjects(map[string]interface{}(doc), segments) {
+					v, ok := obj[r.From]
+					if !ok {
+						continue
+					}
+					delete(obj, r.From)
+					changed = true
+					if r.To == "" {
+						continue
+					}
+					// Do not overwrite a field already using the new name
+					if _, ok := obj[r.To]; !ok {
+						obj[r.To] = v
+					}
+				}
+			}
+			return changed, nil
+		},
+	}
+}
+
+// selectObjects returns the objects found at the path
+func selectObjects(obj map[string]interface{}, segments []string) []map[string]interface{} {
+	if len(segments) == 0 {
+		return []map[string]interface{}{obj}
+	}
+	var values []interface{}
+	s := segments[0]
+	switch {
+	case s == "*":
+		for _, v := range obj {
+			values = append(values, v)
+		}
+	case strings.HasSuffix(s, "[]"):
+		if a, ok := obj[strings.TrimSuffix(s, "[]")].([]interface{}); ok {
+			values = append(values, a...)
+		}
+	default:
+		if v, ok := obj[s]; ok {
+			values = append(values, v)
+		}
+	}
+	var objs []map[string]interface{}
+	for _, v := range values {
+		if o, ok := v.(map[string]interface{}); ok {
+			objs = append(objs, selectObjects(o, segments[1:])...)
+		}
+	}
+	return objs
+}
+
+// GetMigrationVersions returns the data version of each component for the collection
+func GetMigrationVersions(ctx context.Context, s Store, coll string) (map[string]int, error) {
+	versions := make(map[string]int)
+	values, err := s.Find(ctx, coll, MigrationVersionKey{Collection: coll}, migrationVersionTag)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range values {
+		if v == nil {
+			continue
+		}
+		if err := s.Unmarshal(v, &versions); err != nil {
+			return nil, err
+		}
+	}
+	return versions, nil
+}
+
+// RunMigrations runs the registered migrations which have not been applied yet.
+// In a dry run, the documents are not changed and the report lists the number
+// of documents each migration would change.
+func RunMigrations(ctx context.Context, s Store, dryRun bool) ([]MigrationReport, error) {
+	migrationsLock.Lock()
+	pending := make(map[string][]Migration)
+	for _, m := range migrations {
+		pending[m.Collection] = append(pending[m.Collection], m)
+	}
+	migrationsLock.Unlock()
+
+	reports := make([]MigrationReport, 0)
+	if len(pending) == 0 {
+		return reports, nil
+	}
+	ms, ok := s.(MigrationStore)
+	if !ok {
+		return reports, pkgerrors.New("The database does not support migrations")
+	}
+
+	var colls []string
+	for coll := range pending {
+		colls = append(colls, coll)
+	}
+	sort.Strings(colls)
+	for _, coll := range colls {
+		r, err := runCollectionMigrations(ctx, s, ms, coll, pending[coll], dryRun)
+		reports = append(reports, r...)
+		if err != nil {
+			return reports, err
+		}
+	}
+	return reports, nil
+}
+
+func runCollectionMigrations(ctx context.Context, s Store, ms MigrationStore, coll string, pending []Migration, dryRun bool) ([]MigrationReport, error) {
+	reports := make([]MigrationReport, 0)
+
+	// Only one service migrates a collection at a time, the others wait for it
+	for i := 0; ; i++ {
+		locked, err := ms.Lock(ctx, coll, migrationLockName)
+		if err != nil {
+			return reports, pkgerrors.Wrapf(err, "Error locking collection %s for migration", coll)
+		}
+		if locked {
+			break
+		}
+		if i == migrationLockRetries {
+			return reports, pkgerrors.Errorf("Collection %s is being migrated by another service", coll)
+		}
+		log.Info("Waiting for the migration of another service", log.Fields{"collection": coll})
+		time.Sleep(migrationLockInterval)
+	}
+	defer func() {
+		if err := ms.Unlock(ctx, coll, migrationLockName); err != nil {
+			log.Error("Error unlocking collection after migration", log.Fields{"collection": coll, "error": err})
+		}
+	}()
+
+	versions, err := GetMigrationVersions(ctx, s, coll)
+	if err != nil {
+		return reports, pkgerrors.Wrapf(err, "Error reading the data versions of collection %s", coll)
+	}
+
+	sort.SliceStable(pending, func(i, j int) bool {
+		if pending[i].Component != pending[j].Component {
+			return pending[i].Component < pending[j].Component
+		}
+		return pending[i].Version < pending[j].Version
+	})
+	stopped := make(map[string]bool)
+	for _, m := range pending {
+		if m.Version <= versions[m.Component] || stopped[m.Component] {
+			continue
+		}
+		report := MigrationReport{
+			Component:   m.Component,
+			Collection:  coll,
+			Version:     m.Version,
+			Description: m.Description,
+			DryRun:      dryRun,
+		}
+		docs, err := ms.FindDocuments(ctx, coll, m.KeyId)
+		if err != nil {
+			return reports, pkgerrors.Wrapf(err, "Error finding the documents to migrate. Component: %s, Version: %d", m.Component, m.Version)
+		}
+		for _, doc := range docs {
+			migrated := copyDocument(doc)
+			changed, err := m.Migrate(migrated)
+			if err != nil {
+				return reports, pkgerrors.Wrapf(err, "Error migrating document. Component: %s, Version: %d", m.Component, m.Version)
+			}
+			if !changed {
+				continue
+			}
+			report.Documents++
+			if dryRun {
+				continue
+			}
+			if err := ms.ReplaceDocument(ctx, coll, doc, migrated); err != nil {
+				return reports, pkgerrors.Wrapf(err, "Error storing migrated document. Component: %s, Version: %d", m.Component, m.Version)
+			}
+		}
+		reports = append(reports, report)
+		if dryRun {
+			// The later migrations of the component may depend on this one
+			stopped[m.Component] = true
+			continue
+		}
+		versions[m.Component] = m.Version
+		err = s.Insert(ctx, coll, MigrationVersionKey{Collection: coll}, nil, migrationVersionTag, versions)
+		if err != nil {
+			return reports, pkgerrors.Wrapf(err, "Error storing the data versions of collection %s", coll)
+		}
+		log.Info("Migrated documents", log.Fields{"component": m.Component, "collection": coll, "version": m.Version, "documents": report.Documents})
+	}
+	return reports, nil
+}
+
+// copyDocument returns a deep copy of the document, so that a migration can change it in place
+func copyDocument(doc Document) Document {
+	return Document(copyValue(map[string]interface{}(doc)).(map[string]interface{}))
+}
+
+func copyValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		c := make(map[string]interface{}, len(t))
+		for k, e := range t {
+			c[k] = copyValue(e)
+		}
+		return c
+	case []interface{}:
+		c := make([]interface{}, len(t))
+		for i, e := range t {
+			c[i] = copyValue(e)
+		}
+		return c
+	}
+	return v
+}
+
+// MigrationReports returns the reports of the migrations run, or of the dry run,
+// when the database connection was initialized
+func MigrationReports() []MigrationReport {
+	migrationsLock.Lock()
+	defer migrationsLock.Unlock()
+	return append([]MigrationReport{}, migrationReports...)
+}
+
+// runConfiguredMigrations runs the registered migrations according to the configuration
+func runConfiguredMigrations(ctx context.Context) error {
+	mode := config.GetConfiguration().DbMigration
+	if mode == MigrationDisable {
+		return nil
+	}
+	reports, err := RunMigrations(ctx, DBconn, mode == MigrationDryRun)
+	migrationsLock.Lock()
+	migrationReports = reports
+	migrationsLock.Unlock()
+	for _, r := range reports {
+		log.Info("Database migration report", log.Fields{"component": r.Component, "collection": r.Collection, "version": r.Version,
+			"description": r.Description, "documents": r.Documents, "dryRun": r.DryRun})
+	}
+	return err
+}
+
+// LatestMigrationVersions returns the version of the last registered migration of each component
+func LatestMigrationVersions() map[string]int {
+	migrationsLock.Lock()
+	defer migrationsLock.Unlock()
+
+	versions := make(map[string]int)
+	for _, m := range migrations {
+		if m.Version > versions[m.Component] {
+			versions[m.Component] = m.Version
+		}
+	}
+	return versions
+}
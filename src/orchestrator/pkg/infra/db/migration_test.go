@@ -0,0 +1,414 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package db
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	pkgerrors "github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type testOldSpec struct {
+	Spec map[string]interface{} `json:"spec"`
+}
+
+var _ = Describe("Migrations",
+	func() {
+		var (
+			ctx   context.Context
+			store *EmbeddedStore
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			store = &EmbeddedStore{
+				colls: make(map[string][]embeddedDocument),
+				locks: make(map[string]bool),
+			}
+			refSchemaFile = wd + "/test-schemas/emco-base.yaml"
+			schema, err := readSchema()
+			Expect(err).To(BeNil())
+			_, err = processSchema(ctx, store, schema)
+			Expect(err).To(BeNil())
+
+			for _, p := range []string{"p1", "p2"} {
+				data := testOldSpec{Spec: map[string]interface{}{
+					"cluster-name": "c-" + p,
+					"intent":       map[string]interface{}{"allOf": []interface{}{map[string]interface{}{"provider-name": p}}},
+				}}
+				Expect(store.Insert(ctx, "resources", testClusterProviderKey{p}, nil, "data", data)).To(BeNil())
+			}
+			migrations = []Migration{
+				RenameFieldsMigration("test", "resources", 1, "rename", testClusterProviderKey{}, []FieldRename{
+					{Path: "data.spec", From: "cluster-name", To: "cluster"},
+					{Path: "data.spec.intent.allOf[]", From: "provider-name", To: "clusterProvider"},
+				}),
+				RenameFieldsMigration("test", "resources", 2, "remove", testClusterProviderKey{}, []FieldRename{
+					{Path: "data.spec", From: "cluster"},
+				}),
+			}
+		})
+
+		AfterEach(func() {
+			migrations = nil
+			refSchemaFile = ""
+			refSchemaMap = nil
+			refKeyMap = nil
+		})
+
+		spec := func(p string) map[string]interface{} {
+			values, err := store.Find(ctx, "resources", testClusterProviderKey{p}, "data")
+			Expect(err).To(BeNil())
+			Expect(values).To(HaveLen(1))
+			s := testOldSpec{}
+			Expect(store.Unmarshal(values[0], &s)).To(BeNil())
+			return s.Spec
+		}
+
+		It("reports the first pending migration without changing the documents in a dry run", func() {
+			reports, err := RunMigrations(ctx, store, true)
+			Expect(err).To(BeNil())
+			Expect(reports).To(Equal([]MigrationReport{{Component: "test", Collection: "resources", Version: 1, Description: "rename", Documents: 2, DryRun: true}}))
+			Expect(spec("p1")).To(HaveKeyWithValue("cluster-name", "c-p1"))
+			versions, err := GetMigrationVersions(ctx, store, "resources")
+			Expect(err).To(BeNil())
+			Expect(versions).To(BeEmpty())
+		})
+
+		It("applies the migrations in order, once", func() {
+			migrations = migrations[:1]
+			reports, err := RunMigrations(ctx, store, false)
+			Expect(err).To(BeNil())
+			Expect(reports).To(HaveLen(1))
+			Expect(reports[0].Documents).To(Equal(2))
+			Expect(spec("p1")).To(Equal(map[string]interface{}{
+				"cluster": "c-p1",
+				"intent":  map[string]interface{}{"allOf": []interface{}{map[string]interface{}{"clusterProvider": "p1"}}},
+			}))
+
+			RegisterMigrations(RenameFieldsMigration("test", "resources", 2, "remove", testClusterProviderKey{}, []FieldRename{
+				{Path: "data.spec", From: "cluster"},
+			}))
+			reports, err = RunMigrations(ctx, store, false)
+			Expect(err).To(BeNil())
+			Expect(reports).To(Equal([]MigrationReport{{Component: "test", Collection: "resources", Version: 2, Description: "remove", Documents: 2}}))
+			Expect(spec("p2")).NotTo(HaveKey("cluster"))
+
+			versions, err := GetMigrationVersions(ctx, store, "resources")
+			Expect(err).To(BeNil())
+			Expect(versions).To(Equal(map[string]int{"test": 2}))
+
+			reports, err = RunMigrations(ctx, store, false)
+			Expect(err).To(BeNil())
+			Expect(reports).To(BeEmpty())
+		})
+
+		It("does not run while the collection is locked", func() {
+			interval := migrationLockInterval
+			migrationLockInterval = 0
+			defer func() { migrationLockInterval = interval }()
+			locked, err := store.Lock(ctx, "resources", migrationLockName)
+			Expect(err).To(BeNil())
+			Expect(locked).To(BeTrue())
+			_, err = RunMigrations(ctx, store, false)
+			Expect(err).To(MatchError(ContainSubstring("being migrated by another service")))
+			Expect(spec("p1")).To(HaveKey("cluster-name"))
+		})
+	},
+)
+
+// bsonCollection keeps the documents of a collection as Mongo does, with the
+// bson encoding of the data, so that the migrations see the stored field names
+type bsonCollection struct {
+	mockCollection
+	docs []bson.M
+}
+
+// bsonValue returns the value as decoded from its bson encoding
+func bsonValue(v interface{}) interface{} {
+	b, err := bson.Marshal(bson.M{"v": v})
+	Expect(err).To(BeNil())
+	var m bson.M
+	Expect(bson.Unmarshal(b, &m)).To(BeNil())
+	return normalizeBson(m["v"])
+}
+
+func (c *bsonCollection) matches(doc bson.M, filter interface{}) bool {
+	for k, v := range bsonValue(filter).(map[string]interface{}) {
+		if k == "$and" {
+			for _, f := range v.([]interface{}) {
+				if !c.matches(doc, f) {
+					return false
+				}
+			}
+			continue
+		}
+		if op, ok := v.(map[string]interface{}); ok {
+			// only the operators on the _id of the locks are used
+			if _, ok := op["$type"]; ok {
+				if _, ok := doc[k].(primitive.ObjectID); !ok {
+					return false
+				}
+			}
+			continue
+		}
+		if doc[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *bsonCollection) update(doc bson.M, update interface{}) {
+	u := bsonValue(update).(map[string]interface{})
+	if set, ok := u["$set"].(map[string]interface{}); ok {
+		for k, v := range set {
+			doc[k] = v
+		}
+	}
+	if unset, ok := u["$unset"].(map[string]interface{}); ok {
+		for k := range unset {
+			delete(doc, k)
+		}
+	}
+}
+
+func (c *bsonCollection) InsertOne(ctx context.Context, document interface{},
+	opts ...*options.InsertOneOptions) (*mongo.InsertOneResult, error) {
+	doc := bson.M(bsonValue(document).(map[string]interface{}))
+	for _, d := range c.docs {
+		if d["_id"] == doc["_id"] {
+			return nil, mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000}}}
+		}
+	}
+	c.docs = append(c.docs, doc)
+	return &mongo.InsertOneResult{InsertedID: doc["_id"]}, nil
+}
+
+func (c *bsonCollection) FindOneAndUpdate(ctx context.Context, filter interface{},
+	update interface{}, opts ...*options.FindOneAndUpdateOptions) *mongo.SingleResult {
+	for _, d := range c.docs {
+		if c.matches(d, filter) {
+			c.update(d, update)
+			return &mongo.SingleResult{}
+		}
+	}
+	// upsert the document with the fields of the filter
+	doc := bson.M{"_id": primitive.NewObjectID()}
+	for _, f := range bsonValue(filter).(map[string]interface{})["$and"].([]interface{}) {
+		for k, v := range f.(map[string]interface{}) {
+			doc[k] = v
+		}
+	}
+	c.update(doc, update)
+	c.docs = append(c.docs, doc)
+	return &mongo.SingleResult{}
+}
+
+func (c *bsonCollection) DeleteOne(ctx context.Context, filter interface{},
+	opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	for i, d := range c.docs {
+		if c.matches(d, filter) {
+			c.docs = append(c.docs[:i], c.docs[i+1:]...)
+			return &mongo.DeleteResult{DeletedCount: 1}, nil
+		}
+	}
+	return &mongo.DeleteResult{}, nil
+}
+
+func (c *bsonCollection) DeleteMany(ctx context.Context, filter interface{},
+	opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	var docs []bson.M
+	for _, d := range c.docs {
+		if !c.matches(d, filter) {
+			docs = append(docs, d)
+		}
+	}
+	n := len(c.docs) - len(docs)
+	c.docs = docs
+	return &mongo.DeleteResult{DeletedCount: int64(n)}, nil
+}
+
+func (c *bsonCollection) Find(ctx context.Context, filter interface{},
+	opts ...*options.FindOptions) (*mongo.Cursor, error) {
+	var docs []interface{}
+	for _, d := range c.docs {
+		if c.matches(d, filter) {
+			docs = append(docs, d)
+		}
+	}
+	return mongo.NewCursorFromDocuments(docs, nil, nil)
+}
+
+func (c *bsonCollection) CountDocuments(ctx context.Context, filter interface{},
+	opts ...*options.CountOptions) (int64, error) {
+	var n int64
+	for _, d := range c.docs {
+		if c.matches(d, filter) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (c *bsonCollection) UpdateOne(ctx context.Context, filter interface{}, update interface{},
+	opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	for _, d := range c.docs {
+		if c.matches(d, filter) {
+			c.update(d, update)
+			return &mongo.UpdateResult{MatchedCount: 1}, nil
+		}
+	}
+	return &mongo.UpdateResult{}, nil
+}
+
+// testStoredSpec is stored by Mongo as {"spec": {"clustername": ...}}, the json tag is not used
+type testStoredSpec struct {
+	Spec struct {
+		ClusterName string `json:"cluster-name"`
+	} `json:"spec"`
+}
+
+type testMigratedSpec struct {
+	Spec struct {
+		Cluster string `json:"cluster"`
+	} `json:"spec"`
+}
+
+var _ = Describe("Migrations of Mongo documents",
+	func() {
+		var (
+			ctx   context.Context
+			store *MongoStore
+
+			savedGetCollection = getCollection
+			savedDecodeBytes   = decodeBytes
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			store = &MongoStore{}
+			c := &bsonCollection{}
+			getCollection = func(coll string, m *MongoStore) MongoCollection { return c }
+			decodeBytes = func(sr *mongo.SingleResult) (bson.Raw, error) { return nil, nil }
+			refSchemaFile = wd + "/test-schemas/emco-base.yaml"
+			schema, err := readSchema()
+			Expect(err).To(BeNil())
+			_, err = processSchema(ctx, store, schema)
+			Expect(err).To(BeNil())
+
+			data := testStoredSpec{}
+			data.Spec.ClusterName = "c1"
+			Expect(store.Insert(ctx, "resources", testClusterProviderKey{"p1"}, nil, "data", data)).To(BeNil())
+		})
+
+		AfterEach(func() {
+			getCollection = savedGetCollection
+			decodeBytes = savedDecodeBytes
+			migrations = nil
+			refSchemaFile = ""
+			refSchemaMap = nil
+			refKeyMap = nil
+		})
+
+		It("renames the bson field names, not the json tags", func() {
+			migrations = []Migration{
+				RenameFieldsMigration("test", "resources", 1, "json tag", testClusterProviderKey{}, []FieldRename{
+					{Path: "data.spec", From: "cluster-name", To: "cluster"},
+				}),
+				RenameFieldsMigration("test", "resources", 2, "go field", testClusterProviderKey{}, []FieldRename{
+					{Path: "data.spec", From: "clustername", To: "cluster"},
+				}),
+			}
+			reports, err := RunMigrations(ctx, store, false)
+			Expect(err).To(BeNil())
+			Expect(reports).To(Equal([]MigrationReport{
+				{Component: "test", Collection: "resources", Version: 1, Description: "json tag", Documents: 0},
+				{Component: "test", Collection: "resources", Version: 2, Description: "go field", Documents: 1},
+			}))
+
+			values, err := store.Find(ctx, "resources", testClusterProviderKey{"p1"}, "data")
+			Expect(err).To(BeNil())
+			Expect(values).To(HaveLen(1))
+			s := testMigratedSpec{}
+			Expect(store.Unmarshal(values[0], &s)).To(BeNil())
+			Expect(s.Spec.Cluster).To(Equal("c1"))
+		})
+	},
+)
+
+// failingCollection fails the inserts
+type failingCollection struct {
+	bsonCollection
+}
+
+func (c *failingCollection) InsertOne(ctx context.Context, document interface{},
+	opts ...*options.InsertOneOptions) (*mongo.InsertOneResult, error) {
+	return nil, pkgerrors.New("insert failed")
+}
+
+var _ = Describe("Import of Mongo collections",
+	func() {
+		var (
+			ctx   context.Context
+			store *MongoStore
+			colls map[string]MongoCollection
+
+			savedGetCollection    = getCollection
+			savedRenameCollection = renameCollection
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			store = &MongoStore{}
+			colls = map[string]MongoCollection{
+				"resources": &bsonCollection{docs: []bson.M{{"_id": "old"}}},
+			}
+			getCollection = func(coll string, m *MongoStore) MongoCollection {
+				if _, ok := colls[coll]; !ok {
+					colls[coll] = &bsonCollection{}
+				}
+				return colls[coll]
+			}
+			renameCollection = func(ctx context.Context, m *MongoStore, from, to string) error {
+				colls[to] = colls[from]
+				delete(colls, from)
+				return nil
+			}
+		})
+
+		AfterEach(func() {
+			getCollection = savedGetCollection
+			renameCollection = savedRenameCollection
+		})
+
+		It("replaces the collection with the imported documents", func() {
+			Expect(store.Import(ctx, "resources", []json.RawMessage{
+				json.RawMessage(`{"_id": "new1"}`),
+				json.RawMessage(`{"_id": "new2"}`),
+			})).To(BeNil())
+			Expect(colls).To(HaveLen(1))
+			Expect(colls["resources"].(*bsonCollection).docs).To(Equal([]bson.M{{"_id": "new1"}, {"_id": "new2"}}))
+		})
+
+		It("leaves the collection unchanged if an insert fails", func() {
+			colls["resources"+importSuffix] = &failingCollection{}
+			err := store.Import(ctx, "resources", []json.RawMessage{json.RawMessage(`{"_id": "new1"}`)})
+			Expect(err).To(MatchError(ContainSubstring("insert failed")))
+			Expect(colls["resources"].(*bsonCollection).docs).To(Equal([]bson.M{{"_id": "old"}}))
+		})
+
+		It("empties the collection if there is no document", func() {
+			Expect(store.Import(ctx, "resources", nil)).To(BeNil())
+			Expect(colls["resources"].(*bsonCollection).docs).To(BeEmpty())
+		})
+	},
+)
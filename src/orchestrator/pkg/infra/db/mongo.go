@@ -11,6 +11,7 @@ import (
 	"reflect"
 	"sort"
 	"strings"
+	"time"
 
 	"golang.org/x/net/context"
 
@@ -77,6 +78,20 @@ var cursorClose = func(ctx context.Context, cursor *mongo.Cursor) error {
 	return cursor.Close(ctx)
 }
 
+// importSuffix is appended to the name of a collection to get the
+// temporary collection of an Import
+const importSuffix = ".import"
+
+// This exists only for allowing us to mock the renameCollection command,
+// which replaces the collection to with the collection from atomically
+var renameCollection = func(ctx context.Context, m *MongoStore, from, to string) error {
+	return m.db.Client().Database("admin").RunCommand(ctx, bson.D{
+		{Key: "renameCollection", Value: m.db.Name() + "." + from},
+		{Key: "to", Value: m.db.Name() + "." + to},
+		{Key: "dropTarget", Value: true},
+	}).Err()
+}
+
 // NewMongoStore initializes a Mongo Database with the name provided
 // If a database with that name exists, it will be returned
 func NewMongoStore(ctx context.Context, name string, store *mongo.Database) (Store, error) {
@@ -130,7 +145,7 @@ func (m *MongoStore) HealthCheck(ctx context.Context) error {
 func (m *MongoStore) findReferencedBys(ctx context.Context, c MongoCollection, key Key) (int64, error) {
 
 	// Create the key tag value for this resource (i.e. resource identifier)
-	keyId, err := m.createKeyIdField(key)
+	keyId, err := createKeyIdField(key)
 	if err != nil {
 		return 0, err
 	}
@@ -277,12 +292,12 @@ func findKeyValues(inKey, filterKey map[string]struct{}, data interface{}) (map[
 	return result, nil
 }
 
-// verifyReferences checks that all references for a resource exist.
+// verifyReferences checks that all references for a resource exist in the store.
 // 1. The parent resource, as defined by the schema, is checked.
 // 2. The keys for other references, as identified for the schema, are found
 //    by searching the "spec" object of the resource "data".
 //    These references are then verified to exist.
-func (m *MongoStore) verifyReferences(ctx context.Context, coll string, key Key, keyId string, data interface{}) ([]ReferenceEntry, error) {
+func verifyReferences(ctx context.Context, m Store, coll string, key Key, keyId string, data interface{}) ([]ReferenceEntry, error) {
 
 	// make a references slice to store keys of any references found
 	refs := make([]ReferenceEntry, 0)
@@ -472,7 +487,7 @@ func (m *MongoStore) verifyReferences(ctx context.Context, coll string, key Key,
 }
 
 // validateParams checks to see if any parameters are empty
-func (m *MongoStore) validateParams(args ...interface{}) bool {
+func validateParams(args ...interface{}) bool {
 	for _, v := range args {
 		val, ok := v.(string)
 		if ok {
@@ -567,7 +582,7 @@ func (m *MongoStore) findFilterWithKey(key Key) (primitive.M, error) {
 		if v == "" {
 			if _, ok := bsonMapFinal["keyId"]; !ok {
 				// add type of key to filter
-				keyId, err := m.createKeyIdField(key)
+				keyId, err := createKeyIdField(key)
 				if err != nil {
 					return primitive.M{}, err
 				}
@@ -608,7 +623,7 @@ func (m *MongoStore) updateFilter(key interface{}) (primitive.M, error) {
 	return filter, nil
 }
 
-func (m *MongoStore) createKeyIdField(key interface{}) (string, error) {
+func createKeyIdField(key interface{}) (string, error) {
 
 	var n map[string]string
 	st, err := json.Marshal(key)
@@ -635,7 +650,7 @@ func (m *MongoStore) Insert(ctx context.Context, coll string, key Key, query int
 		return pkgerrors.Errorf("db Insert error: No data to store")
 	}
 
-	if !m.validateParams(coll, key, tag) {
+	if !validateParams(coll, key, tag) {
 		return pkgerrors.Errorf("db Insert error: Mandatory fields are missing. Collection: %s, Key: %T %v, Tag: %s", coll, key, key, tag)
 	}
 
@@ -647,7 +662,7 @@ func (m *MongoStore) Insert(ctx context.Context, coll string, key Key, query int
 	}
 
 	// Create and add keyId tag
-	keyId, err := m.createKeyIdField(key)
+	keyId, err := createKeyIdField(key)
 	if err != nil {
 		return pkgerrors.Wrapf(err, "db Insert error: Error creating KeyID with key %T %v", key, key)
 	}
@@ -663,18 +678,19 @@ func (m *MongoStore) Insert(ctx context.Context, coll string, key Key, query int
 			edata, err = oe.EncryptObject(data)
 		}
 
-		if err == nil {
-			data = edata
-		} else {
-			log.Warn("Error to encrypt object", log.Fields{"collection": coll, "tag": tag})
+		if err != nil {
+			// Do not store in clear the data which must be encrypted, e.g. while the KMS is unavailable
+			log.Error("Error to encrypt object", log.Fields{"collection": coll, "tag": tag, "error": err})
+			return pkgerrors.Wrap(err, "db Insert error: Error encrypting data")
 		}
+		data = edata
 	}
 
 	// verify references for Inserts with the "data" tag
 	refs := make([]ReferenceEntry, 0)
 
 	if tag == "data" {
-		refs, err = m.verifyReferences(ctx, coll, key, keyId, data)
+		refs, err = verifyReferences(ctx, m, coll, key, keyId, data)
 		if err != nil {
 			if strings.Contains(err.Error(), "Parent resource not found") {
 				// these errors should be handled separately, not as an internal server error
@@ -746,7 +762,7 @@ func (m *MongoStore) Find(ctx context.Context, coll string, key Key, tag string)
 
 	//result, err := m.findInternal(coll, key, tag, "")
 	//return result, err
-	if !m.validateParams(coll, key, tag) {
+	if !validateParams(coll, key, tag) {
 		return nil, pkgerrors.Errorf("db Find error: Mandatory fields are missing. Collection: %s, Key: %T %v, Tag: %s", coll, key, key, tag)
 	}
 
@@ -789,7 +805,7 @@ func (m *MongoStore) Find(ctx context.Context, coll string, key Key, tag string)
 
 // RemoveAll method to removes all the documet matching key
 func (m *MongoStore) RemoveAll(ctx context.Context, coll string, key Key) error {
-	if !m.validateParams(coll, key) {
+	if !validateParams(coll, key) {
 		return pkgerrors.Errorf("db Remove error: Mandatory fields are missing. Collection: %s, Key: %T %v", coll, key, key)
 	}
 	c := getCollection(coll, m)
@@ -806,7 +822,7 @@ func (m *MongoStore) RemoveAll(ctx context.Context, coll string, key Key) error
 
 // Remove method to remove the documet by key if no child references
 func (m *MongoStore) Remove(ctx context.Context, coll string, key Key) error {
-	if !m.validateParams(coll, key) {
+	if !validateParams(coll, key) {
 		return pkgerrors.Errorf("db Remove error: Mandatory fields are missing. Collection: %s, Key: %T %v", coll, key, key)
 	}
 
@@ -875,3 +891,234 @@ func (m *MongoStore) RemoveTag(ctx context.Context, coll string, key Key, tag st
 
 	return nil
 }
+
+// FindDocuments returns the documents of the collection with the keyId, or all the documents if keyId is empty
+func (m *MongoStore) FindDocuments(ctx context.Context, coll string, keyId string) ([]Document, error) {
+	c := getCollection(coll, m)
+	return m.findDocuments(ctx, c, documentsFilter(keyId))
+}
+
+// documentsFilter returns the filter of the documents with the keyId, or of all the documents if keyId is empty
+func documentsFilter(keyId string) bson.M {
+	if keyId == "" {
+		// The locks, which have a name as _id, are not documents
+		return bson.M{"_id": bson.M{"$type": "objectId"}}
+	}
+	return bson.M{"keyId": keyId}
+}
+
+// scanBatchSize is the number of documents fetched at a time by ScanDocuments
+const scanBatchSize = 100
+
+// ScanDocuments calls fn with each document of the collection with the keyId, or with all the
+// documents if keyId is empty. The documents are fetched in batches, not loaded all at once.
+func (m *MongoStore) ScanDocuments(ctx context.Context, coll string, keyId string, fn func(Document) error) error {
+	c := getCollection(coll, m)
+
+	cursor, err := c.Find(ctx, documentsFilter(keyId), options.Find().SetBatchSize(scanBatchSize))
+	if err != nil {
+		return pkgerrors.Wrap(err, "db ScanDocuments error")
+	}
+	defer cursorClose(ctx, cursor)
+	for cursorNext(ctx, cursor) {
+		var d bson.M
+		if err := bson.Unmarshal(cursor.Current, &d); err != nil {
+			return pkgerrors.Wrap(err, "db ScanDocuments error: Unable to decode document")
+		}
+		if err := fn(Document(normalizeBson(d).(map[string]interface{}))); err != nil {
+			return err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return pkgerrors.Wrap(err, "db ScanDocuments error")
+	}
+	return nil
+}
+
+// CountDocuments returns the number of documents of the collection with the keyId, or of all the documents if keyId is empty
+func (m *MongoStore) CountDocuments(ctx context.Context, coll string, keyId string) (int, error) {
+	c := getCollection(coll, m)
+
+	n, err := c.CountDocuments(ctx, documentsFilter(keyId))
+	if err != nil {
+		return 0, pkgerrors.Wrap(err, "db CountDocuments error")
+	}
+	return int(n), nil
+}
+
+// FindDocumentsWithKey returns the documents of the collection matching the key, like Find
+func (m *MongoStore) FindDocumentsWithKey(ctx context.Context, coll string, key Key) ([]Document, error) {
+	c := getCollection(coll, m)
+
+	filter, err := m.findFilterWithKey(key)
+	if err != nil {
+		return nil, pkgerrors.Wrapf(err, "db FindDocuments error: Error finding filter with key %T %v", key, key)
+	}
+	return m.findDocuments(ctx, c, filter)
+}
+
+func (m *MongoStore) findDocuments(ctx context.Context, c MongoCollection, filter interface{}) ([]Document, error) {
+	cursor, err := c.Find(ctx, filter)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "db FindDocuments error")
+	}
+	defer cursorClose(ctx, cursor)
+	var docs []Document
+	for cursorNext(ctx, cursor) {
+		var d bson.M
+		if err := bson.Unmarshal(cursor.Current, &d); err != nil {
+			return nil, pkgerrors.Wrap(err, "db FindDocuments error: Unable to decode document")
+		}
+		docs = append(docs, Document(normalizeBson(d).(map[string]interface{})))
+	}
+	return docs, nil
+}
+
+// normalizeBson converts the bson documents and arrays to maps and slices
+func normalizeBson(v interface{}) interface{} {
+	switch t := v.(type) {
+	case bson.M:
+		return normalizeBson(map[string]interface{}(t))
+	case map[string]interface{}:
+		for k, e := range t {
+			t[k] = normalizeBson(e)
+		}
+		return t
+	case bson.D:
+		d := make(map[string]interface{}, len(t))
+		for _, e := range t {
+			d[e.Key] = normalizeBson(e.Value)
+		}
+		return d
+	case bson.A:
+		return normalizeBson([]interface{}(t))
+	case []interface{}:
+		for i, e := range t {
+			t[i] = normalizeBson(e)
+		}
+		return t
+	}
+	return v
+}
+
+// ReplaceDocument replaces a document returned by FindDocuments with its migrated version
+func (m *MongoStore) ReplaceDocument(ctx context.Context, coll string, old, new Document) error {
+	c := getCollection(coll, m)
+
+	set := bson.M{}
+	for k, v := range new {
+		if k != "_id" {
+			set[k] = v
+		}
+	}
+	update := bson.M{"$set": set}
+	unset := bson.M{}
+	for k := range old {
+		if _, ok := new[k]; !ok {
+			unset[k] = ""
+		}
+	}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+	_, err := c.UpdateOne(ctx, bson.M{"_id": old["_id"]}, update)
+	if err != nil {
+		return pkgerrors.Wrap(err, "db ReplaceDocument error")
+	}
+	return nil
+}
+
+// lockExpiry is the time after which a lock left behind by a failed service is released
+const lockExpiry = 10 * time.Minute
+
+// Lock acquires the named lock, stored as a document of the collection
+func (m *MongoStore) Lock(ctx context.Context, coll string, name string) (bool, error) {
+	c := getCollection(coll, m)
+
+	for i := 0; i < 2; i++ {
+		_, err := c.InsertOne(ctx, bson.M{"_id": name, "expires": time.Now().Add(lockExpiry)})
+		if err == nil {
+			return true, nil
+		}
+		if !mongo.IsDuplicateKeyError(err) {
+			return false, pkgerrors.Wrap(err, "db Lock error")
+		}
+		// Release the lock if it has expired and try again
+		r, err := c.DeleteOne(ctx, bson.M{"_id": name, "expires": bson.M{"$lt": time.Now()}})
+		if err != nil {
+			return false, pkgerrors.Wrap(err, "db Lock error")
+		}
+		if r.DeletedCount == 0 {
+			return false, nil
+		}
+	}
+	return false, nil
+}
+
+// Unlock releases the named lock
+func (m *MongoStore) Unlock(ctx context.Context, coll string, name string) error {
+	c := getCollection(coll, m)
+
+	_, err := c.DeleteOne(ctx, bson.M{"_id": name})
+	if err != nil {
+		return pkgerrors.Wrap(err, "db Unlock error")
+	}
+	return nil
+}
+
+// Export returns all the documents of the collection as canonical extended JSON,
+// which preserves the bson types. The locks, which have a name as _id, are not exported.
+func (m *MongoStore) Export(ctx context.Context, coll string) ([]json.RawMessage, error) {
+	c := getCollection(coll, m)
+
+	filter := bson.M{"_id": bson.M{"$type": "objectId"}}
+	cursor, err := c.Find(ctx, filter, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "db Export error")
+	}
+	defer cursorClose(ctx, cursor)
+	docs := make([]json.RawMessage, 0)
+	for cursorNext(ctx, cursor) {
+		d, err := bson.MarshalExtJSON(cursor.Current, true, false)
+		if err != nil {
+			return nil, pkgerrors.Wrap(err, "db Export error: Unable to encode document")
+		}
+		docs = append(docs, d)
+	}
+	return docs, nil
+}
+
+// Import replaces all the documents of the collection with the documents returned by Export
+func (m *MongoStore) Import(ctx context.Context, coll string, docs []json.RawMessage) error {
+	values := make([]bson.D, 0, len(docs))
+	for _, d := range docs {
+		var v bson.D
+		if err := bson.UnmarshalExtJSON(d, true, &v); err != nil {
+			return pkgerrors.Wrap(err, "db Import error: Unable to decode document")
+		}
+		values = append(values, v)
+	}
+	if len(values) == 0 {
+		if _, err := getCollection(coll, m).DeleteMany(ctx, bson.M{}); err != nil {
+			return pkgerrors.Wrap(err, "db Import error: Error deleting documents")
+		}
+		return nil
+	}
+
+	// The documents are inserted in a temporary collection which then replaces
+	// the collection, so a failed import leaves the collection unchanged
+	tmp := coll + importSuffix
+	c := getCollection(tmp, m)
+	if _, err := c.DeleteMany(ctx, bson.M{}); err != nil {
+		return pkgerrors.Wrap(err, "db Import error: Error deleting documents of a previous import")
+	}
+	for _, v := range values {
+		if _, err := c.InsertOne(ctx, v); err != nil {
+			return pkgerrors.Wrap(err, "db Import error: Error inserting document")
+		}
+	}
+	if err := renameCollection(ctx, m, tmp, coll); err != nil {
+		return pkgerrors.Wrap(err, "db Import error: Error replacing the collection")
+	}
+	return nil
+}
@@ -92,6 +92,12 @@ func (key DbSchemaKey) String() string {
 
 // ReadRefSchema reads the Referential Schema Segment file and creates the refSchemaMap.
 func (m *MongoStore) ReadRefSchema(ctx context.Context) {
+	readRefSchema(ctx, m)
+}
+
+// readRefSchema reads the Referential Schema Segment file and creates the refSchemaMap
+// using the schema segments registered in the store.
+func readRefSchema(ctx context.Context, m Store) {
 	// This function is executed asynchronously, so we must create
 	// a new (not derived) context to prevent the context from
 	// being cancelled when the caller completes: a cancelled
@@ -108,14 +114,14 @@ func (m *MongoStore) ReadRefSchema(ctx context.Context) {
 		return
 	}
 
-	m.verifyReferentialIntegrity(ctx, schema)
+	verifyReferentialIntegrity(ctx, m, schema)
 }
 
 // verifyReferentialIntegrity verifies the referential integrity of the resources
 // defined by the controller(s) schema.
 // Wait for controllers to register schema in scenarios where
 // multiple controllers start simultaneously.
-func (m *MongoStore) verifyReferentialIntegrity(ctx context.Context, serviceSchema DbSchema) {
+func verifyReferentialIntegrity(ctx context.Context, m Store, serviceSchema DbSchema) {
 	var (
 		backOff       int   = config.GetConfiguration().BackOff
 		maxBackOff    int   = config.GetConfiguration().MaxBackOff
@@ -124,7 +130,7 @@ func (m *MongoStore) verifyReferentialIntegrity(ctx context.Context, serviceSche
 	)
 
 	for waitForSchema {
-		waitForSchema, err = m.processSchema(ctx, serviceSchema)
+		waitForSchema, err = processSchema(ctx, m, serviceSchema)
 		if err != nil {
 			return
 		}
@@ -149,7 +155,7 @@ func (m *MongoStore) verifyReferentialIntegrity(ctx context.Context, serviceSche
 }
 
 // processSchema process each schema segment in the db.
-func (m *MongoStore) processSchema(ctx context.Context, serviceSchema DbSchema) (bool, error) {
+func processSchema(ctx context.Context, m Store, serviceSchema DbSchema) (bool, error) {
 	var (
 		emcoRefSchema    DbSchema
 		schemaExists     bool
@@ -5,6 +5,7 @@ package db
 
 import (
 	"encoding/json"
+	"path/filepath"
 	"reflect"
 
 	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/config"
@@ -53,6 +54,13 @@ func createDBClient(ctx context.Context, dbType string, dbName string) error {
 	case "mongo":
 		// create a mongodb database with orchestrator as the name
 		DBconn, err = NewMongoStore(ctx, dbName, nil)
+	case "embedded":
+		// create an embedded database, stored in a file of the service if a directory is configured
+		var file string
+		if dir := config.GetConfiguration().EmbeddedDbDir; dir != "" {
+			file = filepath.Join(dir, config.ServiceName()+"."+dbName+".db.json")
+		}
+		DBconn, err = NewEmbeddedStore(ctx, file)
 	default:
 		return pkgerrors.New(dbType + "DB not supported")
 	}
@@ -90,5 +98,11 @@ func InitializeDatabaseConnection(ctx context.Context, dbName string) error {
 		return pkgerrors.Cause(err)
 	}
 
+	// Bring the stored documents to the version expected by the service
+	err = runConfiguredMigrations(ctx)
+	if err != nil {
+		return pkgerrors.Cause(err)
+	}
+
 	return nil
 }
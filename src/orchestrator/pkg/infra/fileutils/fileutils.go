@@ -0,0 +1,65 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+// Package fileutils provides the file handling of the embedded databases
+package fileutils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+// LockFile takes the lock file of the file, so that a single process uses it.
+// The lock is released when the returned file is closed or the process exits.
+func LockFile(file string) (*os.File, error) {
+	lockFile := file + ".lock"
+	f, err := os.OpenFile(lockFile, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, pkgerrors.Wrapf(err, "Error opening the lock file %s", lockFile)
+	}
+	locked, err := lock(f)
+	if err != nil {
+		f.Close()
+		return nil, pkgerrors.Wrapf(err, "Error locking the lock file %s", lockFile)
+	}
+	if !locked {
+		f.Close()
+		return nil, pkgerrors.Errorf("The file %s is used by another process", file)
+	}
+	return f, nil
+}
+
+// WriteFile replaces the content of the file atomically, so that a crash
+// leaves either the previous or the new content
+func WriteFile(file string, b []byte) error {
+	dir := filepath.Dir(file)
+	tmp, err := ioutil.TempFile(dir, filepath.Base(file)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	// The content must be on disk before the rename is
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), file); err != nil {
+		return err
+	}
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
@@ -0,0 +1,58 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package fileutils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLockFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fileutils")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "emco.db.json")
+
+	f, err := LockFile(file)
+	if err != nil {
+		t.Fatalf("LockFile returned an error: %s", err)
+	}
+	if _, err := LockFile(file); err == nil || !strings.Contains(err.Error(), "used by another process") {
+		t.Errorf("LockFile of a locked file returned %v", err)
+	}
+	f.Close()
+	f, err = LockFile(file)
+	if err != nil {
+		t.Errorf("LockFile of a released file returned %s", err)
+	} else {
+		f.Close()
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fileutils")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "emco.db.json")
+
+	for _, content := range []string{"first", "second"} {
+		if err := WriteFile(file, []byte(content)); err != nil {
+			t.Fatalf("WriteFile returned an error: %s", err)
+		}
+		if b, err := ioutil.ReadFile(file); err != nil || string(b) != content {
+			t.Errorf("Unexpected content %s of the file, expected %s", b, content)
+		}
+	}
+	// The temporary files are removed
+	if files, _ := ioutil.ReadDir(dir); len(files) != 1 {
+		t.Errorf("Unexpected files %d in the directory, expected 1", len(files))
+	}
+}
@@ -0,0 +1,25 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+//go:build !windows
+// +build !windows
+
+package fileutils
+
+import (
+	"os"
+	"syscall"
+)
+
+// lock takes an exclusive flock of the file without waiting. It returns
+// false if another process holds the lock.
+func lock(f *os.File) (bool, error) {
+	err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if err == syscall.EWOULDBLOCK {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,28 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+//go:build windows
+// +build windows
+
+package fileutils
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// lock takes an exclusive lock of the file without waiting. It returns
+// false if another process holds the lock.
+func lock(f *os.File) (bool, error) {
+	ol := new(windows.Overlapped)
+	err := windows.LockFileEx(windows.Handle(f.Fd()),
+		windows.LOCKFILE_EXCLUSIVE_LOCK|windows.LOCKFILE_FAIL_IMMEDIATELY, 0, 1, 0, ol)
+	if err == windows.ERROR_LOCK_VIOLATION {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,291 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+// Package idempotency makes the POST requests carrying an Idempotency-Key header
+// safe to retry. The response to the first request with a key is stored, and
+// replayed to the retries of the request with the same key, within a TTL.
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/config"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/db"
+	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+)
+
+// Header is the request header holding the idempotency key
+const Header = "Idempotency-Key"
+
+// ReplayedHeader is set on the responses replayed from a previous request
+const ReplayedHeader = "Idempotent-Replayed"
+
+// Collection of the stored responses
+const collection = "idempotency"
+
+const responseTag = "response"
+
+// Maximum length of an idempotency key
+const maxKeyLength = 255
+
+// Maximum size of a stored response body. The responses with a larger body are not
+// stored, so the retries of the request are executed again.
+const maxBodySize = 1 << 20
+
+// Response headers stored and replayed with the response body
+var replayedHeaders = []string{"Content-Type", "Location"}
+
+// Key is the key of a stored response. The keys of the clients are scoped by service
+// and by the principal of the client.
+type Key struct {
+	Service        string `json:"idempotencyService"`
+	Principal      string `json:"idempotencyPrincipal"`
+	IdempotencyKey string `json:"idempotencyKey"`
+}
+
+// Response is a stored response
+type Response struct {
+	Principal      string            `json:"principal"`
+	IdempotencyKey string            `json:"idempotencyKey"`
+	Fingerprint    string            `json:"fingerprint"`
+	StatusCode     int               `json:"statusCode"`
+	Header         map[string]string `json:"header,omitempty"`
+	Body           string            `json:"body" encrypted:""`
+	Expires        time.Time         `json:"expires"`
+}
+
+// Interval of the removal of the expired responses
+var purgeInterval = time.Hour
+
+var purgeOnce sync.Once
+
+// principal identifies the authenticated client of the request, so that the
+// clients do not share their keys. It is the issuer and subject of a bearer JWT,
+// which is verified by the gateway of the deployment, or else the credentials of
+// the Authorization header. It is hashed, since the key is stored, and is not empty
+// for the anonymous clients either, since an empty field of a key matches any value.
+func principal(r *http.Request) string {
+	auth := r.Header.Get("Authorization")
+	id := auth
+	if strings.HasPrefix(auth, "Bearer ") {
+		parts := strings.Split(strings.TrimSpace(strings.TrimPrefix(auth, "Bearer ")), ".")
+		if len(parts) == 3 {
+			claims := struct {
+				Iss string `json:"iss"`
+				Sub string `json:"sub"`
+			}{}
+			b, err := base64.RawURLEncoding.DecodeString(parts[1])
+			if err == nil && json.Unmarshal(b, &claims) == nil && claims.Sub != "" {
+				// The token changes when it is refreshed, its subject does not
+				id = claims.Iss + " " + claims.Sub
+			}
+		}
+	}
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:])
+}
+
+// ttl returns the time during which a response is replayed
+func ttl() time.Duration {
+	return time.Duration(config.GetConfiguration().IdempotencyKeyTTL) * time.Second
+}
+
+// fingerprint identifies the request, so that a key reused for a different request is rejected
+func fingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+	h.Write([]byte(r.Header.Get("Content-Type") + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Middleware replays the stored response of a POST request with an Idempotency-Key
+// header, if the same request was sent with the same key within the TTL. A request
+// with the key of a different request is rejected, as is a request with the key of a
+// request still in progress. The responses with a server error are not stored.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		idemKey := r.Header.Get(Header)
+		if r.Method != http.MethodPost || idemKey == "" || db.DBconn == nil || ttl() <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(idemKey) > maxKeyLength {
+			http.Error(w, "Invalid Idempotency-Key header", http.StatusBadRequest)
+			return
+		}
+		purgeOnce.Do(func() { go purgeExpired() })
+
+		ctx := r.Context()
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "Error reading the request body", http.StatusBadRequest)
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		key := Key{Service: config.ServiceName(), Principal: principal(r), IdempotencyKey: idemKey}
+		fp := fingerprint(r, body)
+
+		if replay(ctx, w, key, fp) {
+			return
+		}
+
+		// The lock marks the request in progress, until its response is stored
+		lockName := key.Service + "/" + key.Principal + "/" + key.IdempotencyKey
+		ms, _ := db.DBconn.(db.MigrationStore)
+		if ms != nil {
+			locked, err := ms.Lock(ctx, collection, lockName)
+			if err != nil {
+				log.Error("Error locking the Idempotency-Key", log.Fields{"key": idemKey, "error": err})
+				http.Error(w, "Error checking the Idempotency-Key", http.StatusInternalServerError)
+				return
+			}
+			if !locked {
+				http.Error(w, "A request with the same Idempotency-Key is in progress", http.StatusConflict)
+				return
+			}
+			defer func() {
+				if err := ms.Unlock(context.Background(), collection, lockName); err != nil {
+					log.Error("Error unlocking the Idempotency-Key", log.Fields{"key": idemKey, "error": err})
+				}
+			}()
+			// The request may have completed since the first check
+			if replay(ctx, w, key, fp) {
+				return
+			}
+		}
+
+		rec := &recorder{ResponseWriter: w, statusCode: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		if rec.statusCode >= http.StatusInternalServerError || rec.overflow {
+			return
+		}
+
+		resp := Response{
+			Principal:      key.Principal,
+			IdempotencyKey: idemKey,
+			Fingerprint:    fp,
+			StatusCode:     rec.statusCode,
+			Header:         make(map[string]string),
+			Body:           rec.body.String(),
+			Expires:        time.Now().Add(ttl()),
+		}
+		for _, h := range replayedHeaders {
+			if v := w.Header().Get(h); v != "" {
+				resp.Header[h] = v
+			}
+		}
+		// the request context may be cancelled once the response is sent
+		if err := db.DBconn.Insert(context.Background(), collection, key, nil, responseTag, resp); err != nil {
+			log.Error("Error storing the response of the Idempotency-Key", log.Fields{"key": idemKey, "error": err})
+		}
+	})
+}
+
+// replay sends the stored response of the key, and returns true if the response was sent
+func replay(ctx context.Context, w http.ResponseWriter, key Key, fp string) bool {
+	resp, found, err := getResponse(ctx, key)
+	if err != nil {
+		log.Error("Error getting the response of the Idempotency-Key", log.Fields{"key": key.IdempotencyKey, "error": err})
+		http.Error(w, "Error checking the Idempotency-Key", http.StatusInternalServerError)
+		return true
+	}
+	if !found {
+		return false
+	}
+	if resp.Fingerprint != fp {
+		http.Error(w, "The Idempotency-Key is already used by a different request", http.StatusUnprocessableEntity)
+		return true
+	}
+	for h, v := range resp.Header {
+		w.Header().Set(h, v)
+	}
+	w.Header().Set(ReplayedHeader, "true")
+	w.WriteHeader(resp.StatusCode)
+	w.Write([]byte(resp.Body))
+	return true
+}
+
+// getResponse returns the stored response of the key, unless it has expired
+func getResponse(ctx context.Context, key Key) (Response, bool, error) {
+	var resp Response
+	values, err := db.DBconn.Find(ctx, collection, key, responseTag)
+	if err != nil {
+		return resp, false, err
+	}
+	if len(values) == 0 || values[0] == nil {
+		return resp, false, nil
+	}
+	if err := db.DBconn.Unmarshal(values[0], &resp); err != nil {
+		return resp, false, err
+	}
+	if time.Now().After(resp.Expires) {
+		return resp, false, nil
+	}
+	return resp, true, nil
+}
+
+// purgeExpired removes the expired responses of the service periodically
+func purgeExpired() {
+	for {
+		time.Sleep(purgeInterval)
+		ctx := context.Background()
+		service := config.ServiceName()
+		// The empty Principal and IdempotencyKey match all the keys of the service
+		values, err := db.DBconn.Find(ctx, collection, Key{Service: service}, responseTag)
+		if err != nil {
+			log.Warn("Error finding the responses of the Idempotency-Keys", log.Fields{"error": err})
+			continue
+		}
+		for _, v := range values {
+			var resp Response
+			if v == nil || db.DBconn.Unmarshal(v, &resp) != nil || time.Now().Before(resp.Expires) {
+				continue
+			}
+			key := Key{Service: service, Principal: resp.Principal, IdempotencyKey: resp.IdempotencyKey}
+			if err := db.DBconn.RemoveAll(ctx, collection, key); err != nil {
+				log.Warn("Error removing the response of the Idempotency-Key", log.Fields{"key": resp.IdempotencyKey, "error": err})
+			}
+		}
+	}
+}
+
+// recorder records the response of a request while it is sent
+type recorder struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+	body        bytes.Buffer
+	overflow    bool
+}
+
+func (r *recorder) WriteHeader(statusCode int) {
+	if !r.wroteHeader {
+		r.statusCode = statusCode
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	if !r.overflow {
+		if r.body.Len()+len(b) > maxBodySize {
+			r.overflow = true
+			r.body.Reset()
+		} else {
+			r.body.Write(b)
+		}
+	}
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,122 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package idempotency
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/config"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/db"
+)
+
+func TestMiddleware(t *testing.T) {
+	store, err := db.NewEmbeddedStore(context.Background(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev := db.DBconn
+	db.DBconn = store
+	defer func() { db.DBconn = prev }()
+
+	calls := 0
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := ioutil.ReadAll(r.Body)
+		status := http.StatusCreated
+		if string(body) == "fail" {
+			status = http.StatusInternalServerError
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		fmt.Fprintf(w, `{"call":%d}`, calls)
+	}))
+
+	postAs := func(auth, key, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/v2/projects", strings.NewReader(body))
+		if key != "" {
+			r.Header.Set(Header, key)
+		}
+		if auth != "" {
+			r.Header.Set("Authorization", auth)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+	post := func(key, body string) *httptest.ResponseRecorder {
+		return postAs("", key, body)
+	}
+
+	w := post("key1", "p1")
+	if w.Code != http.StatusCreated || w.Body.String() != `{"call":1}` {
+		t.Fatalf("Unexpected first response %d %s", w.Code, w.Body.String())
+	}
+
+	// A retry is replayed
+	w = post("key1", "p1")
+	if w.Code != http.StatusCreated || w.Body.String() != `{"call":1}` || calls != 1 {
+		t.Errorf("Unexpected replayed response %d %s, calls %d", w.Code, w.Body.String(), calls)
+	}
+	if w.Header().Get(ReplayedHeader) != "true" || w.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("Unexpected replayed headers %v", w.Header())
+	}
+
+	// A different request with the same key is rejected
+	w = post("key1", "p2")
+	if w.Code != http.StatusUnprocessableEntity || calls != 1 {
+		t.Errorf("Unexpected response to a reused key %d, calls %d", w.Code, calls)
+	}
+
+	// The requests without a key are not replayed
+	post("", "p1")
+	post("", "p1")
+	if calls != 3 {
+		t.Errorf("Unexpected calls without a key %d", calls)
+	}
+
+	// The server errors are not stored
+	post("key2", "fail")
+	post("key2", "fail")
+	if calls != 5 {
+		t.Errorf("Unexpected calls after a server error %d", calls)
+	}
+
+	// A request in progress is rejected
+	ms := store.(db.MigrationStore)
+	anonymous := principal(httptest.NewRequest(http.MethodPost, "/", nil))
+	ms.Lock(context.Background(), collection, config.ServiceName()+"/"+anonymous+"/key3")
+	w = post("key3", "p3")
+	if w.Code != http.StatusConflict || calls != 5 {
+		t.Errorf("Unexpected response to a request in progress %d, calls %d", w.Code, calls)
+	}
+
+	// The keys of the clients are scoped by principal
+	alice := "Bearer " + token(`{"iss":"idp","sub":"alice","exp":1}`)
+	w = postAs(alice, "key4", "p4")
+	if w.Code != http.StatusCreated || calls != 6 {
+		t.Errorf("Unexpected first response of a principal %d, calls %d", w.Code, calls)
+	}
+	w = postAs("Bearer "+token(`{"iss":"idp","sub":"alice","exp":2}`), "key4", "p4")
+	if w.Header().Get(ReplayedHeader) != "true" || calls != 6 {
+		t.Errorf("The retry of a principal with a refreshed token is not replayed, calls %d", calls)
+	}
+	for _, other := range []string{"", "Bearer " + token(`{"iss":"idp","sub":"bob","exp":1}`), "Basic Ym9iOnB3"} {
+		w = postAs(other, "key4", "p5")
+		if w.Code != http.StatusCreated || w.Header().Get(ReplayedHeader) != "" {
+			t.Errorf("The key of another principal %q is used: %d %v", other, w.Code, w.Header())
+		}
+	}
+}
+
+// token returns an unsigned JWT with the claims
+func token(claims string) string {
+	return "e30." + base64.RawURLEncoding.EncodeToString([]byte(claims)) + ".sig"
+}
@@ -0,0 +1,148 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package kms
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"io/ioutil"
+	"strings"
+	"sync"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+// fileProvider wraps the data keys with AES-GCM keys read from a file, typically
+// a Kubernetes secret mounted in the pod. Each line of the file is a key entry
+// <key id>=<base64 encoded 256 bit key>, the first entry is used to wrap the data keys.
+// The file is read again when a data key was wrapped with an unknown key, so that
+// the keys of an updated secret are used without restarting the service.
+type fileProvider struct {
+	file  string
+	lock  sync.RWMutex
+	keyId string
+	keys  map[string]cipher.AEAD
+}
+
+// NewFileProvider returns a KMS provider using the keys of the file
+func NewFileProvider(file string) (Provider, error) {
+	p := &fileProvider{file: file}
+	if err := p.load(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// readSecretFile returns the trimmed content of the file
+func readSecretFile(file string) (string, error) {
+	if file == "" {
+		return "", pkgerrors.New("No file configured")
+	}
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+func (p *fileProvider) load() error {
+	content, err := readSecretFile(p.file)
+	if err != nil {
+		return pkgerrors.Wrap(err, "Error reading the KMS key file")
+	}
+
+	var active string
+	keys := make(map[string]cipher.AEAD)
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		kv := strings.SplitN(line, "=", 2)
+		if len(kv) != 2 {
+			return pkgerrors.New("Invalid KMS key file entry, use <key id>=<base64 key>")
+		}
+		if err := validKeyId(kv[0]); err != nil {
+			return err
+		}
+		key, err := base64.StdEncoding.DecodeString(kv[1])
+		if err != nil || len(key) != 32 {
+			return pkgerrors.Errorf("Invalid KMS key %s, the keys must be 256 bit and base64 encoded", kv[0])
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return err
+		}
+		gcm, err := cipher.NewGCM(block)
+		if err != nil {
+			return err
+		}
+		keys[kv[0]] = gcm
+		if active == "" {
+			active = kv[0]
+		}
+	}
+	if active == "" {
+		return pkgerrors.New("The KMS key file has no key")
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.keyId = active
+	p.keys = keys
+	return nil
+}
+
+// KeyId returns the ID of the first key of the file
+func (p *fileProvider) KeyId() string {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	return p.keyId
+}
+
+// WrapKey encrypts the data key with the first key of the file
+func (p *fileProvider) WrapKey(ctx context.Context, key []byte) (string, []byte, error) {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	gcm := p.keys[p.keyId]
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", nil, err
+	}
+	return p.keyId, gcm.Seal(nonce, nonce, key, []byte(p.keyId)), nil
+}
+
+// UnwrapKey decrypts the data key with the key keyId of the file
+func (p *fileProvider) UnwrapKey(ctx context.Context, keyId string, wrapped []byte) ([]byte, error) {
+	p.lock.RLock()
+	gcm, ok := p.keys[keyId]
+	p.lock.RUnlock()
+	if !ok {
+		if err := p.load(); err != nil {
+			return nil, err
+		}
+		p.lock.RLock()
+		gcm, ok = p.keys[keyId]
+		p.lock.RUnlock()
+		if !ok {
+			return nil, pkgerrors.Errorf("Unknown KMS key ID %s", keyId)
+		}
+	}
+	if len(wrapped) < gcm.NonceSize() {
+		return nil, pkgerrors.New("Invalid wrapped key")
+	}
+	key, err := gcm.Open(nil, wrapped[:gcm.NonceSize()], wrapped[gcm.NonceSize():], []byte(keyId))
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "Error unwrapping the data key")
+	}
+	return key, nil
+}
+
+func (p *fileProvider) Close() error {
+	return nil
+}
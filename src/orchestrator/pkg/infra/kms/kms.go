@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package kms
+
+import (
+	"context"
+	"strings"
+
+	pkgerrors "github.com/pkg/errors"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/config"
+)
+
+// Names of the KMS providers
+const (
+	FileProvider       = "file"
+	Pkcs11SoftProvider = "pkcs11-soft"
+	PluginProvider     = "plugin"
+)
+
+// Provider is a key management service used for envelope encryption. The object
+// encryptor encrypts the data with a data encryption key, which is stored next to
+// the data wrapped with a key encryption key held by the provider.
+type Provider interface {
+	// KeyId returns the ID of the key encryption key used to wrap the data keys
+	KeyId() string
+	// WrapKey wraps a data key, and returns the ID of the key encryption key used
+	WrapKey(ctx context.Context, key []byte) (string, []byte, error)
+	// UnwrapKey unwraps a data key wrapped with the key encryption key keyId
+	UnwrapKey(ctx context.Context, keyId string, wrapped []byte) ([]byte, error)
+	// Close releases the resources of the provider
+	Close() error
+}
+
+// Config is the configuration of the KMS providers
+type Config struct {
+	Provider        string
+	KeyFile         string // file provider
+	Pkcs11TokenFile string // pkcs11-soft provider
+	Pkcs11KeyLabel  string
+	Pkcs11PinFile   string
+	PluginEndpoint  string // plugin provider, unix:///path or host:port
+	PluginCAFile    string
+}
+
+// ConfigFromConfiguration returns the KMS configuration of the service
+func ConfigFromConfiguration() Config {
+	c := config.GetConfiguration()
+	return Config{
+		Provider:        c.KmsProvider,
+		KeyFile:         c.KmsKeyFile,
+		Pkcs11TokenFile: c.KmsPkcs11TokenFile,
+		Pkcs11KeyLabel:  c.KmsPkcs11KeyLabel,
+		Pkcs11PinFile:   c.KmsPkcs11PinFile,
+		PluginEndpoint:  c.KmsPluginEndpoint,
+		PluginCAFile:    c.KmsPluginCAFile,
+	}
+}
+
+// NewProvider returns the configured KMS provider
+func NewProvider(c Config) (Provider, error) {
+	switch c.Provider {
+	case FileProvider:
+		return NewFileProvider(c.KeyFile)
+	case Pkcs11SoftProvider:
+		pin, err := readSecretFile(c.Pkcs11PinFile)
+		if err != nil {
+			return nil, pkgerrors.Wrap(err, "Error reading the PKCS#11 PIN")
+		}
+		token, err := OpenSoftToken(c.Pkcs11TokenFile)
+		if err != nil {
+			return nil, err
+		}
+		return NewPkcs11Provider(token, pin, c.Pkcs11KeyLabel)
+	case PluginProvider:
+		return NewPluginProvider(c.PluginEndpoint, c.PluginCAFile)
+	}
+	return nil, pkgerrors.Errorf("Unknown KMS provider %s", c.Provider)
+}
+
+// validKeyId returns an error if the key ID can't be stored in a ciphermessage
+func validKeyId(keyId string) error {
+	if keyId == "" || strings.ContainsAny(keyId, ": \t\r\n") {
+		return pkgerrors.Errorf("Invalid key ID %q", keyId)
+	}
+	return nil
+}
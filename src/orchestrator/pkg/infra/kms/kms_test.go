@@ -0,0 +1,161 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package kms
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeKeyFile(t *testing.T, dir string, ids ...string) string {
+	var content string
+	for _, id := range ids {
+		content += id + "=" + base64.StdEncoding.EncodeToString(bytes.Repeat([]byte(id[:1]), 32)) + "\n"
+	}
+	file := filepath.Join(dir, "keys")
+	if err := ioutil.WriteFile(file, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func testWrapUnwrap(t *testing.T, p Provider, keyId string) []byte {
+	ctx := context.Background()
+	key := bytes.Repeat([]byte{7}, 32)
+	id, wrapped, err := p.WrapKey(ctx, key)
+	if err != nil {
+		t.Fatalf("WrapKey returned an error: %s", err)
+	}
+	if id != keyId || p.KeyId() != keyId {
+		t.Errorf("Unexpected key ID %s", id)
+	}
+	if bytes.Contains(wrapped, key) {
+		t.Errorf("The wrapped key contains the key")
+	}
+	unwrapped, err := p.UnwrapKey(ctx, id, wrapped)
+	if err != nil || !bytes.Equal(unwrapped, key) {
+		t.Errorf("UnwrapKey returned %v, %v", unwrapped, err)
+	}
+	wrapped[len(wrapped)-1] ^= 1
+	if _, err := p.UnwrapKey(ctx, id, wrapped); err == nil {
+		t.Errorf("A corrupted wrapped key was unwrapped")
+	}
+	wrapped[len(wrapped)-1] ^= 1
+	return wrapped
+}
+
+func TestFileProvider(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kms")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := writeKeyFile(t, dir, "a1")
+	p, err := NewFileProvider(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wrapped := testWrapUnwrap(t, p, "a1")
+
+	// The key file is updated with a new key, the previous key is still known
+	writeKeyFile(t, dir, "b2", "a1")
+	if _, err := p.UnwrapKey(context.Background(), "a1", wrapped); err != nil {
+		t.Errorf("UnwrapKey returned an error after the key file update: %s", err)
+	}
+	if _, err := p.UnwrapKey(context.Background(), "c3", wrapped); err == nil {
+		t.Errorf("A key was unwrapped with an unknown key ID")
+	}
+
+	ioutil.WriteFile(file, []byte("a1=c2hvcnQ="), 0600)
+	if _, err := NewFileProvider(file); err == nil {
+		t.Errorf("A short key was accepted")
+	}
+}
+
+func TestAesKeyWrap(t *testing.T) {
+	// RFC 3394 4.1 Wrap 128 bits of Key Data with a 128-bit KEK
+	kek, _ := hex.DecodeString("000102030405060708090A0B0C0D0E0F")
+	key, _ := hex.DecodeString("00112233445566778899AABBCCDDEEFF")
+	expected, _ := hex.DecodeString("1FA68B0A8112B447AEF34BD8FB5A7B829D3E862371D2CFE5")
+	wrapped, err := aesKeyWrap(kek, key)
+	if err != nil || !bytes.Equal(wrapped, expected) {
+		t.Errorf("aesKeyWrap returned %x, %v", wrapped, err)
+	}
+	unwrapped, err := aesKeyUnwrap(kek, wrapped)
+	if err != nil || !bytes.Equal(unwrapped, key) {
+		t.Errorf("aesKeyUnwrap returned %x, %v", unwrapped, err)
+	}
+}
+
+func TestPkcs11SoftProvider(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kms")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "token.json")
+	if err := InitSoftToken(file, "1234", "emco-kek"); err != nil {
+		t.Fatal(err)
+	}
+	token, err := OpenSoftToken(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewPkcs11Provider(token, "0000", "emco-kek"); err == nil {
+		t.Errorf("An incorrect PIN was accepted")
+	}
+	if _, err := NewPkcs11Provider(token, "1234", "other"); err == nil {
+		t.Errorf("An unknown key label was accepted")
+	}
+	p, err := NewPkcs11Provider(token, "1234", "emco-kek")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	testWrapUnwrap(t, p, "emco-kek")
+}
+
+func TestPluginProvider(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kms")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	fp, err := NewFileProvider(writeKeyFile(t, dir, "hsm1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	socket := filepath.Join(dir, "plugin.sock")
+	lis, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go ServePlugin(lis, fp)
+	defer lis.Close()
+
+	p, err := NewProvider(Config{Provider: PluginProvider, PluginEndpoint: "unix://" + socket})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	testWrapUnwrap(t, p, "hsm1")
+}
+
+func TestPluginProviderWithoutTLS(t *testing.T) {
+	_, err := NewProvider(Config{Provider: PluginProvider, PluginEndpoint: "kms.example.com:50051"})
+	if err == nil || !strings.Contains(err.Error(), "CA file is required") {
+		t.Errorf("Connected to a plugin over the network without TLS: %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package kms
+
+import (
+	"context"
+	"sync"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+// CkmAesKeyWrap is the PKCS#11 mechanism CKM_AES_KEY_WRAP (RFC 3394)
+const CkmAesKeyWrap uint = 0x00002109
+
+// Pkcs11ObjectHandle is the handle of a PKCS#11 object
+type Pkcs11ObjectHandle uint
+
+// Pkcs11Token is the subset of the PKCS#11 API used to wrap the data keys:
+// C_Login, C_FindObjects on CKA_LABEL, C_WrapKey and C_UnwrapKey. The data keys
+// are exchanged as CKO_SECRET_KEY values rather than as object handles.
+type Pkcs11Token interface {
+	Login(pin string) error
+	FindKey(label string) (Pkcs11ObjectHandle, error)
+	WrapKey(mechanism uint, wrappingKey Pkcs11ObjectHandle, key []byte) ([]byte, error)
+	UnwrapKey(mechanism uint, unwrappingKey Pkcs11ObjectHandle, wrapped []byte) ([]byte, error)
+	Close() error
+}
+
+// pkcs11Provider wraps the data keys with an AES key of a PKCS#11 token. The label
+// of the token key is the key ID.
+type pkcs11Provider struct {
+	token   Pkcs11Token
+	label   string
+	lock    sync.Mutex
+	handles map[string]Pkcs11ObjectHandle
+}
+
+// NewPkcs11Provider returns a KMS provider using the key label of the token
+func NewPkcs11Provider(token Pkcs11Token, pin string, label string) (Provider, error) {
+	if err := validKeyId(label); err != nil {
+		return nil, err
+	}
+	if err := token.Login(pin); err != nil {
+		return nil, pkgerrors.Wrap(err, "Error logging in the PKCS#11 token")
+	}
+	p := &pkcs11Provider{token: token, label: label, handles: make(map[string]Pkcs11ObjectHandle)}
+	if _, err := p.handle(label); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *pkcs11Provider) handle(label string) (Pkcs11ObjectHandle, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if h, ok := p.handles[label]; ok {
+		return h, nil
+	}
+	h, err := p.token.FindKey(label)
+	if err != nil {
+		return 0, pkgerrors.Wrapf(err, "Error finding the PKCS#11 key %s", label)
+	}
+	p.handles[label] = h
+	return h, nil
+}
+
+// KeyId returns the label of the token key used to wrap the data keys
+func (p *pkcs11Provider) KeyId() string {
+	return p.label
+}
+
+// WrapKey wraps the data key with the token key
+func (p *pkcs11Provider) WrapKey(ctx context.Context, key []byte) (string, []byte, error) {
+	h, err := p.handle(p.label)
+	if err != nil {
+		return "", nil, err
+	}
+	wrapped, err := p.token.WrapKey(CkmAesKeyWrap, h, key)
+	if err != nil {
+		return "", nil, pkgerrors.Wrap(err, "Error wrapping the data key")
+	}
+	return p.label, wrapped, nil
+}
+
+// UnwrapKey unwraps the data key with the token key labeled keyId
+func (p *pkcs11Provider) UnwrapKey(ctx context.Context, keyId string, wrapped []byte) ([]byte, error) {
+	h, err := p.handle(keyId)
+	if err != nil {
+		return nil, err
+	}
+	key, err := p.token.UnwrapKey(CkmAesKeyWrap, h, wrapped)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "Error unwrapping the data key")
+	}
+	return key, nil
+}
+
+func (p *pkcs11Provider) Close() error {
+	return p.token.Close()
+}
@@ -0,0 +1,135 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package kms
+
+import (
+	"context"
+	"net"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/grpc/kmsplugin"
+	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/rpc"
+	"google.golang.org/grpc"
+)
+
+// PluginProtocolVersion is the version of the KMS plugin protocol
+const PluginProtocolVersion = "v1"
+
+// Timeout of the calls to the plugin
+var pluginCallTimeout = 10 * time.Second
+
+// pluginProvider wraps the data keys with a remote KMS plugin implementing the
+// kmsplugin gRPC service, e.g. a vendor service backed by an HSM
+type pluginProvider struct {
+	conn   *grpc.ClientConn
+	client kmsplugin.KmspluginClient
+	keyId  string
+}
+
+// NewPluginProvider connects to the KMS plugin listening on the endpoint. The endpoint
+// is unix:///<path> for a plugin listening on a Unix socket, typically a sidecar, or
+// <host>:<port>. The connection to a host requires TLS with the CA file.
+func NewPluginProvider(endpoint string, caFile string) (Provider, error) {
+	if endpoint == "" {
+		return nil, pkgerrors.New("No KMS plugin endpoint configured")
+	}
+	conn, err := rpc.DialPlugin(endpoint, caFile)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "Error connecting to the KMS plugin")
+	}
+	p := &pluginProvider{conn: conn, client: kmsplugin.NewKmspluginClient(conn)}
+
+	ctx, cancel := context.WithTimeout(context.Background(), pluginCallTimeout)
+	defer cancel()
+	status, err := p.client.Status(ctx, &kmsplugin.StatusRequest{})
+	if err != nil {
+		conn.Close()
+		return nil, pkgerrors.Wrap(err, "Error getting the status of the KMS plugin")
+	}
+	if status.Version != PluginProtocolVersion {
+		conn.Close()
+		return nil, pkgerrors.Errorf("Unsupported KMS plugin protocol version %s", status.Version)
+	}
+	if !status.Healthy {
+		conn.Close()
+		return nil, pkgerrors.New("The KMS plugin is not healthy")
+	}
+	if err := validKeyId(status.KeyId); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	p.keyId = status.KeyId
+	log.Info("Connected to the KMS plugin", log.Fields{"endpoint": endpoint, "keyId": p.keyId})
+	return p, nil
+}
+
+// KeyId returns the ID of the key used by the plugin when it was connected
+func (p *pluginProvider) KeyId() string {
+	return p.keyId
+}
+
+// WrapKey wraps the data key with the current key of the plugin
+func (p *pluginProvider) WrapKey(ctx context.Context, key []byte) (string, []byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, pluginCallTimeout)
+	defer cancel()
+	resp, err := p.client.WrapKey(ctx, &kmsplugin.WrapKeyRequest{Key: key})
+	if err != nil {
+		return "", nil, pkgerrors.Wrap(err, "Error wrapping the data key with the KMS plugin")
+	}
+	if err := validKeyId(resp.KeyId); err != nil {
+		return "", nil, err
+	}
+	return resp.KeyId, resp.WrappedKey, nil
+}
+
+// UnwrapKey unwraps the data key with the key keyId of the plugin
+func (p *pluginProvider) UnwrapKey(ctx context.Context, keyId string, wrapped []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, pluginCallTimeout)
+	defer cancel()
+	resp, err := p.client.UnwrapKey(ctx, &kmsplugin.UnwrapKeyRequest{KeyId: keyId, WrappedKey: wrapped})
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "Error unwrapping the data key with the KMS plugin")
+	}
+	return resp.Key, nil
+}
+
+func (p *pluginProvider) Close() error {
+	return p.conn.Close()
+}
+
+// pluginServer exposes a Provider with the kmsplugin gRPC service
+type pluginServer struct {
+	kmsplugin.UnimplementedKmspluginServer
+	provider Provider
+}
+
+// ServePlugin serves the provider as a KMS plugin on the listener, until the
+// listener is closed. It helps writing plugins in Go.
+func ServePlugin(lis net.Listener, provider Provider) error {
+	s := grpc.NewServer()
+	kmsplugin.RegisterKmspluginServer(s, &pluginServer{provider: provider})
+	return s.Serve(lis)
+}
+
+func (s *pluginServer) Status(ctx context.Context, req *kmsplugin.StatusRequest) (*kmsplugin.StatusResponse, error) {
+	return &kmsplugin.StatusResponse{Version: PluginProtocolVersion, KeyId: s.provider.KeyId(), Healthy: true}, nil
+}
+
+func (s *pluginServer) WrapKey(ctx context.Context, req *kmsplugin.WrapKeyRequest) (*kmsplugin.WrapKeyResponse, error) {
+	keyId, wrapped, err := s.provider.WrapKey(ctx, req.Key)
+	if err != nil {
+		return nil, err
+	}
+	return &kmsplugin.WrapKeyResponse{KeyId: keyId, WrappedKey: wrapped}, nil
+}
+
+func (s *pluginServer) UnwrapKey(ctx context.Context, req *kmsplugin.UnwrapKeyRequest) (*kmsplugin.UnwrapKeyResponse, error) {
+	key, err := s.provider.UnwrapKey(ctx, req.KeyId, req.WrappedKey)
+	if err != nil {
+		return nil, err
+	}
+	return &kmsplugin.UnwrapKeyResponse{Key: key}, nil
+}
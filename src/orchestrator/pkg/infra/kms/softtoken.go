@@ -0,0 +1,192 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package kms
+
+import (
+	"crypto/aes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"sync"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+// softTokenFile is the content of a software token file
+type softTokenFile struct {
+	PinHash string            `json:"pinHash"` // hex of the SHA-256 of the user PIN
+	Keys    map[string][]byte `json:"keys"`    // AES keys by label
+}
+
+// softToken is a software implementation of the Pkcs11Token, for testing. The keys
+// of the token are stored in clear in the token file, so it must not be used in production.
+type softToken struct {
+	lock     sync.Mutex
+	token    softTokenFile
+	loggedIn bool
+	labels   []string // label of each object handle
+}
+
+// InitSoftToken creates a software token file with the PIN and a random AES key for each label
+func InitSoftToken(file string, pin string, labels ...string) error {
+	token := softTokenFile{Keys: make(map[string][]byte)}
+	h := sha256.Sum256([]byte(pin))
+	token.PinHash = hex.EncodeToString(h[:])
+	for _, l := range labels {
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return err
+		}
+		token.Keys[l] = key
+	}
+	b, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, b, 0600)
+}
+
+// OpenSoftToken opens a software token file created by InitSoftToken
+func OpenSoftToken(file string) (Pkcs11Token, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "Error reading the PKCS#11 token file")
+	}
+	t := &softToken{}
+	if err := json.Unmarshal(b, &t.token); err != nil {
+		return nil, pkgerrors.Wrap(err, "Invalid PKCS#11 token file")
+	}
+	return t, nil
+}
+
+func (t *softToken) Login(pin string) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	h := sha256.Sum256([]byte(pin))
+	if subtle.ConstantTimeCompare([]byte(hex.EncodeToString(h[:])), []byte(t.token.PinHash)) != 1 {
+		return pkgerrors.New("CKR_PIN_INCORRECT")
+	}
+	t.loggedIn = true
+	return nil
+}
+
+func (t *softToken) FindKey(label string) (Pkcs11ObjectHandle, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if !t.loggedIn {
+		return 0, pkgerrors.New("CKR_USER_NOT_LOGGED_IN")
+	}
+	if _, ok := t.token.Keys[label]; !ok {
+		return 0, pkgerrors.New("CKR_KEY_HANDLE_INVALID")
+	}
+	for i, l := range t.labels {
+		if l == label {
+			return Pkcs11ObjectHandle(i + 1), nil
+		}
+	}
+	t.labels = append(t.labels, label)
+	return Pkcs11ObjectHandle(len(t.labels)), nil
+}
+
+// key returns the key of the handle
+func (t *softToken) key(mechanism uint, h Pkcs11ObjectHandle) ([]byte, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if !t.loggedIn {
+		return nil, pkgerrors.New("CKR_USER_NOT_LOGGED_IN")
+	}
+	if mechanism != CkmAesKeyWrap {
+		return nil, pkgerrors.New("CKR_MECHANISM_INVALID")
+	}
+	if h == 0 || int(h) > len(t.labels) {
+		return nil, pkgerrors.New("CKR_KEY_HANDLE_INVALID")
+	}
+	return t.token.Keys[t.labels[h-1]], nil
+}
+
+func (t *softToken) WrapKey(mechanism uint, wrappingKey Pkcs11ObjectHandle, key []byte) ([]byte, error) {
+	kek, err := t.key(mechanism, wrappingKey)
+	if err != nil {
+		return nil, err
+	}
+	return aesKeyWrap(kek, key)
+}
+
+func (t *softToken) UnwrapKey(mechanism uint, unwrappingKey Pkcs11ObjectHandle, wrapped []byte) ([]byte, error) {
+	kek, err := t.key(mechanism, unwrappingKey)
+	if err != nil {
+		return nil, err
+	}
+	return aesKeyUnwrap(kek, wrapped)
+}
+
+func (t *softToken) Close() error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.loggedIn = false
+	return nil
+}
+
+// Default initial value of RFC 3394
+var keyWrapIV = []byte{0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6}
+
+// aesKeyWrap wraps the key with the RFC 3394 AES key wrap algorithm
+func aesKeyWrap(kek, key []byte) ([]byte, error) {
+	if len(key) < 16 || len(key)%8 != 0 {
+		return nil, pkgerrors.New("CKR_KEY_SIZE_RANGE")
+	}
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+	n := len(key) / 8
+	out := make([]byte, 8+len(key))
+	copy(out, keyWrapIV)
+	copy(out[8:], key)
+	b := make([]byte, 16)
+	for j := 0; j < 6; j++ {
+		for i := 1; i <= n; i++ {
+			copy(b, out[:8])
+			copy(b[8:], out[8*i:8*i+8])
+			block.Encrypt(b, b)
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(out[:8], binary.BigEndian.Uint64(b[:8])^t)
+			copy(out[8*i:8*i+8], b[8:])
+		}
+	}
+	return out, nil
+}
+
+// aesKeyUnwrap unwraps a key wrapped with the RFC 3394 AES key wrap algorithm
+func aesKeyUnwrap(kek, wrapped []byte) ([]byte, error) {
+	if len(wrapped) < 24 || len(wrapped)%8 != 0 {
+		return nil, pkgerrors.New("CKR_WRAPPED_KEY_LEN_RANGE")
+	}
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+	n := len(wrapped)/8 - 1
+	out := make([]byte, len(wrapped))
+	copy(out, wrapped)
+	b := make([]byte, 16)
+	for j := 5; j >= 0; j-- {
+		for i := n; i >= 1; i-- {
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(b[:8], binary.BigEndian.Uint64(out[:8])^t)
+			copy(b[8:], out[8*i:8*i+8])
+			block.Decrypt(b, b)
+			copy(out[:8], b[:8])
+			copy(out[8*i:8*i+8], b[8:])
+		}
+	}
+	if subtle.ConstantTimeCompare(out[:8], keyWrapIV) != 1 {
+		return nil, pkgerrors.New("CKR_WRAPPED_KEY_INVALID")
+	}
+	return out[8:], nil
+}
@@ -0,0 +1,55 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Lifecycle operations of a Deployment Intent Group
+const (
+	OperationInstantiate = "instantiate"
+	OperationUpdate      = "update"
+	OperationMigrate     = "migrate"
+	OperationRollback    = "rollback"
+	OperationTerminate   = "terminate"
+	OperationValidate    = "validate"
+)
+
+// Phases of a lifecycle operation
+const (
+	// PhaseTotal is the whole operation, as seen by the API caller
+	PhaseTotal = "total"
+	// PhaseRender is the resolution of the templates and the creation of the AppContext
+	PhaseRender = "render"
+	// PhasePlacementController is the call to a placement controller
+	PhasePlacementController = "placement-controller"
+	// PhaseActionController is the call to an action controller
+	PhaseActionController = "action-controller"
+	// PhaseRsync is the call to rsync to install, update or uninstall the resources
+	PhaseRsync = "rsync"
+)
+
+// LifecycleDuration observes the latency of the lifecycle operations by phase.
+// The controller label is only set for the controller phases.
+var LifecycleDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "emco_lifecycle_duration_seconds",
+	Help:    "Latency of the Deployment Intent Group lifecycle operations by phase",
+	Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120},
+}, []string{"operation", "phase", "controller", "result"})
+
+// Result returns the result label value for an error
+func Result(err error) string {
+	if err != nil {
+		return "failure"
+	}
+	return "success"
+}
+
+// ObserveLifecycleDuration records the time elapsed since start for a phase of a lifecycle operation
+func ObserveLifecycleDuration(operation, phase, controller string, start time.Time, err error) {
+	LifecycleDuration.WithLabelValues(operation, phase, controller, Result(err)).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,49 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestObserveLifecycleDuration(t *testing.T) {
+	start := time.Now().Add(-3 * time.Second)
+	ObserveLifecycleDuration(OperationUpdate, PhaseRsync, "", start, nil)
+	ObserveLifecycleDuration(OperationUpdate, PhaseActionController, "gac", start, errors.New("failed"))
+
+	if problems, err := testutil.CollectAndLint(LifecycleDuration); err != nil || len(problems) > 0 {
+		t.Errorf("Collector lint failed: %v %v", problems, err)
+	}
+	r := prometheus.NewPedanticRegistry()
+	r.MustRegister(LifecycleDuration)
+	families, err := r.Gather()
+	if err != nil || len(families) != 1 {
+		t.Fatalf("Unexpected metric families %v %v", families, err)
+	}
+	observed := map[string]bool{}
+	for _, m := range families[0].GetMetric() {
+		labels := ""
+		for _, l := range m.GetLabel() {
+			labels += l.GetName() + "=" + l.GetValue() + ","
+		}
+		h := m.GetHistogram()
+		if h.GetSampleCount() != 1 || h.GetSampleSum() < 3 || h.GetSampleSum() > 5 {
+			t.Errorf("Unexpected observations of %s: %d, %v seconds", labels, h.GetSampleCount(), h.GetSampleSum())
+		}
+		observed[labels] = true
+	}
+	for _, labels := range []string{
+		"controller=,operation=update,phase=rsync,result=success,",
+		"controller=gac,operation=update,phase=action-controller,result=failure,",
+	} {
+		if !observed[labels] {
+			t.Errorf("No observation of %s in %v", labels, observed)
+		}
+	}
+}
@@ -15,6 +15,7 @@ import (
 	pkgerrors "github.com/pkg/errors"
 	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/config"
 	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/tracing"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/connectivity"
@@ -214,7 +215,7 @@ func createClientConn(Host string, Port int) (*grpc.ClientConn, error) {
 	} else {
 		opts = append(opts, grpc.WithInsecure())
 	}
-	opts = append(opts, grpc.WithUnaryInterceptor(otelgrpc.UnaryClientInterceptor()))
+	opts = append(opts, grpc.WithChainUnaryInterceptor(otelgrpc.UnaryClientInterceptor(), tracing.UnaryClientInterceptor()))
 	opts = append(opts, grpc.WithStreamInterceptor(otelgrpc.StreamClientInterceptor()))
 
 	//dialOpts := getGrpcDialOpts()
@@ -247,3 +248,21 @@ func getGrpcDialOpts() []grpc.DialOption {
 
 	return opts
 }
+
+// DialPlugin connects to a plugin listening on the endpoint: unix:///<path> for a
+// plugin listening on a Unix socket, typically a sidecar, or <host>:<port>. The
+// connection to a host requires TLS with the CA file, as the plugins exchange
+// keys and credentials with the services.
+func DialPlugin(endpoint, caFile string) (*grpc.ClientConn, error) {
+	if strings.HasPrefix(endpoint, "unix:") {
+		return grpc.Dial(endpoint, grpc.WithInsecure())
+	}
+	if caFile == "" {
+		return nil, pkgerrors.Errorf("A CA file is required for the TLS connection to the plugin %s, or use a unix:///<path> endpoint", endpoint)
+	}
+	creds, err := credentials.NewClientTLSFromFile(caFile, "")
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "Error creating the plugin TLS credentials")
+	}
+	return grpc.Dial(endpoint, grpc.WithTransportCredentials(creds))
+}
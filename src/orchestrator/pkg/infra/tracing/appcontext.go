@@ -0,0 +1,84 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+)
+
+// AppContextIDKey is the span attribute holding the AppContext ID
+const AppContextIDKey = attribute.Key("emco.appcontext.id")
+
+// appContextRequest is implemented by the gRPC requests that carry an AppContext ID
+type appContextRequest interface {
+	GetAppContext() string
+}
+
+// updateAppContextRequest is implemented by the gRPC requests that update an AppContext
+type updateAppContextRequest interface {
+	GetUpdateToAppContext() string
+}
+
+// SetAppContextID adds the AppContext ID to the current span
+func SetAppContextID(ctx context.Context, acID string) {
+	if acID == "" {
+		return
+	}
+	trace.SpanFromContext(ctx).SetAttributes(AppContextIDKey.String(acID))
+}
+
+func setRequestAppContextID(ctx context.Context, req interface{}) {
+	switch r := req.(type) {
+	case appContextRequest:
+		SetAppContextID(ctx, r.GetAppContext())
+	case updateAppContextRequest:
+		SetAppContextID(ctx, r.GetUpdateToAppContext())
+	}
+}
+
+// UnaryServerInterceptor tags the server span with the AppContext ID of the request.
+// It must be chained after the OpenTelemetry server interceptor.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		setRequestAppContextID(ctx, req)
+		return handler(ctx, req)
+	}
+}
+
+// UnaryClientInterceptor tags the client span with the AppContext ID of the request.
+// It must be chained after the OpenTelemetry client interceptor.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		setRequestAppContextID(ctx, req)
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// InjectTraceContext returns the trace context of ctx in a form that can be stored
+// with an AppContext, so that asynchronous work on the AppContext joins the trace
+func InjectTraceContext(ctx context.Context) map[string]string {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return nil
+	}
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return nil
+	}
+	return carrier
+}
+
+// ExtractTraceContext returns a copy of ctx with the trace context stored by InjectTraceContext
+func ExtractTraceContext(ctx context.Context, tc map[string]string) context.Context {
+	if len(tc) == 0 {
+		return ctx
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(tc))
+}
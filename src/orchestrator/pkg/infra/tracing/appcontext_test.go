@@ -0,0 +1,40 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/contrib/propagators/b3"
+	"go.opentelemetry.io/otel"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTraceContextRoundTrip(t *testing.T) {
+	otel.SetTextMapPropagator(b3.New())
+	tp := tracesdk.NewTracerProvider()
+	ctx, span := tp.Tracer("test").Start(context.Background(), "instantiate")
+	defer span.End()
+
+	tc := InjectTraceContext(ctx)
+	if len(tc) == 0 {
+		t.Fatal("InjectTraceContext returned no trace context")
+	}
+	sc := trace.SpanContextFromContext(ExtractTraceContext(context.Background(), tc))
+	if sc.TraceID() != span.SpanContext().TraceID() {
+		t.Errorf("Extracted trace ID %v, expected %v", sc.TraceID(), span.SpanContext().TraceID())
+	}
+}
+
+func TestNoTraceContext(t *testing.T) {
+	if tc := InjectTraceContext(context.Background()); tc != nil {
+		t.Errorf("InjectTraceContext returned %v without a span", tc)
+	}
+	ctx := context.Background()
+	if ExtractTraceContext(ctx, nil) != ctx {
+		t.Errorf("ExtractTraceContext changed the context without a trace context")
+	}
+}
@@ -4,13 +4,18 @@
 package utils
 
 import (
+	"context"
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/rand"
 	"encoding/hex"
 	"os"
 	"reflect"
 	"strings"
+	"sync"
 
+	pkgerrors "github.com/pkg/errors"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/kms"
 	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
 )
 
@@ -21,43 +26,201 @@ type IObjectEncryptor interface {
 	DecryptString(ciphermessage string) (string, error)
 }
 
+// IKeyRotator is implemented by the object encryptors supporting several key versions
+type IKeyRotator interface {
+	// ActiveKeyId returns the ID of the key used to encrypt
+	ActiveKeyId() string
+	// ReencryptString returns the ciphermessage encrypted with the active key, and
+	// false if the ciphermessage is already encrypted with the active key or is not
+	// a ciphermessage of one of the keys
+	ReencryptString(ciphermessage string) (string, bool, error)
+}
+
+// LegacyKeyId is the ID of the key set with <PROVIDER>_DATA_KEY
+const LegacyKeyId = "0"
+
+// The ciphermessages are stored as <cipherPrefix><key id>:<hex of nonce and ciphertext>.
+// The ciphermessages created before key IDs were supported are the hex of the
+// ciphertext, encrypted with a fixed nonce.
+const cipherPrefix = "v1:"
+
+// When a KMS provider is configured, the ciphermessages are encrypted with a data key
+// wrapped by the KMS, and stored as <envelopePrefix><KMS key id>:<hex of the wrapped
+// data key>:<hex of nonce and ciphertext>.
+const envelopePrefix = "v2:"
+
 type MyObjectEncryptor struct {
-	gcm   cipher.AEAD
-	nonce []byte
+	gcm   cipher.AEAD // active key
+	keyId string      // ID of the active key
+	keys  map[string]cipher.AEAD
+	nonce []byte // fixed nonce of the legacy ciphermessages
+
+	// Envelope encryption, with a KMS provider
+	kmsConfig  *kms.Config
+	kmsLock    sync.Mutex
+	kms        kms.Provider
+	dek        cipher.AEAD            // data key of the service
+	wrappedDek string                 // <KMS key id>:<hex of the wrapped data key>
+	deks       map[string]cipher.AEAD // unwrapped data keys, by wrapped data key
 }
 
 var gobjencs = make(map[string]IObjectEncryptor)
 
+// parseDataKeys returns the keys configured for the provider, and the ID of the active key.
+// <PROVIDER>_DATA_KEYS holds a comma separated list of <key id>=<key>, the first key
+// is the active key. <PROVIDER>_DATA_KEY holds the key with the ID LegacyKeyId.
+func parseDataKeys(provider string) (map[string][]byte, string, error) {
+	keys := make(map[string][]byte)
+	var active string
+	if v := os.Getenv(strings.ToUpper(provider) + "_DATA_KEYS"); len(v) > 0 {
+		for _, entry := range strings.Split(v, ",") {
+			kv := strings.SplitN(strings.TrimSpace(entry), "=", 2)
+			if len(kv) != 2 || kv[0] == "" || kv[1] == "" || strings.Contains(kv[0], ":") {
+				return nil, "", pkgerrors.Errorf("Invalid data key entry %s, use <key id>=<key>", kv[0])
+			}
+			if _, ok := keys[kv[0]]; ok {
+				return nil, "", pkgerrors.Errorf("Duplicate data key ID %s", kv[0])
+			}
+			keys[kv[0]] = []byte(kv[1])
+			if active == "" {
+				active = kv[0]
+			}
+		}
+	}
+	if v := os.Getenv(strings.ToUpper(provider) + "_DATA_KEY"); len(v) > 0 {
+		if _, ok := keys[LegacyKeyId]; !ok {
+			keys[LegacyKeyId] = []byte(v)
+		}
+		if active == "" {
+			active = LegacyKeyId
+		}
+	}
+	return keys, active, nil
+}
+
+// GetObjectEncryptor returns the object encryptor of the provider. If a KMS provider is
+// configured, the data is encrypted with a data key wrapped by the KMS, and the keys of
+// the environment are only used to decrypt the data encrypted before.
 func GetObjectEncryptor(provider string) IObjectEncryptor {
 	if gobjencs[provider] == nil {
-		envkey := strings.ToUpper(provider) + "_DATA_KEY"
-		if len(os.Getenv(envkey)) > 0 {
-			oe, err := createObjectEncryptor([]byte(os.Getenv(envkey)), []byte("emco nonce"))
-			if err != nil {
-				log.Error("Create Object Encryptor error :: ", log.Fields{"Error": err})
+		keys, active, err := parseDataKeys(provider)
+		if err != nil {
+			log.Error("Create Object Encryptor error :: ", log.Fields{"Error": err})
+			return nil
+		}
+		var oe IObjectEncryptor
+		if kmsConfig := kms.ConfigFromConfiguration(); kmsConfig.Provider != "" {
+			oe, err = createKmsObjectEncryptor(kmsConfig, keys, active, []byte("emco nonce"))
+		} else {
+			if len(keys) == 0 {
 				return nil
 			}
-			gobjencs[provider] = oe
-		} else {
+			oe, err = createObjectEncryptor(keys, active, []byte("emco nonce"))
+		}
+		if err != nil {
+			log.Error("Create Object Encryptor error :: ", log.Fields{"Error": err})
 			return nil
 		}
+		gobjencs[provider] = oe
 	}
 
 	return gobjencs[provider]
 }
 
-func createObjectEncryptor(key []byte, nonce []byte) (IObjectEncryptor, error) {
-	// Format key and nonce
-	nkey := make([]byte, 32)
-	nnonce := make([]byte, 12)
-	for i := 0; i < 32; i++ {
-		if i < len(key) {
-			nkey[i] = key[i]
-		} else {
-			nkey[i] = 10
+// createKmsObjectEncryptor returns an object encryptor using the KMS provider for envelope encryption.
+// The KMS provider is created on first use, so that the service starts while the KMS is unavailable.
+func createKmsObjectEncryptor(kmsConfig kms.Config, keys map[string][]byte, active string, nonce []byte) (IObjectEncryptor, error) {
+	oe, err := createObjectEncryptor(keys, active, nonce)
+	if err != nil {
+		return nil, err
+	}
+	c := oe.(*MyObjectEncryptor)
+	c.kmsConfig = &kmsConfig
+	c.deks = make(map[string]cipher.AEAD)
+	return c, nil
+}
+
+// kmsProvider returns the KMS provider, creating it if required
+func (c *MyObjectEncryptor) kmsProvider() (kms.Provider, error) {
+	if c.kms == nil {
+		p, err := kms.NewProvider(*c.kmsConfig)
+		if err != nil {
+			return nil, pkgerrors.Wrap(err, "Error creating the KMS provider")
 		}
+		c.kms = p
+	}
+	return c.kms, nil
+}
+
+// dataKey returns the data key of the service and its wrapped value, creating them if required
+func (c *MyObjectEncryptor) dataKey() (cipher.AEAD, string, error) {
+	c.kmsLock.Lock()
+	defer c.kmsLock.Unlock()
+
+	if c.dek != nil {
+		return c.dek, c.wrappedDek, nil
+	}
+	p, err := c.kmsProvider()
+	if err != nil {
+		return nil, "", err
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, "", err
+	}
+	kekId, wrapped, err := p.WrapKey(context.Background(), key)
+	if err != nil {
+		return nil, "", err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, "", err
+	}
+	c.dek = gcm
+	c.wrappedDek = kekId + ":" + hex.EncodeToString(wrapped)
+	c.deks[c.wrappedDek] = gcm
+	return c.dek, c.wrappedDek, nil
+}
+
+// unwrapDataKey returns the data key of an envelope ciphermessage
+func (c *MyObjectEncryptor) unwrapDataKey(kekId, wrappedHex string) (cipher.AEAD, error) {
+	c.kmsLock.Lock()
+	defer c.kmsLock.Unlock()
+
+	if gcm, ok := c.deks[kekId+":"+wrappedHex]; ok {
+		return gcm, nil
+	}
+	wrapped, err := hex.DecodeString(wrappedHex)
+	if err != nil {
+		return nil, err
+	}
+	p, err := c.kmsProvider()
+	if err != nil {
+		return nil, err
+	}
+	key, err := p.UnwrapKey(context.Background(), kekId, wrapped)
+	if err != nil {
+		return nil, err
 	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	c.deks[kekId+":"+wrappedHex] = gcm
+	return gcm, nil
+}
 
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func createObjectEncryptor(keys map[string][]byte, active string, nonce []byte) (IObjectEncryptor, error) {
+	// Format nonce
+	nnonce := make([]byte, 12)
 	for i := 0; i < 12; i++ {
 		if i < len(nonce) {
 			nnonce[i] = nonce[i]
@@ -66,17 +229,32 @@ func createObjectEncryptor(key []byte, nonce []byte) (IObjectEncryptor, error) {
 		}
 	}
 
-	block, err := aes.NewCipher(nkey)
-	if err != nil {
-		return nil, err
-	}
+	c := &MyObjectEncryptor{keyId: active, keys: make(map[string]cipher.AEAD), nonce: nnonce}
+	for id, key := range keys {
+		// Format key
+		nkey := make([]byte, 32)
+		for i := 0; i < 32; i++ {
+			if i < len(key) {
+				nkey[i] = key[i]
+			} else {
+				nkey[i] = 10
+			}
+		}
 
-	aesgcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return nil, err
+		block, err := aes.NewCipher(nkey)
+		if err != nil {
+			return nil, err
+		}
+
+		aesgcm, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		c.keys[id] = aesgcm
 	}
+	c.gcm = c.keys[active]
 
-	return &MyObjectEncryptor{aesgcm, nnonce}, nil
+	return c, nil
 }
 
 func (c *MyObjectEncryptor) EncryptObject(o interface{}) (interface{}, error) {
@@ -87,24 +265,160 @@ func (c *MyObjectEncryptor) DecryptObject(o interface{}) (interface{}, error) {
 	return c.processObject(o, false, c.DecryptString)
 }
 
+// EncryptString encrypts the message with the active key and a random nonce
 func (c *MyObjectEncryptor) EncryptString(message string) (string, error) {
-	ciphermessage := c.gcm.Seal(nil, c.nonce, []byte(message), nil)
-	return hex.EncodeToString(ciphermessage), nil
+	gcm, prefix := c.gcm, cipherPrefix+c.keyId+":"
+	if c.kmsConfig != nil {
+		dek, wrapped, err := c.dataKey()
+		if err != nil {
+			return "", err
+		}
+		gcm, prefix = dek, envelopePrefix+wrapped+":"
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	ciphermessage := gcm.Seal(nonce, nonce, []byte(message), nil)
+	return prefix + hex.EncodeToString(ciphermessage), nil
 }
 
-func (c *MyObjectEncryptor) DecryptString(ciphermessage string) (string, error) {
-	cm, err := hex.DecodeString(ciphermessage)
+// open decrypts the hex of the nonce and ciphertext
+func open(gcm cipher.AEAD, hexCiphertext string) (string, error) {
+	cm, err := hex.DecodeString(hexCiphertext)
 	if err != nil {
 		return "", err
 	}
+	if len(cm) < gcm.NonceSize() {
+		return "", pkgerrors.New("Invalid ciphermessage")
+	}
+	message, err := gcm.Open(nil, cm[:gcm.NonceSize()], cm[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", err
+	}
+	return string(message), nil
+}
 
-	message, err := c.gcm.Open(nil, c.nonce, cm, nil)
+// decrypt returns the message and the ID of the key of the ciphermessage
+func (c *MyObjectEncryptor) decrypt(ciphermessage string) (string, string, error) {
+	if strings.HasPrefix(ciphermessage, envelopePrefix) {
+		parts := strings.SplitN(strings.TrimPrefix(ciphermessage, envelopePrefix), ":", 3)
+		if len(parts) != 3 {
+			return "", "", pkgerrors.New("Invalid ciphermessage")
+		}
+		if c.kmsConfig == nil {
+			return "", "", pkgerrors.New("No KMS provider configured to decrypt the ciphermessage")
+		}
+		gcm, err := c.unwrapDataKey(parts[0], parts[1])
+		if err != nil {
+			return "", "", err
+		}
+		message, err := open(gcm, parts[2])
+		if err != nil {
+			return "", "", err
+		}
+		return message, envelopePrefix + parts[0], nil
+	}
 
+	if strings.HasPrefix(ciphermessage, cipherPrefix) {
+		parts := strings.SplitN(strings.TrimPrefix(ciphermessage, cipherPrefix), ":", 2)
+		if len(parts) != 2 {
+			return "", "", pkgerrors.New("Invalid ciphermessage")
+		}
+		gcm, ok := c.keys[parts[0]]
+		if !ok {
+			return "", "", pkgerrors.Errorf("Unknown data key ID %s", parts[0])
+		}
+		message, err := open(gcm, parts[1])
+		if err != nil {
+			return "", "", err
+		}
+		return message, parts[0], nil
+	}
+
+	// Legacy ciphermessage, without key ID
+	cm, err := hex.DecodeString(ciphermessage)
 	if err != nil {
-		return "", err
+		return "", "", err
+	}
+	ids := []string{LegacyKeyId}
+	for id := range c.keys {
+		if id != LegacyKeyId {
+			ids = append(ids, id)
+		}
 	}
+	for _, id := range ids {
+		gcm, ok := c.keys[id]
+		if !ok {
+			continue
+		}
+		message, err := gcm.Open(nil, c.nonce, cm, nil)
+		if err == nil {
+			return string(message), "", nil
+		}
+	}
+	return "", "", pkgerrors.New("Unable to decrypt the ciphermessage with the data keys")
+}
 
-	return string(message), nil
+func (c *MyObjectEncryptor) DecryptString(ciphermessage string) (string, error) {
+	message, _, err := c.decrypt(ciphermessage)
+	return message, err
+}
+
+// isKeyIdCiphermessage returns true if the string has the format of a ciphermessage with a key ID
+func isKeyIdCiphermessage(s string) bool {
+	if strings.HasPrefix(s, envelopePrefix) {
+		parts := strings.SplitN(strings.TrimPrefix(s, envelopePrefix), ":", 3)
+		if len(parts) != 3 {
+			return false
+		}
+		_, err1 := hex.DecodeString(parts[1])
+		_, err2 := hex.DecodeString(parts[2])
+		return err1 == nil && err2 == nil
+	}
+	if !strings.HasPrefix(s, cipherPrefix) {
+		return false
+	}
+	parts := strings.SplitN(strings.TrimPrefix(s, cipherPrefix), ":", 2)
+	if len(parts) != 2 {
+		return false
+	}
+	_, err := hex.DecodeString(parts[1])
+	return err == nil
+}
+
+// ActiveKeyId returns the ID of the key used to encrypt. With a KMS provider, it is
+// the ID of the KMS key prefixed with the envelope format version, or an empty string
+// if the KMS is unavailable.
+func (c *MyObjectEncryptor) ActiveKeyId() string {
+	if c.kmsConfig != nil {
+		_, wrapped, err := c.dataKey()
+		if err != nil {
+			return ""
+		}
+		return envelopePrefix + strings.SplitN(wrapped, ":", 2)[0]
+	}
+	return c.keyId
+}
+
+// ReencryptString returns the ciphermessage encrypted with the active key
+func (c *MyObjectEncryptor) ReencryptString(ciphermessage string) (string, bool, error) {
+	message, keyId, err := c.decrypt(ciphermessage)
+	if err != nil {
+		if isKeyIdCiphermessage(ciphermessage) {
+			return ciphermessage, false, err
+		}
+		// Not a ciphermessage, the authentication of the ciphertext fails
+		return ciphermessage, false, nil
+	}
+	if keyId == c.ActiveKeyId() {
+		return ciphermessage, false, nil
+	}
+	reencrypted, err := c.EncryptString(message)
+	if err != nil {
+		return ciphermessage, false, err
+	}
+	return reencrypted, true, nil
 }
 
 func (c *MyObjectEncryptor) processObject(o interface{}, encrypt bool, oper func(string) (string, error)) (interface{}, error) {
@@ -0,0 +1,146 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/kms"
+)
+
+type testEncrypted struct {
+	Name   string
+	Secret string `encrypted:""`
+}
+
+func TestObjectEncryptorKeyRotation(t *testing.T) {
+	defer os.Unsetenv("TEST_DATA_KEY")
+	defer os.Unsetenv("TEST_DATA_KEYS")
+
+	// Ciphermessage of the legacy format, encrypted with the fixed nonce
+	os.Setenv("TEST_DATA_KEY", "oldkey")
+	legacy, err := createObjectEncryptor(map[string][]byte{LegacyKeyId: []byte("oldkey")}, LegacyKeyId, []byte("emco nonce"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, _ := legacy.(*MyObjectEncryptor)
+	legacyMessage := hex.EncodeToString(c.gcm.Seal(nil, c.nonce, []byte("secret"), nil))
+
+	os.Setenv("TEST_DATA_KEYS", "k2=newkey")
+	oe := GetObjectEncryptor("test")
+	if oe == nil {
+		t.Fatal("GetObjectEncryptor returned nil")
+	}
+	rotator, ok := oe.(IKeyRotator)
+	if !ok || rotator.ActiveKeyId() != "k2" {
+		t.Fatalf("Unexpected active key")
+	}
+
+	// Random nonces
+	e1, _ := oe.EncryptString("secret")
+	e2, _ := oe.EncryptString("secret")
+	if e1 == e2 || !strings.HasPrefix(e1, "v1:k2:") {
+		t.Errorf("Unexpected ciphermessages %s %s", e1, e2)
+	}
+
+	for _, m := range []string{e1, legacyMessage} {
+		d, err := oe.DecryptString(m)
+		if err != nil || d != "secret" {
+			t.Errorf("DecryptString(%s) returned %s, %v", m, d, err)
+		}
+	}
+
+	r, changed, err := rotator.ReencryptString(legacyMessage)
+	if err != nil || !changed || !strings.HasPrefix(r, "v1:k2:") {
+		t.Errorf("The legacy ciphermessage was not re-encrypted: %s %v %v", r, changed, err)
+	}
+	if _, changed, _ := rotator.ReencryptString(e1); changed {
+		t.Errorf("A ciphermessage of the active key was re-encrypted")
+	}
+	if _, changed, err := rotator.ReencryptString("deadbeef"); changed || err != nil {
+		t.Errorf("A plain string was re-encrypted")
+	}
+	if _, _, err := rotator.ReencryptString("v1:k9:00112233"); err == nil {
+		t.Errorf("A ciphermessage of an unknown key was accepted")
+	}
+
+	o, err := oe.EncryptObject(testEncrypted{Name: "n", Secret: "s"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := o.(testEncrypted)
+	if e.Name != "n" || !strings.HasPrefix(e.Secret, "v1:k2:") {
+		t.Errorf("Unexpected encrypted object %v", e)
+	}
+	o, err = oe.DecryptObject(e)
+	if err != nil || o.(testEncrypted).Secret != "s" {
+		t.Errorf("Unexpected decrypted object %v %v", o, err)
+	}
+}
+
+func TestObjectEncryptorKms(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kms")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	keyFile := filepath.Join(dir, "keys")
+	kek := base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef"))
+	if err := ioutil.WriteFile(keyFile, []byte("kek1="+kek), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Ciphermessage of a key of the environment, encrypted before the KMS was configured
+	previous, err := createObjectEncryptor(map[string][]byte{"k1": []byte("envkey")}, "k1", []byte("emco nonce"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	envMessage, _ := previous.EncryptString("secret")
+
+	oe, err := createKmsObjectEncryptor(kms.Config{Provider: kms.FileProvider, KeyFile: keyFile},
+		map[string][]byte{"k1": []byte("envkey")}, "k1", []byte("emco nonce"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := oe.EncryptString("secret")
+	if err != nil || !strings.HasPrefix(e, "v2:kek1:") {
+		t.Fatalf("Unexpected envelope ciphermessage %s, %v", e, err)
+	}
+
+	// A new encryptor unwraps the data key with the KMS
+	other, _ := createKmsObjectEncryptor(kms.Config{Provider: kms.FileProvider, KeyFile: keyFile}, nil, "", []byte("emco nonce"))
+	for _, m := range []string{e, envMessage} {
+		d, err := oe.DecryptString(m)
+		if err != nil || d != "secret" {
+			t.Errorf("DecryptString(%s) returned %s, %v", m, d, err)
+		}
+	}
+	if d, err := other.DecryptString(e); err != nil || d != "secret" {
+		t.Errorf("The data key was not unwrapped: %s, %v", d, err)
+	}
+
+	rotator := oe.(IKeyRotator)
+	if rotator.ActiveKeyId() != "v2:kek1" {
+		t.Errorf("Unexpected active key ID %s", rotator.ActiveKeyId())
+	}
+	r, changed, err := rotator.ReencryptString(envMessage)
+	if err != nil || !changed || !strings.HasPrefix(r, "v2:kek1:") {
+		t.Errorf("The ciphermessage of the environment key was not re-encrypted: %s %v %v", r, changed, err)
+	}
+	if _, changed, _ := rotator.ReencryptString(e); changed {
+		t.Errorf("An envelope ciphermessage of the active key was re-encrypted")
+	}
+
+	// The data is not encrypted while the KMS is unavailable
+	unavailable, _ := createKmsObjectEncryptor(kms.Config{Provider: kms.FileProvider, KeyFile: filepath.Join(dir, "missing")}, nil, "", []byte("emco nonce"))
+	if _, err := unavailable.EncryptString("secret"); err == nil {
+		t.Errorf("The data was encrypted without the KMS")
+	}
+}
@@ -0,0 +1,393 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package module
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ghodss/yaml"
+	pkgerrors "github.com/pkg/errors"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/appcontext"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/db"
+	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+	mtypes "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/module/types"
+)
+
+// Enforcement actions of an admission policy
+const (
+	// EnforcementDeny blocks the instantiation or update violating the policy
+	EnforcementDeny = "deny"
+	// EnforcementWarn only reports the violations of the policy
+	EnforcementWarn = "warn"
+)
+
+// Scopes of an admission policy
+const (
+	AdmissionScopeGlobal  = "global"
+	AdmissionScopeProject = "project"
+)
+
+// AdmissionPolicy is a Rego policy evaluated on the rendered AppContext of a
+// deployment intent group, before it is instantiated or updated
+type AdmissionPolicy struct {
+	Metadata mtypes.Metadata     `json:"metadata"`
+	Spec     AdmissionPolicySpec `json:"spec"`
+}
+
+// AdmissionPolicySpec contains the Rego module of the policy. The module reports
+// the violations with a deny rule, e.g. deny[msg] { ... }.
+type AdmissionPolicySpec struct {
+	Rego              string `json:"rego"`
+	EnforcementAction string `json:"enforcementAction,omitempty"`
+}
+
+// AdmissionPolicyKey is the key of a project admission policy
+type AdmissionPolicyKey struct {
+	Project         string `json:"project"`
+	AdmissionPolicy string `json:"admissionPolicy"`
+}
+
+// GlobalAdmissionPolicyKey is the key of a global admission policy, applied to all the projects
+type GlobalAdmissionPolicyKey struct {
+	AdmissionPolicy string `json:"globalAdmissionPolicy"`
+}
+
+// PolicyEvaluator evaluates the Rego module of an admission policy
+type PolicyEvaluator interface {
+	// Validate returns an error if the Rego module does not compile
+	Validate(ctx context.Context, name, module string) error
+	// Evaluate returns the messages of the deny rule of the Rego module for the input
+	Evaluate(ctx context.Context, name, module string, input interface{}) ([]string, error)
+}
+
+var policyEvaluator PolicyEvaluator
+
+// RegisterPolicyEvaluator sets the evaluator of the admission policies
+func RegisterPolicyEvaluator(e PolicyEvaluator) {
+	policyEvaluator = e
+}
+
+// AdmissionViolation is a violation of an admission policy
+type AdmissionViolation struct {
+	Policy  string `json:"policy"`
+	Scope   string `json:"scope"`
+	Message string `json:"message"`
+}
+
+// AdmissionReport is the result of the admission review of an AppContext
+type AdmissionReport struct {
+	Allowed    bool                 `json:"allowed"`
+	Violations []AdmissionViolation `json:"violations,omitempty"`
+	Warnings   []AdmissionViolation `json:"warnings,omitempty"`
+}
+
+// AdmissionWarnings collects the violations of the warn policies reviewed with a
+// context returned by WithAdmissionWarnings, e.g. to return them to the API client
+type AdmissionWarnings struct {
+	lock       sync.Mutex
+	violations []AdmissionViolation
+}
+
+type admissionWarningsKey struct{}
+
+// WithAdmissionWarnings returns a context collecting the violations of the warn policies
+func WithAdmissionWarnings(ctx context.Context) (context.Context, *AdmissionWarnings) {
+	w := &AdmissionWarnings{}
+	return context.WithValue(ctx, admissionWarningsKey{}, w), w
+}
+
+func (w *AdmissionWarnings) add(violations []AdmissionViolation) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.violations = append(w.violations, violations...)
+}
+
+// Violations returns the violations of the warn policies collected so far
+func (w *AdmissionWarnings) Violations() []AdmissionViolation {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return append([]AdmissionViolation{}, w.violations...)
+}
+
+// AdmissionDeniedError is returned when the AppContext violates a deny policy
+type AdmissionDeniedError struct {
+	Report AdmissionReport
+}
+
+func (e *AdmissionDeniedError) Error() string {
+	msgs := make([]string, 0, len(e.Report.Violations))
+	for _, v := range e.Report.Violations {
+		msgs = append(msgs, fmt.Sprintf("%s: %s", v.Policy, v.Message))
+	}
+	return "Admission denied by policy: " + strings.Join(msgs, "; ")
+}
+
+// AdmissionResource is a rendered resource of the AppContext, in the admission review input
+type AdmissionResource struct {
+	App     string                 `json:"app"`
+	Cluster string                 `json:"cluster"`
+	Name    string                 `json:"name"`
+	Object  map[string]interface{} `json:"object"`
+}
+
+// AdmissionInput is the input of the admission policies
+type AdmissionInput struct {
+	Project               string              `json:"project"`
+	CompositeApp          string              `json:"compositeApp"`
+	CompositeAppVersion   string              `json:"compositeAppVersion"`
+	DeploymentIntentGroup string              `json:"deploymentIntentGroup"`
+	Operation             string              `json:"operation"`
+	LogicalCloud          AdmissionCloud      `json:"logicalCloud"`
+	Resources             []AdmissionResource `json:"resources"`
+}
+
+// AdmissionCloud describes the logical cloud of the deployment intent group
+type AdmissionCloud struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Level     string `json:"level"`
+}
+
+// AdmissionPolicyManager is an interface exposing the AdmissionPolicy functionality.
+// The project is empty for the global admission policies.
+type AdmissionPolicyManager interface {
+	CreateAdmissionPolicy(ctx context.Context, p string, ap AdmissionPolicy, exists bool) (AdmissionPolicy, error)
+	GetAdmissionPolicy(ctx context.Context, p, name string) (AdmissionPolicy, error)
+	GetAdmissionPolicies(ctx context.Context, p string) ([]AdmissionPolicy, error)
+	DeleteAdmissionPolicy(ctx context.Context, p, name string) error
+}
+
+// AdmissionPolicyClient implements the AdmissionPolicyManager
+type AdmissionPolicyClient struct {
+	storeName string
+	tagMeta   string
+}
+
+// NewAdmissionPolicyClient returns an instance of the AdmissionPolicyClient
+func NewAdmissionPolicyClient() *AdmissionPolicyClient {
+	return &AdmissionPolicyClient{
+		storeName: "resources",
+		tagMeta:   "data",
+	}
+}
+
+func admissionPolicyKey(p, name string) db.Key {
+	if p == "" {
+		return GlobalAdmissionPolicyKey{AdmissionPolicy: name}
+	}
+	return AdmissionPolicyKey{Project: p, AdmissionPolicy: name}
+}
+
+// CreateAdmissionPolicy creates or updates an admission policy
+func (v *AdmissionPolicyClient) CreateAdmissionPolicy(ctx context.Context, p string, ap AdmissionPolicy, exists bool) (AdmissionPolicy, error) {
+	switch ap.Spec.EnforcementAction {
+	case "":
+		ap.Spec.EnforcementAction = EnforcementDeny
+	case EnforcementDeny, EnforcementWarn:
+	default:
+		return AdmissionPolicy{}, pkgerrors.Errorf("Invalid AdmissionPolicy enforcement action: %s", ap.Spec.EnforcementAction)
+	}
+	if policyEvaluator != nil {
+		if err := policyEvaluator.Validate(ctx, ap.Metadata.Name, ap.Spec.Rego); err != nil {
+			return AdmissionPolicy{}, pkgerrors.Wrap(err, "Invalid AdmissionPolicy Rego module")
+		}
+	}
+
+	_, err := v.GetAdmissionPolicy(ctx, p, ap.Metadata.Name)
+	if err == nil && !exists {
+		return AdmissionPolicy{}, pkgerrors.New("AdmissionPolicy already exists")
+	}
+
+	err = db.DBconn.Insert(ctx, v.storeName, admissionPolicyKey(p, ap.Metadata.Name), nil, v.tagMeta, ap)
+	if err != nil {
+		return AdmissionPolicy{}, pkgerrors.Wrap(err, "Create DB entry error")
+	}
+	return ap, nil
+}
+
+// GetAdmissionPolicy returns an admission policy
+func (v *AdmissionPolicyClient) GetAdmissionPolicy(ctx context.Context, p, name string) (AdmissionPolicy, error) {
+	value, err := db.DBconn.Find(ctx, v.storeName, admissionPolicyKey(p, name), v.tagMeta)
+	if err != nil {
+		return AdmissionPolicy{}, err
+	}
+	if len(value) == 0 || value[0] == nil {
+		return AdmissionPolicy{}, pkgerrors.New("AdmissionPolicy not found")
+	}
+
+	ap := AdmissionPolicy{}
+	if err := db.DBconn.Unmarshal(value[0], &ap); err != nil {
+		return AdmissionPolicy{}, err
+	}
+	return ap, nil
+}
+
+// GetAdmissionPolicies returns the admission policies of the project, or the global ones
+func (v *AdmissionPolicyClient) GetAdmissionPolicies(ctx context.Context, p string) ([]AdmissionPolicy, error) {
+	values, err := db.DBconn.Find(ctx, v.storeName, admissionPolicyKey(p, ""), v.tagMeta)
+	if err != nil {
+		return []AdmissionPolicy{}, err
+	}
+
+	res := []AdmissionPolicy{}
+	for _, value := range values {
+		if value == nil {
+			continue
+		}
+		ap := AdmissionPolicy{}
+		if err := db.DBconn.Unmarshal(value, &ap); err != nil {
+			return []AdmissionPolicy{}, err
+		}
+		res = append(res, ap)
+	}
+	return res, nil
+}
+
+// DeleteAdmissionPolicy removes an admission policy
+func (v *AdmissionPolicyClient) DeleteAdmissionPolicy(ctx context.Context, p, name string) error {
+	if _, err := v.GetAdmissionPolicy(ctx, p, name); err != nil {
+		return err
+	}
+	return db.DBconn.Remove(ctx, v.storeName, admissionPolicyKey(p, name))
+}
+
+// reviewAppContext evaluates the global and project admission policies on the rendered
+// AppContext of the deployment intent group. It returns an AdmissionDeniedError if
+// a deny policy is violated. The violations of the warn policies are logged, and
+// collected if the context is returned by WithAdmissionWarnings.
+func reviewAppContext(ctx context.Context, operation string, ac appcontext.AppContext, p, ca, v, di, lcName string) (AdmissionReport, error) {
+	report := AdmissionReport{Allowed: true}
+
+	c := NewAdmissionPolicyClient()
+	type scopedPolicy struct {
+		scope  string
+		policy AdmissionPolicy
+	}
+	var policies []scopedPolicy
+	for _, scope := range []string{AdmissionScopeGlobal, AdmissionScopeProject} {
+		project := p
+		if scope == AdmissionScopeGlobal {
+			project = ""
+		}
+		aps, err := c.GetAdmissionPolicies(ctx, project)
+		if err != nil {
+			return report, pkgerrors.Wrap(err, "Error getting the admission policies")
+		}
+		for _, ap := range aps {
+			policies = append(policies, scopedPolicy{scope, ap})
+		}
+	}
+	if len(policies) == 0 {
+		return report, nil
+	}
+	if policyEvaluator == nil {
+		return report, pkgerrors.New("No admission policy evaluator is registered")
+	}
+
+	input, unreviewed, err := makeAdmissionInput(ctx, ac, p, ca, v, di, lcName)
+	if err != nil {
+		return report, err
+	}
+	input.Operation = operation
+
+	for _, sp := range policies {
+		msgs, err := policyEvaluator.Evaluate(ctx, sp.policy.Metadata.Name, sp.policy.Spec.Rego, input)
+		if err != nil {
+			return report, pkgerrors.Wrapf(err, "Error evaluating the admission policy %s", sp.policy.Metadata.Name)
+		}
+		// The resources which can't be reviewed violate every policy, so that they are denied by the enforced ones
+		msgs = append(msgs, unreviewed...)
+		for _, msg := range msgs {
+			violation := AdmissionViolation{Policy: sp.policy.Metadata.Name, Scope: sp.scope, Message: msg}
+			if sp.policy.Spec.EnforcementAction == EnforcementWarn {
+				report.Warnings = append(report.Warnings, violation)
+			} else {
+				report.Violations = append(report.Violations, violation)
+			}
+		}
+	}
+
+	for _, w := range report.Warnings {
+		log.Warn("Admission policy violation", log.Fields{"policy": w.Policy, "scope": w.Scope, "message": w.Message,
+			"project": p, "compositeApp": ca, "compositeAppVersion": v, "deploymentIntentGroup": di})
+	}
+	if warnings, ok := ctx.Value(admissionWarningsKey{}).(*AdmissionWarnings); ok {
+		warnings.add(report.Warnings)
+	}
+	if len(report.Violations) > 0 {
+		report.Allowed = false
+		return report, &AdmissionDeniedError{Report: report}
+	}
+	return report, nil
+}
+
+// makeAdmissionInput collects the rendered resources of the AppContext. It also returns
+// the violation messages of the resources which can't be parsed for the review.
+func makeAdmissionInput(ctx context.Context, ac appcontext.AppContext, p, ca, v, di, lcName string) (AdmissionInput, []string, error) {
+	input := AdmissionInput{
+		Project:               p,
+		CompositeApp:          ca,
+		CompositeAppVersion:   v,
+		DeploymentIntentGroup: di,
+		Resources:             []AdmissionResource{},
+	}
+	var unreviewed []string
+
+	lc, err := NewLogicalCloudClient().Get(ctx, p, lcName)
+	if err == nil {
+		input.LogicalCloud = AdmissionCloud{
+			Name:      lcName,
+			Namespace: lc.Specification.NameSpace,
+			Level:     lc.Specification.Level,
+		}
+	} else {
+		input.LogicalCloud.Name = lcName
+	}
+
+	order, err := ac.GetAppInstruction(ctx, appcontext.OrderInstruction)
+	if err != nil {
+		return input, nil, pkgerrors.Wrap(err, "Error getting the app order instruction")
+	}
+	var apps appOrderInstr
+	if err := json.Unmarshal([]byte(order.(string)), &apps); err != nil {
+		return input, nil, pkgerrors.Wrap(err, "Error unmarshalling the app order instruction")
+	}
+
+	for _, app := range apps.Apporder {
+		clusters, err := ac.GetClusterNames(ctx, app)
+		if err != nil {
+			return input, nil, pkgerrors.Wrapf(err, "Error getting the clusters of app %s", app)
+		}
+		for _, cluster := range clusters {
+			names, err := ac.GetResourceNames(ctx, app, cluster)
+			if err != nil {
+				return input, nil, pkgerrors.Wrapf(err, "Error getting the resources of app %s in cluster %s", app, cluster)
+			}
+			for _, name := range names {
+				rh, err := ac.GetResourceHandle(ctx, app, cluster, name)
+				if err != nil {
+					return input, nil, err
+				}
+				value, err := ac.GetValue(ctx, rh)
+				if err != nil {
+					return input, nil, err
+				}
+				obj := map[string]interface{}{}
+				if s, ok := value.(string); ok {
+					if err := yaml.Unmarshal([]byte(s), &obj); err != nil {
+						log.Warn("Resource not reviewed by the admission policies", log.Fields{"app": app, "cluster": cluster, "resource": name, "error": err})
+						unreviewed = append(unreviewed, fmt.Sprintf("resource %s of app %s in cluster %s can't be reviewed: %s", name, app, cluster, err.Error()))
+						continue
+					}
+				}
+				input.Resources = append(input.Resources, AdmissionResource{App: app, Cluster: cluster, Name: name, Object: obj})
+			}
+		}
+	}
+	return input, unreviewed, nil
+}
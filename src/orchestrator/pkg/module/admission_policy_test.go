@@ -0,0 +1,158 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package module
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	pkgerrors "github.com/pkg/errors"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/appcontext"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/contextdb"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/db"
+	mtypes "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/module/types"
+)
+
+// fakeEvaluator denies the inputs containing a resource of the kind named by the policy module
+type fakeEvaluator struct{}
+
+func (e fakeEvaluator) Validate(ctx context.Context, name, module string) error {
+	if module == "invalid" {
+		return pkgerrors.New("rego_parse_error")
+	}
+	return nil
+}
+
+func (e fakeEvaluator) Evaluate(ctx context.Context, name, module string, input interface{}) ([]string, error) {
+	var msgs []string
+	for _, r := range input.(AdmissionInput).Resources {
+		if r.Object["kind"] == module {
+			msgs = append(msgs, module+" "+r.Name+" is not allowed in "+r.Cluster)
+		}
+	}
+	return msgs, nil
+}
+
+func TestReviewAppContext(t *testing.T) {
+	ctx := context.Background()
+	savedDb, savedContextDb, savedEvaluator := db.DBconn, contextdb.Db, policyEvaluator
+	defer func() { db.DBconn, contextdb.Db, policyEvaluator = savedDb, savedContextDb, savedEvaluator }()
+
+	var err error
+	db.DBconn = &db.MockDB{}
+	contextdb.Db, err = contextdb.NewEmbeddedContextDb("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	RegisterPolicyEvaluator(fakeEvaluator{})
+
+	// An AppContext with a Pod and a ClusterRole
+	ac := appcontext.AppContext{}
+	if _, err := ac.InitAppContext(); err != nil {
+		t.Fatal(err)
+	}
+	cah, err := ac.CreateCompositeApp(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ac.AddInstruction(ctx, cah, "app", "order", `{"apporder":["app1"]}`); err != nil {
+		t.Fatal(err)
+	}
+	ah, err := ac.AddApp(ctx, cah, "app1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch, err := ac.AddCluster(ctx, ah, "provider1+cluster1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resources := map[string]string{
+		"pod1+Pod":               "apiVersion: v1\nkind: Pod\nmetadata:\n  name: pod1\n",
+		"reader+ClusterRole":     "apiVersion: rbac.authorization.k8s.io/v1\nkind: ClusterRole\nmetadata:\n  name: reader\n",
+		"unparsable+ConfigMap":   "{",
+		"deployment1+Deployment": "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: deployment1\n",
+	}
+	for name, value := range resources {
+		if _, err := ac.AddResource(ctx, ch, name, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report, err := reviewAppContext(ctx, "instantiate", ac, "p1", "ca1", "v1", "dig1", "lc1")
+	if err != nil || !report.Allowed {
+		t.Fatalf("The AppContext was not admitted without policies: %v", err)
+	}
+
+	c := NewAdmissionPolicyClient()
+	newPolicy := func(name, module, action string) AdmissionPolicy {
+		return AdmissionPolicy{Metadata: mtypes.Metadata{Name: name}, Spec: AdmissionPolicySpec{Rego: module, EnforcementAction: action}}
+	}
+	if _, err := c.CreateAdmissionPolicy(ctx, "", newPolicy("invalid", "invalid", ""), false); err == nil || !strings.Contains(err.Error(), "Invalid AdmissionPolicy") {
+		t.Fatalf("Expected an invalid policy error, got %v", err)
+	}
+	if _, err := c.CreateAdmissionPolicy(ctx, "", newPolicy("pods", "Pod", "maybe"), false); err == nil || !strings.Contains(err.Error(), "Invalid AdmissionPolicy") {
+		t.Fatalf("Expected an invalid enforcement action error, got %v", err)
+	}
+	ap, err := c.CreateAdmissionPolicy(ctx, "", newPolicy("pods", "Pod", ""), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ap.Spec.EnforcementAction != EnforcementDeny {
+		t.Errorf("The default enforcement action is %s", ap.Spec.EnforcementAction)
+	}
+	if _, err := c.CreateAdmissionPolicy(ctx, "", newPolicy("pods", "Pod", ""), false); err == nil || err.Error() != "AdmissionPolicy already exists" {
+		t.Fatalf("Expected AdmissionPolicy already exists, got %v", err)
+	}
+	if _, err := c.CreateAdmissionPolicy(ctx, "", newPolicy("roles", "ClusterRole", EnforcementWarn), false); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err = reviewAppContext(ctx, "instantiate", ac, "p1", "ca1", "v1", "dig1", "lc1")
+	denied, ok := err.(*AdmissionDeniedError)
+	if !ok {
+		t.Fatalf("Expected an AdmissionDeniedError, got %v", err)
+	}
+	// The resource which can't be parsed is denied too
+	if report.Allowed || len(denied.Report.Violations) != 2 || len(denied.Report.Warnings) != 2 {
+		t.Fatalf("Unexpected admission report %+v", denied.Report)
+	}
+	v := denied.Report.Violations[0]
+	if v.Policy != "pods" || v.Scope != AdmissionScopeGlobal || v.Message != "Pod pod1+Pod is not allowed in provider1+cluster1" {
+		t.Errorf("Unexpected violation %+v", v)
+	}
+	if v := denied.Report.Violations[1]; v.Policy != "pods" || !strings.Contains(v.Message, "unparsable+ConfigMap of app app1 in cluster provider1+cluster1 can't be reviewed") {
+		t.Errorf("Unexpected violation %+v", v)
+	}
+	if w := denied.Report.Warnings[0]; w.Policy != "roles" {
+		t.Errorf("Unexpected warning %+v", w)
+	}
+
+	// The warn only policies do not block the AppContext
+	if err := c.DeleteAdmissionPolicy(ctx, "", "pods"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.CreateAdmissionPolicy(ctx, "", newPolicy("pods", "Pod", EnforcementWarn), false); err != nil {
+		t.Fatal(err)
+	}
+	wctx, warnings := WithAdmissionWarnings(ctx)
+	report, err = reviewAppContext(wctx, "update", ac, "p1", "ca1", "v1", "dig1", "lc1")
+	if err != nil || !report.Allowed || len(report.Warnings) != 4 {
+		t.Errorf("Unexpected admission report %+v, error %v", report, err)
+	}
+	if len(warnings.Violations()) != 4 {
+		t.Errorf("Unexpected collected warnings %+v", warnings.Violations())
+	}
+
+	policies, err := c.GetAdmissionPolicies(ctx, "")
+	if err != nil || len(policies) != 2 {
+		t.Errorf("Unexpected global policies %v, error %v", policies, err)
+	}
+	if err := c.DeleteAdmissionPolicy(ctx, "", "pods"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetAdmissionPolicy(ctx, "", "pods"); err == nil || err.Error() != "AdmissionPolicy not found" {
+		t.Errorf("Expected AdmissionPolicy not found, got %v", err)
+	}
+}
@@ -5,6 +5,7 @@ package module
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 
@@ -13,6 +14,7 @@ import (
 	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/appcontext"
 	gpic "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/gpic"
 	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/utils/helm"
 )
 
 type Instantiator struct {
@@ -55,6 +57,10 @@ func (i *Instantiator) makeAppContextForCompositeApp(ctx context.Context, namesp
 	if err != nil {
 		return contextForCompositeApp{}, pkgerrors.Wrap(err, "Error creating CompositeApp handle")
 	}
+	helmReleases, err := i.getHelmReleases(ctx)
+	if err != nil {
+		return contextForCompositeApp{}, err
+	}
 	rName := i.deploymentIntentGrp.Spec.Version //rName is releaseName
 	err = context.AddCompositeAppMeta(ctx, appcontext.CompositeAppMeta{
 		Project:               i.project,
@@ -65,6 +71,10 @@ func (i *Instantiator) makeAppContextForCompositeApp(ctx context.Context, namesp
 		Namespace:             namespace,
 		Level:                 level,
 		LogicalCloud:          logicalCloud,
+		DriftPolicy:           i.deploymentIntentGrp.Spec.DriftPolicy,
+		RetryPolicy:           i.deploymentIntentGrp.Spec.RetryPolicy,
+		HelmReleases:          helmReleases,
+		ForceApply:            i.deploymentIntentGrp.Spec.ForceApply,
 	})
 	if err != nil {
 		return contextForCompositeApp{}, pkgerrors.Wrap(err, "Error Adding CompositeAppMeta")
@@ -78,6 +88,31 @@ func (i *Instantiator) makeAppContextForCompositeApp(ctx context.Context, namesp
 
 }
 
+// getHelmReleases returns the charts of the apps of the deployment intent group
+// deployed as Helm releases
+func (i *Instantiator) getHelmReleases(ctx context.Context) (map[string]appcontext.HelmRelease, error) {
+	if len(i.deploymentIntentGrp.Spec.HelmReleases) == 0 {
+		return nil, nil
+	}
+	releases := map[string]appcontext.HelmRelease{}
+	for _, app := range i.deploymentIntentGrp.Spec.HelmReleases {
+		aC, err := NewAppClient().GetAppContent(ctx, app, i.project, i.compositeApp, i.compAppVersion)
+		if err != nil {
+			return nil, pkgerrors.Wrap(err, fmt.Sprint("AppContent not found for Helm release:: ", app))
+		}
+		appContent, err := base64.StdEncoding.DecodeString(aC.FileContent)
+		if err != nil {
+			return nil, pkgerrors.Wrap(err, "Fail to convert to byte array")
+		}
+		m, err := helm.ChartMetadata(appContent, app)
+		if err != nil {
+			return nil, pkgerrors.Wrap(err, fmt.Sprint("Chart not found for Helm release:: ", app))
+		}
+		releases[app] = appcontext.HelmRelease{Chart: m.Name, Version: m.Version, AppVersion: m.AppVersion}
+	}
+	return releases, nil
+}
+
 func (i *Instantiator) storeAppContextIntoRunTimeDB(ctx context.Context, cxtForCApp contextForCompositeApp, dcmClusters []common.Cluster, namespace string) error {
 
 	cappCtx := cxtForCApp.context
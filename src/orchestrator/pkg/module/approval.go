@@ -0,0 +1,414 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package module
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/db"
+	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+)
+
+// Status of an approval of a deployment intent group
+const (
+	// ApprovalValid counts toward the required approvals
+	ApprovalValid = "valid"
+	// ApprovalExpired is older than the expiry of the approval policy
+	ApprovalExpired = "expired"
+	// ApprovalInvalidated was given before a change of the intents of the deployment
+	// intent group, or by an approver no longer allowed by the approval policy
+	ApprovalInvalidated = "invalidated"
+	// ApprovalInstantiated was used by an instantiation of the deployment intent group
+	ApprovalInstantiated = "instantiated"
+)
+
+// Scope of an approval policy
+const (
+	ApprovalPolicyScopeProject               = "project"
+	ApprovalPolicyScopeDeploymentIntentGroup = "deploymentIntentGroup"
+)
+
+// ApprovalPolicy defines the approvals required to instantiate the deployment intent
+// groups of a project, or a deployment intent group
+type ApprovalPolicy struct {
+	Spec ApprovalPolicySpec `json:"spec"`
+}
+
+// ApprovalPolicySpec contains the parameters of an approval policy
+type ApprovalPolicySpec struct {
+	// Number of distinct approvers required
+	RequiredApprovals int `json:"requiredApprovals"`
+	// The approvers must belong to one of the groups, if any
+	ApproverGroups []string `json:"approverGroups,omitempty"`
+	// Validity of an approval, e.g. 72h. The approvals do not expire if empty.
+	Expiry string `json:"expiry,omitempty"`
+}
+
+// ApprovalRequest is an approval of a deployment intent group by an approver
+type ApprovalRequest struct {
+	Approver string   `json:"-"`
+	Groups   []string `json:"-"`
+	Comment  string   `json:"comment,omitempty"`
+}
+
+// Approval is a recorded approval of a deployment intent group
+type Approval struct {
+	Approver    string     `json:"approver"`
+	Groups      []string   `json:"groups,omitempty"`
+	Comment     string     `json:"comment,omitempty"`
+	Time        time.Time  `json:"time"`
+	Expires     *time.Time `json:"expires,omitempty"`
+	IntentsHash string     `json:"intentsHash,omitempty"`
+	Used        bool       `json:"used,omitempty"`
+	Status      string     `json:"status,omitempty"`
+}
+
+// approvalRecord is stored with the deployment intent group
+type approvalRecord struct {
+	Approvals []Approval `json:"approvals"`
+}
+
+// ApprovalStatus reports the approvals of a deployment intent group
+type ApprovalStatus struct {
+	Policy            *ApprovalPolicySpec `json:"policy,omitempty"`
+	PolicyScope       string              `json:"policyScope,omitempty"`
+	RequiredApprovals int                 `json:"requiredApprovals"`
+	ValidApprovals    int                 `json:"validApprovals"`
+	Approved          bool                `json:"approved"`
+	Approvals         []Approval          `json:"approvals"`
+}
+
+// ApprovalManager is an interface exposing the approval functionality. The approval
+// policy of the project is managed when the deployment intent group is empty.
+type ApprovalManager interface {
+	CreateApprovalPolicy(ctx context.Context, p, ca, v, di string, ap ApprovalPolicy, exists bool) (ApprovalPolicy, error)
+	GetApprovalPolicy(ctx context.Context, p, ca, v, di string) (ApprovalPolicy, error)
+	DeleteApprovalPolicy(ctx context.Context, p, ca, v, di string) error
+	GetApprovals(ctx context.Context, p, ca, v, di string) (ApprovalStatus, error)
+}
+
+// ApprovalClient implements the ApprovalManager
+type ApprovalClient struct {
+	storeName    string
+	tagPolicy    string
+	tagApprovals string
+}
+
+// NewApprovalClient returns an instance of the ApprovalClient
+func NewApprovalClient() *ApprovalClient {
+	return &ApprovalClient{
+		storeName:    "resources",
+		tagPolicy:    "approvalPolicy",
+		tagApprovals: "approvals",
+	}
+}
+
+// approvalPolicyKey returns the key of the document holding the approval policy,
+// and the error reported when the document does not exist
+func approvalPolicyKey(p, ca, v, di string) (db.Key, string) {
+	if di == "" {
+		return ProjectKey{ProjectName: p}, "Project not found"
+	}
+	return DeploymentIntentGroupKey{Name: di, Project: p, CompositeApp: ca, Version: v}, "DeploymentIntentGroup not found"
+}
+
+// CreateApprovalPolicy sets the approval policy of the project or deployment intent group
+func (c *ApprovalClient) CreateApprovalPolicy(ctx context.Context, p, ca, v, di string, ap ApprovalPolicy, exists bool) (ApprovalPolicy, error) {
+	if ap.Spec.RequiredApprovals < 1 {
+		return ApprovalPolicy{}, pkgerrors.New("Invalid ApprovalPolicy: at least one approval is required")
+	}
+	if ap.Spec.Expiry != "" {
+		if d, err := time.ParseDuration(ap.Spec.Expiry); err != nil || d <= 0 {
+			return ApprovalPolicy{}, pkgerrors.Errorf("Invalid ApprovalPolicy expiry: %s", ap.Spec.Expiry)
+		}
+	}
+
+	_, err := c.GetApprovalPolicy(ctx, p, ca, v, di)
+	if err == nil && !exists {
+		return ApprovalPolicy{}, pkgerrors.New("ApprovalPolicy already exists")
+	}
+	if err != nil && err.Error() != "ApprovalPolicy not found" {
+		return ApprovalPolicy{}, err
+	}
+
+	key, _ := approvalPolicyKey(p, ca, v, di)
+	if err := db.DBconn.Insert(ctx, c.storeName, key, nil, c.tagPolicy, ap); err != nil {
+		return ApprovalPolicy{}, pkgerrors.Wrap(err, "Create DB entry error")
+	}
+	return ap, nil
+}
+
+// GetApprovalPolicy returns the approval policy of the project or deployment intent group
+func (c *ApprovalClient) GetApprovalPolicy(ctx context.Context, p, ca, v, di string) (ApprovalPolicy, error) {
+	key, notFound := approvalPolicyKey(p, ca, v, di)
+	value, err := db.DBconn.Find(ctx, c.storeName, key, c.tagPolicy)
+	if err != nil {
+		return ApprovalPolicy{}, err
+	}
+	if len(value) == 0 {
+		return ApprovalPolicy{}, pkgerrors.New(notFound)
+	}
+	if value[0] == nil {
+		return ApprovalPolicy{}, pkgerrors.New("ApprovalPolicy not found")
+	}
+
+	ap := ApprovalPolicy{}
+	if err := db.DBconn.Unmarshal(value[0], &ap); err != nil {
+		return ApprovalPolicy{}, err
+	}
+	return ap, nil
+}
+
+// DeleteApprovalPolicy removes the approval policy of the project or deployment intent group
+func (c *ApprovalClient) DeleteApprovalPolicy(ctx context.Context, p, ca, v, di string) error {
+	if _, err := c.GetApprovalPolicy(ctx, p, ca, v, di); err != nil {
+		return err
+	}
+	key, _ := approvalPolicyKey(p, ca, v, di)
+	return db.DBconn.RemoveTag(ctx, c.storeName, key, c.tagPolicy)
+}
+
+// effectivePolicy returns the approval policy of the deployment intent group, or else
+// the one of its project, or nil if the deployment intent group has no approval policy
+func (c *ApprovalClient) effectivePolicy(ctx context.Context, p, ca, v, di string) (*ApprovalPolicySpec, string, error) {
+	for _, scope := range []string{ApprovalPolicyScopeDeploymentIntentGroup, ApprovalPolicyScopeProject} {
+		d := di
+		if scope == ApprovalPolicyScopeProject {
+			d = ""
+		}
+		ap, err := c.GetApprovalPolicy(ctx, p, ca, v, d)
+		if err == nil {
+			return &ap.Spec, scope, nil
+		}
+		if err.Error() != "ApprovalPolicy not found" {
+			return nil, "", err
+		}
+	}
+	return nil, "", nil
+}
+
+func (c *ApprovalClient) getRecord(ctx context.Context, p, ca, v, di string) (approvalRecord, error) {
+	key := DeploymentIntentGroupKey{Name: di, Project: p, CompositeApp: ca, Version: v}
+	value, err := db.DBconn.Find(ctx, c.storeName, key, c.tagApprovals)
+	if err != nil {
+		return approvalRecord{}, err
+	}
+	if len(value) == 0 {
+		return approvalRecord{}, pkgerrors.New("DeploymentIntentGroup not found")
+	}
+	r := approvalRecord{}
+	if value[0] == nil {
+		return r, nil
+	}
+	if err := db.DBconn.Unmarshal(value[0], &r); err != nil {
+		return approvalRecord{}, err
+	}
+	return r, nil
+}
+
+func (c *ApprovalClient) putRecord(ctx context.Context, p, ca, v, di string, r approvalRecord) error {
+	key := DeploymentIntentGroupKey{Name: di, Project: p, CompositeApp: ca, Version: v}
+	if err := db.DBconn.Insert(ctx, c.storeName, key, nil, c.tagApprovals, r); err != nil {
+		return pkgerrors.Wrap(err, "Error storing the approvals of the DeploymentIntentGroup: "+di)
+	}
+	return nil
+}
+
+// GetApprovals returns the approvals of the deployment intent group, and whether
+// they satisfy its approval policy
+func (c *ApprovalClient) GetApprovals(ctx context.Context, p, ca, v, di string) (ApprovalStatus, error) {
+	r, err := c.getRecord(ctx, p, ca, v, di)
+	if err != nil {
+		return ApprovalStatus{}, err
+	}
+	policy, scope, err := c.effectivePolicy(ctx, p, ca, v, di)
+	if err != nil {
+		return ApprovalStatus{}, err
+	}
+	hash, err := intentsHash(ctx, p, ca, v, di)
+	if err != nil {
+		return ApprovalStatus{}, err
+	}
+
+	s := ApprovalStatus{Policy: policy, PolicyScope: scope, Approvals: r.Approvals}
+	now := time.Now()
+	for i := range s.Approvals {
+		s.Approvals[i].Status = approvalStatus(s.Approvals[i], policy, hash, now)
+		if s.Approvals[i].Status == ApprovalValid {
+			s.ValidApprovals++
+		}
+	}
+	if s.Approvals == nil {
+		s.Approvals = []Approval{}
+	}
+	if policy != nil {
+		s.RequiredApprovals = policy.RequiredApprovals
+	}
+	s.Approved = s.ValidApprovals >= s.RequiredApprovals
+	return s, nil
+}
+
+// approvalStatus returns the status of an approval for the current intents and approval policy
+func approvalStatus(a Approval, policy *ApprovalPolicySpec, hash string, now time.Time) string {
+	switch {
+	case a.Used:
+		return ApprovalInstantiated
+	case a.Expires != nil && now.After(*a.Expires):
+		return ApprovalExpired
+	case hash != "" && a.IntentsHash != "" && a.IntentsHash != hash:
+		return ApprovalInvalidated
+	case policy != nil && (a.Approver == "" || !allowedApprover(a.Groups, policy.ApproverGroups)):
+		return ApprovalInvalidated
+	}
+	return ApprovalValid
+}
+
+// allowedApprover returns true if one of the groups is allowed, or if all the groups are allowed
+func allowedApprover(groups, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, g := range groups {
+		for _, a := range allowed {
+			if g == a {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// recordApproval records an approval of the deployment intent group, replacing the
+// unused approval of the same approver, and returns true if the approval policy of
+// the deployment intent group is satisfied
+func (c *ApprovalClient) recordApproval(ctx context.Context, p, ca, v, di string, req ApprovalRequest) (bool, error) {
+	policy, _, err := c.effectivePolicy(ctx, p, ca, v, di)
+	if err != nil {
+		return false, err
+	}
+	if policy != nil {
+		if req.Approver == "" {
+			return false, pkgerrors.New("Approver identity is required by the approval policy")
+		}
+		if !allowedApprover(req.Groups, policy.ApproverGroups) {
+			return false, pkgerrors.Errorf("%s is not an allowed approver of DeploymentIntentGroup %s", req.Approver, di)
+		}
+	}
+
+	r, err := c.getRecord(ctx, p, ca, v, di)
+	if err != nil {
+		return false, err
+	}
+	hash, err := intentsHash(ctx, p, ca, v, di)
+	if err != nil {
+		return false, err
+	}
+	a := Approval{
+		Approver:    req.Approver,
+		Groups:      req.Groups,
+		Comment:     req.Comment,
+		Time:        time.Now(),
+		IntentsHash: hash,
+	}
+	if policy != nil && policy.Expiry != "" {
+		// the expiry is validated by CreateApprovalPolicy, but may have been restored from a backup
+		d, err := time.ParseDuration(policy.Expiry)
+		if err != nil {
+			return false, pkgerrors.Wrapf(err, "Invalid ApprovalPolicy expiry: %s", policy.Expiry)
+		}
+		expires := a.Time.Add(d)
+		a.Expires = &expires
+	}
+	approvals := []Approval{}
+	for _, old := range r.Approvals {
+		if old.Used || old.Approver == "" || old.Approver != a.Approver {
+			approvals = append(approvals, old)
+		}
+	}
+	r.Approvals = append(approvals, a)
+	if err := c.putRecord(ctx, p, ca, v, di, r); err != nil {
+		return false, err
+	}
+	log.Info("DeploymentIntentGroup approved", log.Fields{"project": p, "compositeApp": ca, "compositeAppVersion": v,
+		"deploymentIntentGroup": di, "approver": a.Approver, "comment": a.Comment})
+
+	if policy == nil {
+		return true, nil
+	}
+	valid := 0
+	for _, old := range r.Approvals {
+		if approvalStatus(old, policy, hash, a.Time) == ApprovalValid {
+			valid++
+		}
+	}
+	return valid >= policy.RequiredApprovals, nil
+}
+
+// checkApprovals returns an error if the approval policy of the deployment intent
+// group is not satisfied by its valid approvals
+func (c *ApprovalClient) checkApprovals(ctx context.Context, p, ca, v, di string) error {
+	s, err := c.GetApprovals(ctx, p, ca, v, di)
+	if err != nil {
+		return err
+	}
+	if !s.Approved {
+		return pkgerrors.Errorf("DeploymentIntentGroup approvals are insufficient: %d of %d valid approvals", s.ValidApprovals, s.RequiredApprovals)
+	}
+	return nil
+}
+
+// useApprovals marks the approvals as used by an instantiation, so that the next
+// instantiation of the deployment intent group is approved again
+func (c *ApprovalClient) useApprovals(ctx context.Context, p, ca, v, di string) error {
+	r, err := c.getRecord(ctx, p, ca, v, di)
+	if err != nil || len(r.Approvals) == 0 {
+		return err
+	}
+	for i := range r.Approvals {
+		r.Approvals[i].Used = true
+	}
+	return c.putRecord(ctx, p, ca, v, di, r)
+}
+
+// intentsHash returns a hash of the documents of the deployment intent group and of
+// its intents, so that the approvals are invalidated when any of them changes. The
+// hash is empty if the database does not support listing its documents.
+func intentsHash(ctx context.Context, p, ca, v, di string) (string, error) {
+	ms, ok := db.DBconn.(db.MigrationStore)
+	if !ok {
+		return "", nil
+	}
+	key := DeploymentIntentGroupKey{Name: di, Project: p, CompositeApp: ca, Version: v}
+	docs, err := ms.FindDocumentsWithKey(ctx, "resources", key)
+	if err != nil {
+		return "", pkgerrors.Wrap(err, "Error reading the intents of the DeploymentIntentGroup")
+	}
+
+	var entries []string
+	for _, d := range docs {
+		data, ok := d["data"]
+		if !ok {
+			continue
+		}
+		entry, err := json.Marshal([]interface{}{d["keyId"], data})
+		if err != nil {
+			return "", err
+		}
+		entries = append(entries, string(entry))
+	}
+	sort.Strings(entries)
+
+	h := sha256.New()
+	for _, e := range entries {
+		h.Write([]byte(e))
+		h.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,175 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package module
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/contextdb"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/db"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/state"
+)
+
+func TestApprovals(t *testing.T) {
+	ctx := context.Background()
+	savedDb, savedContextDb := db.DBconn, contextdb.Db
+	defer func() { db.DBconn, contextdb.Db = savedDb, savedContextDb }()
+
+	var err error
+	db.DBconn, err = db.NewEmbeddedStore(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	contextdb.Db, err = contextdb.NewEmbeddedContextDb("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The project and deployment intent group documents, without the referential checks of the data tag
+	err = db.DBconn.Insert(ctx, "resources", ProjectKey{ProjectName: "p1"}, nil, "metadata", ProjectMetaData{Name: "p1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	digKey := DeploymentIntentGroupKey{Name: "dig1", Project: "p1", CompositeApp: "ca1", Version: "v1"}
+	err = db.DBconn.Insert(ctx, "resources", digKey, nil, "stateInfo", state.StateInfo{Actions: []state.ActionEntry{{State: state.StateEnum.Created}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	currentState := func() string {
+		s, err := NewDeploymentIntentGroupClient().GetDeploymentIntentGroupState(ctx, "dig1", "p1", "ca1", "v1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, err := state.GetCurrentStateFromStateInfo(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	c := NewApprovalClient()
+	if _, err := c.CreateApprovalPolicy(ctx, "p2", "", "", "", ApprovalPolicy{Spec: ApprovalPolicySpec{RequiredApprovals: 1}}, false); err == nil || err.Error() != "Project not found" {
+		t.Fatalf("Expected Project not found, got %v", err)
+	}
+	if _, err := c.CreateApprovalPolicy(ctx, "p1", "", "", "", ApprovalPolicy{Spec: ApprovalPolicySpec{RequiredApprovals: 1, Expiry: "soon"}}, false); err == nil || !strings.Contains(err.Error(), "Invalid ApprovalPolicy") {
+		t.Fatalf("Expected an invalid expiry error, got %v", err)
+	}
+	projectPolicy := ApprovalPolicy{Spec: ApprovalPolicySpec{RequiredApprovals: 1}}
+	if _, err := c.CreateApprovalPolicy(ctx, "p1", "", "", "", projectPolicy, false); err != nil {
+		t.Fatal(err)
+	}
+	digPolicy := ApprovalPolicy{Spec: ApprovalPolicySpec{RequiredApprovals: 2, ApproverGroups: []string{"release-managers"}, Expiry: "24h"}}
+	if _, err := c.CreateApprovalPolicy(ctx, "p1", "ca1", "v1", "dig1", digPolicy, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.CreateApprovalPolicy(ctx, "p1", "ca1", "v1", "dig1", digPolicy, false); err == nil || err.Error() != "ApprovalPolicy already exists" {
+		t.Fatalf("Expected ApprovalPolicy already exists, got %v", err)
+	}
+
+	// The policy of the deployment intent group overrides the one of the project
+	ic := NewInstantiationClient()
+	if err := ic.Approve(ctx, "p1", "ca1", "v1", "dig1", ApprovalRequest{}); err == nil || !strings.Contains(err.Error(), "Approver identity is required") {
+		t.Fatalf("Expected an approver identity error, got %v", err)
+	}
+	if err := ic.Approve(ctx, "p1", "ca1", "v1", "dig1", ApprovalRequest{Approver: "alice", Groups: []string{"developers"}}); err == nil || !strings.Contains(err.Error(), "is not an allowed approver") {
+		t.Fatalf("Expected a not allowed approver error, got %v", err)
+	}
+	approver := func(name, comment string) ApprovalRequest {
+		return ApprovalRequest{Approver: name, Groups: []string{"developers", "release-managers"}, Comment: comment}
+	}
+	if err := ic.Approve(ctx, "p1", "ca1", "v1", "dig1", approver("alice", "first")); err != nil {
+		t.Fatal(err)
+	}
+	if err := ic.Approve(ctx, "p1", "ca1", "v1", "dig1", approver("alice", "second")); err != nil {
+		t.Fatal(err)
+	}
+	if s := currentState(); s != state.StateEnum.Created {
+		t.Fatalf("The DeploymentIntentGroup was approved by a single approver: %s", s)
+	}
+	if err := c.checkApprovals(ctx, "p1", "ca1", "v1", "dig1"); err == nil || !strings.Contains(err.Error(), "DeploymentIntentGroup approvals are insufficient") {
+		t.Fatalf("Expected insufficient approvals, got %v", err)
+	}
+	if err := ic.Approve(ctx, "p1", "ca1", "v1", "dig1", approver("bob", "")); err != nil {
+		t.Fatal(err)
+	}
+	if s := currentState(); s != state.StateEnum.Approved {
+		t.Fatalf("The DeploymentIntentGroup is not approved: %s", s)
+	}
+
+	s, err := c.GetApprovals(ctx, "p1", "ca1", "v1", "dig1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.Approved || s.ValidApprovals != 2 || s.RequiredApprovals != 2 || s.PolicyScope != ApprovalPolicyScopeDeploymentIntentGroup || len(s.Approvals) != 2 {
+		t.Fatalf("Unexpected approvals %+v", s)
+	}
+	if a := s.Approvals[0]; a.Approver != "alice" || a.Comment != "second" || a.Expires == nil || a.Status != ApprovalValid {
+		t.Errorf("Unexpected approval %+v", a)
+	}
+
+	// A change of the intents invalidates the approvals
+	ms := db.DBconn.(db.MigrationStore)
+	docs, err := ms.FindDocuments(ctx, "resources", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range docs {
+		if d["deploymentIntentGroup"] != "dig1" {
+			continue
+		}
+		changed := db.Document{}
+		for k, v := range d {
+			changed[k] = v
+		}
+		changed["data"] = map[string]interface{}{"metadata": map[string]interface{}{"name": "dig1", "description": "changed"}}
+		if err := ms.ReplaceDocument(ctx, "resources", d, changed); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s, err = c.GetApprovals(ctx, "p1", "ca1", "v1", "dig1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Approved || s.ValidApprovals != 0 || s.Approvals[1].Status != ApprovalInvalidated {
+		t.Fatalf("The approvals were not invalidated %+v", s)
+	}
+
+	// The approvals are used by an instantiation
+	if err := ic.Approve(ctx, "p1", "ca1", "v1", "dig1", approver("alice", "")); err != nil {
+		t.Fatal(err)
+	}
+	if err := ic.Approve(ctx, "p1", "ca1", "v1", "dig1", approver("bob", "")); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.checkApprovals(ctx, "p1", "ca1", "v1", "dig1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.useApprovals(ctx, "p1", "ca1", "v1", "dig1"); err != nil {
+		t.Fatal(err)
+	}
+	s, err = c.GetApprovals(ctx, "p1", "ca1", "v1", "dig1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Approved || len(s.Approvals) != 2 || s.Approvals[0].Status != ApprovalInstantiated {
+		t.Fatalf("The approvals were not used %+v", s)
+	}
+
+	// The project policy applies without the policy of the deployment intent group
+	if err := c.DeleteApprovalPolicy(ctx, "p1", "ca1", "v1", "dig1"); err != nil {
+		t.Fatal(err)
+	}
+	s, err = c.GetApprovals(ctx, "p1", "ca1", "v1", "dig1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.PolicyScope != ApprovalPolicyScopeProject || s.RequiredApprovals != 1 {
+		t.Errorf("Unexpected approvals %+v", s)
+	}
+	if _, err := c.GetApprovalPolicy(ctx, "p1", "ca1", "v1", "dig1"); err == nil || err.Error() != "ApprovalPolicy not found" {
+		t.Errorf("Expected ApprovalPolicy not found, got %v", err)
+	}
+}
@@ -0,0 +1,450 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package module
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/config"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/contextdb"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/db"
+	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+)
+
+// BackupFormatVersion is the version of the backup archive format
+const BackupFormatVersion = 1
+
+const (
+	backupManifestFile   = "manifest.json"
+	backupContextDbFile  = "contextdb/keys.json"
+	backupLockName       = "backup-lock"
+	backupAttempts       = 5
+	backupAttemptBackoff = 2 * time.Second
+)
+
+// backupCollections are the collections of the EMCO services
+var backupCollections = []string{"resources"}
+
+// backupContextPrefixes are the context database keys of the AppContexts
+// and of the rsync active context records
+var backupContextPrefixes = []string{"/context/", "/activecontext/"}
+
+// BackupManifest describes the content of a backup archive
+type BackupManifest struct {
+	FormatVersion int                       `json:"formatVersion"`
+	CreatedAt     time.Time                 `json:"createdAt"`
+	DatabaseType  string                    `json:"databaseType"`
+	DataVersions  map[string]map[string]int `json:"dataVersions"` // data version of each component, by collection
+	Files         []BackupFile              `json:"files"`
+}
+
+// BackupFile is a file of the backup archive
+type BackupFile struct {
+	Name    string `json:"name"`
+	Entries int    `json:"entries"`
+	Sha256  string `json:"sha256"`
+}
+
+// KubeconfigRemap replaces the kubeconfig of a cluster when restoring
+type KubeconfigRemap struct {
+	ClusterProvider string `json:"clusterProvider"`
+	Cluster         string `json:"cluster"`
+	Kubeconfig      string `json:"kubeconfig"` // base64 encoded kubeconfig
+}
+
+// RestoreOptions are the options of a restore
+type RestoreOptions struct {
+	Kubeconfigs []KubeconfigRemap `json:"kubeconfigs,omitempty"`
+}
+
+// cloudConfigKey is the key of the kubeconfigs stored by rsync
+type cloudConfigKey struct {
+	Provider  string `json:"cloudConfigClusterProvider"`
+	Cluster   string `json:"cloudConfigCluster"`
+	Level     string `json:"level"`
+	Namespace string `json:"namespace"`
+}
+
+// kubeConfig is the kubeconfig stored by rsync
+type kubeConfig struct {
+	Config string `json:"config" encrypted:""`
+}
+
+// BackupManager is an interface which exposes the backup and restore functionality
+type BackupManager interface {
+	Backup(ctx context.Context, w io.Writer) (BackupManifest, error)
+	Restore(ctx context.Context, r io.Reader, options RestoreOptions) (BackupManifest, error)
+}
+
+// BackupClient implements the BackupManager
+type BackupClient struct {
+}
+
+// NewBackupClient returns an instance of the BackupClient
+func NewBackupClient() *BackupClient {
+	return &BackupClient{}
+}
+
+func collectionFile(coll string) string {
+	return "db/" + coll + ".json"
+}
+
+func backupStores() (db.BackupStore, db.MigrationStore, error) {
+	bs, ok := db.DBconn.(db.BackupStore)
+	if !ok {
+		return nil, nil, pkgerrors.New("The database does not support backup and restore")
+	}
+	ms, ok := db.DBconn.(db.MigrationStore)
+	if !ok {
+		return nil, nil, pkgerrors.New("The database does not support backup and restore")
+	}
+	return bs, ms, nil
+}
+
+// isKeyNotFound returns true if the context database error is about a missing key
+func isKeyNotFound(err error) bool {
+	return strings.Contains(err.Error(), "Key doesn't exist")
+}
+
+// exportContexts returns the context database keys of the AppContexts and active context records
+func exportContexts(ctx context.Context) (map[string]json.RawMessage, error) {
+	kvs := make(map[string]json.RawMessage)
+	for _, prefix := range backupContextPrefixes {
+		keys, err := contextdb.Db.GetAllKeys(ctx, prefix)
+		if err != nil {
+			if isKeyNotFound(err) {
+				// No key with the prefix
+				continue
+			}
+			return nil, pkgerrors.Wrapf(err, "Error listing the context database keys of %s", prefix)
+		}
+		for _, k := range keys {
+			var v json.RawMessage
+			if err := contextdb.Db.Get(ctx, k, &v); err != nil {
+				if isKeyNotFound(err) {
+					// The key was deleted after it was listed
+					continue
+				}
+				return nil, pkgerrors.Wrapf(err, "Error reading the context database key %s", k)
+			}
+			kvs[k] = v
+		}
+	}
+	return kvs, nil
+}
+
+// exportCollections returns the documents of the collections, encoded as json
+func exportCollections(ctx context.Context, bs db.BackupStore) (map[string][]byte, map[string]int, error) {
+	files := make(map[string][]byte)
+	entries := make(map[string]int)
+	for _, coll := range backupCollections {
+		docs, err := bs.Export(ctx, coll)
+		if err != nil {
+			return nil, nil, err
+		}
+		b, err := json.Marshal(docs)
+		if err != nil {
+			return nil, nil, err
+		}
+		files[collectionFile(coll)] = b
+		entries[collectionFile(coll)] = len(docs)
+	}
+	return files, entries, nil
+}
+
+// Backup writes a gzipped tar archive of the EMCO resources and of the AppContexts
+func (c *BackupClient) Backup(ctx context.Context, w io.Writer) (BackupManifest, error) {
+	bs, ms, err := backupStores()
+	if err != nil {
+		return BackupManifest{}, err
+	}
+	locked, err := ms.Lock(ctx, backupCollections[0], backupLockName)
+	if err != nil {
+		return BackupManifest{}, pkgerrors.Wrap(err, "Error locking the database for backup")
+	}
+	if !locked {
+		return BackupManifest{}, pkgerrors.New("A backup or restore is in progress")
+	}
+	defer ms.Unlock(ctx, backupCollections[0], backupLockName)
+
+	// The two databases cannot be read at the same moment. The AppContexts are read
+	// between two reads of the resources, and the backup is consistent if the
+	// resources did not change in between.
+	var files map[string][]byte
+	var entries map[string]int
+	var kvs map[string]json.RawMessage
+	consistent := false
+	for i := 0; i < backupAttempts && !consistent; i++ {
+		if i > 0 {
+			log.Info("Resources changed during the backup, retrying", log.Fields{"attempt": i + 1})
+			time.Sleep(backupAttemptBackoff)
+		}
+		files, entries, err = exportCollections(ctx, bs)
+		if err != nil {
+			return BackupManifest{}, pkgerrors.Wrap(err, "Error exporting the resources")
+		}
+		kvs, err = exportContexts(ctx)
+		if err != nil {
+			return BackupManifest{}, pkgerrors.Wrap(err, "Error exporting the AppContexts")
+		}
+		after, _, err := exportCollections(ctx, bs)
+		if err != nil {
+			return BackupManifest{}, pkgerrors.Wrap(err, "Error exporting the resources")
+		}
+		consistent = true
+		for name, b := range files {
+			if !bytes.Equal(b, after[name]) {
+				consistent = false
+			}
+		}
+	}
+	if !consistent {
+		return BackupManifest{}, pkgerrors.New("The resources kept changing during the backup")
+	}
+
+	b, err := json.Marshal(kvs)
+	if err != nil {
+		return BackupManifest{}, pkgerrors.Wrap(err, "Error exporting the AppContexts")
+	}
+	files[backupContextDbFile] = b
+	entries[backupContextDbFile] = len(kvs)
+
+	manifest := BackupManifest{
+		FormatVersion: BackupFormatVersion,
+		CreatedAt:     time.Now().UTC(),
+		DatabaseType:  config.GetConfiguration().DatabaseType,
+		DataVersions:  make(map[string]map[string]int),
+	}
+	for _, coll := range backupCollections {
+		versions, err := db.GetMigrationVersions(ctx, db.DBconn, coll)
+		if err != nil {
+			return BackupManifest{}, pkgerrors.Wrap(err, "Error reading the data versions")
+		}
+		manifest.DataVersions[coll] = versions
+	}
+	names := make([]string, 0, len(files))
+	for _, coll := range backupCollections {
+		names = append(names, collectionFile(coll))
+	}
+	names = append(names, backupContextDbFile)
+	for _, name := range names {
+		sum := sha256.Sum256(files[name])
+		manifest.Files = append(manifest.Files, BackupFile{Name: name, Entries: entries[name], Sha256: hex.EncodeToString(sum[:])})
+	}
+	m, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return BackupManifest{}, pkgerrors.Wrap(err, "Error creating the backup manifest")
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	write := func(name string, b []byte) error {
+		hdr := &tar.Header{Name: name, Mode: 0600, Size: int64(len(b)), ModTime: manifest.CreatedAt}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		_, err := tw.Write(b)
+		return err
+	}
+	if err := write(backupManifestFile, m); err != nil {
+		return BackupManifest{}, pkgerrors.Wrap(err, "Error writing the backup archive")
+	}
+	for _, name := range names {
+		if err := write(name, files[name]); err != nil {
+			return BackupManifest{}, pkgerrors.Wrap(err, "Error writing the backup archive")
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return BackupManifest{}, pkgerrors.Wrap(err, "Error writing the backup archive")
+	}
+	if err := gz.Close(); err != nil {
+		return BackupManifest{}, pkgerrors.Wrap(err, "Error writing the backup archive")
+	}
+
+	log.Info("Backup created", log.Fields{"files": manifest.Files})
+	return manifest, nil
+}
+
+// readBackup reads the files of a backup archive and verifies them against the manifest
+func readBackup(r io.Reader) (BackupManifest, map[string][]byte, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return BackupManifest{}, nil, pkgerrors.Wrap(err, "Invalid backup archive")
+	}
+	tr := tar.NewReader(gz)
+	files := make(map[string][]byte)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return BackupManifest{}, nil, pkgerrors.Wrap(err, "Invalid backup archive")
+		}
+		b, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return BackupManifest{}, nil, pkgerrors.Wrap(err, "Invalid backup archive")
+		}
+		files[hdr.Name] = b
+	}
+
+	var manifest BackupManifest
+	m, ok := files[backupManifestFile]
+	if !ok {
+		return BackupManifest{}, nil, pkgerrors.New("Invalid backup archive: The manifest is missing")
+	}
+	if err := json.Unmarshal(m, &manifest); err != nil {
+		return BackupManifest{}, nil, pkgerrors.Wrap(err, "Invalid backup archive: Error decoding the manifest")
+	}
+	for _, f := range manifest.Files {
+		b, ok := files[f.Name]
+		if !ok {
+			return BackupManifest{}, nil, pkgerrors.Errorf("Invalid backup archive: The file %s is missing", f.Name)
+		}
+		sum := sha256.Sum256(b)
+		if hex.EncodeToString(sum[:]) != f.Sha256 {
+			return BackupManifest{}, nil, pkgerrors.Errorf("Invalid backup archive: The checksum of the file %s does not match", f.Name)
+		}
+	}
+	return manifest, files, nil
+}
+
+// checkBackupVersions verifies that the backup can be restored by this version of EMCO
+func checkBackupVersions(manifest BackupManifest) error {
+	if manifest.FormatVersion < 1 || manifest.FormatVersion > BackupFormatVersion {
+		return pkgerrors.Errorf("Unsupported backup format version %d", manifest.FormatVersion)
+	}
+	if manifest.DatabaseType != config.GetConfiguration().DatabaseType {
+		return pkgerrors.Errorf("The backup of a %s database cannot be restored to a %s database", manifest.DatabaseType, config.GetConfiguration().DatabaseType)
+	}
+	// Data from an older version is migrated when the services start, but
+	// data from a newer version cannot be used
+	latest := db.LatestMigrationVersions()
+	for coll, versions := range manifest.DataVersions {
+		for component, v := range versions {
+			if l, ok := latest[component]; ok && v > l {
+				return pkgerrors.Errorf("The backup has version %d of the %s data in collection %s, which is newer than version %d", v, component, coll, l)
+			}
+		}
+	}
+	return nil
+}
+
+// Restore replaces the EMCO resources and the AppContexts with the content of a backup archive
+func (c *BackupClient) Restore(ctx context.Context, r io.Reader, options RestoreOptions) (BackupManifest, error) {
+	bs, ms, err := backupStores()
+	if err != nil {
+		return BackupManifest{}, err
+	}
+	manifest, files, err := readBackup(r)
+	if err != nil {
+		return BackupManifest{}, err
+	}
+	if err := checkBackupVersions(manifest); err != nil {
+		return BackupManifest{}, err
+	}
+
+	docs := make(map[string][]json.RawMessage)
+	for _, coll := range backupCollections {
+		b, ok := files[collectionFile(coll)]
+		if !ok {
+			continue
+		}
+		var d []json.RawMessage
+		if err := json.Unmarshal(b, &d); err != nil {
+			return BackupManifest{}, pkgerrors.Wrapf(err, "Invalid backup archive: Error decoding the collection %s", coll)
+		}
+		docs[coll] = d
+	}
+	kvs := make(map[string]json.RawMessage)
+	if b, ok := files[backupContextDbFile]; ok {
+		if err := json.Unmarshal(b, &kvs); err != nil {
+			return BackupManifest{}, pkgerrors.Wrap(err, "Invalid backup archive: Error decoding the AppContexts")
+		}
+	}
+	for _, k := range options.Kubeconfigs {
+		if _, err := base64.StdEncoding.DecodeString(k.Kubeconfig); err != nil {
+			return BackupManifest{}, pkgerrors.Errorf("Invalid kubeconfig of cluster %s+%s: %s", k.ClusterProvider, k.Cluster, err.Error())
+		}
+	}
+
+	locked, err := ms.Lock(ctx, backupCollections[0], backupLockName)
+	if err != nil {
+		return BackupManifest{}, pkgerrors.Wrap(err, "Error locking the database for restore")
+	}
+	if !locked {
+		return BackupManifest{}, pkgerrors.New("A backup or restore is in progress")
+	}
+	defer ms.Unlock(ctx, backupCollections[0], backupLockName)
+
+	for coll, d := range docs {
+		if err := bs.Import(ctx, coll, d); err != nil {
+			return BackupManifest{}, pkgerrors.Wrapf(err, "Error restoring the collection %s", coll)
+		}
+	}
+	for _, prefix := range backupContextPrefixes {
+		if err := contextdb.Db.DeleteAll(ctx, prefix); err != nil {
+			return BackupManifest{}, pkgerrors.Wrap(err, "Error deleting the AppContexts")
+		}
+	}
+	for k, v := range kvs {
+		if err := contextdb.Db.Put(ctx, k, v); err != nil {
+			return BackupManifest{}, pkgerrors.Wrap(err, "Error restoring the AppContexts")
+		}
+	}
+
+	if err := remapKubeconfigs(ctx, ms, options.Kubeconfigs); err != nil {
+		return BackupManifest{}, err
+	}
+
+	log.Info("Backup restored", log.Fields{"createdAt": manifest.CreatedAt, "files": manifest.Files})
+	return manifest, nil
+}
+
+// remapKubeconfigs replaces the kubeconfigs of the clusters, e.g. when the
+// clusters are reached at a different address after a disaster recovery
+func remapKubeconfigs(ctx context.Context, ms db.MigrationStore, remaps []KubeconfigRemap) error {
+	if len(remaps) == 0 {
+		return nil
+	}
+	docs, err := ms.FindDocuments(ctx, "resources", db.KeyIdOf(cloudConfigKey{}))
+	if err != nil {
+		return pkgerrors.Wrap(err, "Error finding the kubeconfigs")
+	}
+	for _, remap := range remaps {
+		found := false
+		for _, d := range docs {
+			key := cloudConfigKey{}
+			key.Provider, _ = d["cloudConfigClusterProvider"].(string)
+			key.Cluster, _ = d["cloudConfigCluster"].(string)
+			key.Level, _ = d["level"].(string)
+			key.Namespace, _ = d["namespace"].(string)
+			// Only the cluster level kubeconfig is remapped
+			if key.Provider != remap.ClusterProvider || key.Cluster != remap.Cluster || key.Level != "0" {
+				continue
+			}
+			err := db.DBconn.Insert(ctx, "resources", key, nil, "config", kubeConfig{Config: remap.Kubeconfig})
+			if err != nil {
+				return pkgerrors.Wrapf(err, "Error replacing the kubeconfig of cluster %s+%s", remap.ClusterProvider, remap.Cluster)
+			}
+			found = true
+		}
+		if !found {
+			log.Warn("The backup has no kubeconfig for the cluster", log.Fields{"clusterProvider": remap.ClusterProvider, "cluster": remap.Cluster})
+		}
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package module
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	pkgerrors "github.com/pkg/errors"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/contextdb"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/db"
+)
+
+func getKubeconfig(t *testing.T, ctx context.Context, provider, cluster string) string {
+	values, err := db.DBconn.Find(ctx, "resources", cloudConfigKey{Provider: provider, Cluster: cluster, Level: "0"}, "config")
+	if err != nil {
+		t.Fatalf("Find returned an error: %s", err)
+	}
+	if len(values) == 0 {
+		return ""
+	}
+	kc := kubeConfig{}
+	if err := db.DBconn.Unmarshal(values[0], &kc); err != nil {
+		t.Fatalf("Unmarshal returned an error: %s", err)
+	}
+	return kc.Config
+}
+
+func TestBackupRestore(t *testing.T) {
+	ctx := context.Background()
+	savedDb, savedContextDb := db.DBconn, contextdb.Db
+	defer func() { db.DBconn, contextdb.Db = savedDb, savedContextDb }()
+
+	var err error
+	db.DBconn, err = db.NewEmbeddedStore(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	contextdb.Db, err = contextdb.NewEmbeddedContextDb("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kc1 := base64.StdEncoding.EncodeToString([]byte("kubeconfig1"))
+	err = db.DBconn.Insert(ctx, "resources", cloudConfigKey{"p1", "c1", "0", "default"}, nil, "config", kubeConfig{Config: kc1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	contextdb.Db.Put(ctx, "/context/1234/", "1234")
+	contextdb.Db.Put(ctx, "/context/1234/app/app1/", "app1")
+	contextdb.Db.Put(ctx, "/activecontext/1234/", "1234")
+	contextdb.Db.Put(ctx, "/other/", "other")
+
+	client := NewBackupClient()
+	var archive bytes.Buffer
+	manifest, err := client.Backup(ctx, &archive)
+	if err != nil {
+		t.Fatalf("Backup returned an error: %s", err)
+	}
+	if len(manifest.Files) != 2 || manifest.Files[0].Entries != 1 || manifest.Files[1].Entries != 3 {
+		t.Fatalf("Unexpected backup manifest files: %v", manifest.Files)
+	}
+
+	// Change the state after the backup
+	kc2 := base64.StdEncoding.EncodeToString([]byte("kubeconfig2"))
+	err = db.DBconn.Insert(ctx, "resources", cloudConfigKey{"p1", "c2", "0", "default"}, nil, "config", kubeConfig{Config: kc2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	contextdb.Db.DeleteAll(ctx, "/context/1234/")
+	contextdb.Db.Put(ctx, "/context/5678/", "5678")
+
+	kc3 := base64.StdEncoding.EncodeToString([]byte("kubeconfig3"))
+	options := RestoreOptions{Kubeconfigs: []KubeconfigRemap{{ClusterProvider: "p1", Cluster: "c1", Kubeconfig: kc3}}}
+	_, err = client.Restore(ctx, bytes.NewReader(archive.Bytes()), options)
+	if err != nil {
+		t.Fatalf("Restore returned an error: %s", err)
+	}
+
+	if kc := getKubeconfig(t, ctx, "p1", "c1"); kc != kc3 {
+		t.Errorf("The kubeconfig was not remapped: %s", kc)
+	}
+	if kc := getKubeconfig(t, ctx, "p1", "c2"); kc != "" {
+		t.Errorf("The cluster created after the backup was not removed")
+	}
+	var v string
+	if err := contextdb.Db.Get(ctx, "/context/1234/app/app1/", &v); err != nil || v != "app1" {
+		t.Errorf("The AppContext was not restored: %v %s", err, v)
+	}
+	if err := contextdb.Db.Get(ctx, "/context/5678/", &v); err == nil {
+		t.Errorf("The AppContext created after the backup was not removed")
+	}
+	if err := contextdb.Db.Get(ctx, "/other/", &v); err != nil {
+		t.Errorf("A key which is not backed up was removed")
+	}
+
+	// A corrupted archive is rejected
+	corrupted := archive.Bytes()
+	_, err = client.Restore(ctx, bytes.NewReader(corrupted[:len(corrupted)/2]), RestoreOptions{})
+	if err == nil || !strings.Contains(err.Error(), "Invalid backup archive") {
+		t.Errorf("Restore of a corrupted archive returned: %v", err)
+	}
+}
+
+func TestBackupContextDbError(t *testing.T) {
+	ctx := context.Background()
+	savedDb, savedContextDb := db.DBconn, contextdb.Db
+	defer func() { db.DBconn, contextdb.Db = savedDb, savedContextDb }()
+
+	var err error
+	db.DBconn, err = db.NewEmbeddedStore(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	contextdb.Db = &contextdb.MockConDb{Err: pkgerrors.New("Error getting etcd entry: context deadline exceeded")}
+
+	// The backup fails instead of leaving out the AppContexts
+	var archive bytes.Buffer
+	_, err = NewBackupClient().Backup(ctx, &archive)
+	if err == nil || !strings.Contains(err.Error(), "context deadline exceeded") {
+		t.Errorf("Backup with an unavailable context database returned: %v", err)
+	}
+}
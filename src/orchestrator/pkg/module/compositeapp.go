@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 
 	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/db"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/quota"
 
 	pkgerrors "github.com/pkg/errors"
 )
@@ -82,11 +83,23 @@ func (v *CompositeAppClient) CreateCompositeApp(ctx context.Context, c Composite
 		Project:          p,
 	}
 
+	// The quota is checked and the CompositeApp created under the lock of the project
+	unlock, err := quota.Lock(ctx, p)
+	if err != nil {
+		return CompositeApp{}, err
+	}
+	defer unlock()
+
 	//Check if this CompositeApp already exists
-	_, err := v.GetCompositeApp(ctx, c.Metadata.Name, c.Spec.Version, p)
+	_, err = v.GetCompositeApp(ctx, c.Metadata.Name, c.Spec.Version, p)
 	if err == nil && !exists {
 		return CompositeApp{}, pkgerrors.New("CompositeApp already exists")
 	}
+	if err != nil {
+		if err := quota.CheckProjectQuota(ctx, p, quota.CompositeApps); err != nil {
+			return CompositeApp{}, err
+		}
+	}
 
 	err = db.DBconn.Insert(ctx, v.storeName, key, nil, v.tagMeta, c)
 	if err != nil {
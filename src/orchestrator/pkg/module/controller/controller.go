@@ -21,6 +21,7 @@ import (
 	register "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/grpc"
 	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/config"
 	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/db"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/idempotency"
 	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
 	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/metrics"
 	rpc "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/rpc"
@@ -230,6 +231,7 @@ func NewControllerServer(name string, httpRouter *mux.Router, grpcServer *regist
 		httpRouter = mux.NewRouter()
 	}
 	httpRouter.Use(tracing.Middleware)
+	httpRouter.Use(idempotency.Middleware)
 	httpServer, err := newHttpServer(httpServerPort, httpRouter)
 	if err != nil {
 		log.Error("Unable to create HTTP server", log.Fields{"Error": err})
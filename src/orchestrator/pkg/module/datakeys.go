@@ -0,0 +1,73 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package module
+
+import (
+	"context"
+	"sync"
+
+	pkgerrors "github.com/pkg/errors"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/db"
+	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/utils"
+)
+
+// DataKeyManager is an interface which exposes the data key rotation functionality
+type DataKeyManager interface {
+	StartRotation() (db.KeyRotationReport, error)
+	GetRotation() (db.KeyRotationReport, error)
+}
+
+// DataKeyClient implements the DataKeyManager
+type DataKeyClient struct {
+	lock   sync.Mutex
+	report *db.KeyRotationReport // progress of the last rotation
+}
+
+// NewDataKeyClient returns an instance of the DataKeyClient
+func NewDataKeyClient() *DataKeyClient {
+	return &DataKeyClient{}
+}
+
+// StartRotation starts the re-encryption of the encrypted fields with the active data key
+func (c *DataKeyClient) StartRotation() (db.KeyRotationReport, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.report != nil && c.report.Status == db.KeyRotationRunning {
+		return *c.report, pkgerrors.New("A data key rotation is already running")
+	}
+	oe := utils.GetObjectEncryptor("emco")
+	rotator, ok := oe.(utils.IKeyRotator)
+	if oe == nil || !ok {
+		return db.KeyRotationReport{}, pkgerrors.New("Data encryption is not configured")
+	}
+
+	c.report = &db.KeyRotationReport{Status: db.KeyRotationRunning, ActiveKeyId: rotator.ActiveKeyId()}
+	go func() {
+		// The rotation outlives the request which started it
+		_, err := db.RotateDataKeys(context.Background(), db.DBconn, backupCollections, oe, c.setReport)
+		if err != nil {
+			log.Error("Data key rotation failed", log.Fields{"error": err})
+		}
+	}()
+	return *c.report, nil
+}
+
+// GetRotation returns the progress of the last data key rotation
+func (c *DataKeyClient) GetRotation() (db.KeyRotationReport, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.report == nil {
+		return db.KeyRotationReport{}, pkgerrors.New("No data key rotation found")
+	}
+	return *c.report, nil
+}
+
+func (c *DataKeyClient) setReport(report db.KeyRotationReport) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.report = &report
+}
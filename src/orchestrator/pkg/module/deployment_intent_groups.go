@@ -11,6 +11,7 @@ import (
 
 	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/appcontext"
 	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/db"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/quota"
 	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/state"
 
 	pkgerrors "github.com/pkg/errors"
@@ -32,10 +33,14 @@ type DepMetaData struct {
 
 // DepSpecData has profile, version, OverrideValuesObj
 type DepSpecData struct {
-	Profile           string           `json:"compositeProfile"`
-	Version           string           `json:"version"`
-	OverrideValuesObj []OverrideValues `json:"overrideValues"`
-	LogicalCloud      string           `json:"logicalCloud"`
+	Profile           string                  `json:"compositeProfile"`
+	Version           string                  `json:"version"`
+	OverrideValuesObj []OverrideValues        `json:"overrideValues"`
+	LogicalCloud      string                  `json:"logicalCloud"`
+	DriftPolicy       string                  `json:"driftPolicy,omitempty"`
+	RetryPolicy       *appcontext.RetryPolicy `json:"retryPolicy,omitempty"`
+	HelmReleases      []string                `json:"helmReleases,omitempty"`
+	ForceApply        bool                    `json:"forceApply,omitempty"`
 }
 
 // OverrideValues has appName and ValuesObj
@@ -96,6 +101,13 @@ func NewDeploymentIntentGroupClient() *DeploymentIntentGroupClient {
 func (c *DeploymentIntentGroupClient) CreateDeploymentIntentGroup(ctx context.Context, d DeploymentIntentGroup, p string, ca string, v string, failIfExists bool) (DeploymentIntentGroup, bool, error) {
 	digExists := false
 
+	// The quota is checked and the DeploymentIntentGroup created under the lock of the project
+	unlock, err := quota.Lock(ctx, p)
+	if err != nil {
+		return DeploymentIntentGroup{}, digExists, err
+	}
+	defer unlock()
+
 	// check if the DeploymentIntentGroup already exists.
 	res, err := c.GetDeploymentIntentGroup(ctx, d.MetaData.Name, p, ca, v)
 	if err == nil && !reflect.DeepEqual(res, DeploymentIntentGroup{}) {
@@ -142,6 +154,9 @@ func (c *DeploymentIntentGroupClient) CreateDeploymentIntentGroup(ctx context.Co
 	}
 
 	// The DeploymentIntentGroup does not exists. Create the DeploymentIntentGroup and add the StateInfo details
+	if err := quota.CheckProjectQuota(ctx, p, quota.DeploymentIntentGroups); err != nil {
+		return DeploymentIntentGroup{}, digExists, err
+	}
 	err = db.DBconn.Insert(ctx, c.storeName, gkey, nil, c.tagMetaData, d)
 	if err != nil {
 		return DeploymentIntentGroup{}, digExists, err
@@ -0,0 +1,34 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package module
+
+import (
+	"context"
+
+	pkgerrors "github.com/pkg/errors"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/contextdb"
+	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+)
+
+// lockDeploymentIntentGroup serializes the lifecycle operations on the DeploymentIntentGroup
+// across the orchestrator replicas. The lock is bound to the etcd lease of the replica, so it
+// is released if the replica dies during the operation. The returned func releases the lock.
+func lockDeploymentIntentGroup(ctx context.Context, p, ca, v, di string) (func(), error) {
+	name := "dig/" + p + "/" + ca + "/" + v + "/" + di
+	unlock, err := contextdb.TryLock(ctx, name)
+	if err == contextdb.ErrLocked {
+		log.Info("DeploymentIntentGroup is locked by another lifecycle operation", log.Fields{"project": p,
+			"compositeapp": ca, "version": v, "deploymentintentgroup": di})
+		return nil, pkgerrors.Errorf("DeploymentIntentGroup %s is locked by another lifecycle operation", di)
+	}
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "Error locking DeploymentIntentGroup: "+di)
+	}
+	return func() {
+		// the request context may be cancelled once the operation is done
+		if err := unlock(context.Background()); err != nil {
+			log.Error("Error unlocking DeploymentIntentGroup", log.Fields{"deploymentintentgroup": di, "error": err})
+		}
+	}, nil
+}
@@ -17,6 +17,8 @@ import (
 	gpic "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/gpic"
 	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/db"
 	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/metrics"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/quota"
 	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/state"
 	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/status"
 	"gitlab.com/project-emco/core/emco-base/src/orchestrator/utils/helm"
@@ -95,8 +97,9 @@ type InstantiationKey struct {
 // InstantiationManager is an interface which exposes the
 // InstantiationManager functionalities
 type InstantiationManager interface {
-	Approve(ctx context.Context, p string, ca string, v string, di string) error
+	Approve(ctx context.Context, p string, ca string, v string, di string, req ApprovalRequest) error
 	Instantiate(ctx context.Context, p string, ca string, v string, di string) error
+	Validate(ctx context.Context, p string, ca string, v string, di string) (ValidationReport, error)
 	Status(ctx context.Context, p, ca, v, di, qInstance, qType, qOutput string, fApps, fClusters, fResources []string) (DeploymentStatus, error)
 	GenericStatus(ctx context.Context, p, ca, v, di, qInstance, qType, qOutput string, fApps, fClusters, fResources []string) (status.StatusResult, error)
 	StatusAppsList(ctx context.Context, p, ca, v, di, qInstance string) (DeploymentAppsListStatus, error)
@@ -125,8 +128,15 @@ func NewInstantiationClient() *InstantiationClient {
 	}
 }
 
-//Approve approves an instantiation
-func (c InstantiationClient) Approve(ctx context.Context, p string, ca string, v string, di string) error {
+//Approve approves an instantiation. The deployment intent group is approved once
+//its approval policy, if any, is satisfied.
+func (c InstantiationClient) Approve(ctx context.Context, p string, ca string, v string, di string, req ApprovalRequest) error {
+	unlock, err := lockDeploymentIntentGroup(ctx, p, ca, v, di)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	s, err := NewDeploymentIntentGroupClient().GetDeploymentIntentGroupState(ctx, di, p, ca, v)
 	if err != nil {
 		log.Info("DeploymentIntentGroup has no state info ", log.Fields{"DeploymentIntentGroup: ": di})
@@ -139,7 +149,7 @@ func (c InstantiationClient) Approve(ctx context.Context, p string, ca string, v
 	}
 	switch stateVal {
 	case state.StateEnum.Approved:
-		return nil
+		break
 	case state.StateEnum.Terminated:
 		break
 	case state.StateEnum.Created:
@@ -154,6 +164,14 @@ func (c InstantiationClient) Approve(ctx context.Context, p string, ca string, v
 		return pkgerrors.Errorf("DeploymentIntentGroup is in an unknown state" + stateVal)
 	}
 
+	approved, err := NewApprovalClient().recordApproval(ctx, p, ca, v, di, req)
+	if err != nil {
+		return err
+	}
+	if !approved || stateVal == state.StateEnum.Approved {
+		return nil
+	}
+
 	key := DeploymentIntentGroupKey{
 		Name:         di,
 		Project:      p,
@@ -308,6 +326,9 @@ func validateLogicalCloud(ctx context.Context, p string, lc string, dcmCloudClie
 	switch acStatus.Status {
 	case appcontext.AppContextStatusEnum.Instantiated:
 		log.Info("The Logical Cloud is instantiated, proceeding with DIG instantiation.", log.Fields{"logicalcloud": lc})
+	case appcontext.AppContextStatusEnum.Pending:
+		// The DIG is delivered to the offline clusters after the Logical Cloud
+		log.Info("The Logical Cloud is pending on offline clusters, proceeding with DIG instantiation.", log.Fields{"logicalcloud": lc})
 	case appcontext.AppContextStatusEnum.Terminated:
 		log.Error("The Logical Cloud is not currently instantiated (has been terminated).", log.Fields{"logicalcloud": lc})
 		return pkgerrors.New("The Logical Cloud is not currently instantiated (has been terminated).")
@@ -387,7 +408,17 @@ Instantiate methods takes in projectName, compositeAppName, compositeAppVersion,
 DeploymentIntentName. This method is responsible for template resolution, intent
 resolution, creation and saving of context for saving into etcd.
 */
-func (c InstantiationClient) Instantiate(ctx context.Context, p string, ca string, v string, di string) error {
+func (c InstantiationClient) Instantiate(ctx context.Context, p string, ca string, v string, di string) (err error) {
+	start := time.Now()
+	defer func() {
+		metrics.ObserveLifecycleDuration(metrics.OperationInstantiate, metrics.PhaseTotal, "", start, err)
+	}()
+
+	unlock, err := lockDeploymentIntentGroup(ctx, p, ca, v, di)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
 	log.Info(":: Orchestrator Instantiate ::", log.Fields{"project": p, "composite-app": ca, "composite-app-ver": v, "dep-group": di})
 
@@ -406,24 +437,47 @@ func (c InstantiationClient) Instantiate(ctx context.Context, p string, ca strin
 		return pkgerrors.Wrap(err, "Error in handleStateInfo for DeploymentIntent:: "+di)
 	}
 
+	approvals := NewApprovalClient()
+	if err := approvals.checkApprovals(ctx, p, ca, v, di); err != nil {
+		return err
+	}
+
+	// The app context is counted once the instantiation completes, so concurrent
+	// instantiations in the project may exceed the quota by the ones in progress
+	if err := quota.CheckProjectQuota(ctx, p, quota.AppContexts); err != nil {
+		return err
+	}
+
 	// BEGIN : Make app context
 	span.AddEvent("create-app-context")
 	instantiator := Instantiator{p, ca, v, di, dIGrp}
+	renderStart := time.Now()
 	cca, err := instantiator.MakeAppContext(ctx)
+	metrics.ObserveLifecycleDuration(metrics.OperationInstantiate, metrics.PhaseRender, "", renderStart, err)
 	if err != nil {
 		return pkgerrors.Wrap(err, "Error in making AppContext")
 	}
 	// END : Make app context
 
 	// BEGIN : callScheduler
-	err = callScheduler(ctx, cca.context, cca.ctxval, nil, p, ca, v, di)
+	err = callScheduler(ctx, metrics.OperationInstantiate, cca.context, cca.ctxval, nil, p, ca, v, di)
 	if err != nil {
 		return pkgerrors.Wrap(err, "Error in callScheduler")
 	}
 	// END : callScheduler
 
+	if err := checkClusterQuota(ctx, p, cca.context); err != nil {
+		deleteAppContext(ctx, cca.context)
+		return err
+	}
+
+	if _, err := reviewAppContext(ctx, metrics.OperationInstantiate, cca.context, p, ca, v, di, dIGrp.Spec.LogicalCloud); err != nil {
+		deleteAppContext(ctx, cca.context)
+		return err
+	}
+
 	// BEGIN : Rsync code
-	err = callRsyncInstall(ctx, cca.ctxval)
+	err = callRsyncInstall(ctx, metrics.OperationInstantiate, cca.ctxval)
 	if err != nil {
 		deleteAppContext(ctx, cca.context)
 		return pkgerrors.Wrap(err, "Error calling rsync")
@@ -431,6 +485,9 @@ func (c InstantiationClient) Instantiate(ctx context.Context, p string, ca strin
 	// END : Rsync code
 
 	err = storeAppContextIntoMetaDB(ctx, cca.ctxval, c.db.storeName, c.db.tagState, s, p, ca, v, di)
+	if err == nil {
+		err = approvals.useApprovals(ctx, p, ca, v, di)
+	}
 
 	// Call Post INSTANTIATE Event for all controllers
 	_ = callPostEventScheduler(ctx, cca.ctxval, p, ca, v, di, "INSTANTIATE")
@@ -596,7 +653,17 @@ func (c InstantiationClient) StatusResourcesByApp(ctx context.Context, p, ca, v,
 Terminate takes in projectName, compositeAppName, compositeAppVersion,
 DeploymentIntentName and calls rsync to terminate.
 */
-func (c InstantiationClient) Terminate(ctx context.Context, p string, ca string, v string, di string) error {
+func (c InstantiationClient) Terminate(ctx context.Context, p string, ca string, v string, di string) (err error) {
+	start := time.Now()
+	defer func() {
+		metrics.ObserveLifecycleDuration(metrics.OperationTerminate, metrics.PhaseTotal, "", start, err)
+	}()
+
+	unlock, err := lockDeploymentIntentGroup(ctx, p, ca, v, di)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
 	s, err := NewDeploymentIntentGroupClient().GetDeploymentIntentGroupState(ctx, di, p, ca, v)
 	if err != nil {
@@ -684,6 +751,12 @@ DeploymentIntentName and sets the stopFlag in the associated appContext.
 */
 func (c InstantiationClient) Stop(ctx context.Context, p string, ca string, v string, di string) error {
 
+	unlock, err := lockDeploymentIntentGroup(ctx, p, ca, v, di)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	s, err := NewDeploymentIntentGroupClient().GetDeploymentIntentGroupState(ctx, di, p, ca, v)
 	if err != nil {
 		return pkgerrors.Wrap(err, "DeploymentIntentGroup has no state info: "+di)
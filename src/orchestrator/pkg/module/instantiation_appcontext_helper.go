@@ -29,6 +29,13 @@ import (
 type resource struct {
 	name        string
 	filecontent string
+	hook        *helm.Hook
+}
+
+// hookInstr is the weight and delete policies of a hook resource
+type hookInstr struct {
+	Weight         int      `json:"weight"`
+	DeletePolicies []string `json:"deletepolicies,omitempty"`
 }
 
 type contextForCompositeApp struct {
@@ -125,7 +132,7 @@ func getHookResources(hk []*helm.Hook) (map[string][]resource, error) {
 				log.Info(":: Ignoring, Unable to render the template ::", log.Fields{"YAML PATH": res.KRT.FilePath})
 				continue
 			}
-			resources[hookName] = append(resources[hookName], resource{name: n, filecontent: string(yamlFile)})
+			resources[hookName] = append(resources[hookName], resource{name: n, filecontent: string(yamlFile), hook: res})
 		}
 	}
 	return resources, nil
@@ -172,6 +179,10 @@ func (ah *AppHandler) addHooksToCluster(ctx context.Context, ct appcontext.AppCo
 		Resdep map[string][]string `json:"resdependency"`
 	}
 	resdep := make(map[string][]string)
+	var resHookInstr struct {
+		Reshooks map[string]hookInstr `json:"reshooks"`
+	}
+	reshooks := make(map[string]hookInstr)
 
 	// Add Hooks resources and add in the dependency instruction
 	for name, t := range hk {
@@ -181,6 +192,7 @@ func (ah *AppHandler) addHooksToCluster(ctx context.Context, ct appcontext.AppCo
 				return err
 			}
 			resdep[name] = append(resdep[name], res.name)
+			reshooks[res.name] = hookInstr{Weight: res.hook.Hook.Weight, DeletePolicies: res.hook.DeletePolicies()}
 		}
 	}
 	// Add CRD Resources also
@@ -198,6 +210,15 @@ func (ah *AppHandler) addHooksToCluster(ctx context.Context, ct appcontext.AppCo
 	if err != nil {
 		return pkgerrors.Wrapf(err, "Error adding instruction for resource to AppContext")
 	}
+	if len(reshooks) > 0 {
+		// Add the weights and delete policies of the hooks
+		resHookInstr.Reshooks = reshooks
+		jresHookInstr, _ := json.Marshal(resHookInstr)
+		_, err = ct.AddInstruction(ctx, ch, "resource", appcontext.HookInstruction, string(jresHookInstr))
+		if err != nil {
+			return pkgerrors.Wrapf(err, "Error adding hook instruction for resource to AppContext")
+		}
+	}
 	return nil
 }
 
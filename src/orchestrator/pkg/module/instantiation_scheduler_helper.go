@@ -7,6 +7,7 @@ import (
 	"container/heap"
 	"context"
 	"strings"
+	"time"
 
 	"fmt"
 
@@ -16,6 +17,8 @@ import (
 	rsyncclient "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/grpc/installappclient"
 	plsGrpcClient "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/grpc/placementcontrollerclient"
 	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/metrics"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/tracing"
 	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/module/controller"
 	mtypes "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/module/types"
 	"go.opentelemetry.io/otel/trace"
@@ -176,7 +179,7 @@ func getPrioritizedControllerList(ctx context.Context, p, ca, v, di string) (Pri
 callGrpcForControllerList method shall take in a list of controllers, a map of contollers to controllerIntentNames and contextID. It invokes the context
 updation through the grpc client for the given list of controllers.
 */
-func callGrpcForControllerList(ctx context.Context, cl []controller.Controller, mc map[string]string, contextid, updateFromContextid interface{}) error {
+func callGrpcForControllerList(ctx context.Context, operation string, cl []controller.Controller, mc map[string]string, contextid, updateFromContextid interface{}) error {
 	for _, c := range cl {
 		controller := c.Metadata.Name
 		controllerIntentName := mc[controller]
@@ -184,7 +187,9 @@ func callGrpcForControllerList(ctx context.Context, cl []controller.Controller,
 		updateAppContextId := fmt.Sprintf("%v", updateFromContextid)
 		log.Info("callGrpcForControllerList .. Invoking action-controller.", log.Fields{
 			"controller": controller, "controllerIntentName": controllerIntentName, "appContextID": appContextID})
+		start := time.Now()
 		err := client.InvokeContextUpdate(ctx, controller, controllerIntentName, appContextID, updateAppContextId)
+		metrics.ObserveLifecycleDuration(operation, metrics.PhaseActionController, controller, start, err)
 		if err != nil {
 			return err
 		}
@@ -196,13 +201,15 @@ func callGrpcForControllerList(ctx context.Context, cl []controller.Controller,
 callGrpcForPlacementControllerList method shall take in a list of placement controllers, a map of contollers to controllerIntentNames and contextID.
 It invokes the filter clusters through the grpc client for the given list of controllers.
 */
-func callGrpcForPlacementControllerList(ctx context.Context, cl []controller.Controller, contextid interface{}) error {
+func callGrpcForPlacementControllerList(ctx context.Context, operation string, cl []controller.Controller, contextid interface{}) error {
 	for _, c := range cl {
 		controller := c.Metadata.Name
 		appContextID := fmt.Sprintf("%v", contextid)
 		log.Info("callGrpcForControllerList .. Invoking placement-controller.", log.Fields{
 			"controller": controller, "appContextID": appContextID})
+		start := time.Now()
 		err := plsGrpcClient.InvokeFilterClusters(ctx, c, appContextID)
+		metrics.ObserveLifecycleDuration(operation, metrics.PhasePlacementController, controller, start, err)
 		if err != nil {
 			return pkgerrors.Wrapf(err, "Placement-controller returned error. failed-placement-controller[%v] appContextID[%v]", controller, appContextID)
 		}
@@ -230,9 +237,10 @@ func queryDBAndSetRsyncInfo(ctx context.Context) (rsyncclient.RsyncInfo, error)
 }
 
 /*
-callRsyncInstall method shall take in the app context id and invokes the rsync service via grpc
+callRsyncInstall method shall take in the lifecycle operation and the app context id and invokes the rsync service via grpc
 */
-func callRsyncInstall(ctx context.Context, contextid interface{}) error {
+func callRsyncInstall(ctx context.Context, operation string, contextid interface{}) error {
+	start := time.Now()
 	span := trace.SpanFromContext(ctx)
 	span.AddEvent("invoke-rsync")
 
@@ -246,6 +254,7 @@ func callRsyncInstall(ctx context.Context, contextid interface{}) error {
 
 	appContextID := fmt.Sprintf("%v", contextid)
 	err = rsyncclient.InvokeInstallApp(ctx, appContextID)
+	metrics.ObserveLifecycleDuration(operation, metrics.PhaseRsync, "", start, err)
 	if err != nil {
 		return err
 	}
@@ -256,6 +265,7 @@ func callRsyncInstall(ctx context.Context, contextid interface{}) error {
 callRsyncUninstall method shall take in the app context id and invokes the rsync service via grpc
 */
 func callRsyncUninstall(ctx context.Context, contextid interface{}) error {
+	start := time.Now()
 	rsyncInfo, err := queryDBAndSetRsyncInfo(ctx)
 	log.Info("Calling the Rsync ", log.Fields{
 		"RsyncName": rsyncInfo.RsyncName,
@@ -266,6 +276,7 @@ func callRsyncUninstall(ctx context.Context, contextid interface{}) error {
 
 	appContextID := fmt.Sprintf("%v", contextid)
 	err = rsyncclient.InvokeUninstallApp(ctx, appContextID)
+	metrics.ObserveLifecycleDuration(metrics.OperationTerminate, metrics.PhaseRsync, "", start, err)
 	if err != nil {
 		return err
 	}
@@ -310,8 +321,9 @@ func deleteExtraClusters(ctx context.Context, apps []App, ct appcontext.AppConte
 }
 
 // callScheduler instantiates based on the controller priority list
-func callScheduler(ctx context.Context, appCtx appcontext.AppContext, ctxval, ctxUpdateFromval interface{}, p, ca, v, di string) error {
+func callScheduler(ctx context.Context, operation string, appCtx appcontext.AppContext, ctxval, ctxUpdateFromval interface{}, p, ca, v, di string) error {
 	span := trace.SpanFromContext(ctx)
+	tracing.SetAppContextID(ctx, fmt.Sprintf("%v", ctxval))
 
 	// BEGIN: scheduler code
 	span.AddEvent("retrieve-controllers")
@@ -330,7 +342,7 @@ func callScheduler(ctx context.Context, appCtx appcontext.AppContext, ctxval, ct
 
 	// Invoke all Placement Controllers communication interface in loop
 	span.AddEvent("invoke-placement-controllers")
-	err = callGrpcForPlacementControllerList(ctx, pl.pPlaCont, ctxval)
+	err = callGrpcForPlacementControllerList(ctx, operation, pl.pPlaCont, ctxval)
 	if err != nil {
 		deleteAppContext(ctx, appCtx)
 		log.Error("Orchestrator Instantiate .. Error calling PlacementController gRPC.", log.Fields{"all-placement-controllers": pl.pPlaCont, "err": err})
@@ -346,7 +358,7 @@ func callScheduler(ctx context.Context, appCtx appcontext.AppContext, ctxval, ct
 
 	// Invoke all Action Controllers communication interface
 	span.AddEvent("invoke-action-controllers")
-	err = callGrpcForControllerList(ctx, pl.pActCont, mapOfControllers, ctxval, ctxUpdateFromval)
+	err = callGrpcForControllerList(ctx, operation, pl.pActCont, mapOfControllers, ctxval, ctxUpdateFromval)
 	log.Warn("", log.Fields{"pl.pActCont::": pl.pActCont})
 	log.Warn("", log.Fields{"mapOfControllers::": mapOfControllers})
 	log.Warn("", log.Fields{"ctxval::": ctxval})
@@ -372,7 +384,9 @@ func callTerminateScheduler(ctx context.Context, ctxval interface{}, p, ca, v, d
 		appContextID := fmt.Sprintf("%v", ctxval)
 		log.Info("callTerminateScheduler .. Invoking action-controller.", log.Fields{
 			"controller": controller, "controllerIntentName": controllerIntentName, "appContextID": appContextID})
+		start := time.Now()
 		err := client.InvokeContextTerminate(ctx, controller, appContextID)
+		metrics.ObserveLifecycleDuration(metrics.OperationTerminate, metrics.PhaseActionController, controller, start, err)
 		// If GRPC endpoint not implemented by controller don't consider that as an error
 		if err != nil && !strings.Contains(err.Error(), "TerminateAppContext not implemented") {
 			log.Error("InvokeContextTerminate: Error", log.Fields{"controller": controller, "err": err})
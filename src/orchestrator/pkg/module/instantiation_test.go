@@ -4,12 +4,30 @@
 package module_test
 
 import (
-	//. "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/module"
+	"context"
 
 	. "github.com/onsi/ginkgo"
-	//. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/contextdb"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/module"
 )
 
 var _ = Describe("Instantiation", func() {
+	It("rejects a lifecycle operation on a locked DeploymentIntentGroup", func() {
+		ctx := context.Background()
+		unlock, err := contextdb.TryLock(ctx, "dig/p1/ca1/v1/dig1")
+		Expect(err).To(BeNil())
 
+		c := module.NewInstantiationClient()
+		err = c.Approve(ctx, "p1", "ca1", "v1", "dig1", module.ApprovalRequest{})
+		Expect(err).NotTo(BeNil())
+		Expect(err.Error()).To(ContainSubstring("is locked by another lifecycle operation"))
+		_, err = c.Update(ctx, "p1", "ca1", "v1", "dig1")
+		Expect(err.Error()).To(ContainSubstring("is locked by another lifecycle operation"))
+
+		Expect(unlock(ctx)).To(BeNil())
+		unlock, err = contextdb.TryLock(ctx, "dig/p1/ca1/v1/dig1")
+		Expect(err).To(BeNil())
+		Expect(unlock(ctx)).To(BeNil())
+	})
 })
@@ -10,6 +10,10 @@ import (
 // Client for using the services in the orchestrator
 type Client struct {
 	Project                *ProjectClient
+	ProjectQuota           *ProjectQuotaClient
+	AdmissionPolicy        *AdmissionPolicyClient
+	Approval               *ApprovalClient
+	Queue                  *QueueClient
 	CompositeApp           *CompositeAppClient
 	App                    *AppClient
 	Controller             *controller.ControllerClient
@@ -20,6 +24,8 @@ type Client struct {
 	CompositeProfile       *CompositeProfileClient
 	AppProfile             *AppProfileClient
 	AppDependency          *AppDependencyClient
+	Backup                 *BackupClient
+	DataKey                *DataKeyClient
 	// Add Clients for API's here
 	Instantiation *InstantiationClient
 }
@@ -28,6 +34,10 @@ type Client struct {
 func NewClient() *Client {
 	c := &Client{}
 	c.Project = NewProjectClient()
+	c.ProjectQuota = NewProjectQuotaClient()
+	c.AdmissionPolicy = NewAdmissionPolicyClient()
+	c.Approval = NewApprovalClient()
+	c.Queue = NewQueueClient()
 	c.CompositeApp = NewCompositeAppClient()
 	c.App = NewAppClient()
 	c.Controller = controller.NewControllerClient("resources", "data", "orchestrator")
@@ -38,6 +48,8 @@ func NewClient() *Client {
 	c.CompositeProfile = NewCompositeProfileClient()
 	c.AppProfile = NewAppProfileClient()
 	c.AppDependency = NewAppDependencyClient()
+	c.Backup = NewBackupClient()
+	c.DataKey = NewDataKeyClient()
 	// Add Client API handlers here
 	c.Instantiation = NewInstantiationClient()
 	return c
@@ -0,0 +1,150 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package module
+
+import (
+	"context"
+	"encoding/json"
+
+	pkgerrors "github.com/pkg/errors"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/appcontext"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/db"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/quota"
+)
+
+// The quota and the usage of the projects, see package quota
+type (
+	ProjectQuota     = quota.ProjectQuota
+	ProjectQuotaSpec = quota.ProjectQuotaSpec
+	ProjectUsage     = quota.ProjectUsage
+)
+
+// ProjectQuotaStatus reports the quota of a project and its usage
+type ProjectQuotaStatus struct {
+	Quota *ProjectQuotaSpec `json:"quota,omitempty"`
+	Usage ProjectUsage      `json:"usage"`
+}
+
+// QuotaResource is a kind of object limited by the project quota
+type QuotaResource = quota.Resource
+
+const (
+	QuotaCompositeApps          = quota.CompositeApps
+	QuotaDeploymentIntentGroups = quota.DeploymentIntentGroups
+	QuotaAppContexts            = quota.AppContexts
+	QuotaLogicalClouds          = quota.LogicalClouds
+)
+
+// ProjectQuotaManager is an interface exposing the ProjectQuota functionality
+type ProjectQuotaManager interface {
+	CreateProjectQuota(ctx context.Context, p string, q ProjectQuota, exists bool) (ProjectQuota, error)
+	GetProjectQuota(ctx context.Context, p string) (ProjectQuota, error)
+	DeleteProjectQuota(ctx context.Context, p string) error
+	GetProjectQuotaStatus(ctx context.Context, p string) (ProjectQuotaStatus, error)
+}
+
+// ProjectQuotaClient implements the ProjectQuotaManager. The quota is stored in
+// the document of the project, so it is deleted with the project.
+type ProjectQuotaClient struct {
+	storeName string
+	tagQuota  string
+}
+
+// NewProjectQuotaClient returns an instance of the ProjectQuotaClient
+func NewProjectQuotaClient() *ProjectQuotaClient {
+	return &ProjectQuotaClient{
+		storeName: "resources",
+		tagQuota:  "quota",
+	}
+}
+
+// CreateProjectQuota sets the quota of the project
+func (v *ProjectQuotaClient) CreateProjectQuota(ctx context.Context, p string, q ProjectQuota, exists bool) (ProjectQuota, error) {
+	_, err := v.GetProjectQuota(ctx, p)
+	if err == nil && !exists {
+		return ProjectQuota{}, pkgerrors.New("ProjectQuota already exists")
+	}
+	if err != nil && err.Error() != "ProjectQuota not found" {
+		return ProjectQuota{}, err
+	}
+
+	err = db.DBconn.Insert(ctx, v.storeName, ProjectKey{ProjectName: p}, nil, v.tagQuota, q)
+	if err != nil {
+		return ProjectQuota{}, pkgerrors.Wrap(err, "Create DB entry error")
+	}
+	return q, nil
+}
+
+// GetProjectQuota returns the quota of the project
+func (v *ProjectQuotaClient) GetProjectQuota(ctx context.Context, p string) (ProjectQuota, error) {
+	return quota.GetProjectQuota(ctx, p)
+}
+
+// DeleteProjectQuota removes the quota of the project
+func (v *ProjectQuotaClient) DeleteProjectQuota(ctx context.Context, p string) error {
+	if _, err := v.GetProjectQuota(ctx, p); err != nil {
+		return err
+	}
+	return db.DBconn.RemoveTag(ctx, v.storeName, ProjectKey{ProjectName: p}, v.tagQuota)
+}
+
+// GetProjectQuotaStatus returns the quota of the project, if any, and its usage
+func (v *ProjectQuotaClient) GetProjectQuotaStatus(ctx context.Context, p string) (ProjectQuotaStatus, error) {
+	s := ProjectQuotaStatus{}
+	q, err := v.GetProjectQuota(ctx, p)
+	if err == nil {
+		s.Quota = &q.Spec
+	} else if err.Error() != "ProjectQuota not found" {
+		return ProjectQuotaStatus{}, err
+	}
+	s.Usage, err = v.GetProjectUsage(ctx, p)
+	if err != nil {
+		return ProjectQuotaStatus{}, err
+	}
+	return s, nil
+}
+
+// GetProjectUsage counts the objects of the project
+func (v *ProjectQuotaClient) GetProjectUsage(ctx context.Context, p string) (ProjectUsage, error) {
+	return quota.GetProjectUsage(ctx, p)
+}
+
+// CheckProjectQuota returns an error if one more object of the resource kind
+// would exceed the quota of the project
+func CheckProjectQuota(ctx context.Context, p string, r QuotaResource) error {
+	return quota.CheckProjectQuota(ctx, p, r)
+}
+
+// checkClusterQuota returns an error if the app context deploys to more clusters
+// than the quota of the project allows for a deployment intent group
+func checkClusterQuota(ctx context.Context, p string, ac appcontext.AppContext) error {
+	q, err := NewProjectQuotaClient().GetProjectQuota(ctx, p)
+	if err != nil || q.Spec.ClustersPerDeploymentIntentGroup == nil {
+		return nil
+	}
+
+	order, err := ac.GetAppInstruction(ctx, appcontext.OrderInstruction)
+	if err != nil {
+		return pkgerrors.Wrap(err, "Error getting the app order instruction")
+	}
+	var apps appOrderInstr
+	if err := json.Unmarshal([]byte(order.(string)), &apps); err != nil {
+		return pkgerrors.Wrap(err, "Error unmarshalling the app order instruction")
+	}
+	clusters := make(map[string]struct{})
+	for _, app := range apps.Apporder {
+		names, err := ac.GetClusterNames(ctx, app)
+		if err != nil {
+			return pkgerrors.Wrapf(err, "Error getting the clusters of app %s", app)
+		}
+		for _, n := range names {
+			clusters[n] = struct{}{}
+		}
+	}
+
+	if limit := *q.Spec.ClustersPerDeploymentIntentGroup; len(clusters) > limit {
+		return pkgerrors.Errorf("Project quota exceeded: %d clusters resolved, %d clusters per deployment intent group allowed in project %s", len(clusters), limit, p)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package module
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/db"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/state"
+)
+
+func TestProjectQuota(t *testing.T) {
+	ctx := context.Background()
+	savedDb := db.DBconn
+	defer func() { db.DBconn = savedDb }()
+
+	var err error
+	db.DBconn, err = db.NewEmbeddedStore(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	qc := NewProjectQuotaClient()
+	if _, err := qc.CreateProjectQuota(ctx, "p1", ProjectQuota{}, false); err == nil || err.Error() != "Project not found" {
+		t.Fatalf("Expected Project not found, got %v", err)
+	}
+
+	// The project document, without the referential checks of the data tag
+	err = db.DBconn.Insert(ctx, "resources", ProjectKey{ProjectName: "p1"}, nil, "metadata", ProjectMetaData{Name: "p1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := qc.GetProjectQuota(ctx, "p1"); err == nil || err.Error() != "ProjectQuota not found" {
+		t.Fatalf("Expected ProjectQuota not found, got %v", err)
+	}
+	if err := CheckProjectQuota(ctx, "p1", QuotaDeploymentIntentGroups); err != nil {
+		t.Fatalf("A project without quota is limited: %s", err)
+	}
+
+	one, two := 1, 2
+	q := ProjectQuota{Spec: ProjectQuotaSpec{DeploymentIntentGroups: &two, AppContexts: &one}}
+	if _, err := qc.CreateProjectQuota(ctx, "p1", q, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := qc.CreateProjectQuota(ctx, "p1", q, false); err == nil || err.Error() != "ProjectQuota already exists" {
+		t.Fatalf("Expected ProjectQuota already exists, got %v", err)
+	}
+
+	// Two deployment intent groups, one of them instantiated
+	digs := []struct {
+		name string
+		s    state.StateInfo
+	}{
+		{"dig1", state.StateInfo{Actions: []state.ActionEntry{{State: state.StateEnum.Created}}}},
+		{"dig2", state.StateInfo{Actions: []state.ActionEntry{{State: state.StateEnum.Created}, {State: state.StateEnum.Instantiated, ContextId: "1234"}}}},
+	}
+	for _, d := range digs {
+		key := DeploymentIntentGroupKey{Name: d.name, Project: "p1", CompositeApp: "ca", Version: "v1"}
+		if err := db.DBconn.Insert(ctx, "resources", key, nil, "stateInfo", d.s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, r := range []QuotaResource{QuotaDeploymentIntentGroups, QuotaAppContexts} {
+		if err := CheckProjectQuota(ctx, "p1", r); err == nil || !strings.Contains(err.Error(), "Project quota exceeded") {
+			t.Errorf("Expected the quota of %s to be exceeded, got %v", r, err)
+		}
+	}
+	if err := CheckProjectQuota(ctx, "p1", QuotaCompositeApps); err != nil {
+		t.Errorf("The composite apps are not limited: %s", err)
+	}
+
+	s, err := qc.GetProjectQuotaStatus(ctx, "p1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Quota == nil || *s.Quota.DeploymentIntentGroups != 2 || s.Usage.DeploymentIntentGroups != 2 || s.Usage.AppContexts != 1 {
+		t.Errorf("Unexpected quota status %+v", s)
+	}
+
+	// The update raises the limits
+	q.Spec.AppContexts = &two
+	if _, err := qc.CreateProjectQuota(ctx, "p1", q, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckProjectQuota(ctx, "p1", QuotaAppContexts); err != nil {
+		t.Errorf("The updated quota was not applied: %s", err)
+	}
+
+	if err := qc.DeleteProjectQuota(ctx, "p1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckProjectQuota(ctx, "p1", QuotaDeploymentIntentGroups); err != nil {
+		t.Errorf("The quota was enforced after its removal: %s", err)
+	}
+	if err := qc.DeleteProjectQuota(ctx, "p1"); err == nil || err.Error() != "ProjectQuota not found" {
+		t.Errorf("Expected ProjectQuota not found, got %v", err)
+	}
+}
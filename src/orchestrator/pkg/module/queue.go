@@ -0,0 +1,214 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package module
+
+import (
+	"context"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/grpc/rsyncmgmtclient"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/db"
+	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/state"
+	mgmtpb "gitlab.com/project-emco/core/emco-base/src/rsync/pkg/grpc/rsyncmgmt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Events of the rsync queues whose cancellation changes the state of the deployment intent group
+const (
+	instantiateEvent = "Instantiate"
+	updateEvent      = "Update"
+)
+
+// QueueEvent is an event queued in rsync for an AppContext
+type QueueEvent struct {
+	Index  int    `json:"index"`
+	Event  string `json:"event"`
+	UCID   string `json:"updateAppContextId,omitempty"`
+	Status string `json:"status"`
+}
+
+// QueueInProgress is the resource rsync is handling for an app on a cluster
+type QueueInProgress struct {
+	App       string `json:"app"`
+	Cluster   string `json:"cluster"`
+	Operation string `json:"operation"`
+	Resource  string `json:"resource,omitempty"`
+	Since     string `json:"since"`
+}
+
+// AppContextQueue is the rsync event queue of an AppContext of a deployment intent group
+type AppContextQueue struct {
+	AppContextID string            `json:"appContextId"`
+	Running      bool              `json:"running"`
+	Events       []QueueEvent      `json:"events"`
+	InProgress   []QueueInProgress `json:"inProgress,omitempty"`
+}
+
+// OperationsQueue is the rsync event queues of the AppContexts of a deployment intent group
+type OperationsQueue struct {
+	AppContexts []AppContextQueue `json:"appContexts"`
+}
+
+// QueueCancelRequest identifies the pending event to cancel. The AppContext is the
+// current AppContext of the deployment intent group if empty.
+type QueueCancelRequest struct {
+	AppContextID string `json:"appContextId,omitempty"`
+	Index        int    `json:"index"`
+}
+
+// QueueManager is an interface exposing the rsync event queues of a deployment intent group
+type QueueManager interface {
+	GetQueue(ctx context.Context, p, ca, v, di string) (OperationsQueue, error)
+	CancelEvent(ctx context.Context, p, ca, v, di string, req QueueCancelRequest) error
+}
+
+// QueueClient implements the QueueManager
+type QueueClient struct {
+}
+
+// NewQueueClient returns an instance of the QueueClient
+func NewQueueClient() *QueueClient {
+	return &QueueClient{}
+}
+
+// contextIDs returns the AppContexts of the deployment intent group, in the order of its actions
+func contextIDs(s state.StateInfo) []string {
+	var ids []string
+	found := map[string]bool{}
+	for _, a := range s.Actions {
+		if a.ContextId != "" && !found[a.ContextId] {
+			found[a.ContextId] = true
+			ids = append(ids, a.ContextId)
+		}
+	}
+	return ids
+}
+
+// GetQueue returns the rsync event queues of the AppContexts of the deployment intent group
+func (c *QueueClient) GetQueue(ctx context.Context, p, ca, v, di string) (OperationsQueue, error) {
+	s, err := NewDeploymentIntentGroupClient().GetDeploymentIntentGroupState(ctx, di, p, ca, v)
+	if err != nil {
+		return OperationsQueue{}, err
+	}
+	oq := OperationsQueue{AppContexts: []AppContextQueue{}}
+	for _, id := range contextIDs(s) {
+		resp, err := rsyncmgmtclient.InvokeGetQueue(ctx, id)
+		// The AppContexts of previous revisions may have been deleted
+		if status.Code(err) == codes.NotFound {
+			continue
+		}
+		if err != nil {
+			log.Error("Error getting the AppContext queue from rsync", log.Fields{"appContext": id, "error": err})
+			return OperationsQueue{}, pkgerrors.Wrap(err, "Error getting the AppContext queue from rsync")
+		}
+		// AppContexts never sent to rsync have no queue
+		if len(resp.Events) == 0 {
+			continue
+		}
+		oq.AppContexts = append(oq.AppContexts, appContextQueue(id, resp))
+	}
+	return oq, nil
+}
+
+func appContextQueue(id string, resp *mgmtpb.GetQueueResponse) AppContextQueue {
+	q := AppContextQueue{AppContextID: id, Running: resp.Running, Events: []QueueEvent{}}
+	for _, e := range resp.Events {
+		q.Events = append(q.Events, QueueEvent{Index: int(e.Index), Event: e.Event, UCID: e.Ucid, Status: e.Status})
+	}
+	for _, ip := range resp.InProgress {
+		q.InProgress = append(q.InProgress, QueueInProgress{App: ip.App, Cluster: ip.Cluster, Operation: ip.Operation,
+			Resource: ip.Resource, Since: ip.Since})
+	}
+	return q
+}
+
+// CancelEvent cancels a pending event of an AppContext of the deployment intent group and rolls
+// back the state of the deployment intent group to before the lifecycle operation which queued it
+func (c *QueueClient) CancelEvent(ctx context.Context, p, ca, v, di string, req QueueCancelRequest) error {
+	unlock, err := lockDeploymentIntentGroup(ctx, p, ca, v, di)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	s, err := NewDeploymentIntentGroupClient().GetDeploymentIntentGroupState(ctx, di, p, ca, v)
+	if err != nil {
+		return err
+	}
+	id := req.AppContextID
+	if id == "" {
+		id = state.GetLastContextIdFromStateInfo(s)
+	}
+	found := false
+	for _, cid := range contextIDs(s) {
+		if cid == id {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return pkgerrors.New("AppContext not found for the DeploymentIntentGroup")
+	}
+	// The queue is only appended to, the event at the index doesn't change
+	resp, err := rsyncmgmtclient.InvokeGetQueue(ctx, id)
+	if err != nil {
+		log.Error("Error getting the AppContext queue from rsync", log.Fields{"appContext": id, "error": err})
+		return pkgerrors.Wrap(err, "Error getting the AppContext queue from rsync")
+	}
+	if req.Index < 0 || req.Index >= len(resp.Events) {
+		return pkgerrors.Errorf("Event not found in the AppContext queue: %d", req.Index)
+	}
+	e := resp.Events[req.Index]
+	if err := rsyncmgmtclient.InvokeCancelEvent(ctx, id, req.Index); err != nil {
+		log.Error("Error cancelling the AppContext event in rsync", log.Fields{"appContext": id, "index": req.Index, "error": err})
+		return pkgerrors.Wrap(err, "Event could not be cancelled")
+	}
+
+	actions := cancelledActions(s, id, e.Event, e.Ucid)
+	if len(actions) == 0 {
+		return nil
+	}
+	s.Actions = append(s.Actions, actions...)
+	key := DeploymentIntentGroupKey{
+		Name:         di,
+		Project:      p,
+		CompositeApp: ca,
+		Version:      v,
+	}
+	dc := NewDeploymentIntentGroupClient()
+	if err := db.DBconn.Insert(ctx, dc.storeName, key, nil, dc.tagState, s); err != nil {
+		return pkgerrors.Wrap(err, "Error updating the stateInfo of the DeploymentIntentGroup: "+di)
+	}
+	log.Info("Rolled back the state of the DeploymentIntentGroup after cancelling the event", log.Fields{"appContext": id, "event": e.Event,
+		"state": actions[len(actions)-1].State})
+	return nil
+}
+
+// cancelledActions returns the actions rolling back the state of the deployment intent group after
+// cancelling the event of the AppContext, none if the state doesn't come from the event anymore
+func cancelledActions(s state.StateInfo, id, event, ucid string) []state.ActionEntry {
+	if len(s.Actions) == 0 {
+		return nil
+	}
+	last := s.Actions[len(s.Actions)-1]
+	if last.State != state.StateEnum.Instantiated || last.ContextId != id {
+		return nil
+	}
+	now := time.Now()
+	switch event {
+	case instantiateEvent:
+		// Nothing of the AppContext was deployed
+		return []state.ActionEntry{{State: state.StateEnum.Terminated, ContextId: id, TimeStamp: now, Revision: last.Revision}}
+	case updateEvent:
+		// The resources of the updated AppContext are still deployed, as after a rollback to it
+		return []state.ActionEntry{
+			{State: state.StateEnum.Updated, ContextId: id, TimeStamp: now, Revision: last.Revision},
+			{State: state.StateEnum.Instantiated, ContextId: ucid, TimeStamp: now, Revision: last.Revision + 1},
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package module
+
+import (
+	"reflect"
+	"testing"
+
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/state"
+	mgmtpb "gitlab.com/project-emco/core/emco-base/src/rsync/pkg/grpc/rsyncmgmt"
+)
+
+func TestQueueContextIDs(t *testing.T) {
+	s := state.StateInfo{Actions: []state.ActionEntry{
+		{State: state.StateEnum.Created},
+		{State: state.StateEnum.Instantiated, ContextId: "100"},
+		{State: state.StateEnum.Instantiated, ContextId: "200"},
+		{State: state.StateEnum.Terminated, ContextId: "200"},
+		{State: state.StateEnum.Instantiated, ContextId: "100"},
+	}}
+	if ids := contextIDs(s); !reflect.DeepEqual(ids, []string{"100", "200"}) {
+		t.Errorf("Unexpected AppContexts %v", ids)
+	}
+}
+
+func TestAppContextQueue(t *testing.T) {
+	resp := &mgmtpb.GetQueueResponse{
+		Running: true,
+		Events: []*mgmtpb.QueueEvent{
+			{Index: 0, Event: "Instantiate", Status: "Done"},
+			{Index: 1, Event: "Update", Ucid: "300", Status: "Running"},
+			{Index: 2, Event: "Terminate", Status: "Pending"},
+		},
+		InProgress: []*mgmtpb.InProgress{
+			{App: "app1", Cluster: "provider1+cluster1", Operation: "Apply", Resource: "r1+Deployment", Since: "2022-06-01T10:00:00Z"},
+		},
+	}
+	want := AppContextQueue{
+		AppContextID: "100",
+		Running:      true,
+		Events: []QueueEvent{
+			{Index: 0, Event: "Instantiate", Status: "Done"},
+			{Index: 1, Event: "Update", UCID: "300", Status: "Running"},
+			{Index: 2, Event: "Terminate", Status: "Pending"},
+		},
+		InProgress: []QueueInProgress{
+			{App: "app1", Cluster: "provider1+cluster1", Operation: "Apply", Resource: "r1+Deployment", Since: "2022-06-01T10:00:00Z"},
+		},
+	}
+	if q := appContextQueue("100", resp); !reflect.DeepEqual(q, want) {
+		t.Errorf("Unexpected queue %+v, expected %+v", q, want)
+	}
+}
+
+func TestQueueCancelledActions(t *testing.T) {
+	instantiated := state.StateInfo{Actions: []state.ActionEntry{
+		{State: state.StateEnum.Approved},
+		{State: state.StateEnum.Instantiated, ContextId: "100", Revision: 1},
+	}}
+	a := cancelledActions(instantiated, "100", "Instantiate", "")
+	if len(a) != 1 || a[0].State != state.StateEnum.Terminated || a[0].ContextId != "100" {
+		t.Errorf("Unexpected actions after cancelling the instantiation %+v", a)
+	}
+
+	updated := state.StateInfo{Actions: append(instantiated.Actions,
+		state.ActionEntry{State: state.StateEnum.Updated, ContextId: "100", Revision: 1},
+		state.ActionEntry{State: state.StateEnum.Instantiated, ContextId: "200", Revision: 2},
+	)}
+	a = cancelledActions(updated, "200", "Update", "100")
+	if len(a) != 2 || a[0].State != state.StateEnum.Updated || a[0].ContextId != "200" ||
+		a[1].State != state.StateEnum.Instantiated || a[1].ContextId != "100" || a[1].Revision != 3 {
+		t.Errorf("Unexpected actions after cancelling the update %+v", a)
+	}
+	rolledBack := state.StateInfo{Actions: append(updated.Actions, a...)}
+	if s, _ := state.GetCurrentStateFromStateInfo(rolledBack); s != state.StateEnum.Instantiated || state.GetLastContextIdFromStateInfo(rolledBack) != "100" {
+		t.Errorf("Unexpected state after cancelling the update %s", s)
+	}
+
+	// The state doesn't come from the cancelled event anymore
+	terminated := state.StateInfo{Actions: append(instantiated.Actions,
+		state.ActionEntry{State: state.StateEnum.Terminated, ContextId: "100"},
+	)}
+	if a := cancelledActions(terminated, "100", "Instantiate", ""); len(a) != 0 {
+		t.Errorf("Unexpected actions after a termination %+v", a)
+	}
+	if a := cancelledActions(updated, "100", "Read", ""); len(a) != 0 {
+		t.Errorf("Unexpected actions after cancelling a read %+v", a)
+	}
+}
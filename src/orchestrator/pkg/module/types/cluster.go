@@ -18,6 +18,8 @@ type GitOpsProps struct {
 	GitOpsReferenceObject string `json:"gitOpsReferenceObject"`
 	// Resource Sync Object for resurces
 	GitOpsResourceObject string `json:"gitOpsResourceObject"`
+	// Deployment target served by an rsync connector plugin, overriding the GitOps type
+	DeploymentTarget string `json:"deploymentTarget,omitempty"`
 }
 
 // Sync Objects
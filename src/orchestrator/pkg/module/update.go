@@ -12,6 +12,7 @@ import (
 	pkgerrors "github.com/pkg/errors"
 	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/db"
 	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/metrics"
 	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/state"
 )
 
@@ -46,7 +47,22 @@ DeploymentIntentName, targetCompositeAppVersion and targetDeploymentIntentName.
 This method is responsible for creation and saving of context for saving into etcd
 and ensuring sourceDeploymentIntent gets migrated to targetDeploymentIntent.
 */
-func (c InstantiationClient) Migrate(ctx context.Context, p string, ca string, v string, tCav string, di string, tDi string) error {
+func (c InstantiationClient) Migrate(ctx context.Context, p string, ca string, v string, tCav string, di string, tDi string) (err error) {
+	start := time.Now()
+	defer func() {
+		metrics.ObserveLifecycleDuration(metrics.OperationMigrate, metrics.PhaseTotal, "", start, err)
+	}()
+	unlock, err := lockDeploymentIntentGroup(ctx, p, ca, v, di)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	unlockTarget, err := lockDeploymentIntentGroup(ctx, p, ca, tCav, tDi)
+	if err != nil {
+		return err
+	}
+	defer unlockTarget()
+
 	log.Info("Migrate API", log.Fields{"project": p, "compositeapp": ca, "version": v, "targetcompositeappversion": tCav,
 		"sourcedeploymentintentgroup": di, "targetdeploymentintentgroup": tDi})
 
@@ -75,14 +91,16 @@ func (c InstantiationClient) Migrate(ctx context.Context, p string, ca string, v
 
 	// BEGIN : Make app context
 	instantiator := Instantiator{p, ca, tCav, tDi, dIGrp}
+	renderStart := time.Now()
 	cca, err := instantiator.MakeAppContext(ctx)
+	metrics.ObserveLifecycleDuration(metrics.OperationMigrate, metrics.PhaseRender, "", renderStart, err)
 	if err != nil {
 		return pkgerrors.Wrap(err, "Error in making AppContext")
 	}
 	// END : Make app context
 
 	// BEGIN : callScheduler
-	err = callScheduler(ctx, cca.context, cca.ctxval, sourceCtxId, p, ca, tCav, tDi)
+	err = callScheduler(ctx, metrics.OperationMigrate, cca.context, cca.ctxval, sourceCtxId, p, ca, tCav, tDi)
 	if err != nil {
 		return pkgerrors.Wrap(err, "Error in callScheduler")
 	}
@@ -116,7 +134,7 @@ func (c InstantiationClient) Migrate(ctx context.Context, p string, ca string, v
 		return err
 	}
 
-	err = callRsyncUpdate(ctx, sourceCtxId, targetCtxId)
+	err = callRsyncUpdate(ctx, metrics.OperationMigrate, sourceCtxId, targetCtxId)
 	if err != nil {
 		return err
 	}
@@ -169,7 +187,17 @@ Update methods takes in projectName, compositeAppName, compositeAppVersion,
 DeploymentIntentName.
 This method is responsible for creation and saving of context into etcd and ensuring new intents are applied on DeploymentIntentGroup.
 */
-func (c InstantiationClient) Update(ctx context.Context, p string, ca string, v string, di string) (int64, error) {
+func (c InstantiationClient) Update(ctx context.Context, p string, ca string, v string, di string) (_ int64, err error) {
+	start := time.Now()
+	defer func() {
+		metrics.ObserveLifecycleDuration(metrics.OperationUpdate, metrics.PhaseTotal, "", start, err)
+	}()
+
+	unlock, err := lockDeploymentIntentGroup(ctx, p, ca, v, di)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
 
 	log.Info("Update API", log.Fields{"project": p, "compositeapp": ca, "version": v, "deploymentintentgroup": di})
 
@@ -201,19 +229,31 @@ func (c InstantiationClient) Update(ctx context.Context, p string, ca string, v
 
 	// BEGIN : Make app context
 	instantiator := Instantiator{p, ca, v, di, dIGrp}
+	renderStart := time.Now()
 	cca, err := instantiator.MakeAppContext(ctx)
+	metrics.ObserveLifecycleDuration(metrics.OperationUpdate, metrics.PhaseRender, "", renderStart, err)
 	if err != nil {
 		return -1, pkgerrors.Wrap(err, "Error in making AppContext")
 	}
 	// END : Make app context
 
 	// BEGIN : callScheduler
-	err = callScheduler(ctx, cca.context, cca.ctxval, sourceCtxId, p, ca, v, di)
+	err = callScheduler(ctx, metrics.OperationUpdate, cca.context, cca.ctxval, sourceCtxId, p, ca, v, di)
 	if err != nil {
 		return -1, pkgerrors.Wrap(err, "Error in callScheduler")
 	}
 	// END : callScheduler
 
+	if err := checkClusterQuota(ctx, p, cca.context); err != nil {
+		deleteAppContext(ctx, cca.context)
+		return -1, err
+	}
+
+	if _, err := reviewAppContext(ctx, metrics.OperationUpdate, cca.context, p, ca, v, di, dIGrp.Spec.LogicalCloud); err != nil {
+		deleteAppContext(ctx, cca.context)
+		return -1, err
+	}
+
 	targetCtxId := fmt.Sprintf("%v", cca.ctxval)
 
 	// Update Status Context ID in AppContext
@@ -223,7 +263,7 @@ func (c InstantiationClient) Update(ctx context.Context, p string, ca string, v
 	if err != nil {
 		return -1, err
 	}
-	err = callRsyncUpdate(ctx, sourceCtxId, targetCtxId)
+	err = callRsyncUpdate(ctx, metrics.OperationUpdate, sourceCtxId, targetCtxId)
 	if err != nil {
 		return -1, err
 	}
@@ -281,7 +321,17 @@ DeploymentIntentName and revision.
 This method is responsible for creation and saving of context for saving into etcd
 and ensuring DeploymentIntentGroup is rollback to given revision.
 */
-func (c InstantiationClient) Rollback(ctx context.Context, p string, ca string, v string, di string, rbRev string) error {
+func (c InstantiationClient) Rollback(ctx context.Context, p string, ca string, v string, di string, rbRev string) (err error) {
+	start := time.Now()
+	defer func() {
+		metrics.ObserveLifecycleDuration(metrics.OperationRollback, metrics.PhaseTotal, "", start, err)
+	}()
+	unlock, err := lockDeploymentIntentGroup(ctx, p, ca, v, di)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	log.Info("Rollback API", log.Fields{"project": p, "compositeapp": ca, "version": v, "deploymentintentgroup": di,
 		"rbRev": rbRev})
 
@@ -306,7 +356,7 @@ func (c InstantiationClient) Rollback(ctx context.Context, p string, ca string,
 		return pkgerrors.Wrap(err, "GetMatchingContextIDforRevision error "+rbRev)
 	}
 
-	err = callRsyncUpdate(ctx, sourceCtxId, targetCtxId)
+	err = callRsyncUpdate(ctx, metrics.OperationRollback, sourceCtxId, targetCtxId)
 	if err != nil {
 		return err
 	}
@@ -6,12 +6,15 @@ package module
 import (
 	"context"
 	"fmt"
+	"time"
 
 	rsyncclient "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/grpc/updateappclient"
 	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/metrics"
 )
 
-func callRsyncUpdate(ctx context.Context, FromContextid, ToContextid interface{}) error {
+func callRsyncUpdate(ctx context.Context, operation string, FromContextid, ToContextid interface{}) error {
+	start := time.Now()
 	rsyncInfo, err := queryDBAndSetRsyncInfo(ctx)
 	log.Info("Calling the Rsync ", log.Fields{
 		"RsyncName": rsyncInfo.RsyncName,
@@ -23,6 +26,7 @@ func callRsyncUpdate(ctx context.Context, FromContextid, ToContextid interface{}
 	fromAppContextID := fmt.Sprintf("%v", FromContextid)
 	toAppContextID := fmt.Sprintf("%v", ToContextid)
 	err = rsyncclient.InvokeUpdateApp(ctx, fromAppContextID, toAppContextID)
+	metrics.ObserveLifecycleDuration(operation, metrics.PhaseRsync, "", start, err)
 	if err != nil {
 		return err
 	}
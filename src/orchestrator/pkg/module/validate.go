@@ -0,0 +1,85 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package module
+
+import (
+	"context"
+	"fmt"
+
+	pkgerrors "github.com/pkg/errors"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/grpc/rsyncmgmtclient"
+	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/metrics"
+	mgmtpb "gitlab.com/project-emco/core/emco-base/src/rsync/pkg/grpc/rsyncmgmt"
+)
+
+// Results of the validation of a resource which fail the validation
+const (
+	validationInvalid     = "Invalid"
+	validationUnreachable = "Unreachable"
+)
+
+// ValidationResult is the result of the server side dry run of a resource on its cluster.
+// The resource is empty for the results of a whole cluster.
+type ValidationResult struct {
+	App      string `json:"app"`
+	Cluster  string `json:"cluster"`
+	Resource string `json:"resource,omitempty"`
+	Result   string `json:"result"`
+	Error    string `json:"error,omitempty"`
+}
+
+// ValidationReport is the result of the validation of a deployment intent group
+type ValidationReport struct {
+	Valid   bool               `json:"valid"`
+	Results []ValidationResult `json:"results"`
+}
+
+/*
+Validate takes in projectName, compositeAppName, compositeAppVersion,
+DeploymentIntentName. It renders the resources of the deployment intent group
+as an instantiation would, and rsync sends them to their clusters with a
+server side dry run. Nothing is deployed and the state of the deployment
+intent group is unchanged.
+*/
+func (c InstantiationClient) Validate(ctx context.Context, p string, ca string, v string, di string) (ValidationReport, error) {
+	log.Info(":: Orchestrator Validate ::", log.Fields{"project": p, "composite-app": ca, "composite-app-ver": v, "dep-group": di})
+
+	dIGrp, err := NewDeploymentIntentGroupClient().GetDeploymentIntentGroup(ctx, di, p, ca, v)
+	if err != nil {
+		return ValidationReport{}, pkgerrors.Wrap(err, "DeploymentIntentGroup not found")
+	}
+
+	instantiator := Instantiator{p, ca, v, di, dIGrp}
+	cca, err := instantiator.MakeAppContext(ctx)
+	if err != nil {
+		return ValidationReport{}, pkgerrors.Wrap(err, "Error in making AppContext")
+	}
+	// The AppContext is only used for the validation
+	defer deleteAppContext(ctx, cca.context)
+
+	err = callScheduler(ctx, metrics.OperationValidate, cca.context, cca.ctxval, nil, p, ca, v, di)
+	if err != nil {
+		return ValidationReport{}, pkgerrors.Wrap(err, "Error in callScheduler")
+	}
+
+	resp, err := rsyncmgmtclient.InvokeValidateAppContext(ctx, fmt.Sprintf("%v", cca.ctxval))
+	if err != nil {
+		log.Error("Error validating the AppContext in rsync", log.Fields{"appContext": cca.ctxval, "error": err})
+		return ValidationReport{}, pkgerrors.Wrap(err, "Error validating the resources in rsync")
+	}
+	return validationReport(resp), nil
+}
+
+func validationReport(resp *mgmtpb.ValidateAppContextResponse) ValidationReport {
+	report := ValidationReport{Valid: true, Results: []ValidationResult{}}
+	for _, r := range resp.Results {
+		if r.Result == validationInvalid || r.Result == validationUnreachable {
+			report.Valid = false
+		}
+		report.Results = append(report.Results, ValidationResult{App: r.App, Cluster: r.Cluster, Resource: r.Resource,
+			Result: r.Result, Error: r.Error})
+	}
+	return report
+}
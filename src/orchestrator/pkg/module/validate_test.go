@@ -0,0 +1,36 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package module
+
+import (
+	"testing"
+
+	mgmtpb "gitlab.com/project-emco/core/emco-base/src/rsync/pkg/grpc/rsyncmgmt"
+)
+
+func TestValidationReport(t *testing.T) {
+	tests := []struct {
+		results []*mgmtpb.ValidationResult
+		valid   bool
+	}{
+		{nil, true},
+		{[]*mgmtpb.ValidationResult{
+			{App: "a1", Cluster: "p1+c1", Resource: "d1+Deployment", Result: "Valid"},
+			{App: "a1", Cluster: "p1+c2", Result: "Skipped", Error: "Validation is not supported by the connector of the cluster"},
+		}, true},
+		{[]*mgmtpb.ValidationResult{
+			{App: "a1", Cluster: "p1+c1", Resource: "d1+Deployment", Result: "Valid"},
+			{App: "a1", Cluster: "p1+c1", Resource: "w1+Widget", Result: "Invalid", Error: "no matches for kind \"Widget\""},
+		}, false},
+		{[]*mgmtpb.ValidationResult{
+			{App: "a1", Cluster: "p1+c2", Result: "Unreachable", Error: "connection refused"},
+		}, false},
+	}
+	for _, test := range tests {
+		report := validationReport(&mgmtpb.ValidateAppContextResponse{Results: test.results})
+		if report.Valid != test.valid || len(report.Results) != len(test.results) {
+			t.Errorf("Unexpected report %+v for %v", report, test.results)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+// Package quota checks the quotas of the projects. It only depends on the
+// database, so that the services creating the objects of a project can
+// enforce its quota without the orchestrator modules.
+package quota
+
+import (
+	"context"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/contextdb"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/db"
+	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/state"
+)
+
+// The quota is stored in the document of the project
+const (
+	storeName = "resources"
+	tagQuota  = "quota"
+)
+
+// The keys of the objects counted, matching the keys of the orchestrator modules
+type projectKey struct {
+	ProjectName string `json:"project"`
+}
+
+type compositeAppKey struct {
+	CompositeAppName string `json:"compositeApp"`
+	Version          string `json:"compositeAppVersion"`
+	Project          string `json:"project"`
+}
+
+type deploymentIntentGroupKey struct {
+	Name         string `json:"deploymentIntentGroup"`
+	Project      string `json:"project"`
+	CompositeApp string `json:"compositeApp"`
+	Version      string `json:"compositeAppVersion"`
+}
+
+type logicalCloudKey struct {
+	Project          string `json:"project"`
+	LogicalCloudName string `json:"logicalCloud"`
+}
+
+// ProjectQuota limits the number of objects of a project
+type ProjectQuota struct {
+	Spec ProjectQuotaSpec `json:"spec"`
+}
+
+// ProjectQuotaSpec contains the limits of a project. A limit which is not set is unlimited.
+type ProjectQuotaSpec struct {
+	CompositeApps                    *int `json:"compositeApps,omitempty"`
+	DeploymentIntentGroups           *int `json:"deploymentIntentGroups,omitempty"`
+	AppContexts                      *int `json:"appContexts,omitempty"`
+	LogicalClouds                    *int `json:"logicalClouds,omitempty"`
+	ClustersPerDeploymentIntentGroup *int `json:"clustersPerDeploymentIntentGroup,omitempty"`
+}
+
+// ProjectUsage contains the number of objects of a project
+type ProjectUsage struct {
+	CompositeApps          int `json:"compositeApps"`
+	DeploymentIntentGroups int `json:"deploymentIntentGroups"`
+	AppContexts            int `json:"appContexts"`
+	LogicalClouds          int `json:"logicalClouds"`
+}
+
+// Resource is a kind of object limited by the project quota
+type Resource string
+
+const (
+	CompositeApps          Resource = "composite apps"
+	DeploymentIntentGroups Resource = "deployment intent groups"
+	AppContexts            Resource = "app contexts"
+	LogicalClouds          Resource = "logical clouds"
+)
+
+// Time waited for the lock of a project held by another request, and interval of the attempts
+var (
+	lockTimeout  = 10 * time.Second
+	lockInterval = 100 * time.Millisecond
+)
+
+// Lock serializes the creations of objects in the project by all the services and
+// their replicas, so that two requests can't both pass the check of the quota before
+// one of them creates its object. The lock is held in the context database, bound to
+// the etcd lease of the process. Lock waits for the lock held by another request and
+// returns the function releasing the lock.
+func Lock(ctx context.Context, p string) (func(), error) {
+	name := "quota/" + p
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		unlock, err := contextdb.TryLock(ctx, name)
+		if err == nil {
+			return func() {
+				// the request context may be cancelled once the object is created
+				if err := unlock(context.Background()); err != nil {
+					log.Error("Error unlocking the quota of the project", log.Fields{"project": p, "error": err})
+				}
+			}, nil
+		}
+		if err != contextdb.ErrLocked {
+			return nil, pkgerrors.Wrap(err, "Error locking the quota of project "+p)
+		}
+		if time.Now().After(deadline) {
+			return nil, pkgerrors.Errorf("The quota of project %s is locked by another request", p)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(lockInterval):
+		}
+	}
+}
+
+// GetProjectQuota returns the quota of the project
+func GetProjectQuota(ctx context.Context, p string) (ProjectQuota, error) {
+	value, err := db.DBconn.Find(ctx, storeName, projectKey{ProjectName: p}, tagQuota)
+	if err != nil {
+		return ProjectQuota{}, err
+	}
+	if len(value) == 0 {
+		return ProjectQuota{}, pkgerrors.New("Project not found")
+	}
+	if value[0] == nil {
+		return ProjectQuota{}, pkgerrors.New("ProjectQuota not found")
+	}
+
+	q := ProjectQuota{}
+	if err := db.DBconn.Unmarshal(value[0], &q); err != nil {
+		return ProjectQuota{}, err
+	}
+	return q, nil
+}
+
+// GetProjectUsage counts the objects of the project. The app contexts are the
+// current ones of the deployment intent groups which are deployed.
+func GetProjectUsage(ctx context.Context, p string) (ProjectUsage, error) {
+	u := ProjectUsage{}
+
+	values, err := db.DBconn.Find(ctx, storeName, compositeAppKey{Project: p}, "data")
+	if err != nil {
+		return ProjectUsage{}, pkgerrors.Wrap(err, "Error counting the composite apps")
+	}
+	u.CompositeApps = len(values)
+
+	values, err = db.DBconn.Find(ctx, storeName, deploymentIntentGroupKey{Project: p}, "stateInfo")
+	if err != nil {
+		return ProjectUsage{}, pkgerrors.Wrap(err, "Error counting the deployment intent groups")
+	}
+	u.DeploymentIntentGroups = len(values)
+	for _, value := range values {
+		if value == nil {
+			continue
+		}
+		s := state.StateInfo{}
+		if err := db.DBconn.Unmarshal(value, &s); err != nil {
+			return ProjectUsage{}, err
+		}
+		if deployed(s) {
+			u.AppContexts++
+		}
+	}
+
+	values, err = db.DBconn.Find(ctx, storeName, logicalCloudKey{Project: p}, "data")
+	if err != nil {
+		return ProjectUsage{}, pkgerrors.Wrap(err, "Error counting the logical clouds")
+	}
+	u.LogicalClouds = len(values)
+
+	return u, nil
+}
+
+// deployed returns true if the deployment intent group has a current app context,
+// the previous ones of its updates are not counted
+func deployed(s state.StateInfo) bool {
+	current, err := state.GetCurrentStateFromStateInfo(s)
+	if err != nil {
+		return false
+	}
+	switch current {
+	case state.StateEnum.Instantiated, state.StateEnum.Updated:
+		return state.GetLastContextIdFromStateInfo(s) != ""
+	}
+	return false
+}
+
+// CheckProjectQuota returns an error if one more object of the resource kind
+// would exceed the quota of the project
+func CheckProjectQuota(ctx context.Context, p string, r Resource) error {
+	q, err := GetProjectQuota(ctx, p)
+	if err != nil {
+		// the project has no quota, or the error is reported by the caller
+		return nil
+	}
+
+	var limit *int
+	switch r {
+	case CompositeApps:
+		limit = q.Spec.CompositeApps
+	case DeploymentIntentGroups:
+		limit = q.Spec.DeploymentIntentGroups
+	case AppContexts:
+		limit = q.Spec.AppContexts
+	case LogicalClouds:
+		limit = q.Spec.LogicalClouds
+	}
+	if limit == nil {
+		return nil
+	}
+
+	u, err := GetProjectUsage(ctx, p)
+	if err != nil {
+		return err
+	}
+	used := map[Resource]int{
+		CompositeApps:          u.CompositeApps,
+		DeploymentIntentGroups: u.DeploymentIntentGroups,
+		AppContexts:            u.AppContexts,
+		LogicalClouds:          u.LogicalClouds,
+	}[r]
+	if used >= *limit {
+		return pkgerrors.Errorf("Project quota exceeded: %d of %d %s used in project %s", used, *limit, r, p)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package quota
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/contextdb"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/db"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/state"
+)
+
+func TestAppContextUsage(t *testing.T) {
+	ctx := context.Background()
+	savedDb := db.DBconn
+	defer func() { db.DBconn = savedDb }()
+
+	var err error
+	db.DBconn, err = db.NewEmbeddedStore(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.DBconn.Insert(ctx, storeName, projectKey{ProjectName: "p1"}, nil, "metadata", struct{}{}); err != nil {
+		t.Fatal(err)
+	}
+
+	created := state.ActionEntry{State: state.StateEnum.Created}
+	digs := []struct {
+		name    string
+		actions []state.ActionEntry
+	}{
+		{"created", []state.ActionEntry{created}},
+		{"instantiated", []state.ActionEntry{created, {State: state.StateEnum.Instantiated, ContextId: "1"}}},
+		// Only the context of the last update is current
+		{"updated", []state.ActionEntry{created, {State: state.StateEnum.Instantiated, ContextId: "2"},
+			{State: state.StateEnum.Updated, ContextId: "3"}, {State: state.StateEnum.Updated, ContextId: "4"}}},
+		{"terminated", []state.ActionEntry{created, {State: state.StateEnum.Instantiated, ContextId: "5"},
+			{State: state.StateEnum.Terminated, ContextId: "5"}}},
+	}
+	for _, d := range digs {
+		key := deploymentIntentGroupKey{Name: d.name, Project: "p1", CompositeApp: "ca", Version: "v1"}
+		if err := db.DBconn.Insert(ctx, storeName, key, nil, "stateInfo", state.StateInfo{Actions: d.actions}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	u, err := GetProjectUsage(ctx, "p1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.DeploymentIntentGroups != 4 || u.AppContexts != 2 {
+		t.Errorf("Unexpected usage %+v", u)
+	}
+
+	two := 2
+	q := ProjectQuota{Spec: ProjectQuotaSpec{AppContexts: &two}}
+	if err := db.DBconn.Insert(ctx, storeName, projectKey{ProjectName: "p1"}, nil, tagQuota, q); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckProjectQuota(ctx, "p1", AppContexts); err == nil || !strings.Contains(err.Error(), "Project quota exceeded") {
+		t.Errorf("Expected the quota of app contexts to be exceeded, got %v", err)
+	}
+	if err := CheckProjectQuota(ctx, "p1", DeploymentIntentGroups); err != nil {
+		t.Errorf("The deployment intent groups are not limited: %s", err)
+	}
+}
+
+func TestLock(t *testing.T) {
+	ctx := context.Background()
+	savedDb := contextdb.Db
+	defer func() { contextdb.Db = savedDb }()
+	defer func(timeout, interval time.Duration) { lockTimeout, lockInterval = timeout, interval }(lockTimeout, lockInterval)
+	lockTimeout, lockInterval = 50*time.Millisecond, time.Millisecond
+
+	var err error
+	contextdb.Db, err = contextdb.NewEmbeddedContextDb("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	unlock, err := Lock(ctx, "p1")
+	if err != nil {
+		t.Fatalf("Lock returned an error: %s", err)
+	}
+	if _, err := Lock(ctx, "p1"); err == nil || !strings.Contains(err.Error(), "is locked by another request") {
+		t.Errorf("Lock of a locked project returned %v", err)
+	}
+	// The lock of another project is independent
+	unlock2, err := Lock(ctx, "p2")
+	if err != nil {
+		t.Fatalf("Lock of another project returned an error: %s", err)
+	}
+	unlock2()
+
+	// A request waits for the lock released by another one
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		unlock()
+	}()
+	unlock, err = Lock(ctx, "p1")
+	if err != nil {
+		t.Fatalf("Lock did not wait for the lock to be released: %s", err)
+	}
+	unlock()
+}
@@ -7,6 +7,11 @@ package resourcestatus
 // that rsync is synchronizing to clusters
 type ResourceStatus struct {
 	Status RsyncStatus
+	// Retries of the last operation on the resource, and the error of the last attempt which failed
+	RetryCount int    `json:",omitempty"`
+	LastError  string `json:",omitempty"`
+	// The resource was not applied, it is unchanged since it was last applied
+	Skipped bool `json:",omitempty"`
 }
 
 type RsyncStatus = string
@@ -497,7 +497,7 @@ func getResourceFromReference(ctx context.Context, ac, sac appcontext.AppContext
 }
 
 // getAppContextResources collects the resource status of all resources in an AppContext subject to the filter parameters
-func getAppContextResources(ctx context.Context, ac, sac appcontext.AppContext, ch interface{}, qOutput, qType string, fResources []string, resourceList *[]ResourceStatus, statusCnts map[string]int, clusterStatusCnts map[string]int, app, cluster string) (int, error) {
+func getAppContextResources(ctx context.Context, ac, sac appcontext.AppContext, ch interface{}, qOutput, qType string, fResources []string, resourceList *[]ResourceStatus, statusCnts map[string]int, clusterStatusCnts map[string]int, driftedCnts map[string]int, skippedCnt *int, app, cluster string) (int, error) {
 	count := 0
 
 	// Get all Resources for the Cluster
@@ -561,6 +561,12 @@ func getAppContextResources(ctx context.Context, ac, sac appcontext.AppContext,
 			statusCnts[rstatus.Status] = cnt + 1
 		} else if qType == "deployed" {
 			r.DeployedStatus = fmt.Sprintf("%v", rstatus.Status)
+			r.RetryCount = rstatus.RetryCount
+			r.LastError = rstatus.LastError
+			r.Skipped = rstatus.Skipped
+			if rstatus.Skipped {
+				*skippedCnt++
+			}
 			cnt := statusCnts[rstatus.Status]
 			statusCnts[rstatus.Status] = cnt + 1
 		} else if qType == "ready" && markAsNotPresent(r.Gvk) {
@@ -570,6 +576,9 @@ func getAppContextResources(ctx context.Context, ac, sac appcontext.AppContext,
 			r.ClusterStatus = "NotPresent"
 			updateNotPresentCount(true, clusterStatusCnts)
 		}
+		if qType == "ready" {
+			r.DriftedStatus = getResourceDriftedStatus(ctx, sac, statusH, driftedCnts)
+		}
 		*resourceList = append(*resourceList, r)
 		count++
 	}
@@ -577,6 +586,55 @@ func getAppContextResources(ctx context.Context, ac, sac appcontext.AppContext,
 	return count, nil
 }
 
+// getResourceDriftedStatus returns the drift status recorded by rsync for a resource in the status AppContext.
+// An empty string is returned if drift has not been evaluated for the resource.
+func getResourceDriftedStatus(ctx context.Context, sac appcontext.AppContext, statusH interface{}, driftedCnts map[string]int) string {
+	dh, err := sac.GetLevelHandle(ctx, statusH, "resdrifted")
+	if err != nil {
+		return ""
+	}
+	v, err := sac.GetValue(ctx, dh)
+	if err != nil {
+		return ""
+	}
+	drifted, ok := v.(bool)
+	if !ok {
+		return ""
+	}
+	if drifted {
+		driftedCnts["Drifted"]++
+		return "Drifted"
+	}
+	driftedCnts["InSync"]++
+	return "InSync"
+}
+
+// FilterDriftedResources removes the resources which have not drifted from the desired state,
+// as well as the clusters and apps which are left without any resources
+func FilterDriftedResources(statusResult *StatusResult) {
+	apps := make([]AppStatus, 0)
+	for _, a := range statusResult.Apps {
+		clusters := make([]ClusterStatus, 0)
+		for _, c := range a.Clusters {
+			resources := make([]ResourceStatus, 0)
+			for _, r := range c.Resources {
+				if r.DriftedStatus == "Drifted" {
+					resources = append(resources, r)
+				}
+			}
+			if len(resources) > 0 {
+				c.Resources = resources
+				clusters = append(clusters, c)
+			}
+		}
+		if len(clusters) > 0 {
+			a.Clusters = clusters
+			apps = append(apps, a)
+		}
+	}
+	statusResult.Apps = apps
+}
+
 // getListOfApps gets the list of apps from the app context
 func getListOfApps(ctx context.Context, ac appcontext.AppContext) []string {
 	ch, err := ac.GetCompositeAppHandle(ctx)
@@ -772,6 +830,8 @@ func prepareStatusResult(ctx context.Context, statusType string, stateInfo state
 
 	rsyncStatusCnts := make(map[string]int)
 	clusterStatusCnts := make(map[string]int)
+	driftedStatusCnts := make(map[string]int)
+	skippedCnt := 0
 
 	// Get the list of apps from the app context
 	apps := getListOfApps(ctx, ac)
@@ -833,7 +893,7 @@ func prepareStatusResult(ctx context.Context, statusType string, stateInfo state
 			}
 
 			clusterStatus.Resources = make([]ResourceStatus, 0)
-			cnt, err := getAppContextResources(ctx, ac, sac, ch, qOutput, qType, fResources, &clusterStatus.Resources, rsyncStatusCnts, clusterStatusCnts, app, cluster)
+			cnt, err := getAppContextResources(ctx, ac, sac, ch, qOutput, qType, fResources, &clusterStatus.Resources, rsyncStatusCnts, clusterStatusCnts, driftedStatusCnts, &skippedCnt, app, cluster)
 			if err != nil {
 				log.Info(":: Error gathering appcontext resources for cluster, app ::",
 					log.Fields{"Cluster": cluster, "AppName": app, "Error": err})
@@ -879,6 +939,10 @@ func prepareStatusResult(ctx context.Context, statusType string, stateInfo state
 	} else {
 		statusResult.DeployedCounts = rsyncStatusCnts
 		statusResult.ReadyCounts = clusterStatusCnts
+		if len(driftedStatusCnts) > 0 {
+			statusResult.DriftedCounts = driftedStatusCnts
+		}
+		statusResult.SkippedCount = skippedCnt
 	}
 
 	if cnt, ok := clusterStatusCnts["NotPresent"]; ok && cnt > 0 {
@@ -1132,7 +1196,7 @@ func prepareResourcesByAppStatusResult(ctx context.Context, ac, sac appcontext.A
 
 			resources := make([]ResourceStatus, 0)
 			// Get all resources from the appcontext for the given app/cluster
-			_, err = getAppContextResources(ctx, ac, sac, ch, "all", qType, make([]string, 0), &resources, rsyncStatusCnts, clusterStatusCnts, app, cluster)
+			_, err = getAppContextResources(ctx, ac, sac, ch, "all", qType, make([]string, 0), &resources, rsyncStatusCnts, clusterStatusCnts, make(map[string]int), new(int), app, cluster)
 			if err != nil {
 				log.Info(":: Error gathering appcontext resources for cluster, app ::",
 					log.Fields{"Cluster": cluster, "AppName": app, "Error": err})
@@ -23,6 +23,7 @@ type queryparams struct {
 	Apps      StatusQueryParam // show all apps in the appcontext
 	Clusters  StatusQueryParam // show all clusters in the appcontext, filter by 'app' (e.g. clusters for an app)
 	Resources StatusQueryParam // show all resources for an app, filter by 'app' (e.g. resources for an app)
+	Drifted   StatusQueryParam // filter results to the resources that drifted from the desired state
 }
 
 // StatusQueryEnum defines the set of valid query parameter strings
@@ -38,6 +39,7 @@ var StatusQueryEnum = &queryparams{
 	Apps:      "apps",
 	Clusters:  "clusters",
 	Resources: "resources",
+	Drifted:   "drifted",
 }
 
 // CaCertStatusResult holds the caCert enrollment or distribution status details
@@ -74,6 +76,8 @@ type StatusResult struct {
 	ClusterStatus   map[string]int         `json:"clusterStatus,omitempty,inline"` // deprecated
 	DeployedCounts  map[string]int         `json:"deployedCounts,omitempty,inline"`
 	ReadyCounts     map[string]int         `json:"readyCounts,omitempty,inline"`
+	DriftedCounts   map[string]int         `json:"driftedCounts,omitempty,inline"`
+	SkippedCount    int                    `json:"skippedCount,omitempty,inline"`
 	Apps            []AppStatus            `json:"apps,omitempty,inline"`
 	ChildContextIDs []string               `json:"ChildContextIDs,omitempty,inline"`
 }
@@ -124,6 +128,10 @@ type ResourceStatus struct {
 	ClusterStatus  string                  `json:"clusterStatus,omitempty"` // deprecated - to be replaced with ReadyStatus
 	DeployedStatus string                  `json:"deployedStatus,omitempty"`
 	ReadyStatus    string                  `json:"readyStatus,omitempty"`
+	DriftedStatus  string                  `json:"driftedStatus,omitempty"`
+	RetryCount     int                     `json:"retryCount,omitempty"`
+	LastError      string                  `json:"lastError,omitempty"`
+	Skipped        bool                    `json:"skipped,omitempty"`
 }
 
 // AppsListResult returns a list of Apps for the given AppContext
@@ -175,5 +175,5 @@ func (d digHelpers) PrepareStatusNotification(reg *statusnotifypb.StatusRegistra
 }
 
 func StartStatusNotifyServer() *statusnotifyserver.StatusNotifyServer {
-	return statusnotifyserver.NewStatusNotifyServer("digStatus", digHelpers{})
+	return statusnotifyserver.NewStatusNotifyServer("orchestrator", "digStatus", digHelpers{})
 }
@@ -15,6 +15,7 @@ import (
 	"strings"
 
 	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chart/loader"
 	"helm.sh/helm/v3/pkg/cli"
 	helmOptions "helm.sh/helm/v3/pkg/cli/values"
@@ -325,6 +326,21 @@ func (h *TemplateClient) Resolve(appContent []byte, appProfileContent []byte, ov
 	return sortedTemplates, hookList, nil
 }
 
+// ChartMetadata returns the metadata of the chart of the app in the app content
+func ChartMetadata(appContent []byte, appName string) (*chart.Metadata, error) {
+	chartBasePath, err := utils.ExtractTarBall(bytes.NewBuffer(appContent))
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "Error while extracting appContent")
+	}
+	defer cleanupTempFiles(chartBasePath)
+	c, err := loader.Load(filepath.Join(chartBasePath, appName))
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "Error while loading the chart")
+	}
+	return c.Metadata, nil
+}
+
+// GetHooksByEvent groups the hooks by event, in the order of their weights
 func GetHooksByEvent(hs []*Hook) (map[string][]*Hook, error) {
 	resources := make(map[string][]*Hook)
 	for _, h := range hs {
@@ -332,9 +348,27 @@ func GetHooksByEvent(hs []*Hook) (map[string][]*Hook, error) {
 			resources[e.String()] = append(resources[e.String()], h)
 		}
 	}
+	for _, hooks := range resources {
+		sort.SliceStable(hooks, func(i, j int) bool {
+			return hookByWeight{&hooks[i].Hook, &hooks[j].Hook}.Less(0, 1)
+		})
+	}
 	return resources, nil
 }
 
+// DeletePolicies returns the delete policies of the hook. Like Helm, the hook is
+// deleted before it is created again when it has no delete policy.
+func (h Hook) DeletePolicies() []string {
+	if len(h.Hook.DeletePolicies) == 0 {
+		return []string{release.HookBeforeHookCreation.String()}
+	}
+	var policies []string
+	for _, p := range h.Hook.DeletePolicies {
+		policies = append(policies, p.String())
+	}
+	return policies
+}
+
 // Copied from https://github.com/helm/helm/blob/a499b4b179307c267bdf3ec49b880e3dbd2a5591/pkg/action/hooks.go#L110
 
 type hookByWeight []*release.Hook
@@ -4,13 +4,18 @@
 package helm
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
 	"crypto/sha256"
 	"fmt"
+	"os"
 
 	"io/ioutil"
 	"path/filepath"
 	"strings"
 	"gopkg.in/yaml.v2"
+	"helm.sh/helm/v3/pkg/release"
 
 	"testing"
 )
@@ -265,3 +270,82 @@ func TestGenerateKubernetesArtifacts(t *testing.T) {
 	}
 }
 
+
+func TestGetHooksByEvent(t *testing.T) {
+	newHook := func(name string, weight int, events ...release.HookEvent) *Hook {
+		return &Hook{Hook: release.Hook{Name: name, Weight: weight, Events: events}}
+	}
+	hooks := []*Hook{
+		newHook("migrate", 5, release.HookPreInstall, release.HookPreUpgrade),
+		newHook("secret", -5, release.HookPreUpgrade),
+		newHook("config", 0, release.HookPreUpgrade),
+		newHook("backup", 0, release.HookPreUpgrade),
+	}
+	hooks[0].Hook.DeletePolicies = []release.HookDeletePolicy{release.HookSucceeded, release.HookFailed}
+
+	r, err := GetHooksByEvent(hooks)
+	if err != nil {
+		t.Fatalf("Got an error %s", err)
+	}
+	var names []string
+	for _, h := range r["pre-upgrade"] {
+		names = append(names, h.Hook.Name)
+	}
+	if strings.Join(names, ",") != "secret,backup,config,migrate" {
+		t.Errorf("Unexpected pre-upgrade hook order %v", names)
+	}
+	if len(r["pre-install"]) != 1 {
+		t.Errorf("Unexpected pre-install hooks %v", r["pre-install"])
+	}
+	if p := hooks[0].DeletePolicies(); strings.Join(p, ",") != "hook-succeeded,hook-failed" {
+		t.Errorf("Unexpected delete policies %v", p)
+	}
+	if p := hooks[1].DeletePolicies(); strings.Join(p, ",") != "before-hook-creation" {
+		t.Errorf("Unexpected default delete policies %v", p)
+	}
+}
+
+// chartTarBall returns the gzipped tar of the chart directory, as uploaded in the app content
+func chartTarBall(t *testing.T, dir, name string) []byte {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if err := tw.WriteHeader(&tar.Header{Name: filepath.Join(name, rel), Mode: 0600, Size: int64(len(b)), Typeflag: tar.TypeReg}); err != nil {
+			return err
+		}
+		_, err = tw.Write(b)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Error creating the chart tarball %s", err)
+	}
+	tw.Close()
+	gw.Close()
+	return buf.Bytes()
+}
+
+func TestChartMetadata(t *testing.T) {
+	content := chartTarBall(t, "../../mock_files/mock_charts/testchart2", "testchart2")
+	m, err := ChartMetadata(content, "testchart2")
+	if err != nil {
+		t.Fatalf("Got an error %s", err)
+	}
+	if m.Name != "testchart2" || m.Version != "0.1.0" {
+		t.Errorf("Unexpected chart metadata %v", m)
+	}
+	if _, err := ChartMetadata(content, "missing"); err == nil {
+		t.Errorf("Expected an error for a missing chart")
+	}
+	if _, err := ChartMetadata([]byte("invalid"), "testchart2"); err == nil {
+		t.Errorf("Expected an error for an invalid app content")
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"os/signal"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	register "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/grpc"
 	contextDb "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/contextdb"
 	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/db"
@@ -19,8 +20,13 @@ import (
 	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/grpc/installappserver"
 	readynotifypb "gitlab.com/project-emco/core/emco-base/src/rsync/pkg/grpc/readynotify"
 	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/grpc/readynotifyserver"
+	rsyncmgmtpb "gitlab.com/project-emco/core/emco-base/src/rsync/pkg/grpc/rsyncmgmt"
+	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/grpc/rsyncmgmtserver"
 	updatepb "gitlab.com/project-emco/core/emco-base/src/rsync/pkg/grpc/updateapp"
 	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/grpc/updateappserver"
+	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/metrics"
+	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/plugins/grpcplugin"
+	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/status"
 
 	con "gitlab.com/project-emco/core/emco-base/src/rsync/pkg/context"
 	"google.golang.org/grpc"
@@ -30,6 +36,7 @@ func RegisterRsyncServices(grpcServer *grpc.Server, srv interface{}) {
 	installpb.RegisterInstallappServer(grpcServer, installappserver.NewInstallAppServer())
 	readynotifypb.RegisterReadyNotifyServer(grpcServer, readynotifyserver.NewReadyNotifyServer())
 	updatepb.RegisterUpdateappServer(grpcServer, updateappserver.NewUpdateAppServer())
+	rsyncmgmtpb.RegisterRsyncmgmtServer(grpcServer, rsyncmgmtserver.NewRsyncmgmtServer())
 }
 
 func main() {
@@ -55,6 +62,8 @@ func main() {
 		os.Exit(1)
 	}
 
+	prometheus.MustRegister(metrics.Collectors()...)
+
 	server, err := controller.NewControllerServer("rsync",
 		nil,
 		grpcServer)
@@ -63,10 +72,21 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Re-apply resources which drifted from the desired state
+	status.RegisterDriftRemediator(con.RemediateDrift)
+
+	// Connect to the connector plugins before the contexts are restored
+	grpcplugin.Start(ctx)
+
 	err = con.RestoreActiveContext(ctx)
 	if err != nil {
 		log.Error("RestoreActiveContext failed", log.Fields{"Error": err})
 	}
+	// Resume delivering the operations pending on offline clusters
+	err = con.RestorePendingOps(ctx)
+	if err != nil {
+		log.Error("RestorePendingOps failed", log.Fields{"Error": err})
+	}
 
 	connectionsClose := make(chan struct{})
 	go func() {
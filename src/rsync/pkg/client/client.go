@@ -11,6 +11,7 @@ import (
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/resource"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
@@ -193,7 +194,7 @@ func failedTo(action string, info *resource.Info, err error) error {
 		resKind = info.Mapping.GroupVersionKind.Kind + " "
 	}
 
-	return fmt.Errorf("cannot %s object Kind: %q,	Name: %q, Namespace: %q. %s", action, resKind, info.Name, info.Namespace, err)
+	return fmt.Errorf("cannot %s object Kind: %q,	Name: %q, Namespace: %q. %w", action, resKind, info.Name, info.Namespace, err)
 }
 
 // IsReachable tests connectivity to the cluster
@@ -209,6 +210,12 @@ func (c *Client) IsReachable() error {
 	return nil
 }
 
+// RESTClientGetter returns the getter of the REST clients of the cluster, for
+// the libraries like Helm which create their own clients
+func (c *Client) RESTClientGetter() genericclioptions.RESTClientGetter {
+	return c.factory
+}
+
 // PopulateResourceListV1WithDefValues takes strings of form <resourceName1>=<value1>,<resourceName1>=<value2>
 // and returns ResourceList.
 func PopulateResourceListV1WithDefValues(spec string) (v1.ResourceList, error) {
@@ -0,0 +1,49 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package client
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+// dryRunFieldManager is the field manager of the server side dry runs
+const dryRunFieldManager = "emco-validate"
+
+// DryRun sends the resource with the given content to the cluster with a
+// server side dry run. The admission and validation errors are returned but
+// nothing is changed in the cluster.
+func (c *Client) DryRun(content []byte) error {
+	r := c.ResultForContent(content, nil)
+	if err := r.Err(); err != nil {
+		return err
+	}
+	return r.Visit(dryRun)
+}
+
+func dryRun(info *resource.Info, err error) error {
+	if err != nil {
+		return failedTo("validate", info, err)
+	}
+
+	data, err := runtime.Encode(unstructured.UnstructuredJSONScheme, info.Object)
+	if err != nil {
+		return failedTo("encode for the validation", info, err)
+	}
+
+	// A server side apply creates or updates the resource, the same way rsync would apply it
+	force := true
+	options := metav1.PatchOptions{Force: &force}
+	_, err = resource.NewHelper(info.Client, info.Mapping).
+		DryRun(true).
+		WithFieldManager(dryRunFieldManager).
+		Patch(info.Namespace, info.Name, types.ApplyPatchType, data, &options)
+	if err != nil {
+		return failedTo("validate", info, err)
+	}
+	return nil
+}
@@ -98,6 +98,7 @@ func (f *factory) ToRESTConfig() (*rest.Config, error) {
 	}
 
 	rest.SetKubernetesDefaults(config)
+	SetRateLimits(config)
 	return config, nil
 }
 
@@ -134,7 +135,10 @@ func (f *factory) ToDiscoveryClient() (discovery.CachedDiscoveryInterface, error
 	if err != nil {
 		return nil, err
 	}
-	factory.Burst = 100
+	// Discovery bursts through the API groups, but stays within the configured burst
+	if factory.Burst < 100 {
+		factory.Burst = 100
+	}
 
 	return diskcached.NewCachedDiscoveryClientForConfig(factory, os.TempDir(), "", time.Duration(10*time.Minute))
 }
@@ -58,7 +58,8 @@ func (c *Client) Get(ctx context.Context, gvkRes []byte, namespace string) ([]by
 		return nil, fmt.Errorf("RESTScopeName for GVK failed %v, %s", err, g.Gvk.String())
 	}
 	if err != nil {
-		return nil, fmt.Errorf("Getting getting RESTScopeName %v", err)
+		// Wrapped so that the callers can tell a resource which is not found
+		return nil, fmt.Errorf("Getting getting RESTScopeName %w", err)
 	}
 
 	b, err := unstruct.MarshalJSON()
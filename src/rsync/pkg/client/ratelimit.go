@@ -0,0 +1,33 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package client
+
+import (
+	"strconv"
+
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/config"
+	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+	"k8s.io/client-go/rest"
+)
+
+// SetRateLimits sets the QPS and burst of the requests to a cluster from the
+// configuration. The client-go defaults are kept if they are not set.
+func SetRateLimits(c *rest.Config) {
+	if s := config.GetConfiguration().ClusterQPS; s != "" {
+		qps, err := strconv.ParseFloat(s, 32)
+		if err != nil || qps <= 0 {
+			log.Warn("Invalid cluster QPS, using the default", log.Fields{"qps": s})
+		} else {
+			c.QPS = float32(qps)
+		}
+	}
+	if s := config.GetConfiguration().ClusterBurst; s != "" {
+		burst, err := strconv.Atoi(s)
+		if err != nil || burst <= 0 {
+			log.Warn("Invalid cluster burst, using the default", log.Fields{"burst": s})
+		} else {
+			c.Burst = burst
+		}
+	}
+}
@@ -11,12 +11,15 @@ import (
 
 	pkgerrors "github.com/pkg/errors"
 	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/db"
 	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/internal/utils"
 	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/plugins/anthos"
 	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/plugins/azurearcv2"
 	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/plugins/fluxv2"
+	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/plugins/grpcplugin"
 	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/plugins/k8s"
 	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/plugins/k8sexp"
+	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/plugins/sim"
 	. "gitlab.com/project-emco/core/emco-base/src/rsync/pkg/types"
 )
 
@@ -31,6 +34,24 @@ func NewProvider(id interface{}) Provider {
 	}
 }
 
+// HasClusterLabel returns true if the cluster is tagged with the label
+func (p *Provider) HasClusterLabel(ctx context.Context, cluster, label string) (bool, error) {
+	result := strings.SplitN(cluster, "+", 2)
+	if len(result) != 2 {
+		return false, pkgerrors.New("Invalid cluster name format")
+	}
+	return db.NewCloudConfigClient().HasClusterLabel(ctx, result[0], result[1], label)
+}
+
+// GetClusterKvPairs returns the key value pairs of the cluster, nil if they don't exist
+func (p *Provider) GetClusterKvPairs(ctx context.Context, cluster, kvpair string) ([]map[string]interface{}, error) {
+	result := strings.SplitN(cluster, "+", 2)
+	if len(result) != 2 {
+		return nil, pkgerrors.New("Invalid cluster name format")
+	}
+	return db.NewCloudConfigClient().GetClusterKvPairs(ctx, result[0], result[1], kvpair)
+}
+
 func (p *Provider) GetClientProviders(ctx context.Context, app, cluster, level, namespace string) (ClientProvider, error) {
 	// Default Provider type
 	var providerType string = "k8s"
@@ -49,12 +70,22 @@ func (p *Provider) GetClientProviders(ctx context.Context, app, cluster, level,
 
 	if len(kc) > 0 {
 		providerType = "k8s"
+		// A cluster with a kubeconfig may be deployed by a connector plugin
+		if grpcplugin.Enabled() {
+			c, err := utils.GetGitOpsConfig(ctx, cluster, "0", "default")
+			if err == nil && c.Props.DeploymentTarget != "" {
+				providerType = c.Props.DeploymentTarget
+			}
+		}
 	} else {
 		c, err := utils.GetGitOpsConfig(ctx, cluster, level, namespace)
 		if err != nil {
 			return nil, err
 		}
 		providerType = c.Props.GitOpsType
+		if c.Props.DeploymentTarget != "" {
+			providerType = c.Props.DeploymentTarget
+		}
 		if providerType == "" {
 			return nil, pkgerrors.New("No provider type specified")
 		}
@@ -74,7 +105,7 @@ func (p *Provider) GetClientProviders(ctx context.Context, app, cluster, level,
 		if err != nil {
 			return nil, err
 		}
-		return cl, nil
+		return instrument(cl, cluster, providerType), nil
 		// This case is unused at this time.
 		// In the above case K8s plugin each resource is written
 		// to the cluster individually. The disadvantage is
@@ -90,26 +121,38 @@ func (p *Provider) GetClientProviders(ctx context.Context, app, cluster, level,
 		if err != nil {
 			return nil, err
 		}
-		return cl, nil
+		return instrument(cl, cluster, providerType), nil
 
 	case "fluxcd":
 		cl, err := fluxv2.NewFluxv2Provider(ctx, p.cid, app, cluster, level, namespace)
 		if err != nil {
 			return nil, err
 		}
-		return cl, nil
+		return instrument(cl, cluster, providerType), nil
 	case "azureArcV2":
 		cl, err := azurearcv2.NewAzureArcProvider(ctx, p.cid, app, cluster, level, namespace)
 		if err != nil {
 			return nil, err
 		}
-		return cl, nil
+		return instrument(cl, cluster, providerType), nil
 	case "anthos":
 		cl, err := anthos.NewAnthosProvider(ctx, p.cid, app, cluster, level, namespace)
 		if err != nil {
 			return nil, err
 		}
-		return cl, nil
+		return instrument(cl, cluster, providerType), nil
+	case sim.SimTarget:
+		cl, err := sim.NewSimProvider(ctx, p.cid, app, cluster, level, namespace)
+		if err != nil {
+			return nil, err
+		}
+		return instrument(cl, cluster, providerType), nil
+	default:
+		// Deployment targets served by out-of-process connector plugins
+		cl, err := grpcplugin.NewGrpcProvider(ctx, p.cid, app, cluster, level, namespace, providerType)
+		if err != nil {
+			return nil, err
+		}
+		return instrument(cl, cluster, providerType), nil
 	}
-	return nil, pkgerrors.New("Provider type not supported")
 }
@@ -0,0 +1,52 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package connector
+
+import (
+	"context"
+
+	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/metrics"
+	. "gitlab.com/project-emco/core/emco-base/src/rsync/pkg/types"
+)
+
+// instrumentedProvider counts the resource operations of a plugin
+type instrumentedProvider struct {
+	ClientProvider
+	cluster string
+	plugin  string
+}
+
+// The optional interfaces of the plugin are found through the wrapper
+var _ WrappedProvider = &instrumentedProvider{}
+
+func instrument(cl ClientProvider, cluster, plugin string) ClientProvider {
+	return &instrumentedProvider{ClientProvider: cl, cluster: cluster, plugin: plugin}
+}
+
+// Unwrap returns the client provider of the plugin, for its optional interfaces
+func (p *instrumentedProvider) Unwrap() ClientProvider {
+	return p.ClientProvider
+}
+
+func (p *instrumentedProvider) count(operation string, err error) {
+	metrics.ResourceOperations.WithLabelValues(operation, metrics.Result(err), p.cluster, p.plugin).Inc()
+}
+
+func (p *instrumentedProvider) Create(name string, ref interface{}, content []byte) (interface{}, error) {
+	q, err := p.ClientProvider.Create(name, ref, content)
+	p.count("create", err)
+	return q, err
+}
+
+func (p *instrumentedProvider) Apply(ctx context.Context, name string, ref interface{}, content []byte) (interface{}, error) {
+	q, err := p.ClientProvider.Apply(ctx, name, ref, content)
+	p.count("apply", err)
+	return q, err
+}
+
+func (p *instrumentedProvider) Delete(name string, ref interface{}, content []byte) (interface{}, error) {
+	q, err := p.ClientProvider.Delete(name, ref, content)
+	p.count("delete", err)
+	return q, err
+}
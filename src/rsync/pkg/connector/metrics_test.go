@@ -0,0 +1,118 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package connector
+
+import (
+	"context"
+	"testing"
+
+	pkgerrors "github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/appcontext"
+	mtypes "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/module/types"
+	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/internal/utils"
+	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/metrics"
+	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/plugins/sim"
+	. "gitlab.com/project-emco/core/emco-base/src/rsync/pkg/types"
+)
+
+const configMap = `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm1
+`
+
+// countingProvider is a client provider whose applies fail for the failed resources
+type countingProvider struct {
+	ClientProvider
+	failed string
+}
+
+func (p *countingProvider) Apply(ctx context.Context, name string, ref interface{}, content []byte) (interface{}, error) {
+	if name == p.failed {
+		return ref, pkgerrors.New("apply failed")
+	}
+	return ref, nil
+}
+
+func (p *countingProvider) Delete(name string, ref interface{}, content []byte) (interface{}, error) {
+	return ref, nil
+}
+
+// releaseProvider is a client provider deploying the apps as Helm releases
+type releaseProvider struct {
+	ClientProvider
+	installed bool
+}
+
+func (p *releaseProvider) InstallRelease(ctx context.Context, release appcontext.HelmRelease, resources [][]byte) error {
+	p.installed = true
+	return nil
+}
+
+func (p *releaseProvider) UninstallRelease(ctx context.Context) error {
+	return nil
+}
+
+func TestDryRunProvider(t *testing.T) {
+	savedKubeConfig, savedGitOpsConfig := utils.GetKubeConfig, utils.GetGitOpsConfig
+	defer func() { utils.GetKubeConfig, utils.GetGitOpsConfig = savedKubeConfig, savedGitOpsConfig }()
+	// The simulated cluster has no kubeconfig
+	utils.GetKubeConfig = func(ctx context.Context, clustername string, level string, namespace string) ([]byte, error) {
+		return nil, nil
+	}
+	utils.GetGitOpsConfig = func(ctx context.Context, clustername string, level string, namespace string) (mtypes.GitOpsSpec, error) {
+		return mtypes.GitOpsSpec{Props: mtypes.GitOpsProps{GitOpsType: sim.SimTarget}}, nil
+	}
+	p := NewProvider("1234")
+	cl, err := p.GetClientProviders(context.Background(), "app1", "provider1+dryrun", "0", "")
+	if err != nil {
+		t.Fatalf("GetClientProviders failed: %v", err)
+	}
+	defer cl.CleanClientProvider()
+	// The dry run of the plugin is found through the metrics of the connector
+	v, ok := AsDryRunProvider(cl)
+	if !ok {
+		t.Fatal("Dry run of the simulated cluster not found")
+	}
+	if err := v.DryRun(context.Background(), "cm1+ConfigMap", []byte(configMap)); err != nil {
+		t.Errorf("DryRun failed: %v", err)
+	}
+	if _, ok := AsReleaseProvider(cl); ok {
+		t.Error("Unexpected Helm releases on the simulated cluster")
+	}
+}
+
+func TestInstrumentedProvider(t *testing.T) {
+	cl := instrument(&countingProvider{failed: "r2"}, "provider1+metrics", "k8s")
+	count := func(operation, result string) float64 {
+		return testutil.ToFloat64(metrics.ResourceOperations.WithLabelValues(operation, result, "provider1+metrics", "k8s"))
+	}
+	cl.Apply(context.Background(), "r1", nil, nil)
+	cl.Apply(context.Background(), "r2", nil, nil)
+	cl.Delete("r1", nil, nil)
+	if count("apply", "success") != 1 || count("apply", "failure") != 1 || count("delete", "success") != 1 {
+		t.Errorf("Unexpected resource operations: apply %v/%v, delete %v",
+			count("apply", "success"), count("apply", "failure"), count("delete", "success"))
+	}
+	if _, ok := cl.(WrappedProvider); !ok {
+		t.Error("The client provider of the plugin is hidden by the metrics wrapper")
+	}
+}
+
+func TestReleaseProvider(t *testing.T) {
+	rp := &releaseProvider{}
+	cl := instrument(rp, "provider1+release", "k8s")
+	v, ok := AsReleaseProvider(cl)
+	if !ok {
+		t.Fatal("Helm releases of the plugin not found")
+	}
+	if err := v.InstallRelease(context.Background(), appcontext.HelmRelease{}, nil); err != nil || !rp.installed {
+		t.Errorf("Release not installed by the plugin: %v", err)
+	}
+	if _, ok := AsDryRunProvider(cl); ok {
+		t.Error("Unexpected dry run of the plugin")
+	}
+}
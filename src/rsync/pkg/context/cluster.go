@@ -5,6 +5,8 @@ package context
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"strings"
 	"time"
 
@@ -12,6 +14,7 @@ import (
 	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/appcontext"
 	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
 	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/resourcestatus"
+	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/metrics"
 	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/status"
 	. "gitlab.com/project-emco/core/emco-base/src/rsync/pkg/types"
 )
@@ -21,6 +24,10 @@ type resProvd struct {
 	cluster string
 	cl      ClientProvider
 	context Context
+	// Operations are queued while the cluster is offline
+	offlineTolerant bool
+	// Backoff between the retries of the resources and of the cluster
+	retry retryPolicy
 }
 
 // Hook Kinds that require wait
@@ -39,6 +46,7 @@ func (r *resProvd) handleResources(ctx context.Context, op RsyncOperation, resou
 	}
 	// Keep retrying for reachability
 	for {
+		r.context.progress.set(r.app, r.cluster, op, "")
 		// Wait for cluster to be reachable
 		err := r.waitForClusterReady(ctx)
 		if err != nil {
@@ -48,7 +56,8 @@ func (r *resProvd) handleResources(ctx context.Context, op RsyncOperation, resou
 		handledRes = 0
 		// Handle all resources in order
 		for _, res := range resources {
-			ref, breakonError, err = r.handleResource(ctx, op, res, ref)
+			r.context.progress.set(r.app, r.cluster, op, res)
+			ref, breakonError, err = r.handleResourceWithRetry(ctx, op, res, ref)
 			if err != nil {
 				log.Error("Error in resource", log.Fields{"error": err, "cluster": r.cluster, "resource": res})
 				// If failure is due to reachability issues start retrying
@@ -106,45 +115,47 @@ func (r *resProvd) waitForClusterReady(ctx context.Context) error {
 
 	// Check if reachable
 	if err := r.cl.IsReachable(); err == nil {
+		metrics.SetClusterReachable(r.cluster, true)
 		r.context.acRef.SetClusterAvailableStatus(ctx, r.app, r.cluster, appcontext.ClusterReadyStatusEnum.Available)
 		return nil
 	}
+	metrics.SetClusterReachable(r.cluster, false)
+	// Don't wait for an offline tolerant cluster, its operations are queued
+	if r.offlineTolerant {
+		r.context.acRef.SetClusterAvailableStatus(ctx, r.app, r.cluster, appcontext.ClusterReadyStatusEnum.Offline)
+		return errClusterOffline
+	}
 	r.context.acRef.SetClusterAvailableStatus(ctx, r.app, r.cluster, appcontext.ClusterReadyStatusEnum.Retrying)
-	timedOut := false
-	retryCnt := 0
-	forceDone := false
-Loop:
+	b := r.retry.newBackoff()
+	// The cluster is checked again at least once
+	if b.policy.maxRetries == 0 {
+		b.policy.maxRetries = 1
+	}
 	for {
+		d, ok := b.next()
+		if !ok {
+			return pkgerrors.Errorf("Retries exceeded max: " + r.cluster)
+		}
 		select {
-		// Wait for wait time before checking cluster ready
-		case <-time.After(time.Duration(r.context.waitTime) * time.Second):
+		// Wait before checking cluster ready
+		case <-time.After(d):
 			// Context is canceled
 			if ctx.Err() != nil {
 				return ctx.Err()
 			}
 			// If cluster is reachable then done
 			if err := r.cl.IsReachable(); err == nil {
+				metrics.SetClusterReachable(r.cluster, true)
 				r.context.acRef.SetClusterAvailableStatus(ctx, r.app, r.cluster, appcontext.ClusterReadyStatusEnum.Available)
 				return nil
 			}
-			log.Info("Cluster is not reachable - keep trying::", log.Fields{"cluster": r.cluster, "retry count": retryCnt})
-			retryCnt++
-			if r.context.maxRetry >= 0 && retryCnt > r.context.maxRetry {
-				timedOut = true
-				break Loop
-			}
+			log.Info("Cluster is not reachable - keep trying::", log.Fields{"cluster": r.cluster, "retry count": b.retries})
+			metrics.ClusterRetries.WithLabelValues(r.cluster).Inc()
 		// Check if the context is canceled
 		case <-ctx.Done():
 			return ctx.Err()
 		}
 	}
-	if timedOut {
-		return pkgerrors.Errorf("Retries exceeded max: " + r.cluster)
-	}
-	if forceDone {
-		return pkgerrors.Errorf("Termination of rsync cluster retry: " + r.cluster)
-	}
-	return nil
 }
 
 func (r *resProvd) handleResource(ctx context.Context, op RsyncOperation, res string, ref interface{}) (interface{}, bool, error) {
@@ -178,6 +189,11 @@ func (r *resProvd) handleResource(ctx context.Context, op RsyncOperation, res st
 			// return false for breakon error
 			return nil, false, err
 		}
+	case OpValidate:
+		// Nothing is changed in the cluster, the resource is only validated
+		if err = r.validateResource(ctx, res); err != nil {
+			return nil, false, err
+		}
 	}
 	// return false for breakon error
 	return q, false, nil
@@ -185,6 +201,8 @@ func (r *resProvd) handleResource(ctx context.Context, op RsyncOperation, res st
 
 func (r *resProvd) instantiateResource(ctx context.Context, name string, ref interface{}) (interface{}, error) {
 	var q interface{}
+	// Status of the last operation, before it is reset
+	last, _ := r.context.scRef.GetResourceStatus(ctx, r.app, r.cluster, name)
 	// call this to ensure 'reference' and 'status' keys are present before Apply() is called
 	r.updateResourceStatus(ctx, name, resourcestatus.ResourceStatus{Status: resourcestatus.RsyncStatusEnum.Pending})
 
@@ -199,15 +217,42 @@ func (r *resProvd) instantiateResource(ctx context.Context, name string, ref int
 		log.Error("Error Tag Resoruce with label:", log.Fields{"err": err, "label": label, "resource": name})
 		return nil, err
 	}
+	hash := contentHash(b)
+	if r.isUnchanged(ctx, name, last, hash) {
+		log.Info("Skipping unchanged resource", log.Fields{"cluster": r.cluster, "app": r.app, "resource": name})
+		metrics.ResourcesSkipped.WithLabelValues(r.cluster).Inc()
+		r.updateResourceStatus(ctx, name, resourcestatus.ResourceStatus{Status: resourcestatus.RsyncStatusEnum.Applied, Skipped: true})
+		return ref, nil
+	}
 	if q, err = r.cl.Apply(ctx, name, ref, b); err != nil {
 		r.updateResourceStatus(ctx, name, resourcestatus.ResourceStatus{Status: resourcestatus.RsyncStatusEnum.Failed})
 		log.Error("Failed to apply res", log.Fields{"error": err, "resource": name})
 		return nil, err
 	}
+	// Treating hash errors as non fatal, the resource is applied again next time
+	_ = r.context.scRef.SetResourceHash(ctx, r.app, r.cluster, name, hash)
 	r.updateResourceStatus(ctx, name, resourcestatus.ResourceStatus{Status: resourcestatus.RsyncStatusEnum.Applied})
 	return q, nil
 }
 
+// contentHash returns the sha256 hash of the content applied for a resource
+func contentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// isUnchanged returns true if the content was already applied successfully and
+// the resource has not drifted since, unless the Composite App forces the apply
+func (r *resProvd) isUnchanged(ctx context.Context, name string, last resourcestatus.ResourceStatus, hash string) bool {
+	if r.context.ca.CompMetadata.ForceApply || last.Status != resourcestatus.RsyncStatusEnum.Applied {
+		return false
+	}
+	if r.context.scRef.GetResourceHash(ctx, r.app, r.cluster, name) != hash {
+		return false
+	}
+	return !r.context.scRef.GetResourceReadyStatus(ctx, r.app, r.cluster, name, string(DriftedStatus))
+}
+
 func (r *resProvd) createResource(ctx context.Context, name string, ref interface{}) (interface{}, error) {
 	var q interface{}
 
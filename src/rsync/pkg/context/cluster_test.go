@@ -0,0 +1,128 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package context
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/appcontext"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/contextdb"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/resourcestatus"
+	. "gitlab.com/project-emco/core/emco-base/src/rsync/pkg/types"
+	contextUtils "gitlab.com/project-emco/core/emco-base/src/rsync/pkg/utils"
+)
+
+var skipCA = CompositeApp{
+	CompMetadata: appcontext.CompositeAppMeta{Project: "proj1", CompositeApp: "ca1", Version: "v1", Release: "r1",
+		DeploymentIntentGroup: "dig1", Namespace: "default", Level: "0"},
+	AppOrder: []string{"a1"},
+	Apps: map[string]*App{"a1": {
+		Name: "a1",
+		Clusters: map[string]*Cluster{"provider1+cluster1": {
+			Name:      "provider1+cluster1",
+			Resources: map[string]*AppResource{"r1": {Name: "r1", Data: "a1c1r1"}},
+			ResOrder:  []string{"r1"}}},
+	}},
+}
+
+func TestContentHash(t *testing.T) {
+	if h := contentHash([]byte("")); h != "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855" {
+		t.Errorf("Unexpected hash %s", h)
+	}
+	if contentHash([]byte("a1c1r1")) == contentHash([]byte("a1c1r2")) {
+		t.Error("Different contents with the same hash")
+	}
+}
+
+func TestSkipUnchanged(t *testing.T) {
+	ctx := context.Background()
+	savedDb := contextdb.Db
+	defer func() { contextdb.Db = savedDb }()
+	contextdb.Db = new(contextdb.MockConDb)
+
+	cid, err := contextUtils.CreateCompApp(ctx, skipCA)
+	if err != nil {
+		t.Fatalf("CreateCompApp failed: %v", err)
+	}
+	c, err := newStandaloneContext(ctx, cid)
+	if err != nil {
+		t.Fatalf("newStandaloneContext failed: %v", err)
+	}
+	cl := &MockClient{cluster: "provider1+cluster1", lock: new(sync.Mutex)}
+	r := resProvd{app: "a1", cluster: "provider1+cluster1", cl: cl, context: *c}
+
+	testCases := []struct {
+		label   string
+		prepare func()
+		applied bool
+	}{
+		{
+			label:   "Apply new resource",
+			applied: true,
+		},
+		{
+			label:   "Skip unchanged resource",
+			applied: false,
+		},
+		{
+			label: "Apply changed resource",
+			prepare: func() {
+				ac := c.acRef.GetAppContextHandle()
+				rh, _ := ac.GetResourceHandle(ctx, "a1", "provider1+cluster1", "r1")
+				_ = ac.UpdateResourceValue(ctx, rh, "a1c1r1-v2")
+			},
+			applied: true,
+		},
+		{
+			label: "Apply drifted resource",
+			prepare: func() {
+				_ = c.scRef.SetResourceReadyStatus(ctx, "a1", "provider1+cluster1", "r1", string(DriftedStatus), true)
+			},
+			applied: true,
+		},
+		{
+			label: "Apply forced resource",
+			prepare: func() {
+				_ = c.scRef.SetResourceReadyStatus(ctx, "a1", "provider1+cluster1", "r1", string(DriftedStatus), false)
+				r.context.ca.CompMetadata.ForceApply = true
+			},
+			applied: true,
+		},
+		{
+			label: "Apply failed resource",
+			prepare: func() {
+				r.context.ca.CompMetadata.ForceApply = false
+				r.updateResourceStatus(ctx, "r1", resourcestatus.ResourceStatus{Status: resourcestatus.RsyncStatusEnum.Failed})
+			},
+			applied: true,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.label, func(t *testing.T) {
+			if testCase.prepare != nil {
+				testCase.prepare()
+			}
+			before := cl.applyCounter
+			if _, err := r.instantiateResource(ctx, "r1", nil); err != nil {
+				t.Fatalf("instantiateResource failed: %v", err)
+			}
+			if applied := cl.applyCounter > before; applied != testCase.applied {
+				t.Errorf("Resource applied %v, expected %v", applied, testCase.applied)
+			}
+			status, err := c.scRef.GetResourceStatus(ctx, "a1", "provider1+cluster1", "r1")
+			if err != nil {
+				t.Fatalf("GetResourceStatus failed: %v", err)
+			}
+			if status.Status != resourcestatus.RsyncStatusEnum.Applied || status.Skipped == testCase.applied {
+				t.Errorf("Unexpected status %+v", status)
+			}
+			res, _, _ := c.acRef.GetRes(ctx, "r1", "a1", "provider1+cluster1")
+			if h := c.scRef.GetResourceHash(ctx, "a1", "provider1+cluster1", "r1"); h != contentHash(res) {
+				t.Errorf("Unexpected hash %s of the applied resource", h)
+			}
+		})
+	}
+}
@@ -12,10 +12,13 @@ import (
 
 	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/config"
 	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/tracing"
 	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/connector"
 	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/depend"
 	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/internal/utils"
+	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/scheduler"
 	. "gitlab.com/project-emco/core/emco-base/src/rsync/pkg/types"
+	contextUtils "gitlab.com/project-emco/core/emco-base/src/rsync/pkg/utils"
 )
 
 // Context is Per AppContext struct
@@ -39,10 +42,18 @@ type Context struct {
 	maxRetry int
 	// wait time (seconds) between trying again for cluster reachability
 	waitTime int
+	// AppContext updating the AppContext, for the UpdateDelete event being handled
+	ucid string
 	// Structure to hold CompositeApp Information
 	ca CompositeApp
 	// To manage dependency
 	dm *depend.DependManager
+	// Index in the queue of the event being handled, -1 if none
+	current int
+	// Resources being handled by app and cluster
+	progress *progressData
+	// Offline tolerant clusters, read once per event
+	offline *sync.Map
 	// Keep track for scheduled monitor CR delete functions
 	// Key for the map is app+cluster
 	timerList map[string]*time.Timer
@@ -105,6 +116,8 @@ func (c *Context) EnqueueToAppContext(ctx context.Context, a interface{}, ucid i
 	} else {
 		elem = AppContextQueueElement{Event: e, Status: "Pending"}
 	}
+	// Store the trace context so that the event is handled in the trace of the request
+	elem.TraceContext = tracing.InjectTraceContext(ctx)
 	// Acquire Mutex before adding to queue
 	c.Lock.Lock()
 	// Push the appContext to ActiveContext space of etcD
@@ -152,6 +165,43 @@ func RestartAppContext(ctx context.Context, a interface{}, con Connector) error
 	return err
 }
 
+// newStandaloneContext returns a Context handling the resources of the AppContext
+// outside of its event queue, to remediate drift or to replay pending operations
+func newStandaloneContext(ctx context.Context, acID string) (*Context, error) {
+	acRef, err := utils.NewAppContextReference(ctx, acID)
+	if err != nil {
+		return nil, err
+	}
+	c := &Context{Lock: &sync.Mutex{}, acID: acID, acRef: acRef, waitTime: 2, maxRetry: getMaxRetries(), offline: &sync.Map{}}
+	c.timerList = make(map[string]*time.Timer)
+	c.ca, err = contextUtils.ReadAppContext(ctx, acID)
+	if err != nil {
+		return nil, err
+	}
+	c.statusAcID, err = acRef.GetStatusAppContext(ctx, StatusAppContextIDKey)
+	if err != nil {
+		c.statusAcID = acID
+		c.scRef = acRef
+	} else {
+		c.scRef, err = utils.NewAppContextReference(ctx, c.statusAcID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	con := connector.NewProvider(acID)
+	c.con = &con
+	// Share the dependency manager of the AppContext, which receives the status of its resources
+	_, q := CreateAppContextData(acID)
+	q.Lock.Lock()
+	c.dm = q.dm
+	q.Lock.Unlock()
+	if c.dm == nil {
+		c.dm = depend.NewDependManager(acID)
+		c.dm.SetStatusAppContext(c.statusAcID)
+	}
+	return c, nil
+}
+
 // Create per AppContext thread data
 func CreateAppContextData(key string) (bool, *Context) {
 	appContextData.Lock()
@@ -162,6 +212,8 @@ func CreateAppContextData(key string) (bool, *Context) {
 		appContextData.Data[key] = &Context{}
 		appContextData.Data[key].Lock = &sync.Mutex{}
 		appContextData.Data[key].Running = false
+		appContextData.Data[key].current = -1
+		appContextData.Data[key].progress = newProgressData()
 		// Initialize timer Map for the lifetime of the appContext
 		appContextData.Data[key].timerList = make(map[string]*time.Timer)
 		// Created appContext data (return true)
@@ -199,6 +251,23 @@ func getMaxRetries() int {
 	return maxRetries
 }
 
+// workers bounds the clusters handled at once, globally and for an AppContext
+var workers = scheduler.New(getWorkerLimit(config.GetConfiguration().MaxWorkers),
+	getWorkerLimit(config.GetConfiguration().AppContextMaxWorkers))
+
+// Read a worker limit from configuration, unbounded if not set
+func getWorkerLimit(s string) int {
+	if s == "" {
+		return 0
+	}
+	limit, err := strconv.Atoi(s)
+	if err != nil || limit < 0 {
+		logutils.Warn("Invalid worker limit, workers are unbounded", logutils.Fields{"limit": s})
+		return 0
+	}
+	return limit
+}
+
 // CompositeAppContext represents composite app
 type CompositeAppContext struct {
 	cid interface{}
@@ -544,6 +544,93 @@ func TestHooks(t *testing.T) {
 	}
 }
 
+func TestHookDeletePolicies(t *testing.T) {
+
+	var ca CompositeApp = CompositeApp{
+		CompMetadata: appcontext.CompositeAppMeta{Project: "proj1", CompositeApp: "ca1", Version: "v1", Release: "r1",
+			DeploymentIntentGroup: "dig1", Namespace: "default", Level: "0"},
+		AppOrder: []string{"a1"},
+		Apps: map[string]*App{"a1": {
+			Name: "a1",
+			Clusters: map[string]*Cluster{
+				"provider1+cluster1": {
+					Name: "provider1+cluster1",
+					Resources: map[string]*AppResource{"r1+ConfigMap": {Name: "r1+ConfigMap", Data: "a1c1r1"}, "r2+Secret": {Name: "r2+Secret", Data: "a1c1r2"},
+						"r3+Pod": {Name: "r3+Pod", Data: "a1c1r3"}, "r4+ConfigMap": {Name: "r4+ConfigMap", Data: "a1c1r4"}},
+					Dependency: map[string][]string{"pre-install": {"r1+ConfigMap", "r2+Secret"}, "post-install": {"r4+ConfigMap"}},
+					Hooks: map[string]*Hook{
+						"r1+ConfigMap": {Weight: 5, DeletePolicies: []string{HookSucceeded}},
+						"r2+Secret":    {Weight: -5, DeletePolicies: []string{HookBeforeCreation}},
+						"r4+ConfigMap": {Weight: 0, DeletePolicies: []string{HookBeforeCreation, HookSucceeded}},
+					},
+					ResOrder: []string{"r3+Pod"}}},
+		},
+		},
+	}
+
+	cid, _ := contextUtils.CreateCompApp(context.Background(), ca)
+	con := NewProvider(cid)
+
+	_ = HandleAppContext(context.Background(), cid, nil, InstantiateEvent, &con)
+	time.Sleep(2 * time.Second)
+	// The hooks are applied by weight, and deleted after success with the hook-succeeded policy
+	if !CompareMaps(map[string]string{"provider1+cluster1": "a1c1r2,a1c1r1,a1c1r3,a1c1r4"}, LoadMap("apply")) {
+		t.Error("Apply resources doesn't match", LoadMap("apply"))
+	}
+	if !CompareMaps(map[string]string{"provider1+cluster1": "a1c1r1,a1c1r4"}, LoadMap("delete")) {
+		t.Error("Delete resources doesn't match", LoadMap("delete"))
+	}
+	if !CompareMaps(map[string]string{"provider1+cluster1": "a1c1r2,a1c1r3"}, LoadMap("resource")) {
+		t.Error("Cluster resources doesn't match", LoadMap("resource"))
+	}
+}
+
+func TestUpdateRemovedAppHooks(t *testing.T) {
+
+	app := func(name string) *App {
+		return &App{
+			Name: name,
+			Clusters: map[string]*Cluster{"provider1+cluster1": {
+				Name:       "provider1+cluster1",
+				Resources:  map[string]*AppResource{"r1+Pod": {Name: "r1+Pod", Data: name + "c1r1"}, "r2+Job": {Name: "r2+Job", Data: name + "c1r2"}},
+				Dependency: map[string][]string{"pre-delete": {"r2+Job"}},
+				ResOrder:   []string{"r1+Pod"}}},
+		}
+	}
+	original := CompositeApp{
+		CompMetadata: appcontext.CompositeAppMeta{Project: "proj1", CompositeApp: "ca1", Version: "v1", Release: "r1",
+			DeploymentIntentGroup: "dig1", Namespace: "default", Level: "0"},
+		AppOrder: []string{"a1", "a2"},
+		Apps:     map[string]*App{"a1": app("a1"), "a2": app("a2")},
+	}
+	updated := CompositeApp{
+		CompMetadata: appcontext.CompositeAppMeta{Project: "proj1", CompositeApp: "ca1", Version: "v2", Release: "r1",
+			DeploymentIntentGroup: "dig2", Namespace: "default", Level: "0"},
+		AppOrder: []string{"a1"},
+		Apps:     map[string]*App{"a1": app("a1")},
+	}
+
+	cid, _ := contextUtils.CreateCompApp(context.Background(), original)
+	ucid, _ := contextUtils.CreateCompApp(context.Background(), updated)
+	con := NewProvider(cid)
+	setSuccessForAllHooks(cid, original)
+
+	_ = HandleAppContext(context.Background(), cid, nil, InstantiateEvent, &con)
+	_ = HandleAppContext(context.Background(), ucid, cid, UpdateEvent, &con)
+	time.Sleep(3 * time.Second)
+
+	// The pre-delete hook of the app removed by the update runs before its resources are deleted
+	if !CompareMaps(map[string]string{"provider1+cluster1": "a1c1r1,a2c1r1,a2c1r2"}, LoadMap("apply")) {
+		t.Error("Apply resources doesn't match", LoadMap("apply"))
+	}
+	if !CompareMaps(map[string]string{"provider1+cluster1": "a2c1r1,a2c1r2"}, LoadMap("delete")) {
+		t.Error("Delete resources doesn't match", LoadMap("delete"))
+	}
+	if !CompareMaps(map[string]string{"provider1+cluster1": "a1c1r1"}, LoadMap("resource")) {
+		t.Error("Cluster resources doesn't match", LoadMap("resource"))
+	}
+}
+
 func TestGetAllActiveContext(t *testing.T) {
 
 	cid, _ := contextUtils.CreateCompApp(context.Background(), TestCA)
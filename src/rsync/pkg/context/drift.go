@@ -0,0 +1,44 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package context
+
+import (
+	"context"
+
+	pkgerrors "github.com/pkg/errors"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/appcontext"
+	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/internal/utils"
+	. "gitlab.com/project-emco/core/emco-base/src/rsync/pkg/types"
+)
+
+// RemediateDrift re-applies the desired state of the drifted resources of an app on a cluster
+func RemediateDrift(ctx context.Context, acID, app, cluster string, resources []string) error {
+	acRef, err := utils.NewAppContextReference(ctx, acID)
+	if err != nil {
+		return err
+	}
+	// Only remediate AppContexts that are deployed and not being modified
+	s, err := acRef.GetAppContextStatus(ctx, CurrentStateKey)
+	if err != nil {
+		return err
+	}
+	if s.Status != appcontext.AppContextStatusEnum.Instantiated && s.Status != appcontext.AppContextStatusEnum.Updated {
+		log.Info("Skipping drift remediation", log.Fields{"acID": acID, "status": s.Status})
+		return nil
+	}
+	c, err := newStandaloneContext(ctx, acID)
+	if err != nil {
+		return err
+	}
+	namespace, level := acRef.GetNamespace(ctx)
+	cl, err := c.con.GetClientProviders(ctx, app, cluster, level, namespace)
+	if err != nil {
+		return pkgerrors.Wrap(err, "Error in creating client")
+	}
+	defer cl.CleanClientProvider()
+	r := resProvd{app: app, cluster: cluster, cl: cl, context: *c, retry: c.getRetryPolicy(ctx, cluster)}
+	_, err = r.handleResources(ctx, OpApply, resources)
+	return err
+}
@@ -0,0 +1,146 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package context
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+	. "gitlab.com/project-emco/core/emco-base/src/rsync/pkg/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// Seconds to wait for the deletion of a hook before it is created again
+var hookDeleteTimeout int = 60
+
+// runHooks runs the hooks of the event in the order of their weights, waiting for each
+// hook to complete before the next one, and deletes them according to their delete
+// policies, like Helm does
+func (r *resProvd) runHooks(ctx context.Context, event string) error {
+	hooks := r.hooksByWeight(event)
+	if len(hooks) == 0 {
+		return nil
+	}
+	log.Info("Running hooks", log.Fields{"App": r.app, "cluster": r.cluster, "event": event, "hooks": hooks})
+	var executed []string
+	for _, h := range hooks {
+		if r.hasDeletePolicy(h, HookBeforeCreation) {
+			if err := r.deleteHook(ctx, h, true); err != nil {
+				return err
+			}
+		}
+		executed = append(executed, h)
+		if _, err := r.handleResourcesWithWait(ctx, OpApply, []string{h}); err != nil {
+			r.deleteHooksByPolicy(ctx, executed, HookFailed)
+			return err
+		}
+		if r.context.scRef.GetResourceReadyStatus(ctx, r.app, r.cluster, h, string(FailedStatus)) {
+			log.Error("Hook failed", log.Fields{"App": r.app, "cluster": r.cluster, "event": event, "hook": h})
+			r.deleteHooksByPolicy(ctx, executed, HookFailed)
+			return pkgerrors.Errorf("%s hook %s failed on cluster %s", event, h, r.cluster)
+		}
+	}
+	r.deleteHooksByPolicy(ctx, executed, HookSucceeded)
+	log.Info("Done running hooks", log.Fields{"App": r.app, "cluster": r.cluster, "event": event, "hooks": hooks})
+	return nil
+}
+
+// hooksByWeight returns the hooks of the event sorted by weight. The hooks of the
+// same weight keep the order of the AppContext, which sorts them by name.
+func (r *resProvd) hooksByWeight(event string) []string {
+	cluster := r.context.ca.Apps[r.app].Clusters[r.cluster]
+	hooks := append([]string{}, cluster.Dependency[event]...)
+	weight := func(h string) int {
+		if hk, ok := cluster.Hooks[h]; ok && hk != nil {
+			return hk.Weight
+		}
+		return 0
+	}
+	sort.SliceStable(hooks, func(i, j int) bool {
+		return weight(hooks[i]) < weight(hooks[j])
+	})
+	return hooks
+}
+
+// hasDeletePolicy returns true if the hook has the delete policy
+func (r *resProvd) hasDeletePolicy(h, policy string) bool {
+	hk, ok := r.context.ca.Apps[r.app].Clusters[r.cluster].Hooks[h]
+	if !ok || hk == nil {
+		return false
+	}
+	for _, p := range hk.DeletePolicies {
+		if p == policy {
+			return true
+		}
+	}
+	return false
+}
+
+// deleteHooksByPolicy deletes the hooks having the delete policy, ignoring errors
+func (r *resProvd) deleteHooksByPolicy(ctx context.Context, hooks []string, policy string) {
+	for _, h := range hooks {
+		if !r.hasDeletePolicy(h, policy) {
+			continue
+		}
+		if err := r.deleteHook(ctx, h, false); err != nil {
+			log.Warn("Failed to delete hook", log.Fields{"App": r.app, "cluster": r.cluster, "hook": h, "policy": policy, "error": err})
+		}
+	}
+}
+
+// deleteHook deletes the hook if it exists on the cluster, and optionally waits for
+// the deletion to complete
+func (r *resProvd) deleteHook(ctx context.Context, h string, wait bool) error {
+	res, _, err := r.context.acRef.GetRes(ctx, h, r.app, r.cluster)
+	if err != nil {
+		return err
+	}
+	if _, err := r.cl.Get(ctx, h, res); err != nil {
+		if isNotOnCluster(err) {
+			return nil
+		}
+		return pkgerrors.Wrapf(err, "Error getting hook %s on cluster %s", h, r.cluster)
+	}
+	log.Info("Deleting hook", log.Fields{"App": r.app, "cluster": r.cluster, "hook": h})
+	if _, err := r.handleResources(ctx, OpDelete, []string{h}); err != nil {
+		return err
+	}
+	// Forget the completion of the deleted hook, which runs again when it is created
+	r.resetHookStatus(ctx, h)
+	if !wait {
+		return nil
+	}
+	for i := 0; i < hookDeleteTimeout; i++ {
+		select {
+		case <-time.After(time.Second):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if _, err := r.cl.Get(ctx, h, res); err != nil {
+			if isNotOnCluster(err) {
+				return nil
+			}
+			return pkgerrors.Wrapf(err, "Error getting hook %s on cluster %s", h, r.cluster)
+		}
+	}
+	return pkgerrors.Errorf("Timed out waiting for the deletion of hook %s on cluster %s", h, r.cluster)
+}
+
+// isNotOnCluster returns true if the error returned by Get means that the resource is not
+// on the cluster, from Kubernetes or from a connector plugin
+func isNotOnCluster(err error) bool {
+	return apierrors.IsNotFound(err) || status.Code(pkgerrors.Cause(err)) == codes.NotFound
+}
+
+// resetHookStatus clears the status of a previous run of the hook
+func (r *resProvd) resetHookStatus(ctx context.Context, h string) {
+	for _, s := range []ResourceStatusType{SuccessStatus, FailedStatus} {
+		_ = r.context.scRef.SetResourceReadyStatus(ctx, r.app, r.cluster, h, string(s), false)
+	}
+}
@@ -0,0 +1,26 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package context
+
+import (
+	"testing"
+
+	pkgerrors "github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestIsNotOnCluster(t *testing.T) {
+	if !isNotOnCluster(pkgerrors.Wrap(apierrors.NewNotFound(schema.GroupResource{Resource: "jobs"}, "r1"), "get")) {
+		t.Error("Kubernetes NotFound error not recognized")
+	}
+	if !isNotOnCluster(pkgerrors.Wrap(status.Error(codes.NotFound, "r1"), "Connector plugin get failed")) {
+		t.Error("Connector plugin NotFound error not recognized")
+	}
+	if isNotOnCluster(pkgerrors.New("connection refused")) || isNotOnCluster(status.Error(codes.Unavailable, "r1")) {
+		t.Error("Unexpected NotFound error")
+	}
+}
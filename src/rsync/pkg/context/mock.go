@@ -14,6 +14,8 @@ import (
 	pkgerrors "github.com/pkg/errors"
 	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/appcontext"
 	. "gitlab.com/project-emco/core/emco-base/src/rsync/pkg/types"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	//"time"
 )
 
@@ -36,6 +38,10 @@ var MatchList Match
 type MockConnector struct {
 	sync.Mutex
 	Clients *sync.Map
+	// Labels of the clusters
+	Labels map[string][]string
+	// Key value pairs of the clusters, by cluster and name
+	KvPairs map[string]map[string][]map[string]interface{}
 	cid     string
 }
 
@@ -67,6 +73,25 @@ func (c *MockConnector) GetClientProviders(ctx context.Context, app, cluster, le
 	return &n, nil
 }
 
+// HasClusterLabel returns true if the label is in the labels of the cluster
+func (c *MockConnector) HasClusterLabel(ctx context.Context, cluster, label string) (bool, error) {
+	c.Lock()
+	defer c.Unlock()
+	for _, l := range c.Labels[cluster] {
+		if l == label {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// GetClusterKvPairs returns the key value pairs of the cluster
+func (c *MockConnector) GetClusterKvPairs(ctx context.Context, cluster, kvpair string) ([]map[string]interface{}, error) {
+	c.Lock()
+	defer c.Unlock()
+	return c.KvPairs[cluster][kvpair], nil
+}
+
 // MockClient mocks client
 type MockClient struct {
 	lock           *sync.Mutex
@@ -181,9 +206,18 @@ func (m *MockClient) Delete(name string, ref interface{}, content []byte) (inter
 	return str, nil
 }
 
+// Get returns the resource if it is applied to the cluster
 func (m *MockClient) Get(ctx context.Context, name string, gvkRes []byte) ([]byte, error) {
-	b := []byte("test")
-	return b, nil
+	i, ok := MatchList.ResourceList.Load(m.cluster)
+	if ok {
+		for _, v := range strings.Split(fmt.Sprintf("%v", i), ",") {
+			if v == string(gvkRes) {
+				b := []byte("test")
+				return b, nil
+			}
+		}
+	}
+	return nil, apierrors.NewNotFound(schema.GroupResource{}, name)
 }
 func (m *MockClient) IsReachable() error {
 	if m.cluster == "provider1+cluster1" {
@@ -0,0 +1,425 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package context
+
+/*
+offline.go queues the operations of the offline tolerant clusters while they are not
+reachable, and replays them once the clusters are reachable again
+*/
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/appcontext"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/contextdb"
+	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/internal/utils"
+	. "gitlab.com/project-emco/core/emco-base/src/rsync/pkg/types"
+	contextUtils "gitlab.com/project-emco/core/emco-base/src/rsync/pkg/utils"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// OfflineTolerantLabel is the label of the clusters whose operations are queued while they are offline
+const OfflineTolerantLabel = "offline-tolerant"
+
+const pendingPrefix string = "/pendingops/"
+
+// Wait time between checks of the reachability of the clusters with pending operations
+var offlineCheckInterval = 30 * time.Second
+
+// errClusterOffline is returned for an offline tolerant cluster which is not reachable
+var errClusterOffline = pkgerrors.New("Cluster offline")
+
+// isOfflineTolerant returns true if the operations of the cluster are queued while it is
+// offline. The label is read once per event of the AppContext.
+func (c *Context) isOfflineTolerant(ctx context.Context, cluster string) bool {
+	if c.offline != nil {
+		if v, ok := c.offline.Load(cluster); ok {
+			return v.(bool)
+		}
+	}
+	ok, err := c.con.HasClusterLabel(ctx, cluster, OfflineTolerantLabel)
+	if err != nil {
+		log.Error("Error reading the labels of the cluster", log.Fields{"cluster": cluster, "error": err})
+		return false
+	}
+	if c.offline != nil {
+		c.offline.Store(cluster, ok)
+	}
+	return ok
+}
+
+// PendingOp is an operation of an AppContext on an offline cluster
+type PendingOp struct {
+	AppContextID string     `json:"appContextID"`
+	Event        RsyncEvent `json:"event"`
+	// AppContext updating the AppContext, for the UpdateDelete event
+	UCID string `json:"ucid,omitempty"`
+}
+
+// PendingOps are the operations of an app on an offline cluster. Only the latest desired
+// state is delivered: the resources deleted by the updates, and then the last operation.
+type PendingOps struct {
+	Cluster string `json:"cluster"`
+	// AppContext receiving the status of the app
+	StatusAppContextID string      `json:"statusAppContextID"`
+	App                string      `json:"app"`
+	Deletes            []PendingOp `json:"deletes,omitempty"`
+	Latest             *PendingOp  `json:"latest,omitempty"`
+	// Time of the first operation, the pending operations are replayed in this order
+	Time time.Time `json:"time"`
+}
+
+// pendingOpsData serializes the changes of the pending operations, and tracks the
+// clusters whose pending operations are being replayed
+type pendingOpsData struct {
+	replaying map[string]bool
+	sync.Mutex
+}
+
+var pendingData = pendingOpsData{replaying: map[string]bool{}}
+
+// pendingOpsPrefix is the prefix of the keys of the pending operations of the apps of
+// the status AppContext on the cluster
+func pendingOpsPrefix(cluster, statusAcID string) string {
+	return pendingPrefix + cluster + "/" + statusAcID + "/"
+}
+
+func pendingOpsKey(cluster, statusAcID, app string) string {
+	return pendingOpsPrefix(cluster, statusAcID) + app + "/"
+}
+
+// add merges the operation into the pending operations
+func (p *PendingOps) add(op PendingOp) {
+	switch op.Event {
+	case UpdateDeleteEvent:
+		for _, d := range p.Deletes {
+			if d.AppContextID == op.AppContextID {
+				return
+			}
+		}
+		p.Deletes = append(p.Deletes, op)
+	case UpdateEvent:
+		// Resources never delivered are instantiated with the update
+		if p.Latest != nil && p.Latest.Event == InstantiateEvent {
+			op.Event = InstantiateEvent
+		}
+		p.Latest = &op
+	default:
+		p.Latest = &op
+	}
+}
+
+// remove removes the replayed operations from the pending operations
+func (p *PendingOps) remove(replayed PendingOps) {
+	var deletes []PendingOp
+	for _, d := range p.Deletes {
+		found := false
+		for _, r := range replayed.Deletes {
+			if d == r {
+				found = true
+				break
+			}
+		}
+		if !found {
+			deletes = append(deletes, d)
+		}
+	}
+	p.Deletes = deletes
+	if p.Latest != nil && replayed.Latest != nil && *p.Latest == *replayed.Latest {
+		p.Latest = nil
+	}
+}
+
+func (p *PendingOps) empty() bool {
+	return len(p.Deletes) == 0 && p.Latest == nil
+}
+
+// queuePendingOp records the operation of the app on the offline cluster. When exists
+// is true, the operation is only recorded if operations are already pending for the app,
+// so that it is delivered after them.
+func (c *Context) queuePendingOp(ctx context.Context, e RsyncEvent, app, cluster string, exists bool) (bool, error) {
+	pendingData.Lock()
+	defer pendingData.Unlock()
+	key := pendingOpsKey(cluster, c.statusAcID, app)
+	var p PendingOps
+	if err := contextdb.Db.Get(ctx, key, &p); err != nil {
+		if exists {
+			return false, nil
+		}
+		p = PendingOps{Cluster: cluster, StatusAppContextID: c.statusAcID, App: app, Time: time.Now()}
+	}
+	op := PendingOp{AppContextID: c.acID, Event: e}
+	if e == UpdateDeleteEvent {
+		op.UCID = c.ucid
+	}
+	p.add(op)
+	if err := contextdb.Db.Put(ctx, key, p); err != nil {
+		log.Error("Error saving the pending operation", log.Fields{"cluster": cluster, "app": app, "event": e, "error": err})
+		return false, err
+	}
+	log.Info("Cluster offline - operation pending", log.Fields{"cluster": cluster, "app": app, "event": e, "acID": c.acID})
+	c.acRef.SetClusterAvailableStatus(ctx, app, cluster, appcontext.ClusterReadyStatusEnum.Offline)
+	startReplay(cluster)
+	return true, nil
+}
+
+// getPendingOps returns the pending operations with the key prefix
+func getPendingOps(ctx context.Context, prefix string) ([]PendingOps, error) {
+	keys, err := contextdb.Db.GetAllKeys(ctx, prefix)
+	if err != nil {
+		// No operations are pending
+		if strings.Contains(err.Error(), "Key doesn't exist") {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var pl []PendingOps
+	for _, k := range keys {
+		var p PendingOps
+		if err := contextdb.Db.Get(ctx, k, &p); err != nil {
+			return nil, err
+		}
+		pl = append(pl, p)
+	}
+	sort.SliceStable(pl, func(i, j int) bool { return pl[i].Time.Before(pl[j].Time) })
+	return pl, nil
+}
+
+// hasPendingOps returns true if operations of the AppContext are pending on the offline
+// clusters. Only the operations of its status AppContext on the clusters are read.
+func hasPendingOps(ctx context.Context, acID, statusAcID string, clusters []string) bool {
+	for _, cluster := range clusters {
+		pl, err := getPendingOps(ctx, pendingOpsPrefix(cluster, statusAcID))
+		if err != nil {
+			log.Error("Error reading the pending operations", log.Fields{"cluster": cluster, "acID": acID, "error": err})
+			continue
+		}
+		for _, p := range pl {
+			if p.Latest != nil && p.Latest.AppContextID == acID {
+				return true
+			}
+			for _, d := range p.Deletes {
+				if d.AppContextID == acID {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// appClusters returns the clusters of the apps of the AppContext
+func appClusters(ca CompositeApp) []string {
+	var clusters []string
+	found := map[string]bool{}
+	for _, a := range ca.Apps {
+		for cluster := range a.Clusters {
+			if !found[cluster] {
+				found[cluster] = true
+				clusters = append(clusters, cluster)
+			}
+		}
+	}
+	return clusters
+}
+
+// startReplay starts replaying the pending operations of the cluster, unless already started
+func startReplay(cluster string) {
+	if pendingData.replaying[cluster] {
+		return
+	}
+	pendingData.replaying[cluster] = true
+	go replayCluster(cluster)
+}
+
+// RestorePendingOps shall be called everytime the rsync restarts. It resumes
+// replaying the pending operations of the offline clusters.
+func RestorePendingOps(ctx context.Context) error {
+	pl, err := getPendingOps(ctx, pendingPrefix)
+	if err != nil {
+		return err
+	}
+	log.Info("Clusters with pending operations to be restored", log.Fields{"Total pending operations": len(pl)})
+	pendingData.Lock()
+	defer pendingData.Unlock()
+	for _, p := range pl {
+		startReplay(p.Cluster)
+	}
+	return nil
+}
+
+// Per cluster go routine replaying the pending operations once the cluster is reachable
+func replayCluster(cluster string) {
+	for {
+		time.Sleep(offlineCheckInterval)
+		// This function is executed asynchronously, with a new context for each attempt
+		ctx, span := otel.Tracer("rsync").Start(context.Background(), "replayPendingOps",
+			trace.WithAttributes(attribute.String("cluster", cluster)),
+		)
+		done := replayPendingOps(ctx, cluster)
+		span.End()
+		if done {
+			return
+		}
+	}
+}
+
+// replayPendingOps replays the pending operations of the cluster in order. It returns
+// true when no operations are pending anymore.
+func replayPendingOps(ctx context.Context, cluster string) bool {
+	pendingData.Lock()
+	pl, err := getPendingOps(ctx, pendingPrefix+cluster+"/")
+	if err == nil && len(pl) == 0 {
+		delete(pendingData.replaying, cluster)
+		pendingData.Unlock()
+		return true
+	}
+	pendingData.Unlock()
+	if err != nil {
+		log.Error("Error reading the pending operations", log.Fields{"cluster": cluster, "error": err})
+		return false
+	}
+	for _, p := range pl {
+		replayed, err := replayApp(ctx, p)
+		if err := removePendingOps(ctx, replayed); err != nil {
+			log.Error("Error removing the replayed operations", log.Fields{"cluster": cluster, "app": p.App, "error": err})
+			return false
+		}
+		for _, acID := range replayed.appContexts() {
+			updatePendingStatus(ctx, acID, replayed, err)
+		}
+		if pkgerrors.Cause(err) == errClusterOffline {
+			log.Info("Cluster still offline", log.Fields{"cluster": cluster})
+			return false
+		}
+	}
+	return false
+}
+
+// replayApp delivers the pending operations of the app, and returns the operations
+// that were replayed and the error of the last one
+func replayApp(ctx context.Context, p PendingOps) (PendingOps, error) {
+	replayed := PendingOps{Cluster: p.Cluster, StatusAppContextID: p.StatusAppContextID, App: p.App}
+	for _, d := range p.Deletes {
+		err := replayDelete(ctx, p, d)
+		if pkgerrors.Cause(err) == errClusterOffline {
+			return replayed, err
+		}
+		// The deleted resources may never have been delivered
+		if err != nil {
+			log.Warn("Error deleting the resources of the update", log.Fields{"cluster": p.Cluster, "app": p.App, "acID": d.AppContextID, "error": err})
+		}
+		replayed.Deletes = append(replayed.Deletes, d)
+	}
+	if p.Latest == nil {
+		return replayed, nil
+	}
+	c, err := newStandaloneContext(ctx, p.Latest.AppContextID)
+	if err != nil {
+		// The AppContext is gone, nothing to deliver
+		replayed.Latest = p.Latest
+		return replayed, err
+	}
+	op := OpApply
+	if p.Latest.Event == TerminateEvent {
+		op = OpDelete
+	}
+	if a, ok := c.ca.Apps[p.App]; ok {
+		if _, ok := a.Clusters[p.Cluster]; ok {
+			err = c.handleCluster(ctx, op, p.Latest.Event, p.App, p.Cluster)
+		}
+	}
+	if pkgerrors.Cause(err) != errClusterOffline {
+		replayed.Latest = p.Latest
+	}
+	return replayed, err
+}
+
+// replayDelete deletes the resources of the app removed by the update
+func replayDelete(ctx context.Context, p PendingOps, d PendingOp) error {
+	c, err := newStandaloneContext(ctx, d.AppContextID)
+	if err != nil {
+		return err
+	}
+	if err := c.updateDeletePhase(ctx, AppContextQueueElement{UCID: d.UCID}); err != nil {
+		return err
+	}
+	a, ok := c.ca.Apps[p.App]
+	if !ok || a.Skip {
+		return nil
+	}
+	if cl, ok := a.Clusters[p.Cluster]; !ok || cl.Skip {
+		return nil
+	}
+	return c.handleCluster(ctx, OpDelete, UpdateDeleteEvent, p.App, p.Cluster)
+}
+
+// removePendingOps removes the replayed operations, keeping the operations queued since
+func removePendingOps(ctx context.Context, replayed PendingOps) error {
+	if replayed.empty() {
+		return nil
+	}
+	pendingData.Lock()
+	defer pendingData.Unlock()
+	key := pendingOpsKey(replayed.Cluster, replayed.StatusAppContextID, replayed.App)
+	var p PendingOps
+	if err := contextdb.Db.Get(ctx, key, &p); err != nil {
+		return err
+	}
+	p.remove(replayed)
+	if p.empty() {
+		return contextdb.Db.Delete(ctx, key)
+	}
+	return contextdb.Db.Put(ctx, key, p)
+}
+
+// appContexts returns the AppContexts of the operations
+func (p *PendingOps) appContexts() []string {
+	var acIDs []string
+	for _, d := range p.Deletes {
+		acIDs = append(acIDs, d.AppContextID)
+	}
+	if p.Latest != nil {
+		acIDs = append(acIDs, p.Latest.AppContextID)
+	}
+	return acIDs
+}
+
+// updatePendingStatus updates the status of the AppContext reported as pending. It is
+// failed if the operation failed, or the desired state once no operations are pending.
+func updatePendingStatus(ctx context.Context, acID string, replayed PendingOps, err error) {
+	acRef, rerr := utils.NewAppContextReference(ctx, acID)
+	if rerr != nil {
+		return
+	}
+	s, serr := acRef.GetAppContextStatus(ctx, StatusKey)
+	if serr != nil || s.Status != appcontext.AppContextStatusEnum.Pending {
+		return
+	}
+	if err != nil && pkgerrors.Cause(err) != errClusterOffline && replayed.Latest != nil && replayed.Latest.AppContextID == acID {
+		log.Error("Error replaying the pending operation", log.Fields{"cluster": replayed.Cluster, "app": replayed.App, "acID": acID, "error": err})
+		es := appcontext.AppContextStatus{Status: StateChanges[replayed.Latest.Event].ErrState}
+		acRef.UpdateAppContextStatus(ctx, StatusKey, es)
+		acRef.UpdateAppContextStatus(ctx, CurrentStateKey, es)
+		return
+	}
+	ca, cerr := contextUtils.ReadAppContext(ctx, acID)
+	if cerr != nil || hasPendingOps(ctx, acID, replayed.StatusAppContextID, appClusters(ca)) {
+		return
+	}
+	ds, serr := acRef.GetAppContextStatus(ctx, DesiredStateKey)
+	if serr != nil {
+		return
+	}
+	log.Info("Pending operations delivered", log.Fields{"acID": acID, "status": ds.Status})
+	acRef.UpdateAppContextStatus(ctx, StatusKey, ds)
+}
@@ -0,0 +1,111 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package context
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+
+	pkgerrors "github.com/pkg/errors"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/contextdb"
+	. "gitlab.com/project-emco/core/emco-base/src/rsync/pkg/types"
+)
+
+func TestPendingOpsMerge(t *testing.T) {
+	p := PendingOps{Cluster: "provider1+cluster1", StatusAppContextID: "1", App: "a1"}
+	// An update of resources never delivered instantiates them
+	p.add(PendingOp{AppContextID: "1", Event: InstantiateEvent})
+	p.add(PendingOp{AppContextID: "2", Event: UpdateEvent})
+	p.add(PendingOp{AppContextID: "1", Event: UpdateDeleteEvent, UCID: "2"})
+	p.add(PendingOp{AppContextID: "1", Event: UpdateDeleteEvent, UCID: "2"})
+	want := PendingOps{Cluster: "provider1+cluster1", StatusAppContextID: "1", App: "a1",
+		Deletes: []PendingOp{{AppContextID: "1", Event: UpdateDeleteEvent, UCID: "2"}},
+		Latest:  &PendingOp{AppContextID: "2", Event: InstantiateEvent},
+	}
+	if !reflect.DeepEqual(p, want) {
+		t.Fatalf("Unexpected pending operations %+v, expected %+v", p, want)
+	}
+	// Only the last operation is delivered
+	p.add(PendingOp{AppContextID: "2", Event: TerminateEvent})
+	if *p.Latest != (PendingOp{AppContextID: "2", Event: TerminateEvent}) {
+		t.Fatalf("Unexpected latest operation %+v", *p.Latest)
+	}
+
+	// The operations queued during the replay are kept
+	replayed := p
+	replayed.Latest = &PendingOp{AppContextID: "2", Event: TerminateEvent}
+	p.add(PendingOp{AppContextID: "2", Event: UpdateDeleteEvent, UCID: "3"})
+	p.add(PendingOp{AppContextID: "3", Event: UpdateEvent})
+	p.remove(replayed)
+	if len(p.Deletes) != 1 || p.Deletes[0].AppContextID != "2" || p.Latest.AppContextID != "3" {
+		t.Fatalf("Unexpected pending operations after replay %+v", p)
+	}
+	p.remove(p)
+	if !p.empty() {
+		t.Fatalf("Pending operations not removed %+v", p)
+	}
+}
+
+func TestHasPendingOps(t *testing.T) {
+	contextdb.Db = new(contextdb.MockConDb)
+	ctx := context.Background()
+	p := PendingOps{Cluster: "provider1+cluster1", StatusAppContextID: "1", App: "a1",
+		Deletes: []PendingOp{{AppContextID: "1", Event: UpdateDeleteEvent, UCID: "2"}},
+		Latest:  &PendingOp{AppContextID: "2", Event: UpdateEvent},
+	}
+	if err := contextdb.Db.Put(ctx, pendingOpsKey(p.Cluster, p.StatusAppContextID, p.App), p); err != nil {
+		t.Fatal(err)
+	}
+	for acID, want := range map[string]bool{"1": true, "2": true, "3": false} {
+		if got := hasPendingOps(ctx, acID, "1", []string{"provider1+cluster2", "provider1+cluster1"}); got != want {
+			t.Errorf("hasPendingOps(%s) = %v, expected %v", acID, got, want)
+		}
+	}
+	if err := removePendingOps(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+	if hasPendingOps(ctx, "2", "1", []string{"provider1+cluster1"}) {
+		t.Errorf("Pending operations of the AppContext not removed")
+	}
+}
+
+func TestGetPendingOpsError(t *testing.T) {
+	ctx := context.Background()
+	savedDb := contextdb.Db
+	defer func() { contextdb.Db = savedDb }()
+	contextdb.Db = &contextdb.MockConDb{Err: pkgerrors.New("etcd unavailable")}
+	// The pending operations are replayed again once they can be read
+	pendingData.Lock()
+	pendingData.replaying["provider1+cluster1"] = true
+	pendingData.Unlock()
+	if _, err := getPendingOps(ctx, pendingPrefix); err == nil {
+		t.Errorf("Expected the error reading the pending operations")
+	}
+	if replayPendingOps(ctx, "provider1+cluster1") {
+		t.Errorf("Replay done while the pending operations can't be read")
+	}
+	pendingData.Lock()
+	defer pendingData.Unlock()
+	if !pendingData.replaying["provider1+cluster1"] {
+		t.Errorf("Replay of the cluster stopped")
+	}
+	delete(pendingData.replaying, "provider1+cluster1")
+}
+
+func TestOfflineTolerantLabel(t *testing.T) {
+	con := NewProvider("1")
+	con.Labels = map[string][]string{"provider1+cluster1": {OfflineTolerantLabel}}
+	c := &Context{con: &con, offline: &sync.Map{}}
+	ctx := context.Background()
+	if !c.isOfflineTolerant(ctx, "provider1+cluster1") || c.isOfflineTolerant(ctx, "provider1+cluster2") {
+		t.Fatalf("Unexpected offline tolerant clusters")
+	}
+	// The label is read once per event
+	con.Labels = nil
+	if !c.isOfflineTolerant(ctx, "provider1+cluster1") {
+		t.Errorf("Label of the cluster not cached")
+	}
+}
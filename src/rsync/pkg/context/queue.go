@@ -0,0 +1,169 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package context
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/internal/utils"
+	. "gitlab.com/project-emco/core/emco-base/src/rsync/pkg/types"
+)
+
+// Status of the event being handled, it is Pending in the queue
+const runningStatus = "Running"
+
+// Status of the events cancelled before being handled
+const cancelledStatus = "Cancelled"
+
+// QueueEvent is an event in the queue of an AppContext
+type QueueEvent struct {
+	Index  int
+	Event  RsyncEvent
+	UCID   string
+	Status string
+}
+
+// InProgress is the resource being handled for an app on a cluster
+type InProgress struct {
+	App       string
+	Cluster   string
+	Operation string
+	// Empty while waiting for the cluster to be reachable
+	Resource string
+	Since    time.Time
+}
+
+// QueueInfo is the queue of an AppContext and the resources being handled
+type QueueInfo struct {
+	Events     []QueueEvent
+	Running    bool
+	InProgress []InProgress
+}
+
+// progressData tracks the resources being handled by app and cluster
+type progressData struct {
+	clusters map[string]InProgress
+	sync.Mutex
+}
+
+func newProgressData() *progressData {
+	return &progressData{clusters: map[string]InProgress{}}
+}
+
+// set records the resource being handled for the app on the cluster
+func (p *progressData) set(app, cluster string, op RsyncOperation, res string) {
+	if p == nil {
+		return
+	}
+	p.Lock()
+	defer p.Unlock()
+	p.clusters[app+"+"+cluster] = InProgress{App: app, Cluster: cluster, Operation: op.String(), Resource: res, Since: time.Now()}
+}
+
+// clear records that nothing is being handled for the app on the cluster
+func (p *progressData) clear(app, cluster string) {
+	if p == nil {
+		return
+	}
+	p.Lock()
+	defer p.Unlock()
+	delete(p.clusters, app+"+"+cluster)
+}
+
+func (p *progressData) list() []InProgress {
+	if p == nil {
+		return nil
+	}
+	p.Lock()
+	defer p.Unlock()
+	var l []InProgress
+	for _, ip := range p.clusters {
+		l = append(l, ip)
+	}
+	sort.Slice(l, func(i, j int) bool {
+		if l[i].App != l[j].App {
+			return l[i].App < l[j].App
+		}
+		return l[i].Cluster < l[j].Cluster
+	})
+	return l
+}
+
+// GetQueue returns the event queue of the AppContext and the resources being handled
+func GetQueue(ctx context.Context, acID string) (QueueInfo, error) {
+	ref, err := utils.NewAppContextReference(ctx, acID)
+	if err != nil {
+		return QueueInfo{}, err
+	}
+	qUtils := &AppContextQueueUtils{ac: ref.GetAppContextHandle()}
+	// The AppContext has no thread data if rsync didn't handle it since it started
+	appContextData.Lock()
+	c, ok := appContextData.Data[acID]
+	appContextData.Unlock()
+	if !ok {
+		c = &Context{Lock: &sync.Mutex{}, current: -1}
+	}
+	c.Lock.Lock()
+	defer c.Lock.Unlock()
+	qi := QueueInfo{Running: c.Running}
+	// No queue until the first event of the AppContext
+	q, err := qUtils.GetAppContextQueue(ctx)
+	if err != nil {
+		return qi, nil
+	}
+	for i, e := range q.AcQueue {
+		s := e.Status
+		if c.Running && i == c.current {
+			s = runningStatus
+		}
+		qi.Events = append(qi.Events, QueueEvent{Index: i, Event: e.Event, UCID: e.UCID, Status: s})
+	}
+	if c.Running {
+		qi.InProgress = c.progress.list()
+	}
+	return qi, nil
+}
+
+// CancelEvent cancels the pending event of the AppContext at the index of the queue
+func CancelEvent(ctx context.Context, acID string, index int) error {
+	ref, err := utils.NewAppContextReference(ctx, acID)
+	if err != nil {
+		return err
+	}
+	qUtils := &AppContextQueueUtils{ac: ref.GetAppContextHandle()}
+	_, c := CreateAppContextData(acID)
+	c.Lock.Lock()
+	defer c.Lock.Unlock()
+	q, err := qUtils.GetAppContextQueue(ctx)
+	if err != nil {
+		return pkgerrors.Wrap(err, "Error reading the AppContext queue")
+	}
+	if index < 0 || index >= len(q.AcQueue) {
+		return pkgerrors.Errorf("Event not found in the AppContext queue: %d", index)
+	}
+	if (c.Running && index == c.current) || q.AcQueue[index].Status != "Pending" {
+		return pkgerrors.Errorf("Event is not pending: %d", index)
+	}
+	if err := checkCancellable(q.AcQueue[index]); err != nil {
+		return err
+	}
+	return qUtils.UpdateStatus(ctx, index, cancelledStatus)
+}
+
+// checkCancellable returns an error if cancelling the event would leave the AppContext half handled
+func checkCancellable(e AppContextQueueElement) error {
+	switch e.Event {
+	case UpdateDeleteEvent:
+		// Queued once the resources of the updated AppContext are applied, it deletes the stale resources
+		return pkgerrors.Errorf("The UpdateDelete event of the update to AppContext %s can't be cancelled", e.UCID)
+	case TerminateEvent:
+		// The events of the AppContext being handled were stopped when it was queued
+		return pkgerrors.New("The Terminate event can't be cancelled")
+	}
+	return nil
+}
@@ -7,10 +7,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	pkgerrors "github.com/pkg/errors"
 	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/appcontext"
 	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/metrics"
 	types "gitlab.com/project-emco/core/emco-base/src/rsync/pkg/types"
 )
 
@@ -122,6 +124,7 @@ func (aq *AppContextQueueUtils) UpdateQueue(ctx context.Context, q []types.AppCo
 		log.Error("Error in updating Qhandle", log.Fields{"err": err})
 		return false, err
 	}
+	aq.recordQueueDepth(ctx, q)
 
 	return true, nil
 }
@@ -144,9 +147,27 @@ func (aq *AppContextQueueUtils) CreateQueue(ctx context.Context, qElement types.
 	}
 	qhandle := fmt.Sprintf("%v", qHandle)
 	log.Info("AppContextQueue created :: Qhandle :: ", log.Fields{"qhandle": qhandle})
+	aq.recordQueueDepth(ctx, q)
 	return true, nil
 }
 
+// recordQueueDepth updates the queue depth metric with the number of pending events
+func (aq *AppContextQueueUtils) recordQueueDepth(ctx context.Context, q []types.AppContextQueueElement) {
+	h, err := aq.ac.GetCompositeAppHandle(ctx)
+	if err != nil {
+		return
+	}
+	pending := 0
+	for _, e := range q {
+		if e.Status == "Pending" {
+			pending++
+		}
+	}
+	// The composite app handle is "/context/<acID>/"
+	acID := strings.TrimSuffix(strings.TrimPrefix(fmt.Sprintf("%v", h), "/context/"), "/")
+	metrics.SetQueueDepth(acID, pending)
+}
+
 func (aq *AppContextQueueUtils) FindFirstPending(ctx context.Context) (int, types.AppContextQueueElement) {
 	q, err := aq.GetAppContextQueue(ctx)
 	if err != nil {
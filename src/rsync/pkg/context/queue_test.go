@@ -0,0 +1,45 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package context
+
+import (
+	"testing"
+
+	. "gitlab.com/project-emco/core/emco-base/src/rsync/pkg/types"
+)
+
+func TestProgress(t *testing.T) {
+	p := newProgressData()
+	p.set("a2", "provider1+cluster1", OpApply, "")
+	p.set("a1", "provider1+cluster2", OpDelete, "r1")
+	p.set("a1", "provider1+cluster1", OpApply, "r1")
+	p.set("a1", "provider1+cluster1", OpApply, "r2")
+	l := p.list()
+	if len(l) != 3 || l[0].Cluster != "provider1+cluster1" || l[0].Resource != "r2" || l[1].Operation != OpDelete.String() || l[2].App != "a2" {
+		t.Fatalf("Unexpected resources in progress %+v", l)
+	}
+	p.clear("a1", "provider1+cluster1")
+	if l := p.list(); len(l) != 2 {
+		t.Fatalf("Resources in progress not cleared %+v", l)
+	}
+	// Contexts handling resources outside of the queue don't track them
+	var np *progressData
+	np.set("a1", "provider1+cluster1", OpApply, "r1")
+	if l := np.list(); l != nil {
+		t.Fatalf("Unexpected resources in progress %+v", l)
+	}
+}
+
+func TestCheckCancellable(t *testing.T) {
+	for _, e := range []RsyncEvent{InstantiateEvent, UpdateEvent, ReadEvent} {
+		if err := checkCancellable(AppContextQueueElement{Event: e, Status: "Pending"}); err != nil {
+			t.Errorf("Event %s not cancellable: %s", e, err)
+		}
+	}
+	for _, e := range []RsyncEvent{UpdateDeleteEvent, TerminateEvent} {
+		if err := checkCancellable(AppContextQueueElement{Event: e, Status: "Pending", UCID: "100"}); err == nil {
+			t.Errorf("Event %s cancellable", e)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package context
+
+import (
+	"context"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/appcontext"
+	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/resourcestatus"
+	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/internal/utils"
+	. "gitlab.com/project-emco/core/emco-base/src/rsync/pkg/types"
+)
+
+// helmRelease returns the Helm release of the app, if the app is deployed as a release
+func (c *Context) helmRelease(app string) (appcontext.HelmRelease, bool) {
+	hr, ok := c.ca.CompMetadata.HelmReleases[app]
+	return hr, ok
+}
+
+// releaseInstalled returns true if the Helm release of the app was installed on
+// the cluster by the AppContext updating this one
+func (c *Context) releaseInstalled(ctx context.Context, app, cluster string) bool {
+	if c.ucid == "" {
+		return false
+	}
+	uRef, err := utils.NewAppContextReference(ctx, c.ucid)
+	if err != nil {
+		return false
+	}
+	return uRef.GetClusterReleaseInstalled(ctx, app, cluster)
+}
+
+// installRelease installs or upgrades the Helm release of the app with the
+// resources, or rolls it back if the AppContext was deployed before. The
+// resources are applied one by one if the cluster doesn't support releases.
+func (r *resProvd) installRelease(ctx context.Context, hr appcontext.HelmRelease, resources []string) (int, error) {
+	rp, ok := AsReleaseProvider(r.cl)
+	if !ok {
+		log.Warn("Helm releases not supported by the cluster, applying the resources", log.Fields{"cluster": r.cluster, "app": r.app})
+		r.context.acRef.SetClusterReleaseInstalled(ctx, r.app, r.cluster, false)
+		return r.handleResources(ctx, OpApply, resources)
+	}
+	if len(resources) <= 0 {
+		return 0, nil
+	}
+	label := r.context.statusAcID + "-" + r.app
+	var content [][]byte
+	for _, name := range resources {
+		r.updateResourceStatus(ctx, name, resourcestatus.ResourceStatus{Status: resourcestatus.RsyncStatusEnum.Pending})
+		res, _, err := r.context.acRef.GetRes(ctx, name, r.app, r.cluster)
+		if err != nil {
+			r.updateResourceStatus(ctx, name, resourcestatus.ResourceStatus{Status: resourcestatus.RsyncStatusEnum.Failed})
+			return 0, err
+		}
+		// The resources keep the label of the status tracker
+		b, err := r.cl.TagResource(res, label)
+		if err != nil {
+			log.Error("Error Tag Resoruce with label:", log.Fields{"err": err, "label": label, "resource": name})
+			return 0, err
+		}
+		content = append(content, b)
+	}
+	err := r.handleRelease(ctx, OpApply, resources, func() error {
+		return rp.InstallRelease(ctx, hr, content)
+	})
+	if err != nil {
+		return 0, err
+	}
+	// The upgrades of the release delete the resources removed from it
+	if err := r.context.acRef.SetClusterReleaseInstalled(ctx, r.app, r.cluster, true); err != nil {
+		log.Error("Failed to record the install of the release", log.Fields{"error": err, "cluster": r.cluster, "app": r.app})
+	}
+	r.updateStatuses(ctx, resources, resourcestatus.ResourceStatus{Status: resourcestatus.RsyncStatusEnum.Applied})
+	return len(resources), nil
+}
+
+// uninstallRelease uninstalls the Helm release of the app with its resources
+func (r *resProvd) uninstallRelease(ctx context.Context, resources []string) error {
+	rp, ok := AsReleaseProvider(r.cl)
+	if !ok {
+		_, err := r.handleResources(ctx, OpDelete, resources)
+		return err
+	}
+	if err := r.handleRelease(ctx, OpDelete, resources, func() error {
+		return rp.UninstallRelease(ctx)
+	}); err != nil {
+		return err
+	}
+	r.updateStatuses(ctx, resources, resourcestatus.ResourceStatus{Status: resourcestatus.RsyncStatusEnum.Deleted})
+	return nil
+}
+
+// handleRelease runs the operation on the release once the cluster is
+// reachable, retrying it while it fails with transient errors
+func (r *resProvd) handleRelease(ctx context.Context, op RsyncOperation, resources []string, f func() error) error {
+	b := r.retry.newBackoff()
+	if b.policy.maxRetries < 0 && b.policy.maxElapsedTime == 0 {
+		b.policy.maxRetries = defaultResourceRetries
+	}
+	for {
+		r.context.progress.set(r.app, r.cluster, op, "")
+		if err := r.waitForClusterReady(ctx); err != nil {
+			return err
+		}
+		err := f()
+		if err == nil {
+			return nil
+		}
+		log.Error("Error in release", log.Fields{"error": err, "cluster": r.cluster, "app": r.app, "operation": op.String()})
+		// The cluster is retried as a whole if it is not reachable
+		if r.cl.IsReachable() != nil {
+			continue
+		}
+		if !r.retry.retryResources || !isTransient(err) {
+			r.updateStatuses(ctx, resources, resourcestatus.ResourceStatus{Status: resourcestatus.RsyncStatusEnum.Failed,
+				RetryCount: b.retries, LastError: err.Error()})
+			return err
+		}
+		d, ok := b.next()
+		if !ok {
+			r.updateStatuses(ctx, resources, resourcestatus.ResourceStatus{Status: resourcestatus.RsyncStatusEnum.Failed,
+				RetryCount: b.retries, LastError: err.Error()})
+			return pkgerrors.Wrapf(err, "Retries exceeded for the release of app %s", r.app)
+		}
+		r.updateStatuses(ctx, resources, resourcestatus.ResourceStatus{Status: resourcestatus.RsyncStatusEnum.Retrying,
+			RetryCount: b.retries, LastError: err.Error()})
+		select {
+		case <-time.After(d):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// updateStatuses updates the status of all the resources of the release
+func (r *resProvd) updateStatuses(ctx context.Context, resources []string, status resourcestatus.ResourceStatus) {
+	for _, name := range resources {
+		r.updateResourceStatus(ctx, name, status)
+	}
+}
@@ -0,0 +1,206 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package context
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"strings"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/appcontext"
+	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/resourcestatus"
+	. "gitlab.com/project-emco/core/emco-base/src/rsync/pkg/types"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClusterRetryPolicyKv is the name of the cluster key value pairs overriding
+// the retry policy of the deployment intent groups on the cluster
+const ClusterRetryPolicyKv = "retry-policy"
+
+// Retries of a resource if the policy limits neither the retries nor the time
+const defaultResourceRetries = 5
+
+// retryPolicy is the backoff between the retries of a resource or a cluster
+type retryPolicy struct {
+	// Negative for unlimited retries
+	maxRetries      int
+	initialInterval time.Duration
+	maxInterval     time.Duration
+	multiplier      float64
+	jitter          float64
+	// Zero for no time limit
+	maxElapsedTime time.Duration
+	// Resources failing with transient errors are only retried with a retry policy
+	retryResources bool
+}
+
+// getRetryPolicy returns the retry policy of the AppContext on the cluster. The
+// default policy retries an unreachable cluster at a fixed interval, up to the
+// configured max-retries.
+func (c *Context) getRetryPolicy(ctx context.Context, cluster string) retryPolicy {
+	p := retryPolicy{
+		maxRetries:      c.maxRetry,
+		initialInterval: time.Duration(c.waitTime) * time.Second,
+		multiplier:      1,
+	}
+	if rp := c.acRef.GetRetryPolicy(ctx); rp != nil {
+		p.merge(*rp)
+	}
+	if cp := c.getClusterRetryPolicy(ctx, cluster); cp != nil {
+		p.merge(*cp)
+	}
+	return p
+}
+
+// merge overrides the policy with the values set in the retry policy
+func (p *retryPolicy) merge(rp appcontext.RetryPolicy) {
+	p.retryResources = true
+	if rp.MaxRetries > 0 {
+		p.maxRetries = rp.MaxRetries
+	}
+	if rp.InitialInterval > 0 {
+		p.initialInterval = time.Duration(rp.InitialInterval) * time.Second
+	}
+	if rp.MaxInterval > 0 {
+		p.maxInterval = time.Duration(rp.MaxInterval) * time.Second
+	}
+	if rp.Multiplier >= 1 {
+		p.multiplier = rp.Multiplier
+	}
+	if rp.Jitter > 0 && rp.Jitter <= 1 {
+		p.jitter = rp.Jitter
+	}
+	if rp.MaxElapsedTime > 0 {
+		p.maxElapsedTime = time.Duration(rp.MaxElapsedTime) * time.Second
+	}
+}
+
+// getClusterRetryPolicy reads the retry policy from the key value pairs of the cluster
+func (c *Context) getClusterRetryPolicy(ctx context.Context, cluster string) *appcontext.RetryPolicy {
+	kvs, err := c.con.GetClusterKvPairs(ctx, cluster, ClusterRetryPolicyKv)
+	if err != nil || len(kvs) == 0 {
+		return nil
+	}
+	values := map[string]interface{}{}
+	for _, kv := range kvs {
+		for k, v := range kv {
+			values[k] = v
+		}
+	}
+	rp := appcontext.RetryPolicy{}
+	b, err := json.Marshal(values)
+	if err == nil {
+		err = json.Unmarshal(b, &rp)
+	}
+	if err != nil {
+		log.Error("Invalid retry policy of the cluster", log.Fields{"cluster": cluster, "error": err})
+		return nil
+	}
+	return &rp
+}
+
+// backoff tracks the retries of an operation under a retry policy
+type backoff struct {
+	policy   retryPolicy
+	start    time.Time
+	retries  int
+	interval time.Duration
+}
+
+func (p retryPolicy) newBackoff() *backoff {
+	return &backoff{policy: p, start: time.Now(), interval: p.initialInterval}
+}
+
+// next returns the wait before the next retry, and false once the retries are exhausted
+func (b *backoff) next() (time.Duration, bool) {
+	if b.policy.maxRetries >= 0 && b.retries >= b.policy.maxRetries {
+		return 0, false
+	}
+	d := b.interval
+	if b.policy.jitter > 0 {
+		d = time.Duration(float64(d) * (1 + b.policy.jitter*(2*rand.Float64()-1)))
+	}
+	if b.policy.maxElapsedTime > 0 && time.Since(b.start)+d > b.policy.maxElapsedTime {
+		return 0, false
+	}
+	b.retries++
+	b.interval = time.Duration(float64(b.interval) * b.policy.multiplier)
+	if b.policy.maxInterval > 0 && b.interval > b.policy.maxInterval {
+		b.interval = b.policy.maxInterval
+	}
+	return d, true
+}
+
+// isTransient returns true for the errors of a resource which may go away on
+// retry, like a conflict or a CRD which is not yet installed. Other errors,
+// like an invalid manifest, fail at once.
+func isTransient(err error) bool {
+	if isNoMatch(err) {
+		return true
+	}
+	switch k8serrors.ReasonForError(err) {
+	case metav1.StatusReasonConflict, metav1.StatusReasonNotFound, metav1.StatusReasonServerTimeout,
+		metav1.StatusReasonTimeout, metav1.StatusReasonTooManyRequests, metav1.StatusReasonInternalError,
+		metav1.StatusReasonServiceUnavailable:
+		return true
+	}
+	return false
+}
+
+// isNoMatch returns true if the kind of the resource is not known by the cluster
+func isNoMatch(err error) bool {
+	var kindErr *meta.NoKindMatchError
+	var resErr *meta.NoResourceMatchError
+	if errors.As(err, &kindErr) || errors.As(err, &resErr) {
+		return true
+	}
+	// The errors of the connector plugins only keep their message
+	return strings.Contains(err.Error(), "no matches for kind")
+}
+
+// handleResourceWithRetry handles the resource, retrying it while it fails with
+// transient errors. The retries and the last error are recorded in the status.
+func (r *resProvd) handleResourceWithRetry(ctx context.Context, op RsyncOperation, res string, ref interface{}) (interface{}, bool, error) {
+	b := r.retry.newBackoff()
+	if b.policy.maxRetries < 0 && b.policy.maxElapsedTime == 0 {
+		b.policy.maxRetries = defaultResourceRetries
+	}
+	for {
+		q, breakonError, err := r.handleResource(ctx, op, res, ref)
+		if err == nil {
+			return q, breakonError, nil
+		}
+		// The cluster is retried as a whole if it is not reachable
+		if r.cl.IsReachable() != nil {
+			return q, breakonError, err
+		}
+		if !r.retry.retryResources || !isTransient(err) {
+			r.recordRetries(ctx, res, resourcestatus.RsyncStatusEnum.Failed, b.retries, err)
+			return q, breakonError, err
+		}
+		d, ok := b.next()
+		if !ok {
+			r.recordRetries(ctx, res, resourcestatus.RsyncStatusEnum.Failed, b.retries, err)
+			return q, breakonError, pkgerrors.Wrapf(err, "Retries exceeded for resource %s", res)
+		}
+		log.Info("Retrying resource", log.Fields{"cluster": r.cluster, "resource": res, "retry count": b.retries, "error": err})
+		r.recordRetries(ctx, res, resourcestatus.RsyncStatusEnum.Retrying, b.retries, err)
+		select {
+		case <-time.After(d):
+		case <-ctx.Done():
+			return q, breakonError, ctx.Err()
+		}
+	}
+}
+
+func (r *resProvd) recordRetries(ctx context.Context, res string, status resourcestatus.RsyncStatus, retries int, err error) {
+	r.updateResourceStatus(ctx, res, resourcestatus.ResourceStatus{Status: status, RetryCount: retries, LastError: err.Error()})
+}
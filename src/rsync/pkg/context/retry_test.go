@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package context
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/appcontext"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestBackoff(t *testing.T) {
+	p := retryPolicy{maxRetries: -1, initialInterval: 2 * time.Second, multiplier: 1}
+	p.merge(appcontext.RetryPolicy{MaxRetries: 5, InitialInterval: 1, MaxInterval: 4, Multiplier: 2})
+	b := p.newBackoff()
+	for _, want := range []time.Duration{1, 2, 4, 4, 4} {
+		d, ok := b.next()
+		if !ok || d != want*time.Second {
+			t.Fatalf("Unexpected backoff %v %v, expected %v", d, ok, want*time.Second)
+		}
+	}
+	if _, ok := b.next(); ok {
+		t.Fatalf("Retries not exhausted after %d retries", b.retries)
+	}
+
+	// The elapsed time bounds the retries
+	p = retryPolicy{maxRetries: -1, initialInterval: time.Second, multiplier: 1, maxElapsedTime: time.Second}
+	b = p.newBackoff()
+	b.start = time.Now().Add(-time.Second)
+	if _, ok := b.next(); ok {
+		t.Fatal("Retry beyond the maximum elapsed time")
+	}
+
+	// The jitter stays within its fraction of the interval
+	p = retryPolicy{maxRetries: -1, initialInterval: 10 * time.Second, multiplier: 1, jitter: 0.5}
+	b = p.newBackoff()
+	for i := 0; i < 100; i++ {
+		if d, _ := b.next(); d < 5*time.Second || d > 15*time.Second {
+			t.Fatalf("Unexpected backoff with jitter %v", d)
+		}
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	gr := schema.GroupResource{Group: "apps", Resource: "deployments"}
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{k8serrors.NewConflict(gr, "d1", fmt.Errorf("modified")), true},
+		{k8serrors.NewTooManyRequests("throttled", 1), true},
+		{&meta.NoKindMatchError{GroupKind: schema.GroupKind{Group: "example.com", Kind: "Widget"}}, true},
+		{k8serrors.NewInvalid(schema.GroupKind{Group: "apps", Kind: "Deployment"}, "d1", nil), false},
+		{k8serrors.NewBadRequest("bad manifest"), false},
+		{pkgerrors.New("no matches for kind \"Widget\" in version \"example.com/v1\""), true},
+		{pkgerrors.New("error converting YAML to JSON"), false},
+	}
+	for _, test := range tests {
+		// The errors are wrapped by the clients
+		err := fmt.Errorf("cannot apply object: %w", pkgerrors.Wrap(test.err, "apply"))
+		if got := isTransient(err); got != test.want {
+			t.Errorf("isTransient(%v) = %v, expected %v", test.err, got, test.want)
+		}
+	}
+}
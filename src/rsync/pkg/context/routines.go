@@ -7,17 +7,21 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	pkgerrors "github.com/pkg/errors"
 	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/appcontext"
 	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/tracing"
 	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/depend"
 	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/internal/utils"
 	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/status"
 	. "gitlab.com/project-emco/core/emco-base/src/rsync/pkg/types"
 	contextUtils "gitlab.com/project-emco/core/emco-base/src/rsync/pkg/utils"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/sync/errgroup"
 )
@@ -96,6 +100,10 @@ func (c *Context) UpdateQStatus(ctx context.Context, index int, status string) e
 	if err := qUtils.UpdateStatus(ctx, index, status); err != nil {
 		return err
 	}
+	// The event is handled
+	if index == c.current {
+		c.current = -1
+	}
 	return nil
 }
 
@@ -177,6 +185,7 @@ func (c *Context) startMainThread(ctx context.Context, a interface{}, con Connec
 	}
 	// Intialize dependency management
 	c.dm = depend.NewDependManager(c.acID)
+	c.dm.SetStatusAppContext(c.statusAcID)
 	// Start Routine to handle AppContext
 	go c.appContextRoutine(ctx)
 	return nil
@@ -235,6 +244,7 @@ func (c *Context) appContextRoutine(callerCtx context.Context) {
 		c.Lock.Lock()
 		index, ele := qUtils.FindFirstPending(ctx)
 		if index >= 0 {
+			c.current = index
 			c.Lock.Unlock()
 			e := ele.Event
 			state, skip, err := c.checkStateChange(ctx, e)
@@ -259,11 +269,18 @@ func (c *Context) appContextRoutine(callerCtx context.Context) {
 				// Continue to process more events
 				continue
 			}
+			// Handle the event in the trace of the request that queued it
+			ectx := tracing.ExtractTraceContext(ctx, ele.TraceContext)
+			ectx, eventSpan := tracer.Start(ectx, "handleEvent",
+				trace.WithAttributes(tracing.AppContextIDKey.String(c.acID), attribute.String("event", string(e))),
+			)
 			// Create a derived context
-			l, lDone = context.WithCancel(ctx)
+			l, lDone = context.WithCancel(ectx)
 			lGroup, lctx = errgroup.WithContext(l)
 			c.Lock.Lock()
 			c.cancel = lDone
+			c.ucid = ele.UCID
+			c.offline = &sync.Map{}
 			c.Lock.Unlock()
 			switch e {
 			case InstantiateEvent:
@@ -281,7 +298,7 @@ func (c *Context) appContextRoutine(callerCtx context.Context) {
 				}
 				op = OpApply
 				// Enqueue Delete Event for the AppContext that is being updated to
-				go HandleAppContext(ctx, ele.UCID, c.acID, UpdateDeleteEvent, c.con)
+				go HandleAppContext(ectx, ele.UCID, c.acID, UpdateDeleteEvent, c.con)
 			case UpdateDeleteEvent:
 				// Update AppContext to decide what needs deleted
 				if err := c.updateDeletePhase(ctx, ele); err != nil {
@@ -290,12 +307,14 @@ func (c *Context) appContextRoutine(callerCtx context.Context) {
 				op = OpDelete
 			case AddChildContextEvent:
 				log.Error("Not Implemented", log.Fields{"event": e})
+				eventSpan.End()
 				if err := c.UpdateQStatus(ctx, index, "Skip"); err != nil {
 					break
 				}
 				continue
 			default:
 				log.Error("Unknown event", log.Fields{"event": e})
+				eventSpan.End()
 				if err := c.UpdateQStatus(ctx, index, "Skip"); err != nil {
 					break
 				}
@@ -306,7 +325,12 @@ func (c *Context) appContextRoutine(callerCtx context.Context) {
 			})
 			// Wait for all subtasks to complete
 			log.Info("Wait for all subtasks to complete", log.Fields{})
-			if err := lGroup.Wait(); err != nil {
+			err = lGroup.Wait()
+			if err != nil {
+				eventSpan.SetStatus(codes.Error, err.Error())
+			}
+			eventSpan.End()
+			if err != nil {
 				log.Error("Failed run", log.Fields{"error": err})
 				// Mark the event in Queue
 				if err := c.UpdateQStatus(ctx, index, "Error"); err != nil {
@@ -332,7 +356,12 @@ func (c *Context) appContextRoutine(callerCtx context.Context) {
 
 			// Success - Update Status for the AppContext to match the Desired State
 			ds, _ := c.acRef.GetAppContextStatus(ctx, DesiredStateKey)
-			err = c.acRef.UpdateAppContextStatus(ctx, StatusKey, ds)
+			// Report the operations pending on offline clusters
+			if hasPendingOps(ctx, c.acID, c.statusAcID, appClusters(c.ca)) {
+				err = c.acRef.UpdateAppContextStatus(ctx, StatusKey, appcontext.AppContextStatus{Status: appcontext.AppContextStatusEnum.Pending})
+			} else {
+				err = c.acRef.UpdateAppContextStatus(ctx, StatusKey, ds)
+			}
 			err = c.acRef.UpdateAppContextStatus(ctx, CurrentStateKey, ds)
 
 		} else {
@@ -377,10 +406,19 @@ func (c *Context) updateDeletePhase(ctx context.Context, e AppContextQueueElemen
 	for _, app := range c.ca.Apps {
 		foundApp := contextUtils.FindApp(uca, app.Name)
 		// If app not found that will be deleted (skip false)
-		if foundApp {
+		if !foundApp {
+			for _, cluster := range app.Clusters {
+				cluster.Removed = true
+			}
+		} else {
 			// Check if any clusters are deleted
 			for _, cluster := range app.Clusters {
 				foundCluster := contextUtils.FindCluster(uca, app.Name, cluster.Name)
+				cluster.Removed = !foundCluster
+				// The resources removed from a Helm release are deleted by its upgrade, once installed
+				if _, ok := c.helmRelease(app.Name); ok && foundCluster {
+					cluster.UpgradeRelease = true
+				}
 				if foundCluster {
 					// Check if any resources are deleted
 					var resCnt int = 0
@@ -526,6 +564,35 @@ func (c *Context) runApp(ctx context.Context, g *errgroup.Group, op RsyncOperati
 
 func (c *Context) runCluster(ctx context.Context, op RsyncOperation, e RsyncEvent, app, cluster string) error {
 	log.Info(" runCluster::", log.Fields{"app": app, "cluster": cluster})
+	ctx, span := otel.Tracer("rsync").Start(ctx, "runCluster",
+		trace.WithAttributes(
+			tracing.AppContextIDKey.String(c.acID),
+			attribute.String("app", app),
+			attribute.String("cluster", cluster),
+		),
+	)
+	defer span.End()
+	// Operations on a cluster with pending operations are delivered after them
+	queued, err := c.queuePendingOp(ctx, e, app, cluster, true)
+	if err == nil && !queued {
+		err = c.handleCluster(ctx, op, e, app, cluster)
+		// Operations on an offline cluster are delivered once it is reachable again
+		if pkgerrors.Cause(err) == errClusterOffline {
+			_, err = c.queuePendingOp(ctx, e, app, cluster, false)
+		}
+	}
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
+
+func (c *Context) handleCluster(ctx context.Context, op RsyncOperation, e RsyncEvent, app, cluster string) error {
+	// Wait for a worker, shared fairly with the other AppContexts
+	if err := workers.Acquire(ctx, c.acID); err != nil {
+		return pkgerrors.Wrap(err, "Waiting for a worker")
+	}
+	defer workers.Release(c.acID)
 	namespace, level := c.acRef.GetNamespace(ctx)
 	cl, err := c.con.GetClientProviders(ctx, app, cluster, level, namespace)
 	if err != nil {
@@ -533,6 +600,16 @@ func (c *Context) runCluster(ctx context.Context, op RsyncOperation, e RsyncEven
 		return err
 	}
 	defer cl.CleanClientProvider()
+	r := resProvd{app: app, cluster: cluster, cl: cl, context: *c, offlineTolerant: c.isOfflineTolerant(ctx, cluster),
+		retry: c.getRetryPolicy(ctx, cluster)}
+	c.progress.set(app, cluster, op, "")
+	defer c.progress.clear(app, cluster)
+	// Nothing is attempted on an offline tolerant cluster which is offline
+	if r.offlineTolerant {
+		if err := r.waitForClusterReady(ctx); err != nil {
+			return err
+		}
+	}
 	// Start cluster watcher if there are resources to be watched
 	// case like admin cloud has no resources
 	if len(c.ca.Apps[app].Clusters[cluster].ResOrder) > 0 {
@@ -549,9 +626,12 @@ func (c *Context) runCluster(ctx context.Context, op RsyncOperation, e RsyncEven
 			"cluster": cluster,
 		})
 	}
-	r := resProvd{app: app, cluster: cluster, cl: cl, context: *c}
 	// Timer key
 	key := app + depend.SEPARATOR + cluster
+	// An app removed from the cluster by an update is deleted as on termination, with its delete hooks
+	if e == UpdateDeleteEvent && c.ca.Apps[app].Clusters[cluster].Removed {
+		e = TerminateEvent
+	}
 	switch e {
 	case InstantiateEvent:
 		// Apply config for the cluster if there are any resources to be applied
@@ -580,7 +660,7 @@ func (c *Context) runCluster(ctx context.Context, op RsyncOperation, e RsyncEven
 				return err
 			}
 			// Install Preinstall hooks with wait
-			_, err := r.handleResourcesWithWait(ctx, op, c.ca.Apps[app].Clusters[cluster].Dependency["pre-install"])
+			err := r.runHooks(ctx, "pre-install")
 			if err != nil {
 				r.deleteStatusTracker(ctx, status.PreInstallHookLabel, namespace)
 				return err
@@ -591,7 +671,12 @@ func (c *Context) runCluster(ctx context.Context, op RsyncOperation, e RsyncEven
 		}
 		// Install main resources without wait
 		log.Info("Installing main resources", log.Fields{"App": app, "cluster": cluster, "resources": c.ca.Apps[app].Clusters[cluster].ResOrder})
-		i, err := r.handleResources(ctx, op, c.ca.Apps[app].Clusters[cluster].ResOrder)
+		var i int
+		if hr, ok := c.helmRelease(app); ok {
+			i, err = r.installRelease(ctx, hr, c.ca.Apps[app].Clusters[cluster].ResOrder)
+		} else {
+			i, err = r.handleResources(ctx, op, c.ca.Apps[app].Clusters[cluster].ResOrder)
+		}
 		// handle status tracking before exiting if at least one resource got handled
 		if i > 0 {
 			// Add Status tracking
@@ -605,7 +690,7 @@ func (c *Context) runCluster(ctx context.Context, op RsyncOperation, e RsyncEven
 		if len(c.ca.Apps[app].Clusters[cluster].Dependency["post-install"]) > 0 {
 			log.Info("Installing Post-install Hooks", log.Fields{"App": app, "cluster": cluster, "hooks": c.ca.Apps[app].Clusters[cluster].Dependency["post-install"]})
 			// Install Postinstall hooks with wait
-			_, err = r.handleResourcesWithWait(ctx, op, c.ca.Apps[app].Clusters[cluster].Dependency["post-install"])
+			err = r.runHooks(ctx, "post-install")
 			if err != nil {
 				return err
 			}
@@ -615,21 +700,25 @@ func (c *Context) runCluster(ctx context.Context, op RsyncOperation, e RsyncEven
 		// Apply Predelete hooks with wait
 		if len(c.ca.Apps[app].Clusters[cluster].Dependency["pre-delete"]) > 0 {
 			log.Info("Deleting pre-delete Hooks", log.Fields{"App": app, "cluster": cluster, "hooks": c.ca.Apps[app].Clusters[cluster].Dependency["pre-delete"]})
-			_, err = r.handleResourcesWithWait(ctx, OpApply, c.ca.Apps[app].Clusters[cluster].Dependency["pre-delete"])
+			err = r.runHooks(ctx, "pre-delete")
 			if err != nil {
 				return err
 			}
 			log.Info("Done Deleting pre-delete Hooks", log.Fields{"App": app, "cluster": cluster, "hooks": c.ca.Apps[app].Clusters[cluster].Dependency["pre-delete"]})
 		}
 		// Delete main resources without wait
-		_, err = r.handleResources(ctx, op, c.ca.Apps[app].Clusters[cluster].ResOrder)
+		if _, ok := c.helmRelease(app); ok {
+			err = r.uninstallRelease(ctx, c.ca.Apps[app].Clusters[cluster].ResOrder)
+		} else {
+			_, err = r.handleResources(ctx, op, c.ca.Apps[app].Clusters[cluster].ResOrder)
+		}
 		if err != nil {
 			return err
 		}
 		// Apply Postdelete hooks with wait
 		if len(c.ca.Apps[app].Clusters[cluster].Dependency["post-delete"]) > 0 {
 			log.Info("Deleting post-delete Hooks", log.Fields{"App": app, "cluster": cluster, "hooks": c.ca.Apps[app].Clusters[cluster].Dependency["post-delete"]})
-			_, err = r.handleResourcesWithWait(ctx, OpApply, c.ca.Apps[app].Clusters[cluster].Dependency["post-delete"])
+			err = r.runHooks(ctx, "post-delete")
 			if err != nil {
 				return err
 			}
@@ -658,7 +747,18 @@ func (c *Context) runCluster(ctx context.Context, op RsyncOperation, e RsyncEven
 		timer := ScheduleDeleteStatusTracker(ctx, c.statusAcID, app, cluster, level, namespace, c.con)
 		c.UpdateDeleteStatusCRTimer(key, timer)
 	case UpdateEvent, UpdateDeleteEvent:
-		// Update and Rollback hooks are not supported at this time
+		// The upgrade hooks of the updated AppContext run before and after its resources
+		if e == UpdateEvent && len(c.ca.Apps[app].Clusters[cluster].Dependency["pre-upgrade"]) > 0 {
+			log.Info("Installing pre-upgrade hooks", log.Fields{"App": app, "cluster": cluster, "hooks": c.ca.Apps[app].Clusters[cluster].Dependency["pre-upgrade"]})
+			// The status tracker is missing on the clusters added by the update
+			if err := r.addStatusTracker(ctx, "", namespace); err != nil {
+				return err
+			}
+			if err := r.runHooks(ctx, "pre-upgrade"); err != nil {
+				return err
+			}
+			log.Info("Done Installing pre-upgrade hooks", log.Fields{"App": app, "cluster": cluster, "hooks": c.ca.Apps[app].Clusters[cluster].Dependency["pre-upgrade"]})
+		}
 		var rl []string
 		// Find resources to handle based on skip bit
 		resOrder := c.ca.Apps[app].Clusters[cluster].ResOrder
@@ -668,8 +768,22 @@ func (c *Context) runCluster(ctx context.Context, op RsyncOperation, e RsyncEven
 				rl = append(rl, res)
 			}
 		}
-		// Handle main resources without wait
-		_, err = r.handleResources(ctx, op, rl)
+		if hr, ok := c.helmRelease(app); ok {
+			// The release is upgraded with all its resources, or uninstalled
+			// if the app is no longer on the cluster
+			if op == OpApply {
+				_, err = r.installRelease(ctx, hr, resOrder)
+			} else if !c.ca.Apps[app].Clusters[cluster].UpgradeRelease {
+				err = r.uninstallRelease(ctx, resOrder)
+			} else if !c.releaseInstalled(ctx, app, cluster) {
+				// The removed resources are deleted one by one if the upgraded release
+				// was not installed, or not yet, through the cluster
+				_, err = r.handleResources(ctx, op, rl)
+			}
+		} else {
+			// Handle main resources without wait
+			_, err = r.handleResources(ctx, op, rl)
+		}
 		if err != nil {
 			return err
 		}
@@ -677,6 +791,13 @@ func (c *Context) runCluster(ctx context.Context, op RsyncOperation, e RsyncEven
 		if op == OpApply {
 			r.addStatusTracker(ctx, "", namespace)
 		}
+		if e == UpdateEvent && len(c.ca.Apps[app].Clusters[cluster].Dependency["post-upgrade"]) > 0 {
+			log.Info("Installing post-upgrade hooks", log.Fields{"App": app, "cluster": cluster, "hooks": c.ca.Apps[app].Clusters[cluster].Dependency["post-upgrade"]})
+			if err := r.runHooks(ctx, "post-upgrade"); err != nil {
+				return err
+			}
+			log.Info("Done Installing post-upgrade hooks", log.Fields{"App": app, "cluster": cluster, "hooks": c.ca.Apps[app].Clusters[cluster].Dependency["post-upgrade"]})
+		}
 	}
 	return nil
 }
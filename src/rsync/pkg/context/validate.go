@@ -0,0 +1,169 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package context
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ghodss/yaml"
+	pkgerrors "github.com/pkg/errors"
+	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/connector"
+	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/internal/utils"
+	. "gitlab.com/project-emco/core/emco-base/src/rsync/pkg/types"
+	contextUtils "gitlab.com/project-emco/core/emco-base/src/rsync/pkg/utils"
+	"golang.org/x/sync/errgroup"
+)
+
+// Results of the validation of a resource
+const (
+	ValidationValid       = "Valid"
+	ValidationInvalid     = "Invalid"
+	ValidationSkipped     = "Skipped"
+	ValidationUnreachable = "Unreachable"
+)
+
+var errDryRunNotSupported = pkgerrors.New("Validation is not supported by the connector of the cluster")
+
+// ValidationResult is the result of the dry run of a resource on its cluster.
+// The resource is empty for the results of a whole cluster.
+type ValidationResult struct {
+	App      string
+	Cluster  string
+	Resource string
+	Result   string
+	Error    string
+}
+
+// ValidateAppContext sends the resources of the AppContext to their clusters
+// with a server side dry run. Nothing is changed in the clusters.
+func ValidateAppContext(ctx context.Context, acID string) ([]ValidationResult, error) {
+	acRef, err := utils.NewAppContextReference(ctx, acID)
+	if err != nil {
+		return nil, err
+	}
+	ca, err := contextUtils.ReadAppContext(ctx, acID)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "Error reading the AppContext")
+	}
+	con := connector.NewProvider(acID)
+	// The AppContext is not deployed, it has no thread data and no status
+	c := &Context{Lock: &sync.Mutex{}, acID: acID, acRef: acRef, statusAcID: acID, scRef: acRef, ca: ca, con: &con}
+
+	var results []ValidationResult
+	var mutex sync.Mutex
+	g, gctx := errgroup.WithContext(ctx)
+	for _, app := range ca.AppOrder {
+		a, ok := ca.Apps[app]
+		if !ok || a.Skip {
+			continue
+		}
+		for cluster, cl := range a.Clusters {
+			if cl.Skip {
+				continue
+			}
+			app, cluster := app, cluster
+			g.Go(func() error {
+				// Validations share the workers with the deployments
+				if err := workers.Acquire(gctx, acID); err != nil {
+					return err
+				}
+				defer workers.Release(acID)
+				r := c.validateCluster(gctx, app, cluster)
+				mutex.Lock()
+				results = append(results, r...)
+				mutex.Unlock()
+				return nil
+			})
+		}
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].App != results[j].App {
+			return results[i].App < results[j].App
+		}
+		return results[i].Cluster < results[j].Cluster
+	})
+	return results, nil
+}
+
+// validateCluster validates the resources of the app on the cluster, the CRDs first
+func (c *Context) validateCluster(ctx context.Context, app, cluster string) []ValidationResult {
+	namespace, level := c.acRef.GetNamespace(ctx)
+	cl, err := c.con.GetClientProviders(ctx, app, cluster, level, namespace)
+	if err != nil {
+		log.Error("Error in creating client", log.Fields{"error": err, "cluster": cluster, "app": app})
+		return []ValidationResult{{App: app, Cluster: cluster, Result: ValidationUnreachable, Error: err.Error()}}
+	}
+	defer cl.CleanClientProvider()
+	if _, ok := AsDryRunProvider(cl); !ok {
+		return []ValidationResult{{App: app, Cluster: cluster, Result: ValidationSkipped, Error: errDryRunNotSupported.Error()}}
+	}
+	if err := cl.IsReachable(); err != nil {
+		return []ValidationResult{{App: app, Cluster: cluster, Result: ValidationUnreachable, Error: err.Error()}}
+	}
+	r := resProvd{app: app, cluster: cluster, cl: cl, context: *c}
+	crds := c.ca.Apps[app].Clusters[cluster].Dependency["crd-install"]
+	kinds := r.crdKinds(ctx, crds)
+	var results []ValidationResult
+	for _, res := range append(append([]string{}, crds...), c.ca.Apps[app].Clusters[cluster].ResOrder...) {
+		result := ValidationResult{App: app, Cluster: cluster, Resource: res, Result: ValidationValid}
+		if _, _, err := r.handleResource(ctx, OpValidate, res, nil); err != nil {
+			result.Result = ValidationInvalid
+			result.Error = err.Error()
+			// The resources of a CRD of the app can't be validated before the CRD is installed
+			s := strings.SplitN(res, "+", 2)
+			if len(s) == 2 && kinds[s[1]] && isNoMatch(err) {
+				result.Result = ValidationSkipped
+			}
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// crdKinds returns the kinds defined by the CRDs
+func (r *resProvd) crdKinds(ctx context.Context, crds []string) map[string]bool {
+	kinds := map[string]bool{}
+	for _, name := range crds {
+		res, _, err := r.context.acRef.GetRes(ctx, name, r.app, r.cluster)
+		if err != nil {
+			continue
+		}
+		crd := struct {
+			Spec struct {
+				Names struct {
+					Kind string `json:"kind"`
+				} `json:"names"`
+			} `json:"spec"`
+		}{}
+		if err := yaml.Unmarshal(res, &crd); err == nil && crd.Spec.Names.Kind != "" {
+			kinds[crd.Spec.Names.Kind] = true
+		}
+	}
+	return kinds
+}
+
+// validateResource sends the resource to the cluster with a dry run
+func (r *resProvd) validateResource(ctx context.Context, name string) error {
+	res, _, err := r.context.acRef.GetRes(ctx, name, r.app, r.cluster)
+	if err != nil {
+		return err
+	}
+	label := r.context.statusAcID + "-" + r.app
+	b, err := r.cl.TagResource(res, label)
+	if err != nil {
+		return err
+	}
+	v, ok := AsDryRunProvider(r.cl)
+	if !ok {
+		return errDryRunNotSupported
+	}
+	return v.DryRun(ctx, name, b)
+}
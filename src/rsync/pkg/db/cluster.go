@@ -47,6 +47,27 @@ type ClusterSyncObjectsKey struct {
 	ClusterSyncObjectsName string `json:"clusterSyncObject"`
 }
 
+// ClusterLabelKey is the key structure of the cluster labels in the database
+type ClusterLabelKey struct {
+	ClusterProviderName string `json:"clusterProvider"`
+	ClusterName         string `json:"cluster"`
+	ClusterLabelName    string `json:"clusterLabel"`
+}
+
+// ClusterKvPairsKey is the key structure of the cluster key value pairs in the database
+type ClusterKvPairsKey struct {
+	ClusterProviderName string `json:"clusterProvider"`
+	ClusterName         string `json:"cluster"`
+	ClusterKvPairsName  string `json:"clusterKv"`
+}
+
+// clusterKvPairs is the part of the cluster key value pairs read by rsync
+type clusterKvPairs struct {
+	Spec struct {
+		Kv []map[string]interface{} `json:"kv"`
+	} `json:"spec"`
+}
+
 // CloudConfig contains the parameters that specify access to a cloud at any level
 type CloudGitOpsConfig struct {
 	Provider  string            `json:"cloudConfigClusterProvider"`
@@ -378,6 +399,43 @@ func (c *CloudConfigClient) GetAllClusterSyncObjects(ctx context.Context, provid
 	return resp, nil
 }
 
+// HasClusterLabel returns true if the cluster is tagged with the label
+func (c *CloudConfigClient) HasClusterLabel(ctx context.Context, provider, cluster, label string) (bool, error) {
+	key := ClusterLabelKey{
+		ClusterProviderName: provider,
+		ClusterName:         cluster,
+		ClusterLabelName:    label,
+	}
+	// The cluster labels are stored by clm under the data tag
+	values, err := db.DBconn.Find(ctx, c.db.storeName, key, "data")
+	if err != nil {
+		return false, err
+	}
+	return len(values) > 0, nil
+}
+
+// GetClusterKvPairs returns the key value pairs of the cluster, nil if they don't exist
+func (c *CloudConfigClient) GetClusterKvPairs(ctx context.Context, provider, cluster, kvpair string) ([]map[string]interface{}, error) {
+	key := ClusterKvPairsKey{
+		ClusterProviderName: provider,
+		ClusterName:         cluster,
+		ClusterKvPairsName:  kvpair,
+	}
+	// The cluster key value pairs are stored by clm under the data tag
+	values, err := db.DBconn.Find(ctx, c.db.storeName, key, "data")
+	if err != nil {
+		return nil, err
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+	kv := clusterKvPairs{}
+	if err := db.DBconn.Unmarshal(values[0], &kv); err != nil {
+		return nil, err
+	}
+	return kv.Spec.Kv, nil
+}
+
 // CreateGitOpsConfig allows to create a new cloud config entry to hold a kubeconfig for access
 func (c *CloudConfigClient) CreateGitOpsConfig(ctx context.Context, provider string, cluster string, gs mtypes.GitOpsSpec, level string, namespace string) (CloudGitOpsConfig, error) {
 	key := CloudConfigKey{
@@ -0,0 +1,50 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package db
+
+import (
+	"context"
+
+	pkgerrors "github.com/pkg/errors"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/db"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/state"
+)
+
+// deploymentIntentGroupKey is the key of the deployment intent groups stored by the orchestrator
+type deploymentIntentGroupKey struct {
+	Name         string `json:"deploymentIntentGroup"`
+	Project      string `json:"project"`
+	CompositeApp string `json:"compositeApp"`
+	Version      string `json:"compositeAppVersion"`
+}
+
+// GetDeployedAppContexts returns the current AppContexts of the deployment intent groups which
+// are deployed, the previous ones of their updates are not returned
+func GetDeployedAppContexts(ctx context.Context) ([]string, error) {
+	values, err := db.DBconn.Find(ctx, "resources", deploymentIntentGroupKey{}, "stateInfo")
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "Error finding the deployment intent groups")
+	}
+	var acIDs []string
+	for _, value := range values {
+		if value == nil {
+			continue
+		}
+		s := state.StateInfo{}
+		if err := db.DBconn.Unmarshal(value, &s); err != nil {
+			return nil, pkgerrors.Wrap(err, "Error reading the state of a deployment intent group")
+		}
+		current, err := state.GetCurrentStateFromStateInfo(s)
+		if err != nil {
+			continue
+		}
+		switch current {
+		case state.StateEnum.Instantiated, state.StateEnum.Updated:
+			if acID := state.GetLastContextIdFromStateInfo(s); acID != "" {
+				acIDs = append(acIDs, acID)
+			}
+		}
+	}
+	return acIDs, nil
+}
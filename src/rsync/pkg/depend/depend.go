@@ -19,6 +19,8 @@ import (
 
 type DependManager struct {
 	acID string
+	// AppContext receiving the status of the resources
+	statusAcID string
 	// Per App Ready channels to notify
 	readyCh map[string][]appData
 	// Per App Deploy channels to notify
@@ -59,7 +61,8 @@ const SEPARATOR = "+"
 // New Manager for acID
 func NewDependManager(acID string) *DependManager {
 	d := DependManager{
-		acID: acID,
+		acID:       acID,
+		statusAcID: acID,
 	}
 	d.deployedCh = make(map[string][]appData)
 	d.readyCh = make(map[string][]appData)
@@ -71,6 +74,14 @@ func NewDependManager(acID string) *DependManager {
 	return &d
 }
 
+// SetStatusAppContext sets the AppContext receiving the status of the resources,
+// which is the AppContext first instantiated after an update
+func (dm *DependManager) SetStatusAppContext(statusAcID string) {
+	dm.Lock()
+	dm.statusAcID = statusAcID
+	dm.Unlock()
+}
+
 // Function registers an app for dependency
 func (dm *DependManager) AddDependency(app string, dep map[string]*types.Criteria) error {
 
@@ -244,62 +255,77 @@ func ResourcesReady(ctx context.Context, acID, app, cluster string) {
 	dmList.RLock()
 	// Check if AppContext has dependency
 	dm, ok := dmList.dm[acID]
-	if !ok {
-		dmList.RUnlock()
-		return
+	// The resources of the updated AppContexts report their status to the first one
+	var resDms []*DependManager
+	for _, d := range dmList.dm {
+		d.RLock()
+		if d.statusAcID == acID {
+			resDms = append(resDms, d)
+		}
+		d.RUnlock()
 	}
 	dmList.RUnlock()
 	key := app + SEPARATOR + cluster
-	// If no app is waiting for ready status of the app
-	// Not further processing needed
-	dm.RLock()
-	if len(dm.readyCh[app]) == 0 && len(dm.resCh[key]) == 0 {
+	if ok {
+		dm.RLock()
+		length := len(dm.readyCh[app])
 		dm.RUnlock()
-		return
-	}
-	length := len(dm.readyCh[app])
-	dm.RUnlock()
-	if length > 0 {
-		// Inform waiting apps
-		go func() {
-			ctx, span := tracer.Start(context.Background(), "ResourcesReady",
-				trace.WithLinks(trace.LinkFromContext(ctx)),
-			)
-			defer span.End()
-			// Check in appContext if app is ready on all clusters inform ready status
-			acUtils, err := utils.NewAppContextReference(ctx, acID)
-			if err != nil {
-				return
-			}
-			if acUtils.CheckAppReadyOnAllClusters(ctx, app) {
-				// Notify the apps waiting for the app to be ready
-				dm.NotifyReadyStatus(app)
-			}
-		}()
+		// If no app is waiting for ready status of the app
+		// Not further processing needed
+		if length > 0 {
+			// Inform waiting apps
+			go func() {
+				ctx, span := tracer.Start(context.Background(), "ResourcesReady",
+					trace.WithLinks(trace.LinkFromContext(ctx)),
+				)
+				defer span.End()
+				// Check in appContext if app is ready on all clusters inform ready status
+				acUtils, err := utils.NewAppContextReference(ctx, acID)
+				if err != nil {
+					return
+				}
+				if acUtils.CheckAppReadyOnAllClusters(ctx, app) {
+					// Notify the apps waiting for the app to be ready
+					dm.NotifyReadyStatus(app)
+				}
+			}()
+		}
 	}
-	dm.RLock()
-	res := dm.resCh[app]
-	dm.RUnlock()
-	if len(res) > 0 {
-		go func() {
+	for _, d := range resDms {
+		d.RLock()
+		var res []resData
+		for _, r := range d.resCh[key] {
+			res = append(res, r)
+		}
+		d.RUnlock()
+		if len(res) == 0 {
+			continue
+		}
+		go func(d *DependManager) {
 			ctx, span := tracer.Start(context.Background(), "ResourcesReady",
 				trace.WithLinks(trace.LinkFromContext(ctx)),
 			)
 			defer span.End()
 			for _, r := range res {
-				b := dm.GetResourceReadyStatus(ctx, app, cluster, r.res)
+				b := d.GetResourceReadyStatus(ctx, app, cluster, r.res)
 				if b {
 					// If succeded inform the waiting channel
-					r.ch <- struct{}{}
+					select {
+					case r.ch <- struct{}{}:
+					default:
+					}
 				}
 			}
-		}()
+		}(d)
 	}
 }
 
 func (dm *DependManager) GetResourceReadyStatus(ctx context.Context, app, cluster, res string) bool {
 	var resStatus bool
-	acUtils, err := utils.NewAppContextReference(ctx, dm.acID)
+	dm.RLock()
+	statusAcID := dm.statusAcID
+	dm.RUnlock()
+	acUtils, err := utils.NewAppContextReference(ctx, statusAcID)
 	if err != nil {
 		return false
 	}
@@ -0,0 +1,975 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.28.0
+// 	protoc        v3.11.4
+// source: rsyncmgmt.proto
+
+package rsyncmgmt
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GetQueueRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	AppContext string `protobuf:"bytes,1,opt,name=app_context,json=appContext,proto3" json:"app_context,omitempty"`
+}
+
+func (x *GetQueueRequest) Reset() {
+	*x = GetQueueRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rsyncmgmt_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetQueueRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetQueueRequest) ProtoMessage() {}
+
+func (x *GetQueueRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rsyncmgmt_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetQueueRequest.ProtoReflect.Descriptor instead.
+func (*GetQueueRequest) Descriptor() ([]byte, []int) {
+	return file_rsyncmgmt_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *GetQueueRequest) GetAppContext() string {
+	if x != nil {
+		return x.AppContext
+	}
+	return ""
+}
+
+type QueueEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Position of the event in the queue
+	Index int32  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Event string `protobuf:"bytes,2,opt,name=event,proto3" json:"event,omitempty"`
+	// AppContext of the update, for the Update and UpdateDelete events
+	Ucid string `protobuf:"bytes,3,opt,name=ucid,proto3" json:"ucid,omitempty"`
+	// Pending, Running, Done, Error, Skip or Cancelled
+	Status string `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+}
+
+func (x *QueueEvent) Reset() {
+	*x = QueueEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rsyncmgmt_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *QueueEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueueEvent) ProtoMessage() {}
+
+func (x *QueueEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_rsyncmgmt_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueueEvent.ProtoReflect.Descriptor instead.
+func (*QueueEvent) Descriptor() ([]byte, []int) {
+	return file_rsyncmgmt_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *QueueEvent) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *QueueEvent) GetEvent() string {
+	if x != nil {
+		return x.Event
+	}
+	return ""
+}
+
+func (x *QueueEvent) GetUcid() string {
+	if x != nil {
+		return x.Ucid
+	}
+	return ""
+}
+
+func (x *QueueEvent) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type InProgress struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	App string `protobuf:"bytes,1,opt,name=app,proto3" json:"app,omitempty"`
+	// <cluster provider>+<cluster>
+	Cluster   string `protobuf:"bytes,2,opt,name=cluster,proto3" json:"cluster,omitempty"`
+	Operation string `protobuf:"bytes,3,opt,name=operation,proto3" json:"operation,omitempty"`
+	// Resource being handled, empty while the cluster is not reachable
+	Resource string `protobuf:"bytes,4,opt,name=resource,proto3" json:"resource,omitempty"`
+	// Start of the handling of the resource, RFC 3339
+	Since string `protobuf:"bytes,5,opt,name=since,proto3" json:"since,omitempty"`
+}
+
+func (x *InProgress) Reset() {
+	*x = InProgress{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rsyncmgmt_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *InProgress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InProgress) ProtoMessage() {}
+
+func (x *InProgress) ProtoReflect() protoreflect.Message {
+	mi := &file_rsyncmgmt_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InProgress.ProtoReflect.Descriptor instead.
+func (*InProgress) Descriptor() ([]byte, []int) {
+	return file_rsyncmgmt_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *InProgress) GetApp() string {
+	if x != nil {
+		return x.App
+	}
+	return ""
+}
+
+func (x *InProgress) GetCluster() string {
+	if x != nil {
+		return x.Cluster
+	}
+	return ""
+}
+
+func (x *InProgress) GetOperation() string {
+	if x != nil {
+		return x.Operation
+	}
+	return ""
+}
+
+func (x *InProgress) GetResource() string {
+	if x != nil {
+		return x.Resource
+	}
+	return ""
+}
+
+func (x *InProgress) GetSince() string {
+	if x != nil {
+		return x.Since
+	}
+	return ""
+}
+
+type GetQueueResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Events []*QueueEvent `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	// True if rsync is processing the events of the AppContext
+	Running    bool          `protobuf:"varint,2,opt,name=running,proto3" json:"running,omitempty"`
+	InProgress []*InProgress `protobuf:"bytes,3,rep,name=in_progress,json=inProgress,proto3" json:"in_progress,omitempty"`
+}
+
+func (x *GetQueueResponse) Reset() {
+	*x = GetQueueResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rsyncmgmt_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetQueueResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetQueueResponse) ProtoMessage() {}
+
+func (x *GetQueueResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rsyncmgmt_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetQueueResponse.ProtoReflect.Descriptor instead.
+func (*GetQueueResponse) Descriptor() ([]byte, []int) {
+	return file_rsyncmgmt_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetQueueResponse) GetEvents() []*QueueEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+func (x *GetQueueResponse) GetRunning() bool {
+	if x != nil {
+		return x.Running
+	}
+	return false
+}
+
+func (x *GetQueueResponse) GetInProgress() []*InProgress {
+	if x != nil {
+		return x.InProgress
+	}
+	return nil
+}
+
+type CancelEventRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	AppContext string `protobuf:"bytes,1,opt,name=app_context,json=appContext,proto3" json:"app_context,omitempty"`
+	Index      int32  `protobuf:"varint,2,opt,name=index,proto3" json:"index,omitempty"`
+}
+
+func (x *CancelEventRequest) Reset() {
+	*x = CancelEventRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rsyncmgmt_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CancelEventRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelEventRequest) ProtoMessage() {}
+
+func (x *CancelEventRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rsyncmgmt_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelEventRequest.ProtoReflect.Descriptor instead.
+func (*CancelEventRequest) Descriptor() ([]byte, []int) {
+	return file_rsyncmgmt_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CancelEventRequest) GetAppContext() string {
+	if x != nil {
+		return x.AppContext
+	}
+	return ""
+}
+
+func (x *CancelEventRequest) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+type CancelEventResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *CancelEventResponse) Reset() {
+	*x = CancelEventResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rsyncmgmt_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CancelEventResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelEventResponse) ProtoMessage() {}
+
+func (x *CancelEventResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rsyncmgmt_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelEventResponse.ProtoReflect.Descriptor instead.
+func (*CancelEventResponse) Descriptor() ([]byte, []int) {
+	return file_rsyncmgmt_proto_rawDescGZIP(), []int{5}
+}
+
+type ValidateAppContextRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	AppContext string `protobuf:"bytes,1,opt,name=app_context,json=appContext,proto3" json:"app_context,omitempty"`
+}
+
+func (x *ValidateAppContextRequest) Reset() {
+	*x = ValidateAppContextRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rsyncmgmt_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ValidateAppContextRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateAppContextRequest) ProtoMessage() {}
+
+func (x *ValidateAppContextRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rsyncmgmt_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateAppContextRequest.ProtoReflect.Descriptor instead.
+func (*ValidateAppContextRequest) Descriptor() ([]byte, []int) {
+	return file_rsyncmgmt_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ValidateAppContextRequest) GetAppContext() string {
+	if x != nil {
+		return x.AppContext
+	}
+	return ""
+}
+
+type ValidationResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	App string `protobuf:"bytes,1,opt,name=app,proto3" json:"app,omitempty"`
+	// <cluster provider>+<cluster>
+	Cluster string `protobuf:"bytes,2,opt,name=cluster,proto3" json:"cluster,omitempty"`
+	// Empty for the results of a whole cluster
+	Resource string `protobuf:"bytes,3,opt,name=resource,proto3" json:"resource,omitempty"`
+	// Valid, Invalid, Skipped or Unreachable
+	Result string `protobuf:"bytes,4,opt,name=result,proto3" json:"result,omitempty"`
+	Error  string `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+}
+
+func (x *ValidationResult) Reset() {
+	*x = ValidationResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rsyncmgmt_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ValidationResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidationResult) ProtoMessage() {}
+
+func (x *ValidationResult) ProtoReflect() protoreflect.Message {
+	mi := &file_rsyncmgmt_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidationResult.ProtoReflect.Descriptor instead.
+func (*ValidationResult) Descriptor() ([]byte, []int) {
+	return file_rsyncmgmt_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ValidationResult) GetApp() string {
+	if x != nil {
+		return x.App
+	}
+	return ""
+}
+
+func (x *ValidationResult) GetCluster() string {
+	if x != nil {
+		return x.Cluster
+	}
+	return ""
+}
+
+func (x *ValidationResult) GetResource() string {
+	if x != nil {
+		return x.Resource
+	}
+	return ""
+}
+
+func (x *ValidationResult) GetResult() string {
+	if x != nil {
+		return x.Result
+	}
+	return ""
+}
+
+func (x *ValidationResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ValidateAppContextResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Results []*ValidationResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+}
+
+func (x *ValidateAppContextResponse) Reset() {
+	*x = ValidateAppContextResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rsyncmgmt_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ValidateAppContextResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateAppContextResponse) ProtoMessage() {}
+
+func (x *ValidateAppContextResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rsyncmgmt_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateAppContextResponse.ProtoReflect.Descriptor instead.
+func (*ValidateAppContextResponse) Descriptor() ([]byte, []int) {
+	return file_rsyncmgmt_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ValidateAppContextResponse) GetResults() []*ValidationResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+var File_rsyncmgmt_proto protoreflect.FileDescriptor
+
+var file_rsyncmgmt_proto_rawDesc = []byte{
+	0x0a, 0x0f, 0x72, 0x73, 0x79, 0x6e, 0x63, 0x6d, 0x67, 0x6d, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x12, 0x09, 0x72, 0x73, 0x79, 0x6e, 0x63, 0x6d, 0x67, 0x6d, 0x74, 0x22, 0x32, 0x0a, 0x0f,
+	0x47, 0x65, 0x74, 0x51, 0x75, 0x65, 0x75, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x1f, 0x0a, 0x0b, 0x61, 0x70, 0x70, 0x5f, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x61, 0x70, 0x70, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74,
+	0x22, 0x64, 0x0a, 0x0a, 0x51, 0x75, 0x65, 0x75, 0x65, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x14,
+	0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x69,
+	0x6e, 0x64, 0x65, 0x78, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x63,
+	0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x75, 0x63, 0x69, 0x64, 0x12, 0x16,
+	0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06,
+	0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x22, 0x88, 0x01, 0x0a, 0x0a, 0x49, 0x6e, 0x50, 0x72, 0x6f,
+	0x67, 0x72, 0x65, 0x73, 0x73, 0x12, 0x10, 0x0a, 0x03, 0x61, 0x70, 0x70, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x03, 0x61, 0x70, 0x70, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6c, 0x75, 0x73, 0x74,
+	0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6c, 0x75, 0x73, 0x74, 0x65,
+	0x72, 0x12, 0x1c, 0x0a, 0x09, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12,
+	0x1a, 0x0a, 0x08, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x08, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x73,
+	0x69, 0x6e, 0x63, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x73, 0x69, 0x6e, 0x63,
+	0x65, 0x22, 0x93, 0x01, 0x0a, 0x10, 0x47, 0x65, 0x74, 0x51, 0x75, 0x65, 0x75, 0x65, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2d, 0x0a, 0x06, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x72, 0x73, 0x79, 0x6e, 0x63, 0x6d, 0x67,
+	0x6d, 0x74, 0x2e, 0x51, 0x75, 0x65, 0x75, 0x65, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52, 0x06, 0x65,
+	0x76, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x72, 0x75, 0x6e, 0x6e, 0x69, 0x6e, 0x67,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x72, 0x75, 0x6e, 0x6e, 0x69, 0x6e, 0x67, 0x12,
+	0x36, 0x0a, 0x0b, 0x69, 0x6e, 0x5f, 0x70, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x18, 0x03,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x72, 0x73, 0x79, 0x6e, 0x63, 0x6d, 0x67, 0x6d, 0x74,
+	0x2e, 0x49, 0x6e, 0x50, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x52, 0x0a, 0x69, 0x6e, 0x50,
+	0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x22, 0x4b, 0x0a, 0x12, 0x43, 0x61, 0x6e, 0x63, 0x65,
+	0x6c, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1f, 0x0a,
+	0x0b, 0x61, 0x70, 0x70, 0x5f, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0a, 0x61, 0x70, 0x70, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x12, 0x14,
+	0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x69,
+	0x6e, 0x64, 0x65, 0x78, 0x22, 0x15, 0x0a, 0x13, 0x43, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x45, 0x76,
+	0x65, 0x6e, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x3c, 0x0a, 0x19, 0x56,
+	0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x41, 0x70, 0x70, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78,
+	0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x61, 0x70, 0x70, 0x5f,
+	0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x61,
+	0x70, 0x70, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x22, 0x88, 0x01, 0x0a, 0x10, 0x56, 0x61,
+	0x6c, 0x69, 0x64, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x10,
+	0x0a, 0x03, 0x61, 0x70, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x61, 0x70, 0x70,
+	0x12, 0x18, 0x0a, 0x07, 0x63, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x07, 0x63, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65,
+	0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x72, 0x65,
+	0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x14,
+	0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65,
+	0x72, 0x72, 0x6f, 0x72, 0x22, 0x53, 0x0a, 0x1a, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65,
+	0x41, 0x70, 0x70, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x35, 0x0a, 0x07, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x72, 0x73, 0x79, 0x6e, 0x63, 0x6d, 0x67, 0x6d, 0x74, 0x2e,
+	0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74,
+	0x52, 0x07, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x32, 0x81, 0x02, 0x0a, 0x09, 0x72, 0x73,
+	0x79, 0x6e, 0x63, 0x6d, 0x67, 0x6d, 0x74, 0x12, 0x43, 0x0a, 0x08, 0x47, 0x65, 0x74, 0x51, 0x75,
+	0x65, 0x75, 0x65, 0x12, 0x1a, 0x2e, 0x72, 0x73, 0x79, 0x6e, 0x63, 0x6d, 0x67, 0x6d, 0x74, 0x2e,
+	0x47, 0x65, 0x74, 0x51, 0x75, 0x65, 0x75, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x1b, 0x2e, 0x72, 0x73, 0x79, 0x6e, 0x63, 0x6d, 0x67, 0x6d, 0x74, 0x2e, 0x47, 0x65, 0x74, 0x51,
+	0x75, 0x65, 0x75, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4c, 0x0a, 0x0b,
+	0x43, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x1d, 0x2e, 0x72, 0x73,
+	0x79, 0x6e, 0x63, 0x6d, 0x67, 0x6d, 0x74, 0x2e, 0x43, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x45, 0x76,
+	0x65, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x72, 0x73, 0x79,
+	0x6e, 0x63, 0x6d, 0x67, 0x6d, 0x74, 0x2e, 0x43, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x45, 0x76, 0x65,
+	0x6e, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x61, 0x0a, 0x12, 0x56, 0x61,
+	0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x41, 0x70, 0x70, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74,
+	0x12, 0x24, 0x2e, 0x72, 0x73, 0x79, 0x6e, 0x63, 0x6d, 0x67, 0x6d, 0x74, 0x2e, 0x56, 0x61, 0x6c,
+	0x69, 0x64, 0x61, 0x74, 0x65, 0x41, 0x70, 0x70, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x25, 0x2e, 0x72, 0x73, 0x79, 0x6e, 0x63, 0x6d, 0x67,
+	0x6d, 0x74, 0x2e, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x41, 0x70, 0x70, 0x43, 0x6f,
+	0x6e, 0x74, 0x65, 0x78, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x0d, 0x5a,
+	0x0b, 0x2e, 0x2f, 0x72, 0x73, 0x79, 0x6e, 0x63, 0x6d, 0x67, 0x6d, 0x74, 0x62, 0x06, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_rsyncmgmt_proto_rawDescOnce sync.Once
+	file_rsyncmgmt_proto_rawDescData = file_rsyncmgmt_proto_rawDesc
+)
+
+func file_rsyncmgmt_proto_rawDescGZIP() []byte {
+	file_rsyncmgmt_proto_rawDescOnce.Do(func() {
+		file_rsyncmgmt_proto_rawDescData = protoimpl.X.CompressGZIP(file_rsyncmgmt_proto_rawDescData)
+	})
+	return file_rsyncmgmt_proto_rawDescData
+}
+
+var file_rsyncmgmt_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_rsyncmgmt_proto_goTypes = []interface{}{
+	(*GetQueueRequest)(nil),            // 0: rsyncmgmt.GetQueueRequest
+	(*QueueEvent)(nil),                 // 1: rsyncmgmt.QueueEvent
+	(*InProgress)(nil),                 // 2: rsyncmgmt.InProgress
+	(*GetQueueResponse)(nil),           // 3: rsyncmgmt.GetQueueResponse
+	(*CancelEventRequest)(nil),         // 4: rsyncmgmt.CancelEventRequest
+	(*CancelEventResponse)(nil),        // 5: rsyncmgmt.CancelEventResponse
+	(*ValidateAppContextRequest)(nil),  // 6: rsyncmgmt.ValidateAppContextRequest
+	(*ValidationResult)(nil),           // 7: rsyncmgmt.ValidationResult
+	(*ValidateAppContextResponse)(nil), // 8: rsyncmgmt.ValidateAppContextResponse
+}
+var file_rsyncmgmt_proto_depIdxs = []int32{
+	1, // 0: rsyncmgmt.GetQueueResponse.events:type_name -> rsyncmgmt.QueueEvent
+	2, // 1: rsyncmgmt.GetQueueResponse.in_progress:type_name -> rsyncmgmt.InProgress
+	7, // 2: rsyncmgmt.ValidateAppContextResponse.results:type_name -> rsyncmgmt.ValidationResult
+	0, // 3: rsyncmgmt.rsyncmgmt.GetQueue:input_type -> rsyncmgmt.GetQueueRequest
+	4, // 4: rsyncmgmt.rsyncmgmt.CancelEvent:input_type -> rsyncmgmt.CancelEventRequest
+	6, // 5: rsyncmgmt.rsyncmgmt.ValidateAppContext:input_type -> rsyncmgmt.ValidateAppContextRequest
+	3, // 6: rsyncmgmt.rsyncmgmt.GetQueue:output_type -> rsyncmgmt.GetQueueResponse
+	5, // 7: rsyncmgmt.rsyncmgmt.CancelEvent:output_type -> rsyncmgmt.CancelEventResponse
+	8, // 8: rsyncmgmt.rsyncmgmt.ValidateAppContext:output_type -> rsyncmgmt.ValidateAppContextResponse
+	6, // [6:9] is the sub-list for method output_type
+	3, // [3:6] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_rsyncmgmt_proto_init() }
+func file_rsyncmgmt_proto_init() {
+	if File_rsyncmgmt_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_rsyncmgmt_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetQueueRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rsyncmgmt_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*QueueEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rsyncmgmt_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*InProgress); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rsyncmgmt_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetQueueResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rsyncmgmt_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CancelEventRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rsyncmgmt_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CancelEventResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rsyncmgmt_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ValidateAppContextRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rsyncmgmt_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ValidationResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rsyncmgmt_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ValidateAppContextResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_rsyncmgmt_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_rsyncmgmt_proto_goTypes,
+		DependencyIndexes: file_rsyncmgmt_proto_depIdxs,
+		MessageInfos:      file_rsyncmgmt_proto_msgTypes,
+	}.Build()
+	File_rsyncmgmt_proto = out.File
+	file_rsyncmgmt_proto_rawDesc = nil
+	file_rsyncmgmt_proto_goTypes = nil
+	file_rsyncmgmt_proto_depIdxs = nil
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// RsyncmgmtClient is the client API for Rsyncmgmt service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type RsyncmgmtClient interface {
+	// Returns the events queued for an AppContext and the resources being handled
+	GetQueue(ctx context.Context, in *GetQueueRequest, opts ...grpc.CallOption) (*GetQueueResponse, error)
+	// Cancels a pending event of an AppContext
+	CancelEvent(ctx context.Context, in *CancelEventRequest, opts ...grpc.CallOption) (*CancelEventResponse, error)
+	// Validates the resources of an AppContext on their clusters with a server side dry run
+	ValidateAppContext(ctx context.Context, in *ValidateAppContextRequest, opts ...grpc.CallOption) (*ValidateAppContextResponse, error)
+}
+
+type rsyncmgmtClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRsyncmgmtClient(cc grpc.ClientConnInterface) RsyncmgmtClient {
+	return &rsyncmgmtClient{cc}
+}
+
+func (c *rsyncmgmtClient) GetQueue(ctx context.Context, in *GetQueueRequest, opts ...grpc.CallOption) (*GetQueueResponse, error) {
+	out := new(GetQueueResponse)
+	err := c.cc.Invoke(ctx, "/rsyncmgmt.rsyncmgmt/GetQueue", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rsyncmgmtClient) CancelEvent(ctx context.Context, in *CancelEventRequest, opts ...grpc.CallOption) (*CancelEventResponse, error) {
+	out := new(CancelEventResponse)
+	err := c.cc.Invoke(ctx, "/rsyncmgmt.rsyncmgmt/CancelEvent", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rsyncmgmtClient) ValidateAppContext(ctx context.Context, in *ValidateAppContextRequest, opts ...grpc.CallOption) (*ValidateAppContextResponse, error) {
+	out := new(ValidateAppContextResponse)
+	err := c.cc.Invoke(ctx, "/rsyncmgmt.rsyncmgmt/ValidateAppContext", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RsyncmgmtServer is the server API for Rsyncmgmt service.
+type RsyncmgmtServer interface {
+	// Returns the events queued for an AppContext and the resources being handled
+	GetQueue(context.Context, *GetQueueRequest) (*GetQueueResponse, error)
+	// Cancels a pending event of an AppContext
+	CancelEvent(context.Context, *CancelEventRequest) (*CancelEventResponse, error)
+	// Validates the resources of an AppContext on their clusters with a server side dry run
+	ValidateAppContext(context.Context, *ValidateAppContextRequest) (*ValidateAppContextResponse, error)
+}
+
+// UnimplementedRsyncmgmtServer can be embedded to have forward compatible implementations.
+type UnimplementedRsyncmgmtServer struct {
+}
+
+func (*UnimplementedRsyncmgmtServer) GetQueue(context.Context, *GetQueueRequest) (*GetQueueResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetQueue not implemented")
+}
+func (*UnimplementedRsyncmgmtServer) CancelEvent(context.Context, *CancelEventRequest) (*CancelEventResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelEvent not implemented")
+}
+func (*UnimplementedRsyncmgmtServer) ValidateAppContext(context.Context, *ValidateAppContextRequest) (*ValidateAppContextResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ValidateAppContext not implemented")
+}
+
+func RegisterRsyncmgmtServer(s *grpc.Server, srv RsyncmgmtServer) {
+	s.RegisterService(&_Rsyncmgmt_serviceDesc, srv)
+}
+
+func _Rsyncmgmt_GetQueue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetQueueRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RsyncmgmtServer).GetQueue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rsyncmgmt.rsyncmgmt/GetQueue",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RsyncmgmtServer).GetQueue(ctx, req.(*GetQueueRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Rsyncmgmt_CancelEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelEventRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RsyncmgmtServer).CancelEvent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rsyncmgmt.rsyncmgmt/CancelEvent",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RsyncmgmtServer).CancelEvent(ctx, req.(*CancelEventRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Rsyncmgmt_ValidateAppContext_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValidateAppContextRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RsyncmgmtServer).ValidateAppContext(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rsyncmgmt.rsyncmgmt/ValidateAppContext",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RsyncmgmtServer).ValidateAppContext(ctx, req.(*ValidateAppContextRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Rsyncmgmt_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "rsyncmgmt.rsyncmgmt",
+	HandlerType: (*RsyncmgmtServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetQueue",
+			Handler:    _Rsyncmgmt_GetQueue_Handler,
+		},
+		{
+			MethodName: "CancelEvent",
+			Handler:    _Rsyncmgmt_CancelEvent_Handler,
+		},
+		{
+			MethodName: "ValidateAppContext",
+			Handler:    _Rsyncmgmt_ValidateAppContext_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "rsyncmgmt.proto",
+}
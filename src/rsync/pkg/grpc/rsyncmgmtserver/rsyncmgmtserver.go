@@ -0,0 +1,69 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package rsyncmgmtserver
+
+import (
+	"context"
+	"time"
+
+	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+	con "gitlab.com/project-emco/core/emco-base/src/rsync/pkg/context"
+	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/grpc/rsyncmgmt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type rsyncmgmtServer struct {
+	rsyncmgmt.UnimplementedRsyncmgmtServer
+}
+
+// GetQueue returns the event queue of the AppContext
+func (cs *rsyncmgmtServer) GetQueue(ctx context.Context, req *rsyncmgmt.GetQueueRequest) (*rsyncmgmt.GetQueueResponse, error) {
+	qi, err := con.GetQueue(ctx, req.GetAppContext())
+	if err != nil {
+		log.Error("Error getting the AppContext queue", log.Fields{"appContext": req.GetAppContext(), "error": err})
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	resp := &rsyncmgmt.GetQueueResponse{Running: qi.Running}
+	for _, e := range qi.Events {
+		resp.Events = append(resp.Events, &rsyncmgmt.QueueEvent{Index: int32(e.Index), Event: string(e.Event), Ucid: e.UCID, Status: e.Status})
+	}
+	for _, ip := range qi.InProgress {
+		resp.InProgress = append(resp.InProgress, &rsyncmgmt.InProgress{App: ip.App, Cluster: ip.Cluster, Operation: ip.Operation,
+			Resource: ip.Resource, Since: ip.Since.Format(time.RFC3339)})
+	}
+	return resp, nil
+}
+
+// CancelEvent cancels a pending event of the AppContext
+func (cs *rsyncmgmtServer) CancelEvent(ctx context.Context, req *rsyncmgmt.CancelEventRequest) (*rsyncmgmt.CancelEventResponse, error) {
+	log.Info("Cancelling the AppContext event", log.Fields{"appContext": req.GetAppContext(), "index": req.GetIndex()})
+	if err := con.CancelEvent(ctx, req.GetAppContext(), int(req.GetIndex())); err != nil {
+		log.Error("Error cancelling the AppContext event", log.Fields{"appContext": req.GetAppContext(), "index": req.GetIndex(), "error": err})
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return &rsyncmgmt.CancelEventResponse{}, nil
+}
+
+// ValidateAppContext validates the resources of the AppContext on their clusters
+func (cs *rsyncmgmtServer) ValidateAppContext(ctx context.Context, req *rsyncmgmt.ValidateAppContextRequest) (*rsyncmgmt.ValidateAppContextResponse, error) {
+	log.Info("Validating the AppContext", log.Fields{"appContext": req.GetAppContext()})
+	results, err := con.ValidateAppContext(ctx, req.GetAppContext())
+	if err != nil {
+		log.Error("Error validating the AppContext", log.Fields{"appContext": req.GetAppContext(), "error": err})
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	resp := &rsyncmgmt.ValidateAppContextResponse{}
+	for _, r := range results {
+		resp.Results = append(resp.Results, &rsyncmgmt.ValidationResult{App: r.App, Cluster: r.Cluster, Resource: r.Resource,
+			Result: r.Result, Error: r.Error})
+	}
+	return resp, nil
+}
+
+// NewRsyncmgmtServer exported
+func NewRsyncmgmtServer() *rsyncmgmtServer {
+	s := &rsyncmgmtServer{}
+	return s
+}
@@ -15,6 +15,12 @@ import (
 	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/resourcestatus"
 )
 
+// Level of the resources in the status AppContext holding the hash of the applied content
+const appliedHashKey = "appliedhash"
+
+// Level of the clusters in the AppContext recording the install of the Helm release of the app
+const releaseInstalledKey = "releaseinstalled"
+
 type AppContextReference struct {
 	acID string
 	ac   appcontext.AppContext
@@ -218,6 +224,15 @@ func (a *AppContextReference) GetNamespace(ctx context.Context) (string, string)
 	return namespace, level
 }
 
+// GetRetryPolicy reads the retry policy from metadata, nil if there is none
+func (a *AppContextReference) GetRetryPolicy(ctx context.Context) *appcontext.RetryPolicy {
+	appmeta, err := a.ac.GetCompositeAppMeta(ctx)
+	if err != nil {
+		return nil
+	}
+	return appmeta.RetryPolicy
+}
+
 //GetLogicalCloudInfo reads logical cloud related info from metadata
 func (a *AppContextReference) GetLogicalCloudInfo(ctx context.Context) (string, string, string, string, string, error) {
 
@@ -405,6 +420,113 @@ func (a *AppContextReference) GetResourceReadyStatus(ctx context.Context, app, c
 	return false
 }
 
+// GetResourceReference gets the ID of the AppContext that last handled the resource
+func (a *AppContextReference) GetResourceReference(ctx context.Context, app, cluster, res string) (string, error) {
+	rh, err := a.ac.GetResourceHandle(ctx, app, cluster, res)
+	if err != nil {
+		return "", err
+	}
+	lh, err := a.ac.GetLevelHandle(ctx, rh, "reference")
+	if err != nil {
+		return "", err
+	}
+	v, err := a.ac.GetValue(ctx, lh)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%v", v), nil
+}
+
+// GetResourceStatus gets the status of the last operation on the resource
+func (a *AppContextReference) GetResourceStatus(ctx context.Context, app, cluster, res string) (resourcestatus.ResourceStatus, error) {
+	var status resourcestatus.ResourceStatus
+	rh, err := a.ac.GetResourceHandle(ctx, app, cluster, res)
+	if err != nil {
+		return status, err
+	}
+	sh, err := a.ac.GetLevelHandle(ctx, rh, "status")
+	if err != nil {
+		return status, err
+	}
+	v, err := a.ac.GetValue(ctx, sh)
+	if err != nil {
+		return status, err
+	}
+	js, err := json.Marshal(v)
+	if err != nil {
+		return status, err
+	}
+	err = json.Unmarshal(js, &status)
+	return status, err
+}
+
+// SetResourceHash records the hash of the content last applied for the resource
+func (a *AppContextReference) SetResourceHash(ctx context.Context, app, cluster, res, hash string) error {
+	rh, err := a.ac.GetResourceHandle(ctx, app, cluster, res)
+	if err != nil {
+		return err
+	}
+	hh, _ := a.ac.GetLevelHandle(ctx, rh, appliedHashKey)
+	// If hash handle was not found, then create it
+	if hh == nil {
+		_, err = a.ac.AddLevelValue(ctx, rh, appliedHashKey, hash)
+		return err
+	}
+	return a.ac.UpdateStatusValue(ctx, hh, hash)
+}
+
+// GetResourceHash gets the hash of the content last applied for the resource,
+// empty if it was never applied
+func (a *AppContextReference) GetResourceHash(ctx context.Context, app, cluster, res string) string {
+	rh, err := a.ac.GetResourceHandle(ctx, app, cluster, res)
+	if err != nil {
+		return ""
+	}
+	hh, _ := a.ac.GetLevelHandle(ctx, rh, appliedHashKey)
+	if hh == nil {
+		return ""
+	}
+	v, err := a.ac.GetValue(ctx, hh)
+	if err != nil {
+		return ""
+	}
+	hash, _ := v.(string)
+	return hash
+}
+
+// SetClusterReleaseInstalled records if the Helm release of the app was installed on the cluster
+func (a *AppContextReference) SetClusterReleaseInstalled(ctx context.Context, app, cluster string, value bool) error {
+	ch, err := a.ac.GetClusterHandle(ctx, app, cluster)
+	if err != nil {
+		return err
+	}
+	rh, _ := a.ac.GetLevelHandle(ctx, ch, releaseInstalledKey)
+	// If release handle was not found, then create it
+	if rh == nil {
+		_, err = a.ac.AddLevelValue(ctx, ch, releaseInstalledKey, value)
+		return err
+	}
+	return a.ac.UpdateStatusValue(ctx, rh, value)
+}
+
+// GetClusterReleaseInstalled returns true if the Helm release of the app was installed on the cluster
+func (a *AppContextReference) GetClusterReleaseInstalled(ctx context.Context, app, cluster string) bool {
+	ch, err := a.ac.GetClusterHandle(ctx, app, cluster)
+	if err != nil {
+		return false
+	}
+	rh, _ := a.ac.GetLevelHandle(ctx, ch, releaseInstalledKey)
+	if rh == nil {
+		return false
+	}
+	v, err := a.ac.GetValue(ctx, rh)
+	if err != nil {
+		return false
+	}
+	installed, _ := v.(bool)
+	return installed
+}
+
 // CheckAppReadyOnAllClusters checks if App is ready on all clusters
 func (a *AppContextReference) CheckAppReadyOnAllClusters(ctx context.Context, app string) bool {
 	// Check if all the clusters are ready
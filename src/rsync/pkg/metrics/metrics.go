@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ResourceOperations counts the create, apply and delete calls made by the plugins
+var ResourceOperations = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "emco_rsync_resource_operations_total",
+	Help: "Count of resource operations by operation, result, cluster and plugin",
+}, []string{"operation", "result", "cluster", "plugin"})
+
+// ResourcesSkipped counts the resources not applied because they are unchanged since they were last applied
+var ResourcesSkipped = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "emco_rsync_resources_skipped_total",
+	Help: "Count of resources skipped by cluster because they are unchanged",
+}, []string{"cluster"})
+
+// AppContextQueueDepth is the number of pending events in the event queue of an AppContext
+var AppContextQueueDepth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "emco_rsync_appcontext_queue_depth",
+	Help: "Number of pending events in the AppContext event queue",
+}, []string{"appcontext"})
+
+// ClusterRetries counts the reachability retries made while waiting for a cluster
+var ClusterRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "emco_rsync_cluster_retries_total",
+	Help: "Count of retries made while waiting for a cluster to become reachable",
+}, []string{"cluster"})
+
+// ClusterReachable is 1 if the cluster was reachable on the last check, 0 otherwise
+var ClusterReachable = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "emco_rsync_cluster_reachable",
+	Help: "Reachability of the cluster on the last check",
+}, []string{"cluster"})
+
+// Collectors returns the rsync collectors to register
+func Collectors() []prometheus.Collector {
+	return []prometheus.Collector{ResourceOperations, ResourcesSkipped, AppContextQueueDepth, ClusterRetries, ClusterReachable}
+}
+
+// Result returns the result label value for an error
+func Result(err error) string {
+	if err != nil {
+		return "failure"
+	}
+	return "success"
+}
+
+// SetClusterReachable records the reachability of a cluster
+func SetClusterReachable(cluster string, reachable bool) {
+	v := 0.0
+	if reachable {
+		v = 1
+	}
+	ClusterReachable.WithLabelValues(cluster).Set(v)
+}
+
+// SetQueueDepth records the number of pending events of an AppContext.
+// The series is removed once the queue is drained.
+func SetQueueDepth(acID string, pending int) {
+	if pending == 0 {
+		AppContextQueueDepth.DeleteLabelValues(acID)
+		return
+	}
+	AppContextQueueDepth.WithLabelValues(acID).Set(float64(pending))
+}
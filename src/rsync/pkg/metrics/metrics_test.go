@@ -0,0 +1,56 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package metrics
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCollectors(t *testing.T) {
+	r := prometheus.NewPedanticRegistry()
+	if err := r.Register(prometheus.NewCounter(prometheus.CounterOpts{Name: "unused", Help: "unused"})); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range Collectors() {
+		if err := r.Register(c); err != nil {
+			t.Errorf("Collector not registered: %s", err)
+		}
+		if problems, err := testutil.CollectAndLint(c); err != nil || len(problems) > 0 {
+			t.Errorf("Collector lint failed: %v %v", problems, err)
+		}
+	}
+}
+
+func TestResult(t *testing.T) {
+	if Result(nil) != "success" || Result(errors.New("failed")) != "failure" {
+		t.Errorf("Unexpected results %s %s", Result(nil), Result(errors.New("failed")))
+	}
+}
+
+func TestSetClusterReachable(t *testing.T) {
+	SetClusterReachable("provider1+cluster1", true)
+	if v := testutil.ToFloat64(ClusterReachable.WithLabelValues("provider1+cluster1")); v != 1 {
+		t.Errorf("Unexpected reachability of a reachable cluster %v", v)
+	}
+	SetClusterReachable("provider1+cluster1", false)
+	if v := testutil.ToFloat64(ClusterReachable.WithLabelValues("provider1+cluster1")); v != 0 {
+		t.Errorf("Unexpected reachability of an unreachable cluster %v", v)
+	}
+}
+
+func TestSetQueueDepth(t *testing.T) {
+	SetQueueDepth("1234", 3)
+	if v := testutil.ToFloat64(AppContextQueueDepth.WithLabelValues("1234")); v != 3 {
+		t.Errorf("Unexpected queue depth %v", v)
+	}
+	// The series of a drained queue is removed
+	SetQueueDepth("1234", 0)
+	if n := testutil.CollectAndCount(AppContextQueueDepth); n != 0 {
+		t.Errorf("Unexpected series of a drained queue %d", n)
+	}
+}
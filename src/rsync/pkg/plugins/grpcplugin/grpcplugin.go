@@ -0,0 +1,217 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package grpcplugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/db"
+	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/grpc/connectorplugin"
+	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/internal/utils"
+)
+
+// Timeout of the calls to the plugin
+var pluginCallTimeout = 2 * time.Minute
+
+// GrpcProvider is the client provider of a cluster whose deployment target is
+// served by an out-of-process connector plugin
+type GrpcProvider struct {
+	cluster string
+	target  string
+	plugin  *plugin
+	client  *connectorplugin.ClientContext
+}
+
+// NewGrpcProvider returns the client provider of the plugin serving the deployment target
+func NewGrpcProvider(ctx context.Context, cid, app, cluster, level, namespace, target string) (*GrpcProvider, error) {
+	p, err := plugins.lookup(target)
+	if err != nil {
+		return nil, err
+	}
+	client, err := newClientContext(ctx, cid, app, cluster, level, namespace, target)
+	if err != nil {
+		return nil, err
+	}
+	return &GrpcProvider{cluster: cluster, target: target, plugin: p, client: client}, nil
+}
+
+// newClientContext returns the client context of the calls to the plugin, with
+// the current configuration of the cluster
+func newClientContext(ctx context.Context, cid, app, cluster, level, namespace, target string) (*connectorplugin.ClientContext, error) {
+	client := &connectorplugin.ClientContext{
+		AppContext:       cid,
+		App:              app,
+		Cluster:          cluster,
+		Level:            level,
+		Namespace:        namespace,
+		DeploymentTarget: target,
+	}
+	// The cluster may have a kubeconfig, GitOps properties or both
+	kc, err := utils.GetKubeConfig(ctx, cluster, level, namespace)
+	if err != nil && !strings.Contains(err.Error(), "Invalid kubeconfig") {
+		return nil, err
+	}
+	client.Kubeconfig = kc
+	if c, err := utils.GetGitOpsConfig(ctx, cluster, "0", "default"); err == nil {
+		client.GitopsType = c.Props.GitOpsType
+		provider := strings.SplitN(cluster, "+", 2)[0]
+		if client.ReferenceObject, err = syncObject(ctx, provider, c.Props.GitOpsReferenceObject); err != nil {
+			return nil, err
+		}
+		if client.ResourceObject, err = syncObject(ctx, provider, c.Props.GitOpsResourceObject); err != nil {
+			return nil, err
+		}
+	}
+	return client, nil
+}
+
+// syncObject returns the key value pairs of the cluster sync object, if any
+func syncObject(ctx context.Context, provider, name string) (map[string]string, error) {
+	if name == "" {
+		return nil, nil
+	}
+	so, err := db.NewCloudConfigClient().GetClusterSyncObjects(ctx, provider, name)
+	if err != nil {
+		log.Error("Invalid cluster sync object", log.Fields{"provider": provider, "syncObject": name, "error": err})
+		return nil, err
+	}
+	kv := map[string]string{}
+	for _, pair := range so.Spec.Kv {
+		for k, v := range pair {
+			kv[k] = fmt.Sprintf("%v", v)
+		}
+	}
+	return kv, nil
+}
+
+// refBytes returns the ref of the plugin, which is nil before the first call
+func refBytes(ref interface{}) []byte {
+	if b, ok := ref.([]byte); ok {
+		return b
+	}
+	return nil
+}
+
+func (p *GrpcProvider) error(op string, err error) error {
+	return pkgerrors.Wrapf(err, "Connector plugin %s failed for deployment target %s on cluster %s", op, p.target, p.cluster)
+}
+
+// Create creates the resource with the plugin
+func (p *GrpcProvider) Create(name string, ref interface{}, content []byte) (interface{}, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), pluginCallTimeout)
+	defer cancel()
+	resp, err := p.plugin.client.Create(ctx, &connectorplugin.ResourceRequest{Client: p.client, Name: name, Ref: refBytes(ref), Content: content})
+	if err != nil {
+		return ref, p.error("create", err)
+	}
+	return resp.Ref, nil
+}
+
+// Apply creates or updates the resource with the plugin
+func (p *GrpcProvider) Apply(ctx context.Context, name string, ref interface{}, content []byte) (interface{}, error) {
+	ctx, cancel := context.WithTimeout(ctx, pluginCallTimeout)
+	defer cancel()
+	resp, err := p.plugin.client.Apply(ctx, &connectorplugin.ResourceRequest{Client: p.client, Name: name, Ref: refBytes(ref), Content: content})
+	if err != nil {
+		return ref, p.error("apply", err)
+	}
+	return resp.Ref, nil
+}
+
+// Delete deletes the resource with the plugin
+func (p *GrpcProvider) Delete(name string, ref interface{}, content []byte) (interface{}, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), pluginCallTimeout)
+	defer cancel()
+	resp, err := p.plugin.client.Delete(ctx, &connectorplugin.ResourceRequest{Client: p.client, Name: name, Ref: refBytes(ref), Content: content})
+	if err != nil {
+		return ref, p.error("delete", err)
+	}
+	return resp.Ref, nil
+}
+
+// Get returns the resource from the cluster
+func (p *GrpcProvider) Get(ctx context.Context, name string, gvkRes []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, pluginCallTimeout)
+	defer cancel()
+	resp, err := p.plugin.client.Get(ctx, &connectorplugin.GetRequest{Client: p.client, Name: name, GvkResource: gvkRes})
+	if err != nil {
+		return nil, p.error("get", err)
+	}
+	return resp.Content, nil
+}
+
+// Commit commits the resources handled with the ref
+func (p *GrpcProvider) Commit(ctx context.Context, ref interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, pluginCallTimeout)
+	defer cancel()
+	if _, err := p.plugin.client.Commit(ctx, &connectorplugin.CommitRequest{Client: p.client, Ref: refBytes(ref)}); err != nil {
+		return p.error("commit", err)
+	}
+	return nil
+}
+
+// IsReachable returns an error if the plugin or the cluster is not reachable
+func (p *GrpcProvider) IsReachable() error {
+	ctx, cancel := context.WithTimeout(context.Background(), pluginCallTimeout)
+	defer cancel()
+	if _, err := p.plugin.client.IsReachable(ctx, &connectorplugin.IsReachableRequest{Client: p.client}); err != nil {
+		return p.error("reachability check", err)
+	}
+	return nil
+}
+
+// TagResource adds the label to the resource
+func (p *GrpcProvider) TagResource(res []byte, label string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), pluginCallTimeout)
+	defer cancel()
+	resp, err := p.plugin.client.TagResource(ctx, &connectorplugin.TagResourceRequest{Client: p.client, Content: res, Label: label})
+	if err != nil {
+		return nil, p.error("tag resource", err)
+	}
+	return resp.Content, nil
+}
+
+// ApplyConfig applies the configuration of the cluster
+func (p *GrpcProvider) ApplyConfig(ctx context.Context, config interface{}) error {
+	b, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, pluginCallTimeout)
+	defer cancel()
+	if _, err := p.plugin.client.ApplyConfig(ctx, &connectorplugin.ConfigRequest{Client: p.client, Config: b}); err != nil {
+		return p.error("apply config", err)
+	}
+	return nil
+}
+
+// DeleteConfig deletes the configuration of the cluster
+func (p *GrpcProvider) DeleteConfig(ctx context.Context, config interface{}) error {
+	b, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, pluginCallTimeout)
+	defer cancel()
+	if _, err := p.plugin.client.DeleteConfig(ctx, &connectorplugin.ConfigRequest{Client: p.client, Config: b}); err != nil {
+		return p.error("delete config", err)
+	}
+	return nil
+}
+
+// CleanClientProvider releases what the plugin holds for the client
+func (p *GrpcProvider) CleanClientProvider() error {
+	ctx, cancel := context.WithTimeout(context.Background(), pluginCallTimeout)
+	defer cancel()
+	if _, err := p.plugin.client.CleanClientProvider(ctx, &connectorplugin.CleanRequest{Client: p.client}); err != nil {
+		return p.error("clean", err)
+	}
+	return nil
+}
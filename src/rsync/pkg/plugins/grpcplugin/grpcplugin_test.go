@@ -0,0 +1,272 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package grpcplugin
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	v1alpha1 "gitlab.com/project-emco/core/emco-base/src/monitor/pkg/apis/k8splugin/v1alpha1"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/config"
+	mtypes "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/module/types"
+	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/grpc/connectorplugin"
+	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/internal/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
+)
+
+// fakePlugin serves the edge deployment target
+type fakePlugin struct {
+	connectorplugin.UnimplementedConnectorpluginServer
+	sync.Mutex
+	committed   string
+	unreachable bool
+	kubeconfig  string
+	watchClient *connectorplugin.ClientContext
+	watching    bool
+}
+
+func (f *fakePlugin) Status(ctx context.Context, req *connectorplugin.StatusRequest) (*connectorplugin.StatusResponse, error) {
+	return &connectorplugin.StatusResponse{Version: PluginProtocolVersion, DeploymentTargets: []string{"edge"}, Healthy: true}, nil
+}
+
+func (f *fakePlugin) Apply(ctx context.Context, req *connectorplugin.ResourceRequest) (*connectorplugin.ResourceResponse, error) {
+	f.Lock()
+	defer f.Unlock()
+	f.kubeconfig = string(req.Client.Kubeconfig)
+	ref := req.Name
+	if len(req.Ref) > 0 {
+		ref = string(req.Ref) + "," + ref
+	}
+	return &connectorplugin.ResourceResponse{Ref: []byte(ref)}, nil
+}
+
+func (f *fakePlugin) Commit(ctx context.Context, req *connectorplugin.CommitRequest) (*connectorplugin.CommitResponse, error) {
+	f.Lock()
+	defer f.Unlock()
+	f.committed = string(req.Ref)
+	return &connectorplugin.CommitResponse{}, nil
+}
+
+func (f *fakePlugin) IsReachable(ctx context.Context, req *connectorplugin.IsReachableRequest) (*connectorplugin.IsReachableResponse, error) {
+	f.Lock()
+	defer f.Unlock()
+	if f.unreachable {
+		return nil, grpcstatus.Error(codes.Unavailable, "edge agent offline")
+	}
+	return &connectorplugin.IsReachableResponse{}, nil
+}
+
+func (f *fakePlugin) WatchStatus(req *connectorplugin.WatchStatusRequest, stream connectorplugin.Connectorplugin_WatchStatusServer) error {
+	f.Lock()
+	f.watchClient, f.watching = req.Client, true
+	f.Unlock()
+	defer func() {
+		f.Lock()
+		f.watching = false
+		f.Unlock()
+	}()
+	if err := stream.Send(&connectorplugin.StatusUpdate{Label: "1234-app1", ResourceBundleState: []byte(`{"metadata":{"name":"rbs"}}`)}); err != nil {
+		return err
+	}
+	<-stream.Context().Done()
+	return nil
+}
+
+func TestGrpcProvider(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	lis, err := net.Listen("unix", filepath.Join(dir, "edge.sock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakePlugin{}
+	s := grpc.NewServer()
+	connectorplugin.RegisterConnectorpluginServer(s, f)
+	go s.Serve(lis)
+	defer s.Stop()
+
+	savedDir := config.GetConfiguration().PluginDir
+	config.GetConfiguration().PluginDir = dir
+	savedKubeConfig, savedGitOpsConfig := utils.GetKubeConfig, utils.GetGitOpsConfig
+	defer func() {
+		config.GetConfiguration().PluginDir = savedDir
+		utils.GetKubeConfig, utils.GetGitOpsConfig = savedKubeConfig, savedGitOpsConfig
+		plugins.discover()
+	}()
+	utils.GetKubeConfig = func(ctx context.Context, cluster, level, namespace string) ([]byte, error) {
+		return []byte("kubeconfig"), nil
+	}
+	utils.GetGitOpsConfig = func(ctx context.Context, cluster, level, namespace string) (mtypes.GitOpsSpec, error) {
+		return mtypes.GitOpsSpec{Props: mtypes.GitOpsProps{DeploymentTarget: "edge"}}, nil
+	}
+
+	plugins.discover()
+	if !Enabled() {
+		t.Fatal("The plugin in the plugin directory was not found")
+	}
+	if _, err := NewGrpcProvider(ctx, "1234", "app1", "provider1+cluster1", "0", "default", "fleet"); err == nil || err.Error() != "Provider type not supported" {
+		t.Fatalf("Expected Provider type not supported, got %v", err)
+	}
+	p, err := NewGrpcProvider(ctx, "1234", "app1", "provider1+cluster1", "0", "default", "edge")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The ref returned by the plugin is passed back to it until the commit
+	var ref interface{}
+	for _, res := range []string{"r1", "r2"} {
+		if ref, err = p.Apply(ctx, res, ref, []byte(res)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := p.Commit(ctx, ref); err != nil {
+		t.Fatal(err)
+	}
+	f.Lock()
+	if f.committed != "r1,r2" || f.kubeconfig != "kubeconfig" {
+		t.Errorf("Unexpected commit %s with kubeconfig %s", f.committed, f.kubeconfig)
+	}
+	f.Unlock()
+
+	if err := p.IsReachable(); err != nil {
+		t.Fatal(err)
+	}
+	f.Lock()
+	f.unreachable = true
+	f.Unlock()
+	if err := p.IsReachable(); err == nil || !strings.Contains(err.Error(), "edge agent offline") {
+		t.Errorf("Expected the cluster to be unreachable, got %v", err)
+	}
+	if _, err := p.TagResource([]byte("r1"), "1234-app1"); grpcstatus.Code(pkgCause(err)) != codes.Unimplemented {
+		t.Errorf("Expected an unimplemented error, got %v", err)
+	}
+
+	// The status streamed by the plugin updates the status of the app
+	type update struct{ acID, app, cluster string }
+	updates := make(chan update, 1)
+	savedHandleStatus := handleStatus
+	defer func() { handleStatus = savedHandleStatus }()
+	handleStatus = func(ctx context.Context, acID, app, cluster string, rbData *v1alpha1.ResourceBundleState) {
+		updates <- update{acID, app, cluster}
+	}
+	if err := p.StartClusterWatcher(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if u := <-updates; u.acID != "1234" || u.app != "app1" || u.cluster != "provider1+cluster1" {
+		t.Errorf("Unexpected status update %+v", u)
+	}
+	// The cluster is watched as a whole, not with the client context of the provider
+	f.Lock()
+	if f.watchClient.AppContext != "" || f.watchClient.Level != "0" || string(f.watchClient.Kubeconfig) != "kubeconfig" {
+		t.Errorf("Unexpected client context of the status stream %+v", f.watchClient)
+	}
+	f.Unlock()
+
+	// The watcher stops with its stream, and the cluster can be watched again
+	StopClusterWatcher("provider1+cluster1")
+	if !eventually(func() bool { f.Lock(); defer f.Unlock(); return !f.watching }) {
+		t.Error("The status stream was not closed when the watcher was stopped")
+	}
+	if err := p.StartClusterWatcher(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if u := <-updates; u.cluster != "provider1+cluster1" {
+		t.Errorf("Unexpected status update %+v", u)
+	}
+	CloseAllClusterWatchers()
+}
+
+// eventually returns true once the condition is true, within a few seconds
+func eventually(cond func() bool) bool {
+	for i := 0; i < 50; i++ {
+		if cond() {
+			return true
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return false
+}
+
+func TestDiscover(t *testing.T) {
+	dir := t.TempDir()
+	savedDir, savedPlugins := config.GetConfiguration().PluginDir, config.GetConfiguration().ConnectorPlugins
+	defer func() {
+		config.GetConfiguration().PluginDir, config.GetConfiguration().ConnectorPlugins = savedDir, savedPlugins
+		plugins.discover()
+	}()
+	config.GetConfiguration().PluginDir = dir
+	config.GetConfiguration().ConnectorPlugins = ""
+	plugins.discover()
+	if Enabled() {
+		t.Fatal("Plugins found in an empty plugin directory")
+	}
+
+	// The lookups only see the plugins of the last discovery
+	lis, err := net.Listen("unix", filepath.Join(dir, "edge.sock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer()
+	connectorplugin.RegisterConnectorpluginServer(s, &fakePlugin{})
+	go s.Serve(lis)
+	defer s.Stop()
+	if _, err := plugins.lookup("edge"); err == nil {
+		t.Error("The plugin was found before its discovery")
+	}
+	plugins.discover()
+	if _, err := plugins.lookup("edge"); err != nil {
+		t.Errorf("The plugin was not found after its discovery: %s", err)
+	}
+
+	// A deployment target served by a removed plugin is served by another plugin
+	lis2, err := net.Listen("unix", filepath.Join(dir, "edge2.sock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s2 := grpc.NewServer()
+	connectorplugin.RegisterConnectorpluginServer(s2, &fakePlugin{})
+	go s2.Serve(lis2)
+	defer s2.Stop()
+	plugins.discover()
+	if p, err := plugins.lookup("edge"); err != nil || !strings.HasSuffix(p.endpoint, "/edge.sock") {
+		t.Fatalf("Unexpected plugin of the deployment target %v %v", p, err)
+	}
+	s.Stop()
+	os.Remove(filepath.Join(dir, "edge.sock"))
+	plugins.discover()
+	if p, err := plugins.lookup("edge"); err != nil || !strings.HasSuffix(p.endpoint, "/edge2.sock") {
+		t.Errorf("The deployment target was not resolved to the other plugin %v %v", p, err)
+	}
+	s2.Stop()
+	os.Remove(filepath.Join(dir, "edge2.sock"))
+	plugins.discover()
+	if _, err := plugins.lookup("edge"); err == nil {
+		t.Error("The deployment target is served after its plugins were removed")
+	}
+
+	// The plugins are not reached over the network without TLS
+	if _, _, err := connect("127.0.0.1:50051"); err == nil || !strings.Contains(err.Error(), "CA file is required") {
+		t.Errorf("Connected to a plugin over the network without TLS: %v", err)
+	}
+}
+
+// pkgCause returns the gRPC error wrapped by the provider
+func pkgCause(err error) error {
+	for err != nil {
+		c, ok := err.(interface{ Cause() error })
+		if !ok {
+			break
+		}
+		err = c.Cause()
+	}
+	return err
+}
@@ -0,0 +1,249 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package grpcplugin
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/config"
+	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/rpc"
+	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/grpc/connectorplugin"
+	"google.golang.org/grpc"
+)
+
+// PluginProtocolVersion is the version of the connector plugin protocol
+const PluginProtocolVersion = "v1"
+
+// Extension of the Unix sockets of the plugins in the plugin directory
+const socketExtension = ".sock"
+
+// Interval of the discovery of the plugins, as plugins may start and stop independently of rsync
+var discoveryInterval = 10 * time.Second
+
+// Wait time before connecting again to a plugin which failed
+var connectRetryInterval = 30 * time.Second
+
+// plugin is a connector plugin serving deployment targets
+type plugin struct {
+	endpoint string
+	conn     *grpc.ClientConn
+	client   connectorplugin.ConnectorpluginClient
+	// Deployment targets served by the plugin, whether or not the registry
+	// resolves them to it
+	targets []string
+}
+
+// registry of the plugins by endpoint and by deployment target
+type registry struct {
+	// Endpoints found by the last discovery
+	endpoints []string
+	plugins   map[string]*plugin
+	targets   map[string]*plugin
+	// Time of the last failure to connect, by endpoint
+	failed map[string]time.Time
+	// Serializes the discoveries, the only writers of the registry
+	discovery sync.Mutex
+	sync.RWMutex
+}
+
+var plugins = registry{plugins: map[string]*plugin{}, targets: map[string]*plugin{}, failed: map[string]time.Time{}}
+
+// endpoints returns the endpoints of the plugins: the Unix sockets of the plugin
+// directory, and the endpoints of the connector-plugins configuration
+func endpoints() []string {
+	var eps []string
+	cfg := config.GetConfiguration()
+	if cfg.PluginDir != "" {
+		socks, err := filepath.Glob(filepath.Join(cfg.PluginDir, "*"+socketExtension))
+		if err != nil {
+			log.Warn("Invalid plugin directory", log.Fields{"pluginDir": cfg.PluginDir, "error": err})
+		}
+		for _, s := range socks {
+			if abs, err := filepath.Abs(s); err == nil {
+				eps = append(eps, "unix://"+abs)
+			}
+		}
+	}
+	for _, ep := range strings.Split(cfg.ConnectorPlugins, ",") {
+		if ep = strings.TrimSpace(ep); ep != "" {
+			eps = append(eps, ep)
+		}
+	}
+	return eps
+}
+
+// Start discovers the plugins, then discovers them again periodically until the
+// context is done, when the cluster watchers are stopped
+func Start(ctx context.Context) {
+	plugins.discover()
+	go func() {
+		ticker := time.NewTicker(discoveryInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				CloseAllClusterWatchers()
+				return
+			case <-ticker.C:
+				plugins.discover()
+			}
+		}
+	}()
+}
+
+// Enabled returns true if connector plugins were found by the last discovery
+func Enabled() bool {
+	plugins.RLock()
+	defer plugins.RUnlock()
+	return len(plugins.endpoints) > 0
+}
+
+// lookup returns the plugin serving the deployment target, among the plugins
+// found by the last discovery
+func (r *registry) lookup(target string) (*plugin, error) {
+	r.RLock()
+	defer r.RUnlock()
+	if p, ok := r.targets[target]; ok {
+		return p, nil
+	}
+	return nil, pkgerrors.New("Provider type not supported")
+}
+
+// discover forgets the plugins whose endpoints are gone, connects to the new plugin
+// endpoints and registers the deployment targets they serve. The deployment targets
+// of a removed plugin are resolved again to another plugin serving them, if any, and
+// the cluster watchers of the targets no plugin serves are stopped. Endpoints which
+// fail are retried by a discovery after the retry interval. The registry is only
+// locked to be updated, so that the lookups don't wait for the connections.
+func (r *registry) discover() {
+	r.discovery.Lock()
+	defer r.discovery.Unlock()
+
+	eps := endpoints()
+	r.Lock()
+	r.endpoints = eps
+	r.Unlock()
+	current := map[string]bool{}
+	for _, ep := range eps {
+		current[ep] = true
+	}
+	var orphans []string
+	for ep, p := range r.plugins {
+		if current[ep] {
+			continue
+		}
+		r.Lock()
+		for t, tp := range r.targets {
+			if tp == p {
+				delete(r.targets, t)
+				orphans = append(orphans, t)
+			}
+		}
+		delete(r.plugins, ep)
+		r.Unlock()
+		p.conn.Close()
+		log.Info("Disconnected from the removed connector plugin", log.Fields{"endpoint": ep})
+	}
+	r.resolve(orphans)
+	for _, ep := range eps {
+		if _, ok := r.plugins[ep]; ok {
+			continue
+		}
+		if t, ok := r.failed[ep]; ok && time.Since(t) < connectRetryInterval {
+			continue
+		}
+		p, targets, err := connect(ep)
+		if err != nil {
+			log.Warn("Failed to connect to the connector plugin", log.Fields{"endpoint": ep, "error": err})
+			r.failed[ep] = time.Now()
+			continue
+		}
+		delete(r.failed, ep)
+		p.targets = targets
+		r.Lock()
+		r.plugins[ep] = p
+		for _, t := range targets {
+			if other, ok := r.targets[t]; ok {
+				log.Warn("Deployment target already served by another connector plugin", log.Fields{"target": t, "endpoint": ep, "servedBy": other.endpoint})
+				continue
+			}
+			r.targets[t] = p
+		}
+		r.Unlock()
+		log.Info("Connected to the connector plugin", log.Fields{"endpoint": ep, "targets": targets})
+	}
+	for _, t := range orphans {
+		if _, err := r.lookup(t); err != nil {
+			stopTargetWatchers(t)
+		}
+	}
+}
+
+// resolve registers the deployment targets with the first remaining plugin, in
+// the order of the endpoints, which serves them. Must be called by a discovery.
+func (r *registry) resolve(targets []string) {
+	if len(targets) == 0 {
+		return
+	}
+	eps := make([]string, 0, len(r.plugins))
+	for ep := range r.plugins {
+		eps = append(eps, ep)
+	}
+	sort.Strings(eps)
+	r.Lock()
+	defer r.Unlock()
+	for _, t := range targets {
+		for _, ep := range eps {
+			if contains(r.plugins[ep].targets, t) {
+				r.targets[t] = r.plugins[ep]
+				log.Info("Deployment target now served by another connector plugin", log.Fields{"target": t, "endpoint": ep})
+				break
+			}
+		}
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// connect connects to the plugin listening on the endpoint. The endpoint is
+// unix:///<path> for a plugin listening on a Unix socket, typically a sidecar, or
+// <host>:<port>. The connection to a host requires TLS with the configured CA file.
+func connect(endpoint string) (*plugin, []string, error) {
+	conn, err := rpc.DialPlugin(endpoint, config.GetConfiguration().ConnectorPluginCAFile)
+	if err != nil {
+		return nil, nil, pkgerrors.Wrap(err, "Error connecting to the connector plugin")
+	}
+	p := &plugin{endpoint: endpoint, conn: conn, client: connectorplugin.NewConnectorpluginClient(conn)}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	status, err := p.client.Status(ctx, &connectorplugin.StatusRequest{})
+	if err != nil {
+		conn.Close()
+		return nil, nil, pkgerrors.Wrap(err, "Error getting the status of the connector plugin")
+	}
+	if status.Version != PluginProtocolVersion {
+		conn.Close()
+		return nil, nil, pkgerrors.Errorf("Unsupported connector plugin protocol version %s", status.Version)
+	}
+	if !status.Healthy {
+		conn.Close()
+		return nil, nil, pkgerrors.New("The connector plugin is not healthy")
+	}
+	return p, status.DeploymentTargets, nil
+}
@@ -0,0 +1,186 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package grpcplugin
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	v1alpha1 "gitlab.com/project-emco/core/emco-base/src/monitor/pkg/apis/k8splugin/v1alpha1"
+	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/grpc/connectorplugin"
+	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/status"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Wait time before watching the status of a cluster again after the stream failed
+var watchRetryInterval = 10 * time.Second
+
+// Handles the status updates, replaced in tests
+var handleStatus = status.HandleResourcesStatus
+
+// watchers of the clusters by deployment target
+type watchers struct {
+	// stop channels of the watchers, by <target>/<cluster>
+	clusters map[string]chan struct{}
+	sync.Mutex
+}
+
+var watcherData = watchers{clusters: map[string]chan struct{}{}}
+
+func watcherKey(target, cluster string) string {
+	return target + "/" + cluster
+}
+
+// StartClusterWatcher watches the status streamed by the plugin for the cluster.
+// A cluster is watched once, until StopClusterWatcher, and watched again when the
+// stream fails.
+func (p *GrpcProvider) StartClusterWatcher(ctx context.Context) error {
+	key := watcherKey(p.target, p.cluster)
+	watcherData.Lock()
+	defer watcherData.Unlock()
+	if _, ok := watcherData.clusters[key]; ok {
+		return nil
+	}
+	stop := make(chan struct{})
+	watcherData.clusters[key] = stop
+	go watchStatus(ctx, p.target, p.cluster, stop)
+	return nil
+}
+
+// StopClusterWatcher stops watching the cluster, for all the deployment targets
+func StopClusterWatcher(cluster string) {
+	watcherData.Lock()
+	defer watcherData.Unlock()
+	for key, stop := range watcherData.clusters {
+		if strings.HasSuffix(key, "/"+cluster) {
+			close(stop)
+			delete(watcherData.clusters, key)
+		}
+	}
+}
+
+// stopTargetWatchers stops watching the clusters of the deployment target, e.g.
+// when no plugin serves it anymore
+func stopTargetWatchers(target string) {
+	watcherData.Lock()
+	defer watcherData.Unlock()
+	for key, stop := range watcherData.clusters {
+		if strings.HasPrefix(key, target+"/") {
+			close(stop)
+			delete(watcherData.clusters, key)
+		}
+	}
+}
+
+// CloseAllClusterWatchers stops watching all the clusters
+func CloseAllClusterWatchers() {
+	watcherData.Lock()
+	defer watcherData.Unlock()
+	for key, stop := range watcherData.clusters {
+		close(stop)
+		delete(watcherData.clusters, key)
+	}
+}
+
+// Per cluster go routine to watch the status, until stop is closed
+func watchStatus(ctx context.Context, target, cluster string, stop <-chan struct{}) {
+	// This function is executed asynchronously, so we must create
+	// a new (not derived) context to prevent the context from
+	// being cancelled when the caller completes.
+	tracer := otel.Tracer("rsync")
+	ctx, span := tracer.Start(context.Background(), "watchStatus",
+		trace.WithLinks(trace.LinkFromContext(ctx)),
+	)
+	defer span.End()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for {
+		err := watchOnce(ctx, target, cluster)
+		if ctx.Err() != nil {
+			log.Info("Stopped watching the status of the cluster", log.Fields{"target": target, "cluster": cluster})
+			return
+		}
+		log.Warn("Status stream of the connector plugin failed", log.Fields{"target": target, "cluster": cluster, "error": err})
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(watchRetryInterval):
+		}
+	}
+}
+
+// watchOnce streams the status of the cluster until the stream fails
+func watchOnce(ctx context.Context, target, cluster string) error {
+	// The plugin serving the target may have been restarted at another endpoint,
+	// or replaced by another plugin
+	pl, err := plugins.lookup(target)
+	if err != nil {
+		return err
+	}
+	// The cluster is watched as a whole, with the current configuration of the
+	// cluster, like the cluster watchers of the other providers
+	client, err := newClientContext(ctx, "", "", cluster, "0", "", target)
+	if err != nil {
+		return err
+	}
+	stream, err := pl.client.WatchStatus(ctx, &connectorplugin.WatchStatusRequest{Client: client})
+	if err != nil {
+		return err
+	}
+	for {
+		u, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		handleStatusUpdate(ctx, cluster, u)
+	}
+}
+
+// handleStatusUpdate updates the status of the app of the label <app context>-<app>
+func handleStatusUpdate(ctx context.Context, cluster string, u *connectorplugin.StatusUpdate) {
+	result := strings.SplitN(u.Label, "-", 2)
+	if len(result) != 2 || result[0] == "" || result[1] == "" {
+		log.Error("::invalid label format::", log.Fields{"label": u.Label, "cluster": cluster})
+		return
+	}
+	v := &v1alpha1.ResourceBundleState{}
+	if err := json.Unmarshal(u.ResourceBundleState, v); err != nil {
+		log.Error("::invalid ResourceBundleState::", log.Fields{"label": u.Label, "cluster": cluster, "error": err})
+		return
+	}
+	handleStatus(ctx, result[0], result[1], cluster, v)
+}
+
+// ApplyStatusCR applies the status CR with the plugin
+func (p *GrpcProvider) ApplyStatusCR(ctx context.Context, name string, content []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, pluginCallTimeout)
+	defer cancel()
+	if _, err := p.plugin.client.ApplyStatusCR(ctx, &connectorplugin.StatusCRRequest{Client: p.client, Name: name, Content: content}); err != nil {
+		return p.error("apply status CR", err)
+	}
+	return nil
+}
+
+// DeleteStatusCR deletes the status CR with the plugin
+func (p *GrpcProvider) DeleteStatusCR(ctx context.Context, name string, content []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, pluginCallTimeout)
+	defer cancel()
+	if _, err := p.plugin.client.DeleteStatusCR(ctx, &connectorplugin.StatusCRRequest{Client: p.client, Name: name, Content: content}); err != nil {
+		return p.error("delete status CR", err)
+	}
+	return nil
+}
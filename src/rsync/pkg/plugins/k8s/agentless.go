@@ -0,0 +1,427 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	yaml "github.com/ghodss/yaml"
+	pkgerrors "github.com/pkg/errors"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/appcontext"
+	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/client"
+	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/db"
+	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/internal/utils"
+	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/status"
+	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/types"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// AgentlessStatusLabel is the label of the clusters without the monitor. The
+// status of their resources is collected by rsync with dynamic informers.
+const AgentlessStatusLabel = "agentless-status"
+
+// Delay before the status of a tracker is updated, to batch the changes of its resources
+var agentlessStatusDelay = time.Second
+
+// Time the agentless label of a cluster is cached, a provider is created for each operation
+var agentlessLabelTTL = time.Minute
+
+type agentlessLabel struct {
+	value bool
+	time  time.Time
+}
+
+var agentlessLabels = struct {
+	clusters map[string]agentlessLabel
+	sync.Mutex
+}{clusters: map[string]agentlessLabel{}}
+
+// hasAgentlessLabel reads the agentless label of the cluster
+var hasAgentlessLabel = func(ctx context.Context, provider, cluster string) (bool, error) {
+	return db.NewCloudConfigClient().HasClusterLabel(ctx, provider, cluster, AgentlessStatusLabel)
+}
+
+// isAgentless returns true if rsync collects the status of the cluster
+func isAgentless(ctx context.Context, cluster string) bool {
+	result := strings.SplitN(cluster, "+", 2)
+	if len(result) != 2 {
+		return false
+	}
+	agentlessLabels.Lock()
+	l, ok := agentlessLabels.clusters[cluster]
+	agentlessLabels.Unlock()
+	if ok && time.Since(l.time) < agentlessLabelTTL {
+		return l.value
+	}
+	value, err := hasAgentlessLabel(ctx, result[0], result[1])
+	if err != nil {
+		log.Error("Error reading the labels of the cluster", log.Fields{"cluster": cluster, "error": err})
+		// Keep the last known value until the labels can be read
+		return l.value
+	}
+	agentlessLabels.Lock()
+	agentlessLabels.clusters[cluster] = agentlessLabel{value: value, time: time.Now()}
+	agentlessLabels.Unlock()
+	return value
+}
+
+// agentlessWatcher watches the resources of a cluster labelled by rsync, and
+// reports their status like the monitor would
+type agentlessWatcher struct {
+	cluster   string
+	mapper    meta.ResettableRESTMapper
+	factory   dynamicinformer.DynamicSharedInformerFactory
+	informers map[schema.GroupVersionResource]cache.SharedIndexInformer
+	// Status CRs of the trackers, by label of the resources they track
+	trackers map[string][]byte
+	queue    workqueue.DelayingInterface
+	stop     chan struct{}
+	sync.Mutex
+}
+
+type agentlessManager struct {
+	watchers map[string]*agentlessWatcher
+	sync.Mutex
+}
+
+var agentlessData = agentlessManager{watchers: map[string]*agentlessWatcher{}}
+
+// getAgentlessWatcher returns the watcher of the cluster, and starts it the first time
+func getAgentlessWatcher(ctx context.Context, cluster string) (*agentlessWatcher, error) {
+	agentlessData.Lock()
+	defer agentlessData.Unlock()
+	if w, ok := agentlessData.watchers[cluster]; ok {
+		return w, nil
+	}
+	// The cluster is watched as a whole, with the L0 cloudconfig
+	configBytes, err := utils.GetKubeConfig(ctx, cluster, "0", "")
+	if err != nil {
+		return nil, err
+	}
+	config, err := clientcmd.RESTConfigFromKubeConfig(configBytes)
+	if err != nil {
+		log.Error("RESTConfigFromKubeConfig error:", log.Fields{"err": err})
+		return nil, pkgerrors.Wrap(err, "RESTConfigFromKubeConfig error")
+	}
+	client.SetRateLimits(config)
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "Dynamic client NewForConfig error")
+	}
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "Discovery client NewForConfig error")
+	}
+	// Only the resources labelled by rsync are cached
+	factory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(dynamicClient, 0, metav1.NamespaceAll,
+		func(o *metav1.ListOptions) { o.LabelSelector = monitorLabel })
+	w := &agentlessWatcher{
+		cluster:   cluster,
+		mapper:    restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(discoveryClient)),
+		factory:   factory,
+		informers: map[schema.GroupVersionResource]cache.SharedIndexInformer{},
+		trackers:  map[string][]byte{},
+		queue:     workqueue.NewNamedDelayingQueue("agentless-" + cluster),
+		stop:      make(chan struct{}),
+	}
+	// The pods of the workloads are labelled too
+	if err := w.watch(schema.GroupVersionKind{Version: "v1", Kind: "Pod"}); err != nil {
+		return nil, err
+	}
+	// The watcher outlives the request which starts it, so a new (not derived) context
+	// is used for it. A link is used so that the traces can be associated.
+	wctx, span := otel.Tracer("rsync").Start(context.Background(), "agentlessStatus",
+		trace.WithLinks(trace.LinkFromContext(ctx)),
+	)
+	// The trackers and informers are rebuilt from the AppContexts after a restart
+	go func() {
+		defer span.End()
+		w.restore(wctx)
+		w.run(wctx)
+	}()
+	agentlessData.watchers[cluster] = w
+	log.Info("Started agentless status watcher", log.Fields{"cluster": cluster})
+	return w, nil
+}
+
+// getDeployedAppContexts returns the current AppContexts of the deployed deployment intent groups
+var getDeployedAppContexts = db.GetDeployedAppContexts
+
+// restore tracks the status of the apps deployed on the cluster, and watches their resources
+func (w *agentlessWatcher) restore(ctx context.Context) {
+	acIDs, err := getDeployedAppContexts(ctx)
+	if err != nil {
+		log.Error("Error finding the AppContexts to restore the agentless status", log.Fields{"cluster": w.cluster, "error": err})
+		return
+	}
+	for _, acID := range acIDs {
+		apps, err := w.appsOnCluster(ctx, acID)
+		if err != nil {
+			log.Error("Error reading the apps of the AppContext", log.Fields{"cluster": w.cluster, "acID": acID, "error": err})
+			continue
+		}
+		for _, app := range apps {
+			if err := w.restoreApp(ctx, acID, app); err != nil {
+				log.Error("Error restoring the agentless status", log.Fields{"cluster": w.cluster, "acID": acID, "app": app, "error": err})
+			}
+		}
+	}
+}
+
+// appsOnCluster returns the apps of the AppContext deployed on the cluster
+func (w *agentlessWatcher) appsOnCluster(ctx context.Context, acID string) ([]string, error) {
+	acRef, err := utils.NewAppContextReference(ctx, acID)
+	if err != nil {
+		return nil, err
+	}
+	ac := acRef.GetAppContextHandle()
+	order, err := ac.GetAppInstruction(ctx, "order")
+	if err != nil {
+		return nil, err
+	}
+	var appOrder map[string][]string
+	if err := json.Unmarshal([]byte(fmt.Sprintf("%v", order)), &appOrder); err != nil {
+		return nil, pkgerrors.Wrap(err, "Error reading the order of the apps")
+	}
+	var apps []string
+	for _, app := range appOrder["apporder"] {
+		clusters, err := ac.GetClusterNames(ctx, app)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range clusters {
+			if c == w.cluster {
+				apps = append(apps, app)
+				break
+			}
+		}
+	}
+	return apps, nil
+}
+
+// restoreApp tracks the status of the app of the AppContext if it is deployed
+func (w *agentlessWatcher) restoreApp(ctx context.Context, acID, app string) error {
+	acRef, err := utils.NewAppContextReference(ctx, acID)
+	if err != nil {
+		return err
+	}
+	s, err := acRef.GetAppContextStatus(ctx, types.CurrentStateKey)
+	if err != nil || (s.Status != appcontext.AppContextStatusEnum.Instantiated && s.Status != appcontext.AppContextStatusEnum.Updated) {
+		return nil
+	}
+	// The label of the resources is shared by the updates of the AppContext
+	statusAcID, err := acRef.GetStatusAppContext(ctx, types.StatusAppContextIDKey)
+	if err != nil || statusAcID == "" {
+		statusAcID = acID
+	}
+	ac := acRef.GetAppContextHandle()
+	names, err := ac.GetResourceNames(ctx, app, w.cluster)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		rh, err := ac.GetResourceHandle(ctx, app, w.cluster, name)
+		if err != nil {
+			return err
+		}
+		v, err := ac.GetValue(ctx, rh)
+		if err != nil {
+			return err
+		}
+		res, ok := v.(string)
+		if !ok {
+			continue
+		}
+		b, err := yaml.YAMLToJSON([]byte(res))
+		if err != nil {
+			return pkgerrors.Wrapf(err, "Error reading the resource %s", name)
+		}
+		u := &unstructured.Unstructured{}
+		if err := u.UnmarshalJSON(b); err != nil {
+			return pkgerrors.Wrapf(err, "Error reading the resource %s", name)
+		}
+		// The kind of a resource may not be served anymore, the others are still watched
+		if err := w.watch(u.GroupVersionKind()); err != nil {
+			log.Error("Error watching the resource for agentless status", log.Fields{"cluster": w.cluster, "kind": u.GetKind(), "error": err})
+		}
+	}
+	namespace, _ := acRef.GetNamespace(ctx)
+	cr, err := status.GetStatusCR(statusAcID+"-"+app, "", namespace)
+	if err != nil {
+		return err
+	}
+	return w.track(cr)
+}
+
+// watch starts an informer for the kind if it is not watched yet
+func (w *agentlessWatcher) watch(gvk schema.GroupVersionKind) error {
+	mapping, err := w.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		// The kind may be defined by a CRD installed after the discovery
+		w.mapper.Reset()
+		if mapping, err = w.mapper.RESTMapping(gvk.GroupKind(), gvk.Version); err != nil {
+			return pkgerrors.Wrapf(err, "Error mapping the kind %s", gvk.String())
+		}
+	}
+	w.Lock()
+	defer w.Unlock()
+	if _, ok := w.informers[mapping.Resource]; ok {
+		return nil
+	}
+	informer := w.factory.ForResource(mapping.Resource).Informer()
+	err = informer.AddIndexers(cache.Indexers{monitorLabel: func(obj interface{}) ([]string, error) {
+		if l, ok := trackerLabel(obj); ok {
+			return []string{l}, nil
+		}
+		return nil, nil
+	}})
+	if err != nil {
+		return pkgerrors.Wrap(err, "Error adding the informer indexer")
+	}
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    w.enqueue,
+		UpdateFunc: func(oldObj, obj interface{}) { w.enqueue(obj) },
+		DeleteFunc: w.enqueue,
+	})
+	w.informers[mapping.Resource] = informer
+	w.factory.Start(w.stop)
+	log.Info("Watching resources for agentless status", log.Fields{"cluster": w.cluster, "resource": mapping.Resource.String()})
+	return nil
+}
+
+// trackerLabel returns the label of the status tracker of the object
+func trackerLabel(obj interface{}) (string, bool) {
+	if d, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = d.Obj
+	}
+	o, err := meta.Accessor(obj)
+	if err != nil {
+		return "", false
+	}
+	l, ok := o.GetLabels()[monitorLabel]
+	return l, ok
+}
+
+func (w *agentlessWatcher) enqueue(obj interface{}) {
+	if l, ok := trackerLabel(obj); ok {
+		w.queue.AddAfter(l, agentlessStatusDelay)
+	}
+}
+
+// run reports the status of the trackers whose resources changed
+func (w *agentlessWatcher) run(ctx context.Context) {
+	for {
+		item, shutdown := w.queue.Get()
+		if shutdown {
+			return
+		}
+		w.updateStatus(ctx, item.(string))
+		w.queue.Done(item)
+	}
+}
+
+// updateStatus computes the status of the resources of the tracker
+func (w *agentlessWatcher) updateStatus(ctx context.Context, label string) {
+	w.Lock()
+	cr, ok := w.trackers[label]
+	informers := make([]cache.SharedIndexInformer, 0, len(w.informers))
+	for _, informer := range w.informers {
+		informers = append(informers, informer)
+	}
+	w.Unlock()
+	if !ok {
+		return
+	}
+	var objs []*unstructured.Unstructured
+	for _, informer := range informers {
+		items, err := informer.GetIndexer().ByIndex(monitorLabel, label)
+		if err != nil {
+			continue
+		}
+		for _, item := range items {
+			if u, ok := item.(*unstructured.Unstructured); ok {
+				objs = append(objs, u)
+			}
+		}
+	}
+	rbState, err := status.NewResourceBundleState(cr, objs)
+	if err != nil {
+		log.Error("Error computing the agentless status", log.Fields{"cluster": w.cluster, "label": label, "error": err})
+		return
+	}
+	HandleStatusUpdate(ctx, w.cluster, label, rbState)
+}
+
+// track starts tracking the status of the resources with the label of the status CR
+func (w *agentlessWatcher) track(statusCR []byte) error {
+	label, err := status.StatusCRName(statusCR)
+	if err != nil {
+		return err
+	}
+	w.Lock()
+	w.trackers[label] = statusCR
+	w.Unlock()
+	w.queue.Add(label)
+	return nil
+}
+
+// untrack stops tracking the status of the resources with the label of the status CR
+func (w *agentlessWatcher) untrack(statusCR []byte) error {
+	label, err := status.StatusCRName(statusCR)
+	if err != nil {
+		return err
+	}
+	w.Lock()
+	delete(w.trackers, label)
+	w.Unlock()
+	return nil
+}
+
+// watchResource watches the kind of the resource applied to an agentless cluster
+func (p *K8sProvider) watchResource(ctx context.Context, content []byte) {
+	if !p.agentless {
+		return
+	}
+	u := &unstructured.Unstructured{}
+	if err := u.UnmarshalJSON(content); err != nil {
+		return
+	}
+	w, err := getAgentlessWatcher(ctx, p.cluster)
+	if err == nil {
+		err = w.watch(u.GroupVersionKind())
+	}
+	if err != nil {
+		log.Error("Error watching the resource for agentless status", log.Fields{"cluster": p.cluster, "kind": u.GetKind(), "error": err})
+	}
+}
+
+// stopAgentlessWatcher stops the watcher of the cluster
+func stopAgentlessWatcher(cluster string) {
+	agentlessData.Lock()
+	defer agentlessData.Unlock()
+	if w, ok := agentlessData.watchers[cluster]; ok {
+		close(w.stop)
+		w.queue.ShutDown()
+		delete(agentlessData.watchers, cluster)
+	}
+}
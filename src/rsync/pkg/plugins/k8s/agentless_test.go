@@ -0,0 +1,142 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package k8s
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	yaml "github.com/ghodss/yaml"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/appcontext"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/contextdb"
+	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/internal/utils"
+	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/status"
+	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/types"
+	contextUtils "gitlab.com/project-emco/core/emco-base/src/rsync/pkg/utils"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// testRESTMapper maps the kinds of the test resources
+type testRESTMapper struct {
+	*meta.DefaultRESTMapper
+}
+
+func (m testRESTMapper) Reset() {}
+
+func TestIsAgentless(t *testing.T) {
+	defer func(f func(context.Context, string, string) (bool, error)) { hasAgentlessLabel = f }(hasAgentlessLabel)
+	defer func(ttl time.Duration) { agentlessLabelTTL = ttl }(agentlessLabelTTL)
+	reads := 0
+	labelled := true
+	hasAgentlessLabel = func(ctx context.Context, provider, cluster string) (bool, error) {
+		reads++
+		return labelled, nil
+	}
+	ctx := context.Background()
+	if !isAgentless(ctx, "provider1+agentless1") || !isAgentless(ctx, "provider1+agentless1") {
+		t.Errorf("Expected an agentless cluster")
+	}
+	if reads != 1 {
+		t.Errorf("Unexpected reads %d of the cluster labels, expected 1", reads)
+	}
+	// The label is read again once the cache expires
+	agentlessLabelTTL = 0
+	labelled = false
+	if isAgentless(ctx, "provider1+agentless1") {
+		t.Errorf("Unexpected agentless cluster after the label is removed")
+	}
+	if reads != 2 {
+		t.Errorf("Unexpected reads %d of the cluster labels, expected 2", reads)
+	}
+	if isAgentless(ctx, "agentless1") {
+		t.Errorf("Unexpected agentless cluster without provider")
+	}
+}
+
+func TestAgentlessRestore(t *testing.T) {
+	defer func(db contextdb.ContextDb) { contextdb.Db = db }(contextdb.Db)
+	contextdb.Db = new(contextdb.MockConDb)
+	ctx := context.Background()
+	cluster := "provider1+cluster1"
+	ca := types.CompositeApp{
+		CompMetadata: appcontext.CompositeAppMeta{Project: "proj1", CompositeApp: "ca1", Version: "v1", Release: "r1",
+			DeploymentIntentGroup: "dig1", Namespace: "ns1", Level: "0"},
+		AppOrder: []string{"app1"},
+		Apps: map[string]*types.App{"app1": {
+			Name: "app1",
+			Clusters: map[string]*types.Cluster{cluster: {
+				Name: cluster,
+				Resources: map[string]*types.AppResource{
+					"cm1+ConfigMap": {Name: "cm1+ConfigMap", Data: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm1\n"},
+				},
+				ResOrder: []string{"cm1+ConfigMap"}}},
+		}},
+	}
+	deployed, err := contextUtils.CreateCompApp(ctx, ca)
+	if err != nil {
+		t.Fatalf("Error creating the AppContext %s", err)
+	}
+	acRef, _ := utils.NewAppContextReference(ctx, deployed)
+	if err := acRef.UpdateAppContextStatus(ctx, types.CurrentStateKey, appcontext.AppContextStatus{Status: appcontext.AppContextStatusEnum.Instantiated}); err != nil {
+		t.Fatalf("Error updating the AppContext state %s", err)
+	}
+	// The AppContext of a terminated app is not tracked
+	terminated, _ := contextUtils.CreateCompApp(ctx, ca)
+	acRef, _ = utils.NewAppContextReference(ctx, terminated)
+	acRef.UpdateAppContextStatus(ctx, types.CurrentStateKey, appcontext.AppContextStatus{Status: appcontext.AppContextStatusEnum.Terminated})
+	// The AppContext of an app deployed on another cluster is not tracked
+	other := ca
+	other.Apps = map[string]*types.App{"app1": {Name: "app1", Clusters: map[string]*types.Cluster{"provider1+cluster2": {
+		Name: "provider1+cluster2", Resources: ca.Apps["app1"].Clusters[cluster].Resources, ResOrder: []string{"cm1+ConfigMap"}}}}}
+	elsewhere, _ := contextUtils.CreateCompApp(ctx, other)
+	acRef, _ = utils.NewAppContextReference(ctx, elsewhere)
+	acRef.UpdateAppContextStatus(ctx, types.CurrentStateKey, appcontext.AppContextStatus{Status: appcontext.AppContextStatusEnum.Instantiated})
+
+	defer func(f func(context.Context) ([]string, error)) { getDeployedAppContexts = f }(getDeployedAppContexts)
+	getDeployedAppContexts = func(ctx context.Context) ([]string, error) {
+		return []string{elsewhere, terminated, deployed}, nil
+	}
+
+	mapper := testRESTMapper{meta.NewDefaultRESTMapper(nil)}
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+	stop := make(chan struct{})
+	defer close(stop)
+	w := &agentlessWatcher{
+		cluster:   cluster,
+		mapper:    mapper,
+		factory:   dynamicinformer.NewDynamicSharedInformerFactory(fake.NewSimpleDynamicClient(runtime.NewScheme()), 0),
+		informers: map[schema.GroupVersionResource]cache.SharedIndexInformer{},
+		trackers:  map[string][]byte{},
+		queue:     workqueue.NewDelayingQueue(),
+		stop:      stop,
+	}
+	defer w.queue.ShutDown()
+	w.restore(ctx)
+
+	if len(w.trackers) != 1 {
+		t.Fatalf("Unexpected trackers %v", w.trackers)
+	}
+	cr, ok := w.trackers[deployed+"-app1"]
+	if !ok {
+		t.Fatalf("The tracker of the deployed app is not restored")
+	}
+	var obj metav1.PartialObjectMetadata
+	if name, err := status.StatusCRName(cr); err != nil || name != deployed+"-app1" {
+		t.Errorf("Unexpected status CR %s", cr)
+	}
+	if err := yaml.Unmarshal(cr, &obj); err != nil || obj.Namespace != "ns1" {
+		t.Errorf("Unexpected namespace %s of the status CR", obj.Namespace)
+	}
+	if _, ok := w.informers[schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}]; !ok {
+		t.Errorf("The resources of the deployed app are not watched %v", w.informers)
+	}
+}
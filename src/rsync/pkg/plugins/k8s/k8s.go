@@ -24,6 +24,8 @@ type K8sProvider struct {
 	level     string
 	fileName  string
 	client    *kubeclient.Client
+	// The status is collected by rsync, the monitor is not installed
+	agentless bool
 }
 
 func NewK8sProvider(ctx context.Context, cid, app, cluster, level, namespace string) (*K8sProvider, error) {
@@ -59,6 +61,7 @@ func NewK8sProvider(ctx context.Context, cid, app, cluster, level, namespace str
 	}
 	p.fileName = fileName
 	p.client = client
+	p.agentless = isAgentless(ctx, cluster)
 	return &p, nil
 }
 
@@ -0,0 +1,173 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	pkgerrors "github.com/pkg/errors"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/appcontext"
+	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+// The releases are stored in secrets, like with the helm CLI
+const releaseDriver = "secret"
+
+// Value of the releases recording the AppContext they deploy
+const releaseAppContextKey = "emcoAppContext"
+
+// Revisions kept for each release
+const releaseMaxHistory = 10
+
+// Chart version of the releases of charts without metadata
+const defaultChartVersion = "0.1.0"
+
+// actionConfig returns the Helm configuration of the namespace of the app on the cluster
+func (p *K8sProvider) actionConfig() (*action.Configuration, error) {
+	cfg := &action.Configuration{}
+	logf := func(format string, v ...interface{}) {
+		log.Trace(fmt.Sprintf(format, v...), log.Fields{"cluster": p.cluster, "app": p.app})
+	}
+	if err := cfg.Init(p.client.RESTClientGetter(), p.namespace, releaseDriver, logf); err != nil {
+		return nil, pkgerrors.Wrap(err, "Error initializing the Helm configuration")
+	}
+	return cfg, nil
+}
+
+// releaseChart returns the chart of the release. The chart has no templates,
+// its resources are the resources of the AppContext.
+func releaseChart(hr appcontext.HelmRelease, app string) *chart.Chart {
+	m := &chart.Metadata{
+		APIVersion: chart.APIVersionV2,
+		Name:       hr.Chart,
+		Version:    hr.Version,
+		AppVersion: hr.AppVersion,
+		Type:       "application",
+	}
+	if m.Name == "" {
+		m.Name = app
+	}
+	if m.Version == "" {
+		m.Version = defaultChartVersion
+	}
+	return &chart.Chart{Metadata: m}
+}
+
+// resourceRenderer replaces the rendered chart with the resources of the AppContext
+type resourceRenderer [][]byte
+
+func (r resourceRenderer) Run(_ *bytes.Buffer) (*bytes.Buffer, error) {
+	b := &bytes.Buffer{}
+	for _, res := range r {
+		b.WriteString("---\n")
+		b.Write(res)
+		b.WriteString("\n")
+	}
+	return b, nil
+}
+
+// releaseRevision returns the last revision of the release deploying the
+// AppContext, 0 if there is none
+func releaseRevision(history []*release.Release, cid string) int {
+	var rev int
+	for _, r := range history {
+		if id, ok := r.Config[releaseAppContextKey].(string); ok && id == cid && r.Version > rev {
+			rev = r.Version
+		}
+	}
+	return rev
+}
+
+// lastRelease returns the last revision of the release
+func lastRelease(history []*release.Release) *release.Release {
+	var last *release.Release
+	for _, r := range history {
+		if last == nil || r.Version > last.Version {
+			last = r
+		}
+	}
+	return last
+}
+
+// InstallRelease installs the release of the app, or upgrades it to the
+// resources of the AppContext. If a previous revision deployed the AppContext,
+// the release is rolled back to that revision.
+func (p *K8sProvider) InstallRelease(ctx context.Context, hr appcontext.HelmRelease, resources [][]byte) error {
+	cfg, err := p.actionConfig()
+	if err != nil {
+		return err
+	}
+	history, err := action.NewHistory(cfg).Run(p.app)
+	if err != nil && !errors.Is(err, driver.ErrReleaseNotFound) {
+		return pkgerrors.Wrap(err, "Error reading the history of the release")
+	}
+	for _, res := range resources {
+		p.watchResource(ctx, res)
+	}
+	values := map[string]interface{}{releaseAppContextKey: p.cid}
+	last := lastRelease(history)
+	if last == nil {
+		install := action.NewInstall(cfg)
+		install.ReleaseName = p.app
+		install.Namespace = p.namespace
+		install.PostRenderer = resourceRenderer(resources)
+		if _, err := install.Run(releaseChart(hr, p.app), values); err != nil {
+			log.Error("Failed to install release", log.Fields{"error": err, "cluster": p.cluster, "release": p.app})
+			return err
+		}
+		log.Info("Installed release", log.Fields{"cluster": p.cluster, "release": p.app})
+		return nil
+	}
+	rev := releaseRevision(history, p.cid)
+	// The AppContext is already deployed by the release
+	if rev == last.Version && last.Info.Status == release.StatusDeployed {
+		return nil
+	}
+	if rev > 0 && rev < last.Version {
+		rollback := action.NewRollback(cfg)
+		rollback.Version = rev
+		rollback.MaxHistory = releaseMaxHistory
+		if err := rollback.Run(p.app); err != nil {
+			log.Error("Failed to roll back release", log.Fields{"error": err, "cluster": p.cluster, "release": p.app, "revision": rev})
+			return err
+		}
+		log.Info("Rolled back release", log.Fields{"cluster": p.cluster, "release": p.app, "revision": rev})
+		return nil
+	}
+	upgrade := action.NewUpgrade(cfg)
+	upgrade.Namespace = p.namespace
+	upgrade.MaxHistory = releaseMaxHistory
+	upgrade.PostRenderer = resourceRenderer(resources)
+	if _, err := upgrade.Run(p.app, releaseChart(hr, p.app), values); err != nil {
+		log.Error("Failed to upgrade release", log.Fields{"error": err, "cluster": p.cluster, "release": p.app})
+		return err
+	}
+	log.Info("Upgraded release", log.Fields{"cluster": p.cluster, "release": p.app})
+	return nil
+}
+
+// UninstallRelease uninstalls the release of the app
+func (p *K8sProvider) UninstallRelease(ctx context.Context) error {
+	cfg, err := p.actionConfig()
+	if err != nil {
+		return err
+	}
+	if _, err := action.NewUninstall(cfg).Run(p.app); err != nil {
+		// Nothing to uninstall if the release was never installed
+		if errors.Is(err, driver.ErrReleaseNotFound) {
+			return nil
+		}
+		log.Error("Failed to uninstall release", log.Fields{"error": err, "cluster": p.cluster, "release": p.app})
+		return err
+	}
+	log.Info("Uninstalled release", log.Fields{"cluster": p.cluster, "release": p.app})
+	return nil
+}
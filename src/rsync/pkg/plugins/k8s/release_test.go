@@ -0,0 +1,61 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package k8s
+
+import (
+	"testing"
+
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/appcontext"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/releaseutil"
+)
+
+func TestReleaseRevision(t *testing.T) {
+	history := []*release.Release{
+		{Version: 1, Config: map[string]interface{}{releaseAppContextKey: "100"}},
+		{Version: 3, Config: map[string]interface{}{releaseAppContextKey: "300"}},
+		{Version: 2, Config: map[string]interface{}{releaseAppContextKey: "100"}},
+		{Version: 4, Config: map[string]interface{}{}},
+	}
+	for cid, rev := range map[string]int{"100": 2, "300": 3, "400": 0} {
+		if r := releaseRevision(history, cid); r != rev {
+			t.Errorf("Unexpected revision %d of AppContext %s, expected %d", r, cid, rev)
+		}
+	}
+	if last := lastRelease(history); last.Version != 4 {
+		t.Errorf("Unexpected last revision %d", last.Version)
+	}
+	if last := lastRelease(nil); last != nil {
+		t.Errorf("Unexpected last revision of a release without history")
+	}
+}
+
+func TestReleaseChart(t *testing.T) {
+	c := releaseChart(appcontext.HelmRelease{}, "app1")
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Invalid default chart %s", err)
+	}
+	if c.Name() != "app1" || c.Metadata.Version != defaultChartVersion {
+		t.Errorf("Unexpected default chart metadata %v", c.Metadata)
+	}
+	c = releaseChart(appcontext.HelmRelease{Chart: "chart1", Version: "1.2.0", AppVersion: "v2"}, "app1")
+	if c.Name() != "chart1" || c.Metadata.Version != "1.2.0" || c.AppVersion() != "v2" {
+		t.Errorf("Unexpected chart metadata %v", c.Metadata)
+	}
+}
+
+func TestResourceRenderer(t *testing.T) {
+	r := resourceRenderer{
+		[]byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm1\n"),
+		[]byte(`{"apiVersion":"v1","kind":"Service","metadata":{"name":"svc1"}}`),
+	}
+	b, err := r.Run(nil)
+	if err != nil {
+		t.Fatalf("Got an error %s", err)
+	}
+	manifests := releaseutil.SplitManifests(b.String())
+	if len(manifests) != 2 {
+		t.Errorf("Unexpected manifests %v", manifests)
+	}
+}
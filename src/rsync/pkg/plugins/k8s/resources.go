@@ -26,6 +26,7 @@ func (p *K8sProvider) Create(name string, ref interface{}, content []byte) (inte
 		}
 		return nil, err
 	}
+	p.watchResource(context.Background(), content)
 	return nil, nil
 }
 
@@ -36,6 +37,7 @@ func (p *K8sProvider) Apply(ctx context.Context, name string, ref interface{}, c
 		log.Error("Failed to apply res", log.Fields{"error": err, "resource": name})
 		return nil, err
 	}
+	p.watchResource(ctx, content)
 	acUtils, err := utils.NewAppContextReference(ctx, p.cid)
 	if err != nil {
 		return nil, nil
@@ -64,6 +66,15 @@ func (p *K8sProvider) Delete(name string, ref interface{}, content []byte) (inte
 	return nil, nil
 }
 
+// DryRun validates the resource on the cluster without changing it
+func (p *K8sProvider) DryRun(ctx context.Context, name string, content []byte) error {
+	if err := p.client.DryRun(content); err != nil {
+		log.Info("Resource failed validation", log.Fields{"error": err, "resource": name})
+		return err
+	}
+	return nil
+}
+
 // Get resource from the cluster
 func (p *K8sProvider) Get(ctx context.Context, name string, gvkRes []byte) ([]byte, error) {
 	b, err := p.client.Get(ctx, gvkRes, p.namespace)
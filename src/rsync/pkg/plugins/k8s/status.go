@@ -14,6 +14,7 @@ import (
 	v1alpha1 "gitlab.com/project-emco/core/emco-base/src/monitor/pkg/apis/k8splugin/v1alpha1"
 	clientset "gitlab.com/project-emco/core/emco-base/src/monitor/pkg/client/clientset/versioned"
 	informers "gitlab.com/project-emco/core/emco-base/src/monitor/pkg/client/informers/externalversions"
+	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/client"
 	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/internal/utils"
 	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/status"
 	"go.opentelemetry.io/otel"
@@ -62,6 +63,12 @@ func (c *K8sProvider) StartClusterWatcher(ctx context.Context) error {
 	// a cluster watcher always watches the cluster as a whole, so rsync's CloudConfig level
 	// is 0 and namespace doesn't need to be specified because the result is non-ambiguous
 	log.Info("Starting cluster watcher with L0 cloudconfig", log.Fields{})
+	// Without the monitor, rsync watches the resources themselves. The watcher
+	// rebuilds its trackers from the AppContexts deployed on the cluster.
+	if c.agentless {
+		_, err := getAgentlessWatcher(ctx, c.cluster)
+		return err
+	}
 
 	//key := provider + "+" + name
 	// Get the lock
@@ -86,6 +93,7 @@ func (c *K8sProvider) StartClusterWatcher(ctx context.Context) error {
 			log.Error("RESTConfigFromKubeConfig error:", log.Fields{"err": err})
 			return pkgerrors.Wrap(err, "RESTConfigFromKubeConfig error")
 		}
+		client.SetRateLimits(config)
 		k8sClient, err := clientset.NewForConfig(config)
 		if err != nil {
 			return pkgerrors.Wrap(err, "Clientset NewForConfig error")
@@ -100,6 +108,7 @@ func (c *K8sProvider) StartClusterWatcher(ctx context.Context) error {
 
 // StopClusterWatcher stop watching a cluster
 func StopClusterWatcher(clusterId string) {
+	stopAgentlessWatcher(clusterId)
 	//key := provider + "+" + name
 	if channelData.channels != nil {
 		c, ok := channelData.channels[clusterId]
@@ -111,6 +120,15 @@ func StopClusterWatcher(clusterId string) {
 
 // CloseAllClusterWatchers close all channels
 func CloseAllClusterWatchers() {
+	agentlessData.Lock()
+	clusters := make([]string, 0, len(agentlessData.watchers))
+	for cluster := range agentlessData.watchers {
+		clusters = append(clusters, cluster)
+	}
+	agentlessData.Unlock()
+	for _, cluster := range clusters {
+		stopAgentlessWatcher(cluster)
+	}
 	if channelData.channels == nil {
 		return
 	}
@@ -164,6 +182,14 @@ func scheduleStatus(ctx context.Context, clusterId string, c <-chan struct{}, s
 
 // ApplyStatusCR applies status CR
 func (p *K8sProvider) ApplyStatusCR(ctx context.Context, name string, content []byte) error {
+	// There is no status CR without the monitor, the status is tracked by rsync
+	if p.agentless {
+		w, err := getAgentlessWatcher(ctx, p.cluster)
+		if err != nil {
+			return err
+		}
+		return w.track(content)
+	}
 	if err := p.client.Apply(content); err != nil {
 		log.Error("Failed to apply Status CR", log.Fields{
 			"error": err,
@@ -176,6 +202,13 @@ func (p *K8sProvider) ApplyStatusCR(ctx context.Context, name string, content []
 
 // DeleteStatusCR deletes status CR
 func (p *K8sProvider) DeleteStatusCR(ctx context.Context, name string, content []byte) error {
+	if p.agentless {
+		w, err := getAgentlessWatcher(ctx, p.cluster)
+		if err != nil {
+			return err
+		}
+		return w.untrack(content)
+	}
 	if err := p.client.Delete(content); err != nil {
 		log.Error("Failed to delete Status CR", log.Fields{
 			"error": err,
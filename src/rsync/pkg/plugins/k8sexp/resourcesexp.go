@@ -123,6 +123,15 @@ func (p *K8sProviderExp) Delete(name string, ref interface{}, content []byte) (i
 
 }
 
+// DryRun validates the resource on the cluster without changing it
+func (p *K8sProviderExp) DryRun(ctx context.Context, name string, content []byte) error {
+	if err := p.client.DryRun(content); err != nil {
+		log.Info("Resource failed validation", log.Fields{"error": err, "resource": name})
+		return err
+	}
+	return nil
+}
+
 // Get resource from the cluster
 func (p *K8sProviderExp) Get(ctx context.Context, name string, gvkRes []byte) ([]byte, error) {
 	b, err := p.client.Get(ctx, gvkRes, p.namespace)
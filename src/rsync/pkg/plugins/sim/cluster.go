@@ -0,0 +1,231 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package sim
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/uuid"
+)
+
+// Label of the resources tracked by a status CR
+const deploymentIDLabel = "emco/deployment-id"
+
+// Kinds which are not in a namespace
+var clusterScopedKinds = map[string]bool{
+	"Namespace":                      true,
+	"Node":                           true,
+	"PersistentVolume":               true,
+	"StorageClass":                   true,
+	"ClusterRole":                    true,
+	"ClusterRoleBinding":             true,
+	"CustomResourceDefinition":       true,
+	"PriorityClass":                  true,
+	"CertificateSigningRequest":      true,
+	"MutatingWebhookConfiguration":   true,
+	"ValidatingWebhookConfiguration": true,
+}
+
+// simObject is a resource of a simulated cluster
+type simObject struct {
+	// Name of the resource in the AppContext, <name>+<kind>
+	name string
+	obj  *unstructured.Unstructured
+	// Content of the resource without the metadata, to detect changes
+	content string
+	// Time of the last change of the content, from which the readiness is delayed
+	changed time.Time
+}
+
+// simCluster is the in-memory state of a simulated cluster
+type simCluster struct {
+	name    string
+	config  simConfig
+	objects map[string]*simObject
+	// Status CRs of the trackers, by label of the resources they track
+	trackers map[string][]byte
+	// Trackers with a status report scheduled
+	pending map[string]bool
+	// Counters of the resource versions and of the allocated IP addresses
+	version int
+	ips     int
+	sync.Mutex
+	// Serializes the status reports of the cluster
+	reports sync.Mutex
+}
+
+type simClusters struct {
+	clusters map[string]*simCluster
+	sync.Mutex
+}
+
+var simData = simClusters{clusters: map[string]*simCluster{}}
+
+// getSimCluster returns the simulated cluster, created empty the first time
+func getSimCluster(cluster string) *simCluster {
+	simData.Lock()
+	defer simData.Unlock()
+	sc, ok := simData.clusters[cluster]
+	if !ok {
+		sc = &simCluster{
+			name:     cluster,
+			objects:  map[string]*simObject{},
+			trackers: map[string][]byte{},
+			pending:  map[string]bool{},
+		}
+		simData.clusters[cluster] = sc
+	}
+	return sc
+}
+
+// setConfig updates the behaviour of the cluster. The status is reported
+// again when the cluster becomes reachable.
+func (c *simCluster) setConfig(cfg simConfig) {
+	c.Lock()
+	defer c.Unlock()
+	reachable := c.config.unreachable && !cfg.unreachable
+	c.config = cfg
+	if reachable {
+		for label := range c.trackers {
+			c.scheduleLocked(label)
+		}
+	}
+}
+
+func objectKey(u *unstructured.Unstructured) string {
+	gvk := u.GroupVersionKind()
+	return gvk.Group + "/" + gvk.Kind + "/" + u.GetNamespace() + "/" + u.GetName()
+}
+
+// objectContent returns the content of the object which is compared to detect changes
+func objectContent(u *unstructured.Unstructured) string {
+	o := make(map[string]interface{}, len(u.Object))
+	for k, v := range u.Object {
+		if k != "metadata" && k != "status" {
+			o[k] = v
+		}
+	}
+	b, _ := json.Marshal(o)
+	return string(b)
+}
+
+// apply stores the object, with the fields the API server would set. The
+// readiness of the object is delayed again if its content changed.
+func (c *simCluster) apply(name string, u *unstructured.Unstructured) {
+	c.Lock()
+	defer c.Unlock()
+	key := objectKey(u)
+	content := objectContent(u)
+	old, ok := c.objects[key]
+	c.version++
+	u.SetResourceVersion(fmt.Sprintf("%d", c.version))
+	if ok && old.content == content {
+		// Only the metadata changed, the readiness is not delayed
+		old.name = name
+		u.SetUID(old.obj.GetUID())
+		u.SetCreationTimestamp(old.obj.GetCreationTimestamp())
+		u.SetGeneration(old.obj.GetGeneration())
+		setDefaults(u)
+		c.allocateIP(u, old.obj)
+		old.obj = u
+		c.scheduleLocked(u.GetLabels()[deploymentIDLabel])
+		return
+	}
+	var prev *unstructured.Unstructured
+	if ok {
+		prev = old.obj
+		u.SetUID(prev.GetUID())
+		u.SetCreationTimestamp(prev.GetCreationTimestamp())
+		u.SetGeneration(prev.GetGeneration() + 1)
+	} else {
+		u.SetUID(types.UID(uuid.NewUUID()))
+		u.SetCreationTimestamp(metav1.Now())
+		u.SetGeneration(1)
+	}
+	setDefaults(u)
+	c.allocateIP(u, prev)
+	c.objects[key] = &simObject{name: name, obj: u, content: content, changed: time.Now()}
+	label := u.GetLabels()[deploymentIDLabel]
+	c.scheduleLocked(label)
+	c.scheduleReady(label)
+}
+
+// get returns the object as observed on the cluster
+func (c *simCluster) get(u *unstructured.Unstructured) (*unstructured.Unstructured, bool) {
+	c.Lock()
+	defer c.Unlock()
+	o, ok := c.objects[objectKey(u)]
+	if !ok {
+		return nil, false
+	}
+	return c.observe(o)[0], true
+}
+
+// delete removes the object, returns false if the object is not on the cluster
+func (c *simCluster) delete(u *unstructured.Unstructured) bool {
+	c.Lock()
+	defer c.Unlock()
+	key := objectKey(u)
+	o, ok := c.objects[key]
+	if !ok {
+		return false
+	}
+	delete(c.objects, key)
+	c.scheduleLocked(o.obj.GetLabels()[deploymentIDLabel])
+	return true
+}
+
+// allocateIP sets the cluster IP of a service, which keeps its IP when it is updated
+func (c *simCluster) allocateIP(u, prev *unstructured.Unstructured) {
+	if u.GetKind() != "Service" {
+		return
+	}
+	if t, _, _ := unstructured.NestedString(u.Object, "spec", "type"); t == "ExternalName" {
+		return
+	}
+	if ip, _, _ := unstructured.NestedString(u.Object, "spec", "clusterIP"); ip != "" {
+		return
+	}
+	if prev != nil {
+		if ip, _, _ := unstructured.NestedString(prev.Object, "spec", "clusterIP"); ip != "" {
+			unstructured.SetNestedField(u.Object, ip, "spec", "clusterIP")
+			unstructured.SetNestedStringSlice(u.Object, []string{ip}, "spec", "clusterIPs")
+			return
+		}
+	}
+	c.ips++
+	ip := fmt.Sprintf("10.96.%d.%d", c.ips/254, c.ips%254+1)
+	unstructured.SetNestedField(u.Object, ip, "spec", "clusterIP")
+	unstructured.SetNestedStringSlice(u.Object, []string{ip}, "spec", "clusterIPs")
+}
+
+// observe returns the object with the status reported by the cluster, followed
+// by the pods of the workload
+func (c *simCluster) observe(o *simObject) []*unstructured.Unstructured {
+	state := statePending
+	switch {
+	case c.config.notReadyResources[o.name]:
+		state = stateFailed
+	case !time.Now().Before(o.changed.Add(c.config.readyDelay)):
+		state = stateReady
+	}
+	return simulateStatus(o.obj, state)
+}
+
+// observeTracker returns the objects tracked by the label
+func (c *simCluster) observeTracker(label string) []*unstructured.Unstructured {
+	var objs []*unstructured.Unstructured
+	for _, o := range c.objects {
+		if o.obj.GetLabels()[deploymentIDLabel] == label {
+			objs = append(objs, c.observe(o)...)
+		}
+	}
+	return objs
+}
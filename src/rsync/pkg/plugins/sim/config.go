@@ -0,0 +1,16 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package sim
+
+import (
+	"context"
+)
+
+// The simulated clusters have no configuration to apply
+func (p *SimProvider) ApplyConfig(ctx context.Context, config interface{}) error {
+	return nil
+}
+func (p *SimProvider) DeleteConfig(ctx context.Context, config interface{}) error {
+	return nil
+}
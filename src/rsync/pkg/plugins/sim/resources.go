@@ -0,0 +1,152 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package sim
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	pkgerrors "github.com/pkg/errors"
+	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/client"
+	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/internal/utils"
+	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/status"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// decode decodes the resource, in the namespace of the provider if it has none
+func (p *SimProvider) decode(content []byte) (*unstructured.Unstructured, error) {
+	unstruct := &unstructured.Unstructured{}
+	//Ignore the returned obj as we expect the data in unstruct
+	if _, err := utils.DecodeYAMLData(string(content), unstruct); err != nil {
+		return nil, err
+	}
+	if unstruct.GetNamespace() == "" && !clusterScopedKinds[unstruct.GetKind()] {
+		unstruct.SetNamespace(p.namespace)
+	}
+	return unstruct, nil
+}
+
+// Creates a new resource if the not already existing
+func (p *SimProvider) Create(name string, ref interface{}, content []byte) (interface{}, error) {
+	if err := p.IsReachable(); err != nil {
+		return nil, err
+	}
+	unstruct, err := p.decode(content)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := p.sc.get(unstruct); ok {
+		log.Warn("Resource is already present, Skipping", log.Fields{"cluster": p.cluster, "resource": name})
+		return nil, nil
+	}
+	return p.apply(name, unstruct)
+}
+
+// Apply resource to the simulated cluster
+func (p *SimProvider) Apply(ctx context.Context, name string, ref interface{}, content []byte) (interface{}, error) {
+	if err := p.IsReachable(); err != nil {
+		return nil, err
+	}
+	unstruct, err := p.decode(content)
+	if err != nil {
+		return nil, err
+	}
+	return p.apply(name, unstruct)
+}
+
+func (p *SimProvider) apply(name string, unstruct *unstructured.Unstructured) (interface{}, error) {
+	p.sc.Lock()
+	fail := p.sc.config.failResources[name]
+	p.sc.Unlock()
+	if fail {
+		log.Error("Failed to apply res", log.Fields{"cluster": p.cluster, "resource": name})
+		return nil, pkgerrors.Errorf("Simulated failure applying resource %s", name)
+	}
+	p.sc.apply(name, unstruct)
+	return nil, nil
+}
+
+// Delete resource from the simulated cluster
+func (p *SimProvider) Delete(name string, ref interface{}, content []byte) (interface{}, error) {
+	if err := p.IsReachable(); err != nil {
+		return nil, err
+	}
+	unstruct, err := p.decode(content)
+	if err != nil {
+		return nil, err
+	}
+	if !p.sc.delete(unstruct) {
+		log.Info("Resource not found on the simulated cluster", log.Fields{"cluster": p.cluster, "resource": name})
+	}
+	return nil, nil
+}
+
+// DryRun validates the resource without changing the simulated cluster
+func (p *SimProvider) DryRun(ctx context.Context, name string, content []byte) error {
+	if err := p.IsReachable(); err != nil {
+		return err
+	}
+	if _, err := p.decode(content); err != nil {
+		log.Info("Resource failed validation", log.Fields{"error": err, "resource": name})
+		return err
+	}
+	return nil
+}
+
+// Get resource from the simulated cluster, with its status
+func (p *SimProvider) Get(ctx context.Context, name string, gvkRes []byte) ([]byte, error) {
+	if err := p.IsReachable(); err != nil {
+		return nil, err
+	}
+	var g client.ReadResource
+	if err := json.Unmarshal(gvkRes, &g); err != nil {
+		return nil, pkgerrors.Errorf("Invalid read resource %v", err)
+	}
+	unstruct := &unstructured.Unstructured{}
+	unstruct.SetGroupVersionKind(g.Gvk)
+	unstruct.SetName(g.Name)
+	if !clusterScopedKinds[g.Gvk.Kind] {
+		ns := p.namespace
+		if ns == "default" && g.Namespace != "" {
+			ns = g.Namespace
+		}
+		unstruct.SetNamespace(ns)
+	}
+	obj, ok := p.sc.get(unstruct)
+	if !ok {
+		err := apierrors.NewNotFound(schema.GroupResource{Group: g.Gvk.Group, Resource: strings.ToLower(g.Gvk.Kind) + "s"}, g.Name)
+		log.Error("Failed to get res", log.Fields{"error": err, "resource": name})
+		return nil, err
+	}
+	return obj.MarshalJSON()
+}
+
+// Commit resources to the cluster
+// Not required for the simulated clusters
+func (p *SimProvider) Commit(ctx context.Context, ref interface{}) error {
+	return nil
+}
+
+// IsReachable cluster reachablity test, fails if the cluster is simulated as unreachable
+func (p *SimProvider) IsReachable() error {
+	p.sc.Lock()
+	defer p.sc.Unlock()
+	if p.sc.config.unreachable {
+		return pkgerrors.Errorf("Simulated cluster %s is not reachable", p.cluster)
+	}
+	return nil
+}
+
+func (p *SimProvider) TagResource(res []byte, label string) ([]byte, error) {
+	b, err := status.TagResource(res, label)
+	if err != nil {
+		log.Error("Error Tag Resoruce with label:", log.Fields{"err": err, "label": label, "resource": res})
+		return nil, err
+	}
+	return b, nil
+}
@@ -0,0 +1,151 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package sim
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/db"
+	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/internal/utils"
+)
+
+// SimTarget is the deployment target of the simulated clusters
+const SimTarget = "sim"
+
+// Keys of the simulation settings, in the resource sync object of the cluster
+const (
+	// Seconds before the applied resources are ready
+	readyDelayKey = "readyDelay"
+	// Comma-separated names (<name>+<kind>) of the resources failing to apply
+	failResourcesKey = "failResources"
+	// Comma-separated names (<name>+<kind>) of the resources which never become
+	// ready: their pods are not ready and their jobs fail
+	notReadyResourcesKey = "notReadyResources"
+	// The cluster is not reachable if true
+	unreachableKey = "unreachable"
+)
+
+// simConfig is the behaviour of a simulated cluster
+type simConfig struct {
+	readyDelay        time.Duration
+	failResources     map[string]bool
+	notReadyResources map[string]bool
+	unreachable       bool
+}
+
+// SimProvider deploys the resources to a simulated cluster, kept in memory by rsync
+type SimProvider struct {
+	cid       string
+	cluster   string
+	app       string
+	namespace string
+	level     string
+	sc        *simCluster
+}
+
+// NewSimProvider returns the provider of a simulated cluster. The simulation
+// settings are read again for each provider, so that they can be changed while
+// the cluster is in use.
+func NewSimProvider(ctx context.Context, cid, app, cluster, level, namespace string) (*SimProvider, error) {
+	c, err := utils.GetGitOpsConfig(ctx, cluster, "0", "default")
+	if err != nil {
+		return nil, err
+	}
+	target := c.Props.DeploymentTarget
+	if target == "" {
+		target = c.Props.GitOpsType
+	}
+	if target != SimTarget {
+		log.Error("Invalid GitOps type:", log.Fields{"cluster": cluster, "type": target})
+		return nil, pkgerrors.Errorf("Invalid GitOps type: " + target)
+	}
+	cfg, err := readConfig(ctx, cluster, c.Props.GitOpsResourceObject)
+	if err != nil {
+		return nil, err
+	}
+	return newSimProvider(cid, app, cluster, level, namespace, cfg), nil
+}
+
+func newSimProvider(cid, app, cluster, level, namespace string, cfg simConfig) *SimProvider {
+	if namespace == "" {
+		namespace = "default"
+	}
+	sc := getSimCluster(cluster)
+	sc.setConfig(cfg)
+	return &SimProvider{
+		cid:       cid,
+		app:       app,
+		cluster:   cluster,
+		level:     level,
+		namespace: namespace,
+		sc:        sc,
+	}
+}
+
+// readConfig reads the simulation settings from the key value pairs of the
+// sync object. The defaults are used if the cluster has no sync object.
+func readConfig(ctx context.Context, cluster, syncObject string) (simConfig, error) {
+	cfg := simConfig{failResources: map[string]bool{}, notReadyResources: map[string]bool{}}
+	if syncObject == "" {
+		return cfg, nil
+	}
+	result := strings.SplitN(cluster, "+", 2)
+	if len(result) != 2 {
+		return cfg, pkgerrors.New("Not a valid cluster name")
+	}
+	obj, err := db.NewCloudConfigClient().GetClusterSyncObjects(ctx, result[0], syncObject)
+	if err != nil {
+		log.Error("Invalid resource object:", log.Fields{"resObj": syncObject, "error": err})
+		return cfg, err
+	}
+	return parseConfig(obj.Spec.Kv)
+}
+
+// parseConfig parses the simulation settings
+func parseConfig(kv []map[string]interface{}) (simConfig, error) {
+	cfg := simConfig{failResources: map[string]bool{}, notReadyResources: map[string]bool{}}
+	for _, kvpair := range kv {
+		for k, v := range kvpair {
+			value := strings.TrimSpace(fmt.Sprintf("%v", v))
+			switch k {
+			case readyDelayKey:
+				s, err := strconv.ParseFloat(value, 64)
+				if err != nil || s < 0 {
+					return cfg, pkgerrors.Errorf("Invalid %s: %s", readyDelayKey, value)
+				}
+				cfg.readyDelay = time.Duration(s * float64(time.Second))
+			case failResourcesKey:
+				addNames(cfg.failResources, value)
+			case notReadyResourcesKey:
+				addNames(cfg.notReadyResources, value)
+			case unreachableKey:
+				b, err := strconv.ParseBool(value)
+				if err != nil {
+					return cfg, pkgerrors.Errorf("Invalid %s: %s", unreachableKey, value)
+				}
+				cfg.unreachable = b
+			}
+		}
+	}
+	return cfg, nil
+}
+
+func addNames(names map[string]bool, value string) {
+	for _, n := range strings.Split(value, ",") {
+		if n = strings.TrimSpace(n); n != "" {
+			names[n] = true
+		}
+	}
+}
+
+// CleanClientProvider has nothing to clean up, the cluster is kept in memory
+func (p *SimProvider) CleanClientProvider() error {
+	return nil
+}
@@ -0,0 +1,267 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package sim
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	v1alpha1 "gitlab.com/project-emco/core/emco-base/src/monitor/pkg/apis/k8splugin/v1alpha1"
+	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/client"
+	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/status"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const label = "1234-app1"
+
+const service = `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  ports:
+  - port: 80
+`
+
+const deployment = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 2
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      containers:
+      - name: web
+        image: nginx
+`
+
+const job = `
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: job1
+spec:
+  template:
+    spec:
+      restartPolicy: Never
+      containers:
+      - name: job
+        image: busybox
+`
+
+const configMap = `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm1
+`
+
+func newConfig() simConfig {
+	return simConfig{failResources: map[string]bool{}, notReadyResources: map[string]bool{}}
+}
+
+// resetCluster removes the simulated cluster, left by a previous run of the test
+func resetCluster(cluster string) {
+	simData.Lock()
+	defer simData.Unlock()
+	delete(simData.clusters, cluster)
+}
+
+// captureStatus returns the channel of the status reports
+func captureStatus(t *testing.T) <-chan *v1alpha1.ResourceBundleState {
+	reports := make(chan *v1alpha1.ResourceBundleState, 10)
+	savedHandleStatus := handleStatus
+	t.Cleanup(func() { handleStatus = savedHandleStatus })
+	handleStatus = func(ctx context.Context, acID, app, cluster string, rbData *v1alpha1.ResourceBundleState) {
+		if acID != "1234" || app != "app1" {
+			t.Errorf("Unexpected status report of %s %s", acID, app)
+		}
+		reports <- rbData
+	}
+	return reports
+}
+
+// waitStatus waits for a status report accepted by the function
+func waitStatus(t *testing.T, reports <-chan *v1alpha1.ResourceBundleState, f func(*v1alpha1.ResourceBundleState) bool) {
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case r := <-reports:
+			if f(r) {
+				return
+			}
+		case <-timeout:
+			t.Fatal("Timed out waiting for the status")
+		}
+	}
+}
+
+func apply(t *testing.T, p *SimProvider, name, content string) error {
+	b, err := p.TagResource([]byte(content), label)
+	if err != nil {
+		t.Fatalf("TagResource failed: %v", err)
+	}
+	_, err = p.Apply(context.Background(), name, nil, b)
+	return err
+}
+
+func trackStatus(t *testing.T, p *SimProvider) {
+	cr, err := status.GetStatusCR(label, "", "default")
+	if err != nil {
+		t.Fatalf("GetStatusCR failed: %v", err)
+	}
+	if err := p.ApplyStatusCR(context.Background(), label, cr); err != nil {
+		t.Fatalf("ApplyStatusCR failed: %v", err)
+	}
+	// Stop the reports scheduled after the test
+	t.Cleanup(func() { p.DeleteStatusCR(context.Background(), label, cr) })
+}
+
+func get(p *SimProvider, gvk schema.GroupVersionKind, name string) (*unstructured.Unstructured, error) {
+	r, _ := json.Marshal(client.ReadResource{Gvk: gvk, Name: name})
+	b, err := p.Get(context.Background(), name, r)
+	if err != nil {
+		return nil, err
+	}
+	u := &unstructured.Unstructured{}
+	return u, u.UnmarshalJSON(b)
+}
+
+func TestApplyGetDelete(t *testing.T) {
+	resetCluster("provider1+apply")
+	p := newSimProvider("1234", "app1", "provider1+apply", "0", "", newConfig())
+	gvk := schema.GroupVersionKind{Version: "v1", Kind: "Service"}
+	if err := apply(t, p, "web+Service", service); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	u, err := get(p, gvk, "web")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	ip, _, _ := unstructured.NestedString(u.Object, "spec", "clusterIP")
+	if ip == "" || u.GetUID() == "" || u.GetNamespace() != "default" || u.GetGeneration() != 1 {
+		t.Errorf("Unexpected service %v", u.Object)
+	}
+	// The service keeps its IP when it is applied again
+	if err := apply(t, p, "web+Service", service); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	u2, err := get(p, gvk, "web")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	ip2, _, _ := unstructured.NestedString(u2.Object, "spec", "clusterIP")
+	if ip2 != ip || u2.GetUID() != u.GetUID() || u2.GetGeneration() != 1 {
+		t.Errorf("Unexpected service after update %v", u2.Object)
+	}
+	if _, err := p.Delete("web+Service", nil, []byte(service)); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := get(p, gvk, "web"); !apierrors.IsNotFound(err) {
+		t.Errorf("Expected not found after delete, got %v", err)
+	}
+}
+
+func TestStatusReports(t *testing.T) {
+	reports := captureStatus(t)
+	cfg := newConfig()
+	cfg.readyDelay = 300 * time.Millisecond
+	resetCluster("provider1+status")
+	p := newSimProvider("1234", "app1", "provider1+status", "0", "", cfg)
+	trackStatus(t, p)
+	if err := apply(t, p, "web+Deployment", deployment); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	checker := status.NewReadyChecker()
+	// The deployment is reported with its pods, not ready until the delay elapsed
+	waitStatus(t, reports, func(r *v1alpha1.ResourceBundleState) bool {
+		s := r.Status
+		if len(s.DeploymentStatuses) != 1 || len(s.PodStatuses) != 2 {
+			return false
+		}
+		if checker.DeploymentReady(&s.DeploymentStatuses[0]) || checker.PodReady(&s.PodStatuses[0]) {
+			t.Errorf("Deployment ready before the delay")
+		}
+		if s.PodStatuses[0].Labels["app"] != "web" {
+			t.Errorf("Unexpected pod labels %v", s.PodStatuses[0].Labels)
+		}
+		return true
+	})
+	waitStatus(t, reports, func(r *v1alpha1.ResourceBundleState) bool {
+		s := r.Status
+		return len(s.DeploymentStatuses) == 1 && len(s.PodStatuses) == 2 &&
+			checker.DeploymentReady(&s.DeploymentStatuses[0]) && checker.PodReady(&s.PodStatuses[1])
+	})
+}
+
+func TestSimulatedFailures(t *testing.T) {
+	reports := captureStatus(t)
+	cfg := newConfig()
+	cfg.failResources["cm1+ConfigMap"] = true
+	cfg.notReadyResources["job1+Job"] = true
+	resetCluster("provider1+failures")
+	p := newSimProvider("1234", "app1", "provider1+failures", "0", "", cfg)
+	trackStatus(t, p)
+	if err := apply(t, p, "cm1+ConfigMap", configMap); err == nil {
+		t.Errorf("Expected the apply of cm1 to fail")
+	}
+	if err := apply(t, p, "job1+Job", job); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	checker := status.NewReadyChecker()
+	waitStatus(t, reports, func(r *v1alpha1.ResourceBundleState) bool {
+		s := r.Status
+		return len(s.JobStatuses) == 1 && checker.JobFailed(&s.JobStatuses[0]) &&
+			len(s.PodStatuses) == 1 && checker.PodFailed(&s.PodStatuses[0])
+	})
+
+	// Nothing is reported while the cluster is unreachable, and the status
+	// is reported again once it is reachable
+	cfg = newConfig()
+	cfg.unreachable = true
+	p = newSimProvider("1234", "app1", "provider1+failures", "0", "", cfg)
+	if err := p.IsReachable(); err == nil {
+		t.Errorf("Expected the cluster to be unreachable")
+	}
+	if err := apply(t, p, "cm1+ConfigMap", configMap); err == nil {
+		t.Errorf("Expected the apply to an unreachable cluster to fail")
+	}
+	p = newSimProvider("1234", "app1", "provider1+failures", "0", "", newConfig())
+	waitStatus(t, reports, func(r *v1alpha1.ResourceBundleState) bool {
+		s := r.Status
+		return len(s.JobStatuses) == 1 && checker.JobSuccess(&s.JobStatuses[0])
+	})
+}
+
+func TestParseConfig(t *testing.T) {
+	cfg, err := parseConfig([]map[string]interface{}{
+		{readyDelayKey: "1.5"},
+		{failResourcesKey: "cm1+ConfigMap, web+Service"},
+		{notReadyResourcesKey: "job1+Job"},
+		{unreachableKey: true},
+	})
+	if err != nil {
+		t.Fatalf("parseConfig failed: %v", err)
+	}
+	if cfg.readyDelay != 1500*time.Millisecond || !cfg.failResources["web+Service"] ||
+		!cfg.notReadyResources["job1+Job"] || !cfg.unreachable {
+		t.Errorf("Unexpected config %+v", cfg)
+	}
+	if _, err := parseConfig([]map[string]interface{}{{readyDelayKey: "soon"}}); err == nil {
+		t.Errorf("Expected an invalid %s to fail", readyDelayKey)
+	}
+}
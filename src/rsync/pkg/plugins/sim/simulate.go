@@ -0,0 +1,236 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package sim
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// simState is the state of a simulated resource
+type simState int
+
+const (
+	// The resource was applied and is not ready yet
+	statePending simState = iota
+	stateReady
+	// The resource never becomes ready
+	stateFailed
+)
+
+// Name of the node running the pods of the simulated clusters
+const simNode = "sim-node"
+
+var (
+	deploymentKind  = schema.GroupKind{Group: "apps", Kind: "Deployment"}
+	statefulSetKind = schema.GroupKind{Group: "apps", Kind: "StatefulSet"}
+	daemonSetKind   = schema.GroupKind{Group: "apps", Kind: "DaemonSet"}
+	jobKind         = schema.GroupKind{Group: "batch", Kind: "Job"}
+	podKind         = schema.GroupKind{Kind: "Pod"}
+	serviceKind     = schema.GroupKind{Kind: "Service"}
+	pvcKind         = schema.GroupKind{Kind: "PersistentVolumeClaim"}
+	namespaceKind   = schema.GroupKind{Kind: "Namespace"}
+)
+
+// setDefault sets the field if it is not set
+func setDefault(u *unstructured.Unstructured, value interface{}, fields ...string) {
+	if _, found, _ := unstructured.NestedFieldNoCopy(u.Object, fields...); !found {
+		unstructured.SetNestedField(u.Object, value, fields...)
+	}
+}
+
+// setDefaults sets the defaults of the API server which the readiness checks rely on
+func setDefaults(u *unstructured.Unstructured) {
+	switch u.GroupVersionKind().GroupKind() {
+	case deploymentKind:
+		setDefault(u, int64(1), "spec", "replicas")
+		setDefault(u, "RollingUpdate", "spec", "strategy", "type")
+		if t, _, _ := unstructured.NestedString(u.Object, "spec", "strategy", "type"); t == "RollingUpdate" {
+			setDefault(u, "25%", "spec", "strategy", "rollingUpdate", "maxUnavailable")
+			setDefault(u, "25%", "spec", "strategy", "rollingUpdate", "maxSurge")
+		}
+	case statefulSetKind:
+		setDefault(u, int64(1), "spec", "replicas")
+		setDefault(u, "RollingUpdate", "spec", "updateStrategy", "type")
+		if t, _, _ := unstructured.NestedString(u.Object, "spec", "updateStrategy", "type"); t == "RollingUpdate" {
+			setDefault(u, int64(0), "spec", "updateStrategy", "rollingUpdate", "partition")
+		}
+	case daemonSetKind:
+		setDefault(u, "RollingUpdate", "spec", "updateStrategy", "type")
+		if t, _, _ := unstructured.NestedString(u.Object, "spec", "updateStrategy", "type"); t == "RollingUpdate" {
+			setDefault(u, int64(1), "spec", "updateStrategy", "rollingUpdate", "maxUnavailable")
+		}
+	case jobKind:
+		setDefault(u, int64(6), "spec", "backoffLimit")
+		setDefault(u, int64(1), "spec", "completions")
+		setDefault(u, int64(1), "spec", "parallelism")
+	case serviceKind:
+		setDefault(u, "ClusterIP", "spec", "type")
+	}
+}
+
+func nestedInt(u *unstructured.Unstructured, def int64, fields ...string) int64 {
+	if v, found, err := unstructured.NestedInt64(u.Object, fields...); found && err == nil {
+		return v
+	}
+	return def
+}
+
+func condition(t string, ok bool) interface{} {
+	status := "False"
+	if ok {
+		status = "True"
+	}
+	return map[string]interface{}{"type": t, "status": status}
+}
+
+// simulateStatus returns the object with the status of the state, followed by
+// the pods of the workloads
+func simulateStatus(obj *unstructured.Unstructured, state simState) []*unstructured.Unstructured {
+	u := obj.DeepCopy()
+	objs := []*unstructured.Unstructured{u}
+	generation := u.GetGeneration()
+	switch u.GroupVersionKind().GroupKind() {
+	case deploymentKind:
+		n := nestedInt(u, 1, "spec", "replicas")
+		ready := readyCount(n, state)
+		u.Object["status"] = map[string]interface{}{
+			"observedGeneration":  generation,
+			"replicas":            n,
+			"updatedReplicas":     n,
+			"readyReplicas":       ready,
+			"availableReplicas":   ready,
+			"unavailableReplicas": n - ready,
+			"conditions":          []interface{}{condition("Available", ready == n)},
+		}
+		for i := int64(0); i < n; i++ {
+			objs = append(objs, newPod(u, fmt.Sprintf("%s-sim-%d", u.GetName(), i), nil, state, false))
+		}
+	case statefulSetKind:
+		n := nestedInt(u, 1, "spec", "replicas")
+		ready := readyCount(n, state)
+		u.Object["status"] = map[string]interface{}{
+			"observedGeneration": generation,
+			"replicas":           n,
+			"currentReplicas":    n,
+			"updatedReplicas":    n,
+			"readyReplicas":      ready,
+			"availableReplicas":  ready,
+		}
+		for i := int64(0); i < n; i++ {
+			objs = append(objs, newPod(u, fmt.Sprintf("%s-%d", u.GetName(), i), nil, state, false))
+		}
+	case daemonSetKind:
+		// The simulated clusters have a single node
+		ready := readyCount(1, state)
+		u.Object["status"] = map[string]interface{}{
+			"observedGeneration":     generation,
+			"desiredNumberScheduled": int64(1),
+			"currentNumberScheduled": int64(1),
+			"updatedNumberScheduled": int64(1),
+			"numberReady":            ready,
+			"numberAvailable":        ready,
+			"numberUnavailable":      1 - ready,
+		}
+		objs = append(objs, newPod(u, u.GetName()+"-sim", nil, state, false))
+	case jobKind:
+		status := map[string]interface{}{}
+		switch state {
+		case statePending:
+			status["active"] = nestedInt(u, 1, "spec", "parallelism")
+		case stateReady:
+			status["succeeded"] = nestedInt(u, 1, "spec", "completions")
+			status["conditions"] = []interface{}{condition("Complete", true)}
+		case stateFailed:
+			status["failed"] = nestedInt(u, 6, "spec", "backoffLimit") + 1
+			status["conditions"] = []interface{}{condition("Failed", true)}
+		}
+		u.Object["status"] = status
+		labels := map[string]string{"job-name": u.GetName(), "controller-uid": string(u.GetUID())}
+		objs = append(objs, newPod(u, u.GetName()+"-sim", labels, state, true))
+	case podKind:
+		_, hook := u.GetAnnotations()["helm.sh/hook"]
+		policy, _, _ := unstructured.NestedString(u.Object, "spec", "restartPolicy")
+		unstructured.SetNestedField(u.Object, simNode, "spec", "nodeName")
+		setPodStatus(u, state, hook || policy == "Never" || policy == "OnFailure")
+	case serviceKind:
+		t, _, _ := unstructured.NestedString(u.Object, "spec", "type")
+		ip, _, _ := unstructured.NestedString(u.Object, "spec", "clusterIP")
+		ingress := []interface{}{}
+		if t == "LoadBalancer" && state == stateReady && ip != "" {
+			ingress = append(ingress, map[string]interface{}{"ip": strings.Replace(ip, "10.96.", "172.18.", 1)})
+		}
+		u.Object["status"] = map[string]interface{}{"loadBalancer": map[string]interface{}{"ingress": ingress}}
+	case pvcKind:
+		phase := "Pending"
+		if state == stateReady {
+			phase = "Bound"
+		}
+		u.Object["status"] = map[string]interface{}{"phase": phase}
+	case namespaceKind:
+		u.Object["status"] = map[string]interface{}{"phase": "Active"}
+	}
+	return objs
+}
+
+func readyCount(n int64, state simState) int64 {
+	if state == stateReady {
+		return n
+	}
+	return 0
+}
+
+// newPod returns a pod of the workload, with the labels of its template
+func newPod(owner *unstructured.Unstructured, name string, labels map[string]string, state simState, completes bool) *unstructured.Unstructured {
+	pod := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	pod.SetAPIVersion("v1")
+	pod.SetKind("Pod")
+	pod.SetName(name)
+	pod.SetNamespace(owner.GetNamespace())
+	pod.SetUID(types.UID(string(owner.GetUID()) + "-" + name))
+	pod.SetCreationTimestamp(owner.GetCreationTimestamp())
+	l, _, _ := unstructured.NestedStringMap(owner.Object, "spec", "template", "metadata", "labels")
+	if l == nil {
+		l = map[string]string{}
+	}
+	for k, v := range labels {
+		l[k] = v
+	}
+	pod.SetLabels(l)
+	controller := true
+	pod.SetOwnerReferences([]metav1.OwnerReference{{APIVersion: owner.GetAPIVersion(), Kind: owner.GetKind(),
+		Name: owner.GetName(), UID: owner.GetUID(), Controller: &controller}})
+	if spec, found, _ := unstructured.NestedMap(owner.Object, "spec", "template", "spec"); found {
+		pod.Object["spec"] = spec
+	}
+	unstructured.SetNestedField(pod.Object, simNode, "spec", "nodeName")
+	setPodStatus(pod, state, completes)
+	return pod
+}
+
+// setPodStatus sets the phase and the ready condition of the pod. The pods
+// which complete succeed when they are ready.
+func setPodStatus(pod *unstructured.Unstructured, state simState, completes bool) {
+	phase, ready := "Pending", false
+	switch {
+	case state == stateReady && completes:
+		phase = "Succeeded"
+	case state == stateReady:
+		phase, ready = "Running", true
+	case state == stateFailed && completes:
+		phase = "Failed"
+	case state == stateFailed:
+		// Crashing containers
+		phase = "Running"
+	}
+	pod.Object["status"] = map[string]interface{}{
+		"phase":      phase,
+		"conditions": []interface{}{condition("Ready", ready)},
+	}
+}
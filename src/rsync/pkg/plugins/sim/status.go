@@ -0,0 +1,111 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package sim
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/status"
+	"go.opentelemetry.io/otel"
+)
+
+// Handles the status updates, replaced in tests
+var handleStatus = status.HandleResourcesStatus
+
+// Delay of the status reports, the changes made in the meantime are reported together
+var reportDelay = 100 * time.Millisecond
+
+// scheduleLocked schedules a status report of the tracker. Must be called
+// with the cluster locked.
+func (c *simCluster) scheduleLocked(label string) {
+	if label == "" || c.pending[label] {
+		return
+	}
+	c.pending[label] = true
+	time.AfterFunc(reportDelay, func() { c.report(label) })
+}
+
+// scheduleReady reports the status of the tracker again when the resources are ready
+func (c *simCluster) scheduleReady(label string) {
+	if label == "" || c.config.readyDelay <= 0 {
+		return
+	}
+	time.AfterFunc(c.config.readyDelay, func() {
+		c.Lock()
+		defer c.Unlock()
+		c.scheduleLocked(label)
+	})
+}
+
+// report reports the status of the resources tracked by the label
+func (c *simCluster) report(label string) {
+	// The reports are asynchronous, so they are not derived from the context
+	// of the request that applied the resources
+	tracer := otel.Tracer("rsync")
+	ctx, span := tracer.Start(context.Background(), "simStatus")
+	defer span.End()
+
+	c.reports.Lock()
+	defer c.reports.Unlock()
+	c.Lock()
+	delete(c.pending, label)
+	cr, ok := c.trackers[label]
+	if !ok || c.config.unreachable {
+		c.Unlock()
+		return
+	}
+	objs := c.observeTracker(label)
+	c.Unlock()
+
+	rbState, err := status.NewResourceBundleState(cr, objs)
+	if err != nil {
+		log.Error("Error computing the simulated status", log.Fields{"cluster": c.name, "label": label, "error": err})
+		return
+	}
+	result := strings.SplitN(label, "-", 2)
+	if len(result) != 2 || result[0] == "" || result[1] == "" {
+		log.Error("::invalid label format::", log.Fields{"id": label, "cluster": c.name})
+		return
+	}
+	handleStatus(ctx, result[0], result[1], c.name, rbState)
+}
+
+// StartClusterWatcher has nothing to watch, the simulated cluster reports the
+// status when its resources change
+func (p *SimProvider) StartClusterWatcher(ctx context.Context) error {
+	return nil
+}
+
+// ApplyStatusCR starts tracking the status of the resources with the label of the status CR
+func (p *SimProvider) ApplyStatusCR(ctx context.Context, name string, content []byte) error {
+	if err := p.IsReachable(); err != nil {
+		return err
+	}
+	label, err := status.StatusCRName(content)
+	if err != nil {
+		log.Error("Failed to apply Status CR", log.Fields{"error": err})
+		return err
+	}
+	p.sc.Lock()
+	defer p.sc.Unlock()
+	p.sc.trackers[label] = content
+	p.sc.scheduleLocked(label)
+	return nil
+}
+
+// DeleteStatusCR stops tracking the status of the resources with the label of the status CR
+func (p *SimProvider) DeleteStatusCR(ctx context.Context, name string, content []byte) error {
+	label, err := status.StatusCRName(content)
+	if err != nil {
+		log.Error("Failed to delete Status CR", log.Fields{"error": err})
+		return err
+	}
+	p.sc.Lock()
+	defer p.sc.Unlock()
+	delete(p.sc.trackers, label)
+	return nil
+}
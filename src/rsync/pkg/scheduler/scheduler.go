@@ -0,0 +1,147 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+// Package scheduler bounds the number of workers running at once and shares
+// them fairly between the owners asking for them.
+package scheduler
+
+import (
+	"context"
+	"sync"
+)
+
+// owner tracks the workers running and waiting for an owner
+type owner struct {
+	active  int
+	waiters []chan struct{}
+}
+
+// Scheduler limits the workers running at once, globally and per owner. When
+// a worker is released it is granted to the waiting owner running the fewest
+// workers, so an owner with many workers can't starve the others.
+type Scheduler struct {
+	limit    int
+	perOwner int
+	active   int
+	owners   map[string]*owner
+	// Owners in the order they were last granted a worker
+	order []string
+	sync.Mutex
+}
+
+// New returns a scheduler running at most limit workers, and at most perOwner
+// workers for an owner. A limit of 0 or less is unbounded.
+func New(limit, perOwner int) *Scheduler {
+	return &Scheduler{limit: limit, perOwner: perOwner, owners: map[string]*owner{}}
+}
+
+// Acquire waits until a worker is granted to the owner or the context is done.
+// A worker free right away is granted even if the context is already done,
+// only the wait is cancelled.
+func (s *Scheduler) Acquire(ctx context.Context, name string) error {
+	s.Lock()
+	o, ok := s.owners[name]
+	if !ok {
+		o = &owner{}
+		s.owners[name] = o
+		s.order = append(s.order, name)
+	}
+	ch := make(chan struct{})
+	o.waiters = append(o.waiters, ch)
+	s.dispatch()
+	s.Unlock()
+
+	select {
+	case <-ch:
+		return nil
+	default:
+	}
+	select {
+	case <-ch:
+		return nil
+	case <-ctx.Done():
+	}
+	s.Lock()
+	defer s.Unlock()
+	select {
+	case <-ch:
+		// Granted while giving up, hand the worker to someone else
+		s.release(name)
+	default:
+		for i, w := range o.waiters {
+			if w == ch {
+				o.waiters = append(o.waiters[:i], o.waiters[i+1:]...)
+				break
+			}
+		}
+		s.cleanup(name)
+	}
+	return ctx.Err()
+}
+
+// Release returns a worker granted to the owner
+func (s *Scheduler) Release(name string) {
+	s.Lock()
+	defer s.Unlock()
+	s.release(name)
+}
+
+// Active returns the number of workers running
+func (s *Scheduler) Active() int {
+	s.Lock()
+	defer s.Unlock()
+	return s.active
+}
+
+func (s *Scheduler) release(name string) {
+	o, ok := s.owners[name]
+	if !ok || o.active == 0 {
+		return
+	}
+	o.active--
+	s.active--
+	s.cleanup(name)
+	s.dispatch()
+}
+
+// dispatch grants the free workers to the waiting owners
+func (s *Scheduler) dispatch() {
+	for s.limit <= 0 || s.active < s.limit {
+		next := -1
+		for i, name := range s.order {
+			o := s.owners[name]
+			if len(o.waiters) == 0 || (s.perOwner > 0 && o.active >= s.perOwner) {
+				continue
+			}
+			if next < 0 || o.active < s.owners[s.order[next]].active {
+				next = i
+			}
+		}
+		if next < 0 {
+			return
+		}
+		name := s.order[next]
+		o := s.owners[name]
+		close(o.waiters[0])
+		o.waiters = o.waiters[1:]
+		o.active++
+		s.active++
+		// Move the owner to the back of the line
+		s.order = append(append(s.order[:next:next], s.order[next+1:]...), name)
+	}
+}
+
+// cleanup forgets an owner with no workers running or waiting
+func (s *Scheduler) cleanup(name string) {
+	o := s.owners[name]
+	if o.active > 0 || len(o.waiters) > 0 {
+		return
+	}
+	delete(s.owners, name)
+	for i, n := range s.order {
+		if n == name {
+			s.order = append(s.order[:i], s.order[i+1:]...)
+			return
+		}
+	}
+}
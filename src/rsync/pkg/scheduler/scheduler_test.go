@@ -0,0 +1,136 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// acquireAsync asks for a worker and reports the owner when it is granted
+func acquireAsync(s *Scheduler, name string, granted chan<- string) {
+	go func() {
+		if err := s.Acquire(context.Background(), name); err == nil {
+			granted <- name
+		}
+	}()
+}
+
+func waitGranted(t *testing.T, granted <-chan string) string {
+	select {
+	case name := <-granted:
+		return name
+	case <-time.After(5 * time.Second):
+		t.Fatal("Worker not granted")
+	}
+	return ""
+}
+
+func waitWaiters(s *Scheduler, name string, n int) {
+	for {
+		s.Lock()
+		o, ok := s.owners[name]
+		done := ok && len(o.waiters) == n
+		s.Unlock()
+		if done {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	s := New(3, 2)
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if err := s.Acquire(ctx, "a"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The owner has all its workers
+	granted := make(chan string, 10)
+	acquireAsync(s, "a", granted)
+	waitWaiters(s, "a", 1)
+	if err := s.Acquire(ctx, "b"); err != nil {
+		t.Fatal(err)
+	}
+	// No worker left
+	cctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if err := s.Acquire(cctx, "c"); err == nil {
+		t.Fatal("Worker granted over the limit")
+	}
+	if s.Active() != 3 {
+		t.Fatalf("Unexpected active workers %d", s.Active())
+	}
+	s.Release("a")
+	if name := waitGranted(t, granted); name != "a" {
+		t.Fatalf("Worker granted to %s", name)
+	}
+}
+
+func TestFairness(t *testing.T) {
+	s := New(2, 0)
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if err := s.Acquire(ctx, "large"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	granted := make(chan string, 10)
+	for i := 0; i < 3; i++ {
+		acquireAsync(s, "large", granted)
+	}
+	waitWaiters(s, "large", 3)
+	acquireAsync(s, "small", granted)
+	waitWaiters(s, "small", 1)
+	// The owner running fewer workers goes first
+	s.Release("large")
+	if name := waitGranted(t, granted); name != "small" {
+		t.Fatalf("Worker granted to %s, expected small", name)
+	}
+	s.Release("small")
+	if name := waitGranted(t, granted); name != "large" {
+		t.Fatalf("Worker granted to %s, expected large", name)
+	}
+}
+
+func TestUnbounded(t *testing.T) {
+	s := New(0, 0)
+	for i := 0; i < 100; i++ {
+		if err := s.Acquire(context.Background(), "a"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 100; i++ {
+		s.Release("a")
+	}
+	if s.Active() != 0 || len(s.owners) != 0 {
+		t.Fatalf("Workers not released %d", s.Active())
+	}
+}
+
+func TestCancelled(t *testing.T) {
+	s := New(1, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	// A free worker is granted, only the wait is cancelled
+	for i := 0; i < 100; i++ {
+		if err := s.Acquire(ctx, "a"); err != nil {
+			t.Fatalf("Free worker not granted: %v", err)
+		}
+		s.Release("a")
+	}
+	if err := s.Acquire(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Acquire(ctx, "b"); err == nil {
+		t.Fatal("Worker granted over the limit")
+	}
+	s.Release("a")
+	if s.Active() != 0 || len(s.owners) != 0 {
+		t.Fatalf("Workers not released %d", s.Active())
+	}
+}
@@ -0,0 +1,121 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package status
+
+import (
+	"encoding/json"
+	"sort"
+
+	yaml "github.com/ghodss/yaml"
+	pkgerrors "github.com/pkg/errors"
+	rb "gitlab.com/project-emco/core/emco-base/src/monitor/pkg/apis/k8splugin/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	certsapi "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// NewResourceBundleState returns the ResourceBundleState of the resources
+// tracked by a status CR, with the status the monitor would report for them.
+// It is used for the clusters where the monitor is not installed.
+func NewResourceBundleState(statusCR []byte, objs []*unstructured.Unstructured) (*rb.ResourceBundleState, error) {
+	rbState := &rb.ResourceBundleState{}
+	if err := yaml.Unmarshal(statusCR, rbState); err != nil {
+		return nil, pkgerrors.Wrap(err, "Error decoding the status CR")
+	}
+	// The resources are reported in a stable order
+	sorted := append([]*unstructured.Unstructured{}, objs...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].GetKind() != sorted[j].GetKind() {
+			return sorted[i].GetKind() < sorted[j].GetKind()
+		}
+		if sorted[i].GetNamespace() != sorted[j].GetNamespace() {
+			return sorted[i].GetNamespace() < sorted[j].GetNamespace()
+		}
+		return sorted[i].GetName() < sorted[j].GetName()
+	})
+	for _, obj := range sorted {
+		if err := addResourceStatus(&rbState.Status, obj.DeepCopy()); err != nil {
+			return nil, err
+		}
+	}
+	return rbState, nil
+}
+
+// addResourceStatus adds the resource to the typed statuses of its kind, or to
+// the generic resource statuses
+func addResourceStatus(s *rb.ResourceBundleStateStatus, obj *unstructured.Unstructured) error {
+	// Clear up some fields to reduce size, like the monitor
+	obj.SetManagedFields(nil)
+	if a := obj.GetAnnotations(); a != nil {
+		if _, ok := a[lastAppliedAnnotation]; ok {
+			a[lastAppliedAnnotation] = ""
+			obj.SetAnnotations(a)
+		}
+	}
+	var typed interface{}
+	switch obj.GroupVersionKind() {
+	case schema.GroupVersionKind{Version: "v1", Kind: "Pod"}:
+		typed = &corev1.Pod{}
+	case schema.GroupVersionKind{Version: "v1", Kind: "Service"}:
+		typed = &corev1.Service{}
+	case schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}:
+		typed = &corev1.ConfigMap{}
+	case schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}:
+		typed = &appsv1.Deployment{}
+	case schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "DaemonSet"}:
+		typed = &appsv1.DaemonSet{}
+	case schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "StatefulSet"}:
+		typed = &appsv1.StatefulSet{}
+	case schema.GroupVersionKind{Group: "batch", Version: "v1", Kind: "Job"}:
+		typed = &batchv1.Job{}
+	case schema.GroupVersionKind{Group: "certificates.k8s.io", Version: "v1", Kind: "CertificateSigningRequest"}:
+		typed = &certsapi.CertificateSigningRequest{}
+	}
+	if typed != nil {
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), typed); err != nil {
+			return pkgerrors.Wrapf(err, "Error converting the resource %s %s", obj.GetKind(), obj.GetName())
+		}
+	}
+	switch r := typed.(type) {
+	case *corev1.Pod:
+		s.PodStatuses = append(s.PodStatuses, *r)
+	case *corev1.Service:
+		s.ServiceStatuses = append(s.ServiceStatuses, *r)
+	case *corev1.ConfigMap:
+		// Only the metadata of the config maps is reported
+		s.ConfigMapStatuses = append(s.ConfigMapStatuses, corev1.ConfigMap{TypeMeta: r.TypeMeta, ObjectMeta: r.ObjectMeta})
+	case *appsv1.Deployment:
+		s.DeploymentStatuses = append(s.DeploymentStatuses, *r)
+	case *appsv1.DaemonSet:
+		s.DaemonSetStatuses = append(s.DaemonSetStatuses, *r)
+	case *appsv1.StatefulSet:
+		s.StatefulSetStatuses = append(s.StatefulSetStatuses, *r)
+	case *batchv1.Job:
+		s.JobStatuses = append(s.JobStatuses, *r)
+	case *certsapi.CertificateSigningRequest:
+		s.CsrStatuses = append(s.CsrStatuses, *r)
+	default:
+		b, err := json.Marshal(obj)
+		if err != nil {
+			return pkgerrors.Wrapf(err, "Error encoding the resource %s %s", obj.GetKind(), obj.GetName())
+		}
+		gvk := obj.GroupVersionKind()
+		s.ResourceStatuses = append(s.ResourceStatuses, rb.ResourceStatus{Group: gvk.Group, Version: gvk.Version,
+			Kind: gvk.Kind, Name: obj.GetName(), Namespace: obj.GetNamespace(), Res: b})
+	}
+	return nil
+}
+
+// StatusCRName returns the name of the status CR, which is the label of the resources it tracks
+func StatusCRName(statusCR []byte) (string, error) {
+	rbState := &rb.ResourceBundleState{}
+	if err := yaml.Unmarshal(statusCR, rbState); err != nil {
+		return "", pkgerrors.Wrap(err, "Error decoding the status CR")
+	}
+	return rbState.GetName(), nil
+}
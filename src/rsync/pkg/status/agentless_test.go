@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package status_test
+
+import (
+	"testing"
+
+	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/status"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func object(apiVersion, kind, name string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion(apiVersion)
+	u.SetKind(kind)
+	u.SetName(name)
+	u.SetNamespace("default")
+	u.SetLabels(map[string]string{"emco/deployment-id": "1234-app1"})
+	u.SetAnnotations(map[string]string{"kubectl.kubernetes.io/last-applied-configuration": "{}"})
+	return u
+}
+
+func TestNewResourceBundleState(t *testing.T) {
+	cr, err := status.GetStatusCR("1234-app1", status.PreInstallHookLabel, "default")
+	if err != nil {
+		t.Fatalf("GetStatusCR failed: %v", err)
+	}
+	name, err := status.StatusCRName(cr)
+	if err != nil || name != "1234-app1" {
+		t.Fatalf("Unexpected status CR name %s: %v", name, err)
+	}
+	pod := object("v1", "Pod", "web-1")
+	unstructured.SetNestedField(pod.Object, "Running", "status", "phase")
+	deployment := observe(t)
+	objs := []*unstructured.Unstructured{
+		object("v1", "Pod", "web-2"),
+		pod,
+		deployment,
+		object("v1", "ConfigMap", "cm1"),
+		object("rbac.authorization.k8s.io/v1", "Role", "role1"),
+	}
+	rbState, err := status.NewResourceBundleState(cr, objs)
+	if err != nil {
+		t.Fatalf("NewResourceBundleState failed: %v", err)
+	}
+	if rbState.GetName() != "1234-app1" || rbState.GetLabels()[status.PreInstallHookLabel] != "true" {
+		t.Errorf("Unexpected metadata %v", rbState.ObjectMeta)
+	}
+	s := rbState.Status
+	if len(s.PodStatuses) != 2 || s.PodStatuses[0].Name != "web-1" || s.PodStatuses[0].Status.Phase != "Running" {
+		t.Errorf("Unexpected pod statuses %v", s.PodStatuses)
+	}
+	if s.PodStatuses[0].Annotations["kubectl.kubernetes.io/last-applied-configuration"] != "" {
+		t.Errorf("Last applied configuration not cleared")
+	}
+	if len(s.DeploymentStatuses) != 1 || s.DeploymentStatuses[0].Status.ReadyReplicas != 2 {
+		t.Errorf("Unexpected deployment statuses %v", s.DeploymentStatuses)
+	}
+	if len(s.ConfigMapStatuses) != 1 || len(s.ServiceStatuses) != 0 {
+		t.Errorf("Unexpected config map and service statuses %v %v", s.ConfigMapStatuses, s.ServiceStatuses)
+	}
+	if len(s.ResourceStatuses) != 1 || s.ResourceStatuses[0].Group != "rbac.authorization.k8s.io" ||
+		s.ResourceStatuses[0].Kind != "Role" || len(s.ResourceStatuses[0].Res) == 0 {
+		t.Errorf("Unexpected resource statuses %v", s.ResourceStatuses)
+	}
+	// The objects of the informers are not modified
+	if pod.GetAnnotations()["kubectl.kubernetes.io/last-applied-configuration"] != "{}" {
+		t.Errorf("Object modified")
+	}
+}
@@ -0,0 +1,359 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package status
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"sync"
+
+	pkgerrors "github.com/pkg/errors"
+	rb "gitlab.com/project-emco/core/emco-base/src/monitor/pkg/apis/k8splugin/v1alpha1"
+	"gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/appcontext"
+	log "gitlab.com/project-emco/core/emco-base/src/orchestrator/pkg/infra/logutils"
+	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/internal/utils"
+	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/types"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// DesiredHashAnnotation holds the hash of the desired state of a resource at apply time
+const DesiredHashAnnotation string = "emco/desired-hash"
+
+const lastAppliedAnnotation string = "kubectl.kubernetes.io/last-applied-configuration"
+
+// DriftRemediator re-applies the desired state of the resources of an app on a cluster
+type DriftRemediator func(ctx context.Context, acID, app, cluster string, resources []string) error
+
+var driftRemediator DriftRemediator
+
+// remediations in progress, keyed by acID+app+cluster
+var remediations sync.Map
+
+// RegisterDriftRemediator registers the function used to remediate drifted resources
+// of the Composite Apps that have the remediate drift policy
+func RegisterDriftRemediator(r DriftRemediator) {
+	driftRemediator = r
+}
+
+// desiredState returns the part of a resource that is compared to detect drift.
+// Server managed fields (metadata other than labels and annotations, status) are left out.
+func desiredState(obj map[string]interface{}) map[string]interface{} {
+	state := make(map[string]interface{})
+	for k, v := range obj {
+		if k == "apiVersion" || k == "kind" || k == "metadata" || k == "status" {
+			continue
+		}
+		state[k] = v
+	}
+	u := unstructured.Unstructured{Object: obj}
+	metadata := make(map[string]interface{})
+	if labels := u.GetLabels(); len(labels) > 0 {
+		metadata["labels"] = labels
+	}
+	annotations := u.GetAnnotations()
+	delete(annotations, DesiredHashAnnotation)
+	delete(annotations, lastAppliedAnnotation)
+	if len(annotations) > 0 {
+		metadata["annotations"] = annotations
+	}
+	if len(metadata) > 0 {
+		state["metadata"] = metadata
+	}
+	return state
+}
+
+// hashState returns the sha256 hash of the JSON encoding of the state.
+// Map keys are sorted by the JSON encoder, which makes the hash stable.
+func hashState(state interface{}) (string, error) {
+	b, err := json.Marshal(state)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// The fields whose values are resource quantities, and the fields which are maps of
+// quantities. The cluster may report a quantity in another form, e.g. 0.5 as 500m.
+var (
+	quantityFields    = map[string]bool{"sizeLimit": true}
+	quantityMapFields = map[string]bool{"limits": true, "requests": true, "hard": true, "capacity": true,
+		"max": true, "min": true, "default": true, "defaultRequest": true}
+)
+
+// project returns the values of the observed object found at the paths present
+// in the desired object. Fields defaulted or added by the cluster are ignored.
+// The observed values equal to the desired ones in another form are replaced by
+// the desired ones, so that they hash the same. quantities is true if the values
+// of the desired map are resource quantities.
+func project(desired, observed interface{}, quantities bool) interface{} {
+	switch d := desired.(type) {
+	case map[string]interface{}:
+		o, ok := observed.(map[string]interface{})
+		if !ok {
+			return observed
+		}
+		p := make(map[string]interface{})
+		for k, dv := range d {
+			ov, found := o[k]
+			if !found {
+				// Zero values are not kept by the cluster, or not reported by the monitor
+				if isZero(dv) {
+					p[k] = dv
+				}
+				continue
+			}
+			if (quantities || quantityFields[k]) && equalQuantities(dv, ov) {
+				p[k] = dv
+				continue
+			}
+			p[k] = project(dv, ov, quantityMapFields[k])
+		}
+		return p
+	case []interface{}:
+		o, ok := observed.([]interface{})
+		if !ok || len(o) != len(d) {
+			return observed
+		}
+		p := make([]interface{}, len(d))
+		for i := range d {
+			p[i] = project(d[i], o[i], false)
+		}
+		return p
+	default:
+		return observed
+	}
+}
+
+// isZero returns true if the value is the zero value of its type, which is omitted
+// by the cluster and by the typed objects reported by the monitor
+func isZero(v interface{}) bool {
+	switch t := v.(type) {
+	case nil:
+		return true
+	case map[string]interface{}:
+		return len(t) == 0
+	case []interface{}:
+		return len(t) == 0
+	case string:
+		return t == ""
+	case bool:
+		return !t
+	case float64:
+		return t == 0
+	case int64:
+		return t == 0
+	}
+	return false
+}
+
+// equalQuantities returns true if both values are the same resource quantity
+func equalQuantities(a, b interface{}) bool {
+	qa, err := toQuantity(a)
+	if err != nil {
+		return false
+	}
+	qb, err := toQuantity(b)
+	if err != nil {
+		return false
+	}
+	return qa.Cmp(qb) == 0
+}
+
+func toQuantity(v interface{}) (resource.Quantity, error) {
+	switch t := v.(type) {
+	case string:
+		return resource.ParseQuantity(t)
+	case float64:
+		return resource.ParseQuantity(strconv.FormatFloat(t, 'f', -1, 64))
+	case int64:
+		return *resource.NewQuantity(t, resource.DecimalSI), nil
+	}
+	return resource.Quantity{}, pkgerrors.Errorf("%v is not a quantity", v)
+}
+
+// SetDesiredHash annotates the resource with the hash of its desired state
+func SetDesiredHash(unstruct *unstructured.Unstructured) error {
+	hash, err := hashState(desiredState(unstruct.Object))
+	if err != nil {
+		return err
+	}
+	annotations := unstruct.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[DesiredHashAnnotation] = hash
+	unstruct.SetAnnotations(annotations)
+	return nil
+}
+
+// IsDrifted compares an observed resource with the desired resource from the AppContext.
+// The observed resource is reduced to the fields of the desired resource and its hash
+// is compared with the hash recorded at apply time.
+func IsDrifted(desired []byte, observed *unstructured.Unstructured) (bool, error) {
+	hash, ok := observed.GetAnnotations()[DesiredHashAnnotation]
+	if !ok {
+		return false, nil
+	}
+	// Tag the desired resource in the same way as when it was applied
+	b, err := TagResource(desired, observed.GetLabels()["emco/deployment-id"])
+	if err != nil {
+		return false, err
+	}
+	var d map[string]interface{}
+	if err := json.Unmarshal(b, &d); err != nil {
+		return false, err
+	}
+	// Use the JSON representation of the observed resource, so that values compare the same way
+	ob, err := observed.MarshalJSON()
+	if err != nil {
+		return false, err
+	}
+	var o map[string]interface{}
+	if err := json.Unmarshal(ob, &o); err != nil {
+		return false, err
+	}
+	h, err := hashState(project(desiredState(d), desiredState(o), false))
+	if err != nil {
+		return false, err
+	}
+	return h != hash, nil
+}
+
+type observedResource struct {
+	kind   string
+	object *unstructured.Unstructured
+}
+
+// observedResources returns the objects reported in the ResourceBundleState
+func observedResources(rbData *rb.ResourceBundleState) []observedResource {
+	resources := make([]observedResource, 0)
+	add := func(kind string, v interface{}) {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return
+		}
+		u := &unstructured.Unstructured{}
+		if err := u.UnmarshalJSON(b); err != nil {
+			// The typed objects may be reported without a kind
+			var obj map[string]interface{}
+			if err := json.Unmarshal(b, &obj); err != nil {
+				return
+			}
+			u.Object = obj
+		}
+		resources = append(resources, observedResource{kind: kind, object: u})
+	}
+	for i := range rbData.Status.PodStatuses {
+		add("Pod", &rbData.Status.PodStatuses[i])
+	}
+	for i := range rbData.Status.ServiceStatuses {
+		add("Service", &rbData.Status.ServiceStatuses[i])
+	}
+	for i := range rbData.Status.ConfigMapStatuses {
+		add("ConfigMap", &rbData.Status.ConfigMapStatuses[i])
+	}
+	for i := range rbData.Status.DeploymentStatuses {
+		add("Deployment", &rbData.Status.DeploymentStatuses[i])
+	}
+	for i := range rbData.Status.DaemonSetStatuses {
+		add("DaemonSet", &rbData.Status.DaemonSetStatuses[i])
+	}
+	for i := range rbData.Status.JobStatuses {
+		add("Job", &rbData.Status.JobStatuses[i])
+	}
+	for i := range rbData.Status.StatefulSetStatuses {
+		add("StatefulSet", &rbData.Status.StatefulSetStatuses[i])
+	}
+	for i := range rbData.Status.CsrStatuses {
+		add("CertificateSigningRequest", &rbData.Status.CsrStatuses[i])
+	}
+	for _, r := range rbData.Status.ResourceStatuses {
+		u := &unstructured.Unstructured{}
+		if err := u.UnmarshalJSON(r.Res); err != nil {
+			continue
+		}
+		resources = append(resources, observedResource{kind: r.Kind, object: u})
+	}
+	return resources
+}
+
+// DetectDrift compares the resources reported for an app on a cluster with their desired
+// state and records the result in the status AppContext. Drifted resources are re-applied
+// if the Composite App has the remediate drift policy.
+func DetectDrift(ctx context.Context, acID, app, cluster string, rbData *rb.ResourceBundleState) {
+	acUtils, err := utils.NewAppContextReference(ctx, acID)
+	if err != nil {
+		return
+	}
+	refs := make(map[string]utils.AppContextReference)
+	drifted := make(map[string][]string)
+	for _, r := range observedResources(rbData) {
+		if _, ok := r.object.GetAnnotations()[DesiredHashAnnotation]; !ok {
+			// Not applied by rsync, e.g. Pods created by a Deployment
+			continue
+		}
+		name := r.object.GetName() + "+" + r.kind
+		// Find the AppContext that last applied the resource
+		refID, err := acUtils.GetResourceReference(ctx, app, cluster, name)
+		if err != nil {
+			continue
+		}
+		ref, ok := refs[refID]
+		if !ok {
+			ref, err = utils.NewAppContextReference(ctx, refID)
+			if err != nil {
+				continue
+			}
+			refs[refID] = ref
+		}
+		desired, _, err := ref.GetRes(ctx, name, app, cluster)
+		if err != nil {
+			continue
+		}
+		d, err := IsDrifted(desired, r.object)
+		if err != nil {
+			log.Error("::Error detecting drift::", log.Fields{"acID": acID, "app": app, "cluster": cluster, "resource": name, "err": err})
+			continue
+		}
+		acUtils.SetResourceReadyStatus(ctx, app, cluster, name, string(types.DriftedStatus), d)
+		if d {
+			log.Warn("::Resource drifted from the desired state::", log.Fields{"acID": acID, "app": app, "cluster": cluster, "resource": name})
+			drifted[refID] = append(drifted[refID], name)
+		}
+	}
+
+	for refID, resources := range drifted {
+		ref := refs[refID]
+		h := ref.GetAppContextHandle()
+		meta, err := h.GetCompositeAppMeta(ctx)
+		if err != nil || meta.DriftPolicy != appcontext.DriftPolicyRemediate {
+			continue
+		}
+		remediateDrift(refID, app, cluster, resources)
+	}
+}
+
+// remediateDrift re-applies the drifted resources, unless a remediation is already in progress
+func remediateDrift(acID, app, cluster string, resources []string) {
+	if driftRemediator == nil {
+		log.Warn("::No drift remediator registered::", log.Fields{"acID": acID, "app": app, "cluster": cluster})
+		return
+	}
+	key := acID + "+" + app + "+" + cluster
+	if _, running := remediations.LoadOrStore(key, true); running {
+		return
+	}
+	go func() {
+		defer remediations.Delete(key)
+		log.Info("::Re-applying drifted resources::", log.Fields{"acID": acID, "app": app, "cluster": cluster, "resources": resources})
+		if err := driftRemediator(context.Background(), acID, app, cluster, resources); err != nil {
+			log.Error("::Error re-applying drifted resources::", log.Fields{"acID": acID, "app": app, "cluster": cluster, "resources": resources, "err": err})
+		}
+	}()
+}
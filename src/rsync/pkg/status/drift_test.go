@@ -0,0 +1,162 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package status_test
+
+import (
+	"testing"
+
+	"gitlab.com/project-emco/core/emco-base/src/rsync/pkg/status"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var desiredDeployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    app: web
+spec:
+  replicas: 2
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      containers:
+      - name: web
+        image: nginx:1.21
+        ports:
+        - containerPort: 80
+`
+
+// observe returns the resource as applied to the cluster, with fields added by the cluster
+func observe(t *testing.T) *unstructured.Unstructured {
+	b, err := status.TagResource([]byte(desiredDeployment), "1234-app1")
+	if err != nil {
+		t.Fatalf("TagResource failed: %v", err)
+	}
+	u := &unstructured.Unstructured{}
+	if err := u.UnmarshalJSON(b); err != nil {
+		t.Fatalf("UnmarshalJSON failed: %v", err)
+	}
+	if _, ok := u.GetAnnotations()[status.DesiredHashAnnotation]; !ok {
+		t.Fatalf("Desired hash annotation not set")
+	}
+	u.SetUID("0a1b2c3d")
+	u.SetResourceVersion("42")
+	unstructured.SetNestedField(u.Object, "RollingUpdate", "spec", "strategy", "type")
+	unstructured.SetNestedField(u.Object, int64(2), "status", "readyReplicas")
+	containers, _, _ := unstructured.NestedSlice(u.Object, "spec", "template", "spec", "containers")
+	containers[0].(map[string]interface{})["imagePullPolicy"] = "IfNotPresent"
+	unstructured.SetNestedSlice(u.Object, containers, "spec", "template", "spec", "containers")
+	return u
+}
+
+func TestNoDrift(t *testing.T) {
+	drifted, err := status.IsDrifted([]byte(desiredDeployment), observe(t))
+	if err != nil {
+		t.Fatalf("IsDrifted failed: %v", err)
+	}
+	if drifted {
+		t.Errorf("Fields defaulted by the cluster were reported as drift")
+	}
+}
+
+func TestDrift(t *testing.T) {
+	u := observe(t)
+	unstructured.SetNestedField(u.Object, int64(5), "spec", "replicas")
+	drifted, err := status.IsDrifted([]byte(desiredDeployment), u)
+	if err != nil {
+		t.Fatalf("IsDrifted failed: %v", err)
+	}
+	if !drifted {
+		t.Errorf("Modified replicas were not reported as drift")
+	}
+
+	u = observe(t)
+	labels := u.GetLabels()
+	delete(labels, "app")
+	u.SetLabels(labels)
+	drifted, err = status.IsDrifted([]byte(desiredDeployment), u)
+	if err != nil {
+		t.Fatalf("IsDrifted failed: %v", err)
+	}
+	if !drifted {
+		t.Errorf("Removed label was not reported as drift")
+	}
+}
+
+func TestNoHashAnnotation(t *testing.T) {
+	u := observe(t)
+	u.SetAnnotations(nil)
+	unstructured.SetNestedField(u.Object, int64(5), "spec", "replicas")
+	drifted, err := status.IsDrifted([]byte(desiredDeployment), u)
+	if err != nil {
+		t.Fatalf("IsDrifted failed: %v", err)
+	}
+	if drifted {
+		t.Errorf("Resource without the desired hash annotation was reported as drift")
+	}
+}
+
+var desiredPod = `apiVersion: v1
+kind: Pod
+metadata:
+  name: worker
+spec:
+  hostNetwork: false
+  terminationGracePeriodSeconds: 0
+  containers:
+  - name: worker
+    image: busybox
+    resources:
+      limits:
+        cpu: 0.5
+        memory: 1Gi
+      requests:
+        cpu: "1"
+        memory: 1024Mi
+`
+
+func TestNoDriftNormalizedValues(t *testing.T) {
+	b, err := status.TagResource([]byte(desiredPod), "1234-app1")
+	if err != nil {
+		t.Fatalf("TagResource failed: %v", err)
+	}
+	u := &unstructured.Unstructured{}
+	if err := u.UnmarshalJSON(b); err != nil {
+		t.Fatalf("UnmarshalJSON failed: %v", err)
+	}
+	// The zero values are omitted and the quantities are normalized in the reported status
+	unstructured.RemoveNestedField(u.Object, "spec", "hostNetwork")
+	unstructured.RemoveNestedField(u.Object, "spec", "terminationGracePeriodSeconds")
+	containers, _, _ := unstructured.NestedSlice(u.Object, "spec", "containers")
+	containers[0].(map[string]interface{})["resources"] = map[string]interface{}{
+		"limits":   map[string]interface{}{"cpu": "500m", "memory": "1024Mi"},
+		"requests": map[string]interface{}{"cpu": "1", "memory": "1Gi"},
+	}
+	unstructured.SetNestedSlice(u.Object, containers, "spec", "containers")
+
+	drifted, err := status.IsDrifted([]byte(desiredPod), u)
+	if err != nil {
+		t.Fatalf("IsDrifted failed: %v", err)
+	}
+	if drifted {
+		t.Errorf("Omitted zero values or normalized quantities were reported as drift")
+	}
+
+	// A changed quantity is a drift
+	containers[0].(map[string]interface{})["resources"].(map[string]interface{})["limits"] = map[string]interface{}{"cpu": "600m", "memory": "1Gi"}
+	unstructured.SetNestedSlice(u.Object, containers, "spec", "containers")
+	drifted, err = status.IsDrifted([]byte(desiredPod), u)
+	if err != nil {
+		t.Fatalf("IsDrifted failed: %v", err)
+	}
+	if !drifted {
+		t.Errorf("Modified cpu limit was not reported as drift")
+	}
+}
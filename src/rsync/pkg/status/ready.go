@@ -244,3 +244,18 @@ func (c *ReadyChecker) JobSuccess(job *batchv1.Job) bool {
 	logutils.Info("Job Status:", logutils.Fields{"Jobs active": job.Status.Active, "Jobs failed": job.Status.Failed, "Jobs Succeded": job.Status.Succeeded})
 	return false
 }
+
+// PodFailed returns true if the pod has failed
+func (c *ReadyChecker) PodFailed(pod *corev1.Pod) bool {
+	return pod.Status.Phase == corev1.PodFailed
+}
+
+// JobFailed returns true if the job has failed
+func (c *ReadyChecker) JobFailed(job *batchv1.Job) bool {
+	for _, c := range job.Status.Conditions {
+		if c.Type == batchv1.JobFailed && c.Status == "True" {
+			return true
+		}
+	}
+	return false
+}
@@ -54,6 +54,9 @@ func HandleResourcesStatus(ctx context.Context, acID, app, cluster string, rbDat
 
 	UpdateAppReadyStatus(ctx, acID, app, cluster, rbData)
 
+	// Compare the observed resources with the desired state
+	DetectDrift(ctx, acID, app, cluster, rbData)
+
 	// Inform Rsync dependency management of the update
 	go depend.ResourcesReady(ctx, acID, app, cluster)
 
@@ -123,6 +126,7 @@ func updateResourcesStatus(ctx context.Context, acID, app, cluster string, rbDat
 		if ok {
 			// Hooks are checked for Success Status
 			acUtils.SetResourceReadyStatus(ctx, app, cluster, name, string(types.SuccessStatus), readyChecker.JobSuccess(&j))
+			acUtils.SetResourceReadyStatus(ctx, app, cluster, name, string(types.FailedStatus), readyChecker.JobFailed(&j))
 			// No need to consider hook resources for ready status
 			continue
 		}
@@ -144,6 +148,7 @@ func updateResourcesStatus(ctx context.Context, acID, app, cluster string, rbDat
 		if ok {
 			// Hooks are checked for Success Status
 			acUtils.SetResourceReadyStatus(ctx, app, cluster, name, string(types.SuccessStatus), readyChecker.PodSuccess(&p))
+			acUtils.SetResourceReadyStatus(ctx, app, cluster, name, string(types.FailedStatus), readyChecker.PodFailed(&p))
 			// No need to consider hook resources for ready status
 			continue
 		}
@@ -261,6 +266,10 @@ func TagResource(res []byte, label string) ([]byte, error) {
 	// If a PodSpec is found, the label will be added to it too.
 	//connector.TagPodsIfPresent(unstruct, client.GetInstanceID())
 	TagPodsIfPresent(unstruct, label)
+	// Record the hash of the desired state, used to detect configuration drift
+	if err := SetDesiredHash(unstruct); err != nil {
+		return nil, err
+	}
 	b, err := unstruct.MarshalJSON()
 	if err != nil {
 		return nil, err
@@ -44,6 +44,7 @@ const (
 	OpDelete
 	OpRead
 	OpCreate
+	OpValidate
 )
 
 // ResourceStatusType defines types of resource statuses
@@ -53,10 +54,25 @@ type ResourceStatusType string
 const (
 	ReadyStatus   ResourceStatusType = "resready"
 	SuccessStatus ResourceStatusType = "ressuccess"
+	DriftedStatus ResourceStatusType = "resdrifted"
+	FailedStatus  ResourceStatusType = "resfailed"
 )
 
+// Helm hook delete policies
+const (
+	HookBeforeCreation string = "before-hook-creation"
+	HookSucceeded      string = "hook-succeeded"
+	HookFailed         string = "hook-failed"
+)
+
+// Hook is the weight and delete policies of a hook resource
+type Hook struct {
+	Weight         int      `json:"weight"`
+	DeletePolicies []string `json:"deletepolicies,omitempty"`
+}
+
 func (d RsyncOperation) String() string {
-	return [...]string{"Apply", "Delete", "Read"}[d]
+	return [...]string{"Apply", "Delete", "Read", "Create", "Validate"}[d]
 }
 
 // StateChange represents a state change rsync handles
@@ -195,8 +211,13 @@ type Cluster struct {
 	ResOrder   []string                `json:"reorder,omitempty"`
 	Resources  map[string]*AppResource `json:"resources,omitempty"`
 	Dependency map[string][]string     `json:"resdependency,omitempty"`
+	Hooks      map[string]*Hook        `json:"reshooks,omitempty"`
 	// Needed to suport updates
 	Skip bool `json:"bool,omitempty"`
+	// The Helm release of the app is upgraded on the cluster by the update
+	UpgradeRelease bool `json:"-"`
+	// The app is removed from the cluster by the update
+	Removed bool `json:"-"`
 }
 
 // App is an app within a composite app
@@ -230,6 +251,22 @@ type ReferenceProvider interface {
 	DeleteConfig(ctx context.Context, config interface{}) error
 }
 
+// DryRunProvider is implemented by the client providers which can validate
+// resources on the cluster without changing them
+type DryRunProvider interface {
+	DryRun(ctx context.Context, name string, content []byte) error
+}
+
+// ReleaseProvider is implemented by the client providers which can deploy an app as
+// a Helm release on the cluster. The release is named after the app.
+type ReleaseProvider interface {
+	// InstallRelease installs or upgrades the release with the resources of the
+	// AppContext, or rolls it back to the revision of the AppContext
+	InstallRelease(ctx context.Context, release appcontext.HelmRelease, resources [][]byte) error
+	// UninstallRelease uninstalls the release and deletes its resources
+	UninstallRelease(ctx context.Context) error
+}
+
 // Client Provider provides functionality to interface with the cluster
 type ClientProvider interface {
 	ResourceProvider
@@ -238,9 +275,49 @@ type ClientProvider interface {
 	CleanClientProvider() error
 }
 
+// WrappedProvider is implemented by the client providers wrapping another
+// client provider, which may implement the optional provider interfaces
+type WrappedProvider interface {
+	Unwrap() ClientProvider
+}
+
+// AsDryRunProvider returns the client provider, or the provider it wraps, as a DryRunProvider
+func AsDryRunProvider(cl ClientProvider) (DryRunProvider, bool) {
+	for cl != nil {
+		if v, ok := cl.(DryRunProvider); ok {
+			return v, true
+		}
+		w, ok := cl.(WrappedProvider)
+		if !ok {
+			break
+		}
+		cl = w.Unwrap()
+	}
+	return nil, false
+}
+
+// AsReleaseProvider returns the client provider, or the provider it wraps, as a ReleaseProvider
+func AsReleaseProvider(cl ClientProvider) (ReleaseProvider, bool) {
+	for cl != nil {
+		if v, ok := cl.(ReleaseProvider); ok {
+			return v, true
+		}
+		w, ok := cl.(WrappedProvider)
+		if !ok {
+			break
+		}
+		cl = w.Unwrap()
+	}
+	return nil, false
+}
+
 // Connection is interface for connection
 type Connector interface {
 	GetClientProviders(ctx context.Context, app, cluster, level, namespace string) (ClientProvider, error)
+	// HasClusterLabel returns true if the cluster <provider>+<cluster> is tagged with the label
+	HasClusterLabel(ctx context.Context, cluster, label string) (bool, error)
+	// GetClusterKvPairs returns the key value pairs of the cluster, nil if they don't exist
+	GetClusterKvPairs(ctx context.Context, cluster, kvpair string) ([]map[string]interface{}, error)
 }
 
 // AppContextQueueElement element in per AppContext Queue
@@ -250,6 +327,8 @@ type AppContextQueueElement struct {
 	UCID string `json:"uCID,omitempty"`
 	// Status - Pending, Done, Error, skip
 	Status string `json:"status"`
+	// Trace context of the request that queued the event
+	TraceContext map[string]string `json:"traceContext,omitempty"`
 }
 
 // AppContextQueue per AppContext queue
@@ -79,6 +79,13 @@ func CreateCompApp(ctx context.Context, ca CompositeApp) (string, error) {
 			if err != nil {
 				return "", pkgerrors.Wrap(err, "Error Adding resdependency")
 			}
+			if len(cluster.Hooks) > 0 {
+				reshooks, err := json.Marshal(map[string]map[string]*Hook{"reshooks": cluster.Hooks})
+				_, err = appCtx.AddInstruction(ctx, c, "resource", appcontext.HookInstruction, string(reshooks))
+				if err != nil {
+					return "", pkgerrors.Wrap(err, "Error Adding reshooks")
+				}
+			}
 			for _, res := range cluster.Resources {
 				_, err = appCtx.AddResource(ctx, c, res.Name, res.Data)
 				if err != nil {
@@ -159,6 +166,18 @@ func ReadAppContext(ctx context.Context, contextID interface{}) (CompositeApp, e
 				}
 			}
 			clusterList[cluster] = &Cluster{Name: cluster, Resources: resList, ResOrder: aov["resorder"], Dependency: dov["resdependency"]}
+			reshooks, err := ac.GetResourceInstruction(ctx, app, cluster, appcontext.HookInstruction)
+			if err != nil {
+				// Not all applications have hooks
+				continue
+			}
+			var hov map[string]map[string]*Hook
+			err = json.Unmarshal([]byte(reshooks.(string)), &hov)
+			if err != nil {
+				logutils.Error("Res hooks Marshalling error, ignoring ", logutils.Fields{"cluster": cluster, "hooks": reshooks.(string)})
+				continue
+			}
+			clusterList[cluster].Hooks = hov["reshooks"]
 		}
 		dep, err := ac.GetAppLevelInstruction(ctx, app, "dependency")
 		depList := make(map[string]*Criteria)
@@ -0,0 +1,37 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var backupFile string
+
+// backupCmd represents the backup command
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Save a backup of the EMCO resources and AppContexts to a file",
+	Run: func(cmd *cobra.Command, args []string) {
+		var c RestyClient
+		if len(token) > 0 {
+			c = NewRestClientToken(token[0])
+		} else {
+			c = NewRestClient()
+		}
+		if backupFile == "" {
+			backupFile = fmt.Sprintf("emco-backup-%s.tar.gz", time.Now().UTC().Format("20060102T150405Z"))
+		}
+		c.RestClientBackup(backupFile)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(backupCmd)
+	backupCmd.Flags().StringVarP(&backupFile, "output", "o", "", "Filename of the backup (default emco-backup-<time>.tar.gz)")
+	backupCmd.Flags().StringSliceVarP(&token, "token", "t", []string{}, "Token for EMCO API")
+}
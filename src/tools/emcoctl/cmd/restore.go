@@ -0,0 +1,41 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022 Intel Corporation
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var restoreKubeconfigs []string
+
+// restoreCmd represents the restore command
+var restoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Restore the EMCO resources and AppContexts from a backup file",
+	Run: func(cmd *cobra.Command, args []string) {
+		var c RestyClient
+		if len(token) > 0 {
+			c = NewRestClientToken(token[0])
+		} else {
+			c = NewRestClient()
+		}
+		if len(inputFiles) != 1 {
+			fmt.Println("Use: 'emcoctl restore -f <backup file>'")
+			return
+		}
+		err := c.RestClientRestore(inputFiles[0], restoreKubeconfigs)
+		if err != nil && err.Error() != "API Error" {
+			fmt.Println("Restore: Error: ", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(restoreCmd)
+	restoreCmd.Flags().StringSliceVarP(&inputFiles, "filename", "f", []string{}, "Filename of the backup")
+	restoreCmd.Flags().StringSliceVarP(&restoreKubeconfigs, "kubeconfig", "k", []string{}, "Kubeconfig replacing the kubeconfig of a cluster, as <provider>+<cluster>=<kubeconfig file>")
+	restoreCmd.Flags().StringSliceVarP(&token, "token", "t", []string{}, "Token for EMCO API")
+}
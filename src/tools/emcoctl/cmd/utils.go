@@ -6,6 +6,7 @@ package cmd
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -444,6 +445,90 @@ func (r RestyClient) RestClientGetAnchor(anchor string) error {
 	return nil
 }
 
+// RestClientBackup saves a backup of EMCO to the file
+func (r RestyClient) RestClientBackup(file string) error {
+	url, err := GetURL("backup")
+	if err != nil {
+		return err
+	}
+	resp, err := r.client.R().
+		SetOutput(file).
+		Get(url)
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+	fmt.Println("---")
+	fmt.Println("GET  --> URL:", url)
+	fmt.Println("Response Code:", resp.StatusCode())
+	if resp.StatusCode() != http.StatusOK {
+		// The error message was written to the file
+		if b, err := ioutil.ReadFile(file); err == nil {
+			fmt.Println("Response:", string(b))
+		}
+		os.Remove(file)
+		return pkgerrors.Errorf("API Error")
+	}
+	fmt.Println("Backup saved to", file)
+	return nil
+}
+
+// RestClientRestore restores a backup of EMCO from the file. The kubeconfigs
+// replace the kubeconfigs of the clusters, in the format <provider>+<cluster>=<kubeconfig file>
+func (r RestyClient) RestClientRestore(file string, kubeconfigs []string) error {
+	f, name, err := getFile(file)
+	if err != nil {
+		return err
+	}
+	type kubeconfigRemap struct {
+		ClusterProvider string `json:"clusterProvider"`
+		Cluster         string `json:"cluster"`
+		Kubeconfig      string `json:"kubeconfig"`
+	}
+	var options struct {
+		Kubeconfigs []kubeconfigRemap `json:"kubeconfigs,omitempty"`
+	}
+	for _, k := range kubeconfigs {
+		s := strings.SplitN(k, "=", 2)
+		c := strings.SplitN(s[0], "+", 2)
+		if len(s) != 2 || len(c) != 2 {
+			return pkgerrors.Errorf("Invalid kubeconfig %s, use <provider>+<cluster>=<kubeconfig file>", k)
+		}
+		kc, err := ioutil.ReadFile(s[1])
+		if err != nil {
+			fmt.Println("Error reading file", "error", err, "filename", s[1])
+			return err
+		}
+		options.Kubeconfigs = append(options.Kubeconfigs, kubeconfigRemap{
+			ClusterProvider: c[0],
+			Cluster:         c[1],
+			Kubeconfig:      base64.StdEncoding.EncodeToString(kc),
+		})
+	}
+	metadata, err := json.Marshal(options)
+	if err != nil {
+		return err
+	}
+
+	url, err := GetURL("restore")
+	if err != nil {
+		return err
+	}
+	resp, err := r.client.R().
+		SetFileReader("file", name, bytes.NewReader(f)).
+		SetFormData(map[string]string{"metadata": string(metadata)}).
+		Post(url)
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+	printOutput(url, "POST", resp)
+	if resp.StatusCode() >= 200 && resp.StatusCode() <= 299 {
+		return nil
+	}
+	return pkgerrors.Errorf("API Error")
+}
+
 func getUpdateUrl(anchor string, body []byte) (string, error) {
 	var e emcoBody
 	err := json.Unmarshal(body, &e)
@@ -573,7 +658,7 @@ func GetURL(anchor string) (string, error) {
 			break
 		}
 		baseUrl = GetClmURL()
-	case "controllers":
+	case "controllers", "backup", "restore", "data-keys":
 		baseUrl = GetOrchestratorURL()
 	case "clm-controllers":
 		baseUrl = GetClmURL()